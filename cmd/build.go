@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/build"
+	"wb2-cli/internal/config"
+)
+
+var (
+	buildPath    string
+	buildJobs    int
+	buildRelease bool
+	buildDebug   bool
+	buildVerbose bool
+)
+
+// buildMaxWarnings 摘要中最多列出的警告数量
+const buildMaxWarnings = 20
+
+// buildTailLines 编译失败且没有识别出错误时输出的末尾行数
+const buildTailLines = 20
+
+// buildCmd represents the build command
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "编译项目",
+	Long: `在项目根目录中执行 SDK 的 make 编译流程。
+
+SDK 路径取自项目清单 wb2.yaml（env 模式下使用环境变量 BL60X_SDK_PATH 或配置文件），
+并行任务数默认为 CPU 核数。编译结束后汇总 GCC 和链接器输出中的错误和警告。
+
+示例:
+  wb2-cli build
+  wb2-cli build --release
+  wb2-cli build --debug -j4 --verbose`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runBuild,
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+
+	buildCmd.Flags().StringVarP(&buildPath, "path", "p", ".", "项目根目录")
+	buildCmd.Flags().IntVarP(&buildJobs, "jobs", "j", 0, "make 并行任务数（默认为 CPU 核数）")
+	buildCmd.Flags().BoolVar(&buildRelease, "release", false, "发布配置：开启 CONFIG_OPTIMIZATION_LEVEL_RELEASE")
+	buildCmd.Flags().BoolVar(&buildDebug, "debug", false, "调试配置：关闭 CONFIG_OPTIMIZATION_LEVEL_RELEASE")
+	buildCmd.Flags().BoolVar(&buildVerbose, "verbose", false, "输出完整的 make 输出")
+}
+
+func runBuild(cmd *cobra.Command, args []string) error {
+	if buildRelease && buildDebug {
+		return fmt.Errorf("--release 和 --debug 不能同时使用")
+	}
+	profile := build.ProfileDefault
+	if buildRelease {
+		profile = build.ProfileRelease
+	} else if buildDebug {
+		profile = build.ProfileDebug
+	}
+
+	projectDir, err := filepath.Abs(buildPath)
+	if err != nil {
+		return fmt.Errorf("解析项目路径失败: %v", err)
+	}
+	manifest, err := config.LoadManifest(projectDir)
+	if err != nil {
+		return err
+	}
+	sdk, err := projectSDKPath(manifest, projectDir)
+	if err != nil {
+		return err
+	}
+
+	jobs := buildJobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	fmt.Printf("🔨 编译 %s（%s，-j%d）\n", manifest.Name, profileName(profile), jobs)
+	fmt.Printf("📦 SDK 路径: %s\n\n", sdk)
+
+	opts := build.Options{
+		ProjectDir: projectDir,
+		SDKPath:    sdk,
+		Jobs:       jobs,
+		Profile:    profile,
+	}
+	if buildVerbose {
+		opts.Output = os.Stdout
+	}
+	if err := syncKVDefaults(projectDir, manifest.Name); err != nil {
+		return err
+	}
+	// 编译前的大小报告，供 wb2-cli size --diff 比较（make clean 会清空 build_out）
+	previous := snapshotSize(projectDir, manifest.Name)
+	result, err := build.Run(opts)
+	if err != nil {
+		return err
+	}
+	saveSizeSnapshot(projectDir, previous)
+
+	if buildVerbose {
+		fmt.Println()
+	}
+	printBuildSummary(os.Stdout, result, manifest.Name, projectDir, buildVerbose)
+	if result.Err != nil {
+		return fmt.Errorf("编译失败")
+	}
+	return nil
+}
+
+// projectSDKPath 返回编译项目使用的 SDK 路径：项目清单中的路径优先，
+// 其次为环境变量 BL60X_SDK_PATH，最后为配置文件或自动检测
+func projectSDKPath(manifest *config.Manifest, projectDir string) (string, error) {
+	sdk := manifest.ResolveSDKPath(projectDir)
+	source := "项目清单"
+	if sdk == "" {
+		sdk, source = os.Getenv("BL60X_SDK_PATH"), "环境变量 BL60X_SDK_PATH"
+	}
+	if sdk == "" {
+		var err error
+		if sdk, err = getSDKPath(); err != nil {
+			return "", fmt.Errorf("获取 SDK 路径失败: %v", err)
+		}
+		source = "配置文件"
+	}
+	if !isValidSDKPath(sdk) {
+		return "", fmt.Errorf("无效的 SDK 路径: %s（来自%s）", sdk, source)
+	}
+	return sdk, nil
+}
+
+// profileName 返回编译配置的显示名称
+func profileName(profile build.Profile) string {
+	if profile == build.ProfileDefault {
+		return "proj_config.mk 默认配置"
+	}
+	return string(profile)
+}
+
+// printBuildSummary 输出编译结果摘要
+func printBuildSummary(w io.Writer, result *build.Result, projectName, projectDir string, verbose bool) {
+	if result.Cleaned {
+		fmt.Fprintf(w, "🧹 编译配置已变化，已先执行 make clean\n")
+	}
+
+	errCount, warnCount := build.Count(result.Diagnostics)
+	var errs, warns []build.Diagnostic
+	for _, d := range result.Diagnostics {
+		if d.Severity == build.SeverityError {
+			errs = append(errs, d)
+		} else {
+			warns = append(warns, d)
+		}
+	}
+
+	if len(errs) > 0 {
+		fmt.Fprintf(w, "错误:\n")
+		for _, d := range errs {
+			fmt.Fprintf(w, "  %s: %s\n", d.Location(), d.Message)
+		}
+	}
+	if len(warns) > 0 {
+		fmt.Fprintf(w, "警告:\n")
+		for i, d := range warns {
+			if i == buildMaxWarnings {
+				fmt.Fprintf(w, "  ... 还有 %d 个警告（使用 --verbose 查看完整输出）\n", len(warns)-buildMaxWarnings)
+				break
+			}
+			fmt.Fprintf(w, "  %s: %s\n", d.Location(), d.Message)
+		}
+	}
+
+	if result.Err != nil {
+		// 没有识别出的错误（如 make 或脚本失败）时输出末尾的原始输出
+		if errCount == 0 && !verbose {
+			lines := strings.Split(strings.TrimRight(result.Output, "\n"), "\n")
+			if len(lines) > buildTailLines {
+				lines = lines[len(lines)-buildTailLines:]
+			}
+			fmt.Fprintf(w, "make 输出（最后 %d 行）:\n", len(lines))
+			for _, line := range lines {
+				fmt.Fprintf(w, "  %s\n", line)
+			}
+		}
+		fmt.Fprintf(w, "\n❌ 编译失败（%d 个错误，%d 个警告，用时 %.1fs）\n", errCount, warnCount, result.Duration.Seconds())
+		return
+	}
+
+	fmt.Fprintf(w, "\n✅ 编译成功（%d 个警告，用时 %.1fs）\n", warnCount, result.Duration.Seconds())
+	firmware := filepath.Join(projectDir, build.BuildDir, projectName+".bin")
+	if info, err := os.Stat(firmware); err == nil {
+		fmt.Fprintf(w, "📄 固件: %s（%s）\n", firmware, formatSize(int(info.Size())))
+	}
+}
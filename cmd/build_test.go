@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/build"
+)
+
+func TestPrintBuildSummary(t *testing.T) {
+	var diags []build.Diagnostic
+	diags = append(diags, build.Diagnostic{File: "demo/main.c", Line: 12, Column: 5, Severity: build.SeverityError, Message: "'x' undeclared"})
+	for i := 0; i < buildMaxWarnings+2; i++ {
+		diags = append(diags, build.Diagnostic{File: "demo/main.c", Line: 100 + i, Severity: build.SeverityWarning, Message: fmt.Sprintf("warning %d", i)})
+	}
+
+	var buf bytes.Buffer
+	printBuildSummary(&buf, &build.Result{Diagnostics: diags, Err: errors.New("exit status 2")}, "demo", t.TempDir(), false)
+	output := buf.String()
+	for _, want := range []string{
+		"错误:\n  demo/main.c:12:5: 'x' undeclared\n",
+		"  demo/main.c:100: warning 0\n",
+		"... 还有 2 个警告",
+		"❌ 编译失败（1 个错误，22 个警告",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "make 输出") {
+		t.Errorf("Expected no raw output when errors were found, got:\n%s", output)
+	}
+
+	// 没有识别出错误时输出 make 的末尾
+	buf.Reset()
+	printBuildSummary(&buf, &build.Result{Output: "make: *** No rule to make target 'all'.  Stop.\n", Err: errors.New("exit status 2")}, "demo", t.TempDir(), false)
+	if !strings.Contains(buf.String(), "make 输出（最后 1 行）:\n  make: *** No rule") {
+		t.Errorf("Expected raw make output, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	printBuildSummary(&buf, &build.Result{Cleaned: true}, "demo", t.TempDir(), false)
+	if !strings.Contains(buf.String(), "已先执行 make clean") || !strings.Contains(buf.String(), "✅ 编译成功（0 个警告") {
+		t.Errorf("Unexpected success summary:\n%s", buf.String())
+	}
+}
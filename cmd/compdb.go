@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/compdb"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/importer"
+)
+
+var (
+	compdbPath   string
+	compdbOutput string
+	compdbMake   bool
+)
+
+// compdbCmd represents the compdb command
+var compdbCmd = &cobra.Command{
+	Use:   "compdb",
+	Short: "生成 compile_commands.json（供 clangd 等工具使用）",
+	Long: `为项目生成 compile_commands.json，使 clangd 和静态分析工具能找到 SDK 的头文件和宏定义。
+
+默认根据项目使用的 SDK 组件（项目 Makefile 中的组件列表和 wb2.yaml 中的组件）查找 SDK 中
+各组件的 bouffalo.mk，推导每个源文件的头文件目录（COMPONENT_ADD_INCLUDEDIRS 导出给所有组件，
+COMPONENT_PRIV_INCLUDEDIRS 只用于本组件）和宏定义，SDK make_scripts_riscv 中 project.mk 和
+component_wrapper.mk 的宏定义用于所有组件，这些 makefile 中的条件按 proj_config.mk 的配置求值。推导只模拟 SDK make 的主要规则，--make 改为执行 make -n（不实际编译）
+并从输出中提取真实的编译命令，结果准确但需要 make 能够运行。
+
+示例:
+  wb2-cli compdb
+  wb2-cli compdb --make
+  clangd --query-driver='**/riscv64-unknown-elf-*'`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runCompdb,
+}
+
+func init() {
+	rootCmd.AddCommand(compdbCmd)
+
+	compdbCmd.Flags().StringVarP(&compdbPath, "path", "p", ".", "项目根目录")
+	compdbCmd.Flags().StringVarP(&compdbOutput, "output", "o", "", "输出文件（默认为项目根目录的 compile_commands.json）")
+	compdbCmd.Flags().BoolVar(&compdbMake, "make", false, "从 make -n 的输出中提取编译命令")
+}
+
+func runCompdb(cmd *cobra.Command, args []string) error {
+	projectDir, err := filepath.Abs(compdbPath)
+	if err != nil {
+		return fmt.Errorf("解析项目路径失败: %v", err)
+	}
+	manifest, err := config.LoadManifest(projectDir)
+	if err != nil {
+		return err
+	}
+	sdk, err := projectSDKPath(manifest, projectDir)
+	if err != nil {
+		return err
+	}
+
+	var entries []compdb.Entry
+	if compdbMake {
+		fmt.Printf("🔍 执行 make -n 提取编译命令...\n")
+		if entries, err = compdb.Capture(projectDir, sdk); err != nil {
+			return err
+		}
+	} else {
+		components := compdbComponents(projectDir, manifest)
+		result, err := compdb.Derive(compdb.Options{
+			ProjectDir:  projectDir,
+			ProjectName: manifest.Name,
+			SDKPath:     sdk,
+			Components:  components,
+			Config:      projectConfig(projectDir),
+		})
+		if err != nil {
+			return err
+		}
+		entries = result.Entries
+		fmt.Printf("🔍 根据 %d 个组件的 %s 推导编译命令\n", len(result.Components), compdb.ComponentMakefile)
+		if len(result.Missing) > 0 {
+			fmt.Printf("⚠️  SDK 中找不到组件: %s\n", strings.Join(result.Missing, ", "))
+		}
+	}
+
+	data, err := compdb.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	output := compdbOutput
+	if output == "" {
+		output = filepath.Join(projectDir, compdb.File)
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", output, err)
+	}
+	fmt.Printf("✅ 已生成 %s（%d 个源文件）\n", output, len(entries))
+	fmt.Printf("   clangd 需要 --query-driver='**/%s*' 才能找到工具链的系统头文件\n", compdb.DefaultToolPrefix)
+	return nil
+}
+
+// compdbComponents 返回项目使用的 SDK 组件：项目 Makefile 中的组件列表，以及 wb2.yaml 中的组件
+// 和额外组件解析出的 SDK 组件（含基础组件）
+func compdbComponents(projectDir string, manifest *config.Manifest) []string {
+	var names []string
+	if content, err := os.ReadFile(filepath.Join(projectDir, "Makefile")); err == nil {
+		_, _, lists := importer.ParseMakefile(string(content))
+		names = append(names, allLists(lists)...)
+	}
+	var selected []config.Component
+	if catalog, err := config.LoadComponents(); err == nil {
+		byName := map[string]config.Component{}
+		for _, comp := range catalog {
+			byName[comp.Name] = comp
+		}
+		for _, name := range manifest.Components {
+			if comp, ok := byName[name]; ok {
+				selected = append(selected, comp)
+			}
+		}
+	}
+	names = append(names, generator.SDKComponents(selected)...)
+	names = append(names, allLists(manifest.Extras)...)
+
+	seen := map[string]bool{manifest.Name: true}
+	var out []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func allLists(lists config.ExtraComponents) []string {
+	var names []string
+	for _, list := range [][]string{lists.Include, lists.Network, lists.BLSys, lists.VFS, lists.MQTT} {
+		names = append(names, list...)
+	}
+	return names
+}
+
+// projectConfig 读取项目 proj_config.mk 中的配置项，没有该文件时返回空
+func projectConfig(projectDir string) map[string]string {
+	content, err := os.ReadFile(filepath.Join(projectDir, "proj_config.mk"))
+	if err != nil {
+		return map[string]string{}
+	}
+	return importer.ParseConfig(string(content))
+}
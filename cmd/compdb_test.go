@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"wb2-cli/internal/config"
+)
+
+func TestCompdbComponents(t *testing.T) {
+	dir := t.TempDir()
+	makefile := "PROJECT_NAME := demo\nCOMPONENTS_NETWORK := sntp\nINCLUDE_COMPONENTS += my_driver $(COMPONENTS_NETWORK)\nINCLUDE_COMPONENTS += $(PROJECT_NAME)\n"
+	os.WriteFile(filepath.Join(dir, "Makefile"), []byte(makefile), 0644)
+	manifest := &config.Manifest{Name: "demo", Extras: config.ExtraComponents{VFS: []string{"custom_vfs"}}}
+
+	got := compdbComponents(dir, manifest)
+	for _, want := range []string{"sntp", "my_driver", "custom_vfs", "freertos_riscv_ram", "romfs"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("compdbComponents() = %v, missing %s", got, want)
+		}
+	}
+	if slices.Contains(got, "demo") {
+		t.Errorf("compdbComponents() should not include the project itself: %v", got)
+	}
+	if !slices.IsSorted(got) || len(slices.Compact(slices.Clone(got))) != len(got) {
+		t.Errorf("compdbComponents() should be sorted and unique: %v", got)
+	}
+}
+
+func TestProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	if got := projectConfig(dir); len(got) != 0 {
+		t.Errorf("projectConfig() without file = %v", got)
+	}
+	os.WriteFile(filepath.Join(dir, "proj_config.mk"), []byte("CONFIG_EASYFLASH_ENABLE:=0\n#CONFIG_X:=1\nCONFIG_EASYFLASH_ENABLE:=1\n"), 0644)
+	if got := projectConfig(dir); got["CONFIG_EASYFLASH_ENABLE"] != "1" || got["CONFIG_X"] != "" {
+		t.Errorf("projectConfig() = %v", got)
+	}
+}
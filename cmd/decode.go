@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/build"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/crash"
+	"wb2-cli/internal/monitor"
+)
+
+var (
+	decodePath string
+	decodeELF  string
+	decodeAll  bool
+)
+
+// decodeCmd represents the decode command
+var decodeCmd = &cobra.Command{
+	Use:   "decode [日志文件]",
+	Short: "解析串口日志中的崩溃信息和 coredump",
+	Long: `从串口日志（如 wb2-cli monitor --log 保存的文件）或标准输入中识别异常信息
+（mcause/mepc/mtval）、回溯（backtrace）和 coredump 组件输出的数据块，并根据项目的
+ELF 文件把其中的 RISC-V 地址解析为函数、源文件和行号。
+
+默认只输出崩溃信息和 coredump 相关的行，--all 输出所有行。wb2-cli monitor 会在
+显示输出时自动解析，收到的 coredump 保存在 build_out 中，可以之后用本命令重新解析。
+
+示例:
+  wb2-cli decode serial.log
+  wb2-cli decode build_out/coredump-20260102-102030.txt --elf old.elf
+  pbpaste | wb2-cli decode`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runDecode,
+}
+
+func init() {
+	rootCmd.AddCommand(decodeCmd)
+
+	decodeCmd.Flags().StringVarP(&decodePath, "path", "p", ".", "项目根目录")
+	decodeCmd.Flags().StringVar(&decodeELF, "elf", "", "ELF 文件（默认为 build_out/<项目名>.elf）")
+	decodeCmd.Flags().BoolVar(&decodeAll, "all", false, "输出所有行")
+}
+
+func runDecode(cmd *cobra.Command, args []string) error {
+	var in io.Reader = os.Stdin
+	if len(args) == 1 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("读取日志失败: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	sym, elfPath, err := loadSymbolizer(decodePath, decodeELF)
+	if err != nil {
+		// 没有 ELF 文件时仍然识别崩溃信息，只是不能解析地址
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "🔍 符号来自 %s\n", elfPath)
+	}
+
+	found, err := decodeLog(os.Stdout, in, crash.NewDecoder(sym), decodeAll)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(os.Stderr, "未发现崩溃信息或 coredump")
+	}
+	return nil
+}
+
+// logTimestamp wb2-cli monitor 在日志文件每行前加的时间戳
+var logTimestamp = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}\.\d{3} `)
+
+// decodeLog 逐行解析日志，输出崩溃信息及其说明（all 为 true 时输出所有行），
+// 返回是否发现了崩溃信息
+func decodeLog(w io.Writer, r io.Reader, decoder *crash.Decoder, all bool) (bool, error) {
+	found := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := monitor.StripANSI(strings.TrimRight(scanner.Text(), "\r"))
+		text = logTimestamp.ReplaceAllString(text, "")
+		// monitor 日志中已有的说明行
+		if strings.HasPrefix(strings.TrimLeft(text, " "), strings.TrimLeft(monitor.NotePrefix, " ")) {
+			continue
+		}
+		notes, important := decoder.Annotate(text)
+		found = found || important
+		if !important && !all {
+			continue
+		}
+		fmt.Fprintln(w, text)
+		for _, note := range notes {
+			fmt.Fprintln(w, monitor.NotePrefix+note)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return found, fmt.Errorf("读取日志失败: %v", err)
+	}
+	return found, nil
+}
+
+// loadSymbolizer 读取用于解析地址的 ELF 文件，默认为项目 build_out 中的 ELF
+func loadSymbolizer(projectDir, elfPath string) (*crash.Symbolizer, string, error) {
+	if elfPath == "" {
+		manifest, err := config.LoadManifest(projectDir)
+		if err != nil {
+			return nil, "", fmt.Errorf("不在项目目录中，请使用 --elf 指定 ELF 文件，地址将不会被解析")
+		}
+		if elfPath, err = findELF(projectDir, manifest.Name); err != nil {
+			return nil, "", err
+		}
+	}
+	sym, err := crash.LoadELF(elfPath)
+	if err != nil {
+		return nil, "", err
+	}
+	return sym, elfPath, nil
+}
+
+// saveCoredump 把 coredump 的原始输出保存到项目的 build_out 中（不在项目中时保存到当前目录），
+// 返回保存的路径
+func saveCoredump(projectDir string, dump *crash.Coredump, now time.Time) (string, error) {
+	dir := projectDir
+	if _, err := config.LoadManifest(projectDir); err == nil {
+		dir = filepath.Join(projectDir, build.BuildDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+	}
+	path := filepath.Join(dir, "coredump-"+now.Format("20060102-150405")+".txt")
+	if err := os.WriteFile(path, []byte(strings.Join(dump.Text, "\n")+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("保存 coredump 失败: %v", err)
+	}
+	return path, nil
+}
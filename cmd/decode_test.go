@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wb2-cli/internal/crash"
+	"wb2-cli/internal/monitor"
+)
+
+func TestDecodeLog(t *testing.T) {
+	// monitor 保存的日志带时间戳和已有的说明行
+	log := "10:20:30.000 [1][INFO  : main.c: 1] hi\n" +
+		"10:20:30.100 Exception Entry--->>>\r\n" +
+		"10:20:30.100 mcause 00000005, mepc 2300001a, mtval 00000000\n" +
+		"10:20:30.100 " + monitor.NotePrefix + "old note\n" +
+		"\x1b[31m10:20:30.200 backtrace: 0x23000024\x1b[0m\n"
+
+	var out bytes.Buffer
+	found, err := decodeLog(&out, strings.NewReader(log), crash.NewDecoder(nil), false)
+	if err != nil || !found {
+		t.Fatalf("decodeLog() = %v, %v", found, err)
+	}
+	want := "Exception Entry--->>>\n" +
+		"mcause 00000005, mepc 2300001a, mtval 00000000\n" +
+		monitor.NotePrefix + "异常原因: 读访问错误，访问的地址为 0x00000000\n" +
+		monitor.NotePrefix + "（没有 ELF 文件，无法解析地址）\n" +
+		"backtrace: 0x23000024\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	found, _ = decodeLog(&out, strings.NewReader("a\nb\n"), crash.NewDecoder(nil), true)
+	if found || out.String() != "a\nb\n" {
+		t.Errorf("decodeLog(all) = %v, %q", found, out.String())
+	}
+}
+
+func TestSaveCoredump(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 2, 10, 20, 30, 0, time.Local)
+	dump := &crash.Coredump{Text: []string{"-+-+-+- BFLB COREDUMP v0.0.1 +-+-+-+", "-+-+-+- END -+-+-+-"}}
+
+	// 不在项目中时保存到指定目录
+	path, err := saveCoredump(dir, dump, now)
+	if err != nil || path != filepath.Join(dir, "coredump-20260102-102030.txt") {
+		t.Fatalf("saveCoredump() = %q, %v", path, err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != strings.Join(dump.Text, "\n")+"\n" {
+		t.Errorf("saved %q", data)
+	}
+
+	os.WriteFile(filepath.Join(dir, "wb2.yaml"), []byte("name: demo\nboard: Ai-WB2-01S\n"), 0644)
+	path, err = saveCoredump(dir, dump, now)
+	if err != nil || path != filepath.Join(dir, "build_out", "coredump-20260102-102030.txt") {
+		t.Errorf("saveCoredump() in project = %q, %v", path, err)
+	}
+}
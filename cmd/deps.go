@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"sort"
+
+	"wb2-cli/internal/config"
+)
+
+// dependencyState 当前选择的依赖解析结果，用于选择菜单的实时提示
+type dependencyState struct {
+	// 已选择及因依赖自动包含的组件
+	included map[string]bool
+	// 因依赖自动包含（未被用户直接选择）的组件
+	auto map[string]bool
+	// 组件 -> 直接依赖它的已包含组件
+	requiredBy map[string][]string
+	// 已包含组件 -> 与之冲突的已包含组件
+	conflicts map[string][]string
+}
+
+// conflictMap 返回组件间的冲突关系（双向）
+func conflictMap(allComponents []config.Component) map[string]map[string]bool {
+	conflicts := make(map[string]map[string]bool)
+	add := func(a, b string) {
+		if conflicts[a] == nil {
+			conflicts[a] = make(map[string]bool)
+		}
+		conflicts[a][b] = true
+	}
+	for _, comp := range allComponents {
+		for _, other := range comp.Conflicts {
+			add(comp.Name, other)
+			add(other, comp.Name)
+		}
+	}
+	return conflicts
+}
+
+// resolveSelection 根据已选择的组件计算自动包含的依赖、反向依赖和冲突
+func resolveSelection(allComponents []config.Component, selected map[string]bool) *dependencyState {
+	componentMap := make(map[string]config.Component)
+	for _, comp := range allComponents {
+		componentMap[comp.Name] = comp
+	}
+
+	state := &dependencyState{
+		included:   make(map[string]bool),
+		auto:       make(map[string]bool),
+		requiredBy: make(map[string][]string),
+		conflicts:  make(map[string][]string),
+	}
+
+	// 按组件目录顺序展开依赖，保证结果稳定
+	var queue []string
+	for _, comp := range allComponents {
+		if selected[comp.Name] {
+			queue = append(queue, comp.Name)
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if state.included[name] {
+			continue
+		}
+		state.included[name] = true
+		if !selected[name] {
+			state.auto[name] = true
+		}
+		for _, dep := range componentMap[name].Dependencies {
+			if _, ok := componentMap[dep]; ok && !state.included[dep] {
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	for _, comp := range allComponents {
+		if !state.included[comp.Name] {
+			continue
+		}
+		for _, dep := range comp.Dependencies {
+			state.requiredBy[dep] = append(state.requiredBy[dep], comp.Name)
+		}
+	}
+
+	conflicts := conflictMap(allComponents)
+	for name := range state.included {
+		for other := range conflicts[name] {
+			if state.included[other] {
+				state.conflicts[name] = append(state.conflicts[name], other)
+			}
+		}
+		sort.Strings(state.conflicts[name])
+	}
+
+	return state
+}
+
+// hasConflicts 判断当前选择是否存在冲突
+func (d *dependencyState) hasConflicts() bool {
+	return len(d.conflicts) > 0
+}
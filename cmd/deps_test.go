@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+)
+
+func testDependencyComponents() []config.Component {
+	return []config.Component{
+		{Name: "wifi"},
+		{Name: "ble"},
+		{Name: "mqtt", Dependencies: []string{"wifi"}},
+		{Name: "blufi", Dependencies: []string{"ble", "wifi"}, Conflicts: []string{"smartconfig"}},
+		{Name: "smartconfig", Dependencies: []string{"wifi"}},
+	}
+}
+
+func TestResolveSelection(t *testing.T) {
+	deps := resolveSelection(testDependencyComponents(), map[string]bool{"mqtt": true, "blufi": true})
+
+	for _, name := range []string{"wifi", "ble", "mqtt", "blufi"} {
+		if !deps.included[name] {
+			t.Errorf("Expected %s to be included", name)
+		}
+	}
+	if deps.included["smartconfig"] {
+		t.Error("Expected smartconfig not to be included")
+	}
+	if !deps.auto["wifi"] || !deps.auto["ble"] || deps.auto["mqtt"] {
+		t.Errorf("Unexpected auto set: %v", deps.auto)
+	}
+	if got := deps.requiredBy["wifi"]; !reflect.DeepEqual(got, []string{"mqtt", "blufi"}) {
+		t.Errorf("Expected wifi required by [mqtt blufi], got %v", got)
+	}
+	if deps.hasConflicts() {
+		t.Errorf("Expected no conflicts, got %v", deps.conflicts)
+	}
+}
+
+func TestResolveSelectionConflicts(t *testing.T) {
+	deps := resolveSelection(testDependencyComponents(), map[string]bool{"blufi": true, "smartconfig": true})
+
+	if !deps.hasConflicts() {
+		t.Fatal("Expected conflicts")
+	}
+	// 冲突关系是双向的，即使只有一方声明
+	if got := deps.conflicts["smartconfig"]; !reflect.DeepEqual(got, []string{"blufi"}) {
+		t.Errorf("Expected smartconfig to conflict with [blufi], got %v", got)
+	}
+	if got := deps.conflicts["blufi"]; !reflect.DeepEqual(got, []string{"smartconfig"}) {
+		t.Errorf("Expected blufi to conflict with [smartconfig], got %v", got)
+	}
+}
+
+func TestResolveDependenciesConflict(t *testing.T) {
+	_, err := resolveDependencies(testDependencyComponents(), []string{"blufi", "smartconfig"})
+	if err == nil || !strings.Contains(err.Error(), "冲突") {
+		t.Errorf("Expected conflict error, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"wb2-cli/internal/generator"
+)
+
+// treeNode 预览文件树中的一个节点
+type treeNode struct {
+	name     string
+	size     int
+	isDir    bool
+	children map[string]*treeNode
+}
+
+// buildTree 将生成结果整理为以 root 为根的文件树
+func buildTree(out *generator.MemoryOutput, root string) *treeNode {
+	tree := &treeNode{name: filepath.Base(root), isDir: true, children: map[string]*treeNode{}}
+
+	insert := func(path string, size int, isDir bool) {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return
+		}
+		node := tree
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		for i, part := range parts {
+			child, ok := node.children[part]
+			if !ok {
+				child = &treeNode{name: part, isDir: true, children: map[string]*treeNode{}}
+				node.children[part] = child
+			}
+			if i == len(parts)-1 {
+				child.isDir = isDir
+				child.size = size
+			}
+			node = child
+		}
+	}
+
+	for _, dir := range out.Dirs() {
+		insert(dir, 0, true)
+	}
+	for _, file := range out.Files() {
+		insert(file, len(out.Content(file)), false)
+	}
+	return tree
+}
+
+// printTree 以树形结构输出文件及其大小
+func printTree(w io.Writer, node *treeNode, indent string) {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		child := node.children[name]
+		branch, next := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, next = "└── ", "    "
+		}
+		if child.isDir {
+			fmt.Fprintf(w, "%s%s%s/\n", indent, branch, child.name)
+			printTree(w, child, indent+next)
+		} else {
+			fmt.Fprintf(w, "%s%s%s (%s)\n", indent, branch, child.name, formatSize(child.size))
+		}
+	}
+}
+
+// formatSize 格式化文件大小
+func formatSize(size int) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f KB", float64(size)/1024)
+}
+
+// checkDryRunFlags 检查 --show-content 和 --diff 只与 --dry-run 一起使用
+func checkDryRunFlags(dryRun, showContent bool, diffDir string) error {
+	if dryRun {
+		return nil
+	}
+	if showContent {
+		return fmt.Errorf("--show-content 只能与 --dry-run 一起使用")
+	}
+	if diffDir != "" {
+		return fmt.Errorf("--diff 只能与 --dry-run 一起使用")
+	}
+	return nil
+}
+
+// printDryRun 输出 dry-run 的预览结果：文件树，以及可选的完整内容或与已有目录的 diff
+func printDryRun(w io.Writer, out *generator.MemoryOutput, root string, showContent bool, diffDir string) error {
+	total := 0
+	for _, file := range out.Files() {
+		total += len(out.Content(file))
+	}
+
+	fmt.Fprintf(w, "🔍 预览（未写入磁盘）: %s\n\n", root)
+	fmt.Fprintf(w, "%s/\n", filepath.Base(root))
+	printTree(w, buildTree(out, root), "")
+	fmt.Fprintf(w, "\n共 %d 个文件，%s\n", len(out.Files()), formatSize(total))
+
+	if showContent {
+		for _, file := range out.Files() {
+			rel, _ := filepath.Rel(root, file)
+			fmt.Fprintf(w, "\n==> %s <==\n", filepath.ToSlash(rel))
+			w.Write(out.Content(file))
+		}
+	}
+
+	if diffDir != "" {
+		changed := 0
+		for _, file := range out.Files() {
+			rel, _ := filepath.Rel(root, file)
+			existingPath := filepath.Join(diffDir, rel)
+
+			oldName := filepath.ToSlash(filepath.Join("a", rel))
+			existing, err := os.ReadFile(existingPath)
+			if os.IsNotExist(err) {
+				oldName = "/dev/null"
+			} else if err != nil {
+				return fmt.Errorf("读取 %s 失败: %v", existingPath, err)
+			}
+
+			diff := generator.UnifiedDiff(oldName, filepath.ToSlash(filepath.Join("b", rel)), string(existing), string(out.Content(file)))
+			if diff != "" {
+				changed++
+				fmt.Fprintf(w, "\n%s", diff)
+			}
+		}
+		fmt.Fprintf(w, "\n与 %s 相比，%d 个文件有差异\n", diffDir, changed)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/generator"
+)
+
+func TestPrintDryRun(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "demo")
+	out := generator.NewMemoryOutput()
+	out.MkdirAll(filepath.Join(root, "demo", "include"))
+	out.WriteFile(filepath.Join(root, "Makefile"), []byte("PROJECT_NAME := demo\n"))
+	out.WriteFile(filepath.Join(root, "demo", "main.c"), []byte("int main;\n"))
+
+	buf := &bytes.Buffer{}
+	if err := printDryRun(buf, out, root, true, ""); err != nil {
+		t.Fatalf("printDryRun failed: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{"├── Makefile (21 B)", "└── demo/", "include/", "main.c (10 B)", "共 2 个文件", "==> demo/main.c <=="} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestPrintDryRunDiff(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "demo")
+	existing := t.TempDir()
+	os.WriteFile(filepath.Join(existing, "Makefile"), []byte("PROJECT_NAME := old\n"), 0644)
+
+	out := generator.NewMemoryOutput()
+	out.WriteFile(filepath.Join(root, "Makefile"), []byte("PROJECT_NAME := demo\n"))
+	out.WriteFile(filepath.Join(root, "README.md"), []byte("# demo\n"))
+
+	buf := &bytes.Buffer{}
+	if err := printDryRun(buf, out, root, false, existing); err != nil {
+		t.Fatalf("printDryRun failed: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{"-PROJECT_NAME := old", "+PROJECT_NAME := demo", "--- /dev/null", "2 个文件有差异"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestCheckDryRunFlags(t *testing.T) {
+	tests := []struct {
+		dryRun      bool
+		showContent bool
+		diffDir     string
+		want        string
+	}{
+		{false, false, "", ""},
+		{true, true, "./demo", ""},
+		{false, true, "", "--show-content"},
+		{false, false, "./demo", "--diff"},
+	}
+	for _, tt := range tests {
+		err := checkDryRunFlags(tt.dryRun, tt.showContent, tt.diffDir)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("checkDryRunFlags(%v, %v, %q) failed: %v", tt.dryRun, tt.showContent, tt.diffDir, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("checkDryRunFlags(%v, %v, %q): expected error containing %q, got %v", tt.dryRun, tt.showContent, tt.diffDir, tt.want, err)
+		}
+	}
+}
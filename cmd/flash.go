@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/build"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/dtb"
+	"wb2-cli/internal/flasher"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/partition"
+	"wb2-cli/internal/serial"
+)
+
+var (
+	flashPath     string
+	flashPort     string
+	flashBaud     int
+	flashLoader   string
+	flashNoVerify bool
+	flashNoReset  bool
+	flashSave     bool
+)
+
+// DefaultFlashBaud 未配置时 eflash_loader 阶段使用的波特率，与生成的 Makefile 一致
+const DefaultFlashBaud = 921600
+
+// flashCmd represents the flash command
+var flashCmd = &cobra.Command{
+	Use:   "flash [file@address]...",
+	Short: "通过串口烧录镜像",
+	Long: `不依赖 SDK 的 Python 烧录工具，直接通过串口与 BL602 的 Boot ROM 通信：
+握手并读取芯片 ID，加载 SDK 中的 eflash_loader，然后擦除、分块写入（每块带校验和）
+并用 SHA-256 校验每个镜像。
+
+在项目目录中不指定镜像时烧录整个项目：
+  - build_out/<项目名>.bin 加上启动头后写入 FW 分区
+  - partition.toml 转换为二进制分区表，写入 0xE000 和 0xF000
+  - board.dts 编译为 DTB，写入 factory 分区
+boot2 不会写入，需要模组上已有 boot2（出厂固件或 SDK 烧录工具烧录过的模组都有）。
+
+指定镜像时地址可以是十六进制或十进制数，也可以是项目 partition.toml 中的分区名称
+（使用分区的第一个地址，并检查镜像是否超出分区）。写入 FW 分区的固件没有启动头时
+自动加上。串口和波特率默认取自配置文件 ~/.config/wb2-cli/config.yaml 中的 port
+和 baud，--save 会保存本次使用的值。
+
+默认通过 DTR/RTS 复位进入下载模式；开发板没有自动下载电路时使用 --no-reset，
+并在烧录前按住 BOOT 键再按一下 EN 键。
+
+示例:
+  wb2-cli flash --port /dev/ttyUSB0 --save
+  wb2-cli flash build_out/romfs.bin@media
+  wb2-cli flash whole_flash_data.bin@0x0 --baud 2000000 --no-reset`,
+	Args:         cobra.ArbitraryArgs,
+	SilenceUsage: true,
+	RunE:         runFlash,
+}
+
+func init() {
+	rootCmd.AddCommand(flashCmd)
+
+	flashCmd.Flags().StringVarP(&flashPath, "path", "p", ".", "项目根目录（用于分区名称、模组晶振和 SDK 路径）")
+	flashCmd.Flags().StringVar(&flashPort, "port", "", "串口设备（默认取自配置文件）")
+	flashCmd.Flags().IntVarP(&flashBaud, "baud", "b", 0, fmt.Sprintf("烧录波特率（默认取自配置文件，否则为 %d）", DefaultFlashBaud))
+	flashCmd.Flags().StringVar(&flashLoader, "loader", "", "eflash_loader 镜像（默认取自 SDK）")
+	flashCmd.Flags().BoolVar(&flashNoVerify, "no-verify", false, "写入后不校验 SHA-256")
+	flashCmd.Flags().BoolVar(&flashNoReset, "no-reset", false, "不通过 DTR/RTS 复位进入下载模式")
+	flashCmd.Flags().BoolVar(&flashSave, "save", false, "将串口和波特率保存到配置文件")
+}
+
+func runFlash(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	port, baud, err := flashPortSettings(cfg)
+	if err != nil {
+		return err
+	}
+
+	// 项目清单和分区表是可选的，不在项目中时只能使用数字地址并指定 --loader
+	manifest, _ := config.LoadManifest(flashPath)
+	var table *partition.Table
+	if data, err := os.ReadFile(filepath.Join(flashPath, generator.PartitionFile)); err == nil {
+		if table, err = partition.Parse(data); err != nil {
+			return fmt.Errorf("解析分区表失败: %v", err)
+		}
+	}
+
+	if len(args) == 0 && manifest == nil {
+		return fmt.Errorf("不在项目目录中，请指定要烧录的镜像（文件@地址）")
+	}
+	loader, err := loadFlashLoader(manifest)
+	if err != nil {
+		return err
+	}
+
+	var images []flasher.Image
+	if len(args) == 0 {
+		if images, err = projectFlashImages(flashPath, manifest, table, loader); err != nil {
+			return err
+		}
+	}
+	for _, spec := range args {
+		img, err := loadFlashImage(spec, table, loader)
+		if err != nil {
+			return err
+		}
+		images = append(images, img)
+	}
+	if err := flasher.ValidateImages(images); err != nil {
+		return err
+	}
+
+	p, err := serial.Open(port, flasher.ROMBaud)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	fmt.Printf("🔌 串口: %s（%d）\n", port, baud)
+	progress := &flashProgress{w: os.Stdout}
+	f := flasher.New(p, flasher.Options{
+		Baud:     baud,
+		Loader:   loader,
+		Verify:   !flashNoVerify,
+		Progress: progress.update,
+	})
+	if !flashNoReset {
+		if err := f.EnterBootloader(); err != nil {
+			fmt.Printf("⚠️  %v，请手动进入下载模式\n", err)
+		}
+	}
+
+	start := time.Now()
+	info, err := f.Connect()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🔧 芯片 ID: %s（Boot ROM 版本 %d）\n", info.ChipID(), info.ROMVersion)
+
+	if err := f.Flash(images); err != nil {
+		progress.finish()
+		return err
+	}
+	progress.finish()
+
+	total := 0
+	for _, img := range images {
+		total += len(img.Data)
+	}
+	fmt.Printf("✅ 烧录完成（%d 个镜像，%s，用时 %.1fs）\n", len(images), formatSize(total), time.Since(start).Seconds())
+
+	if err := f.Reset(); err != nil {
+		fmt.Printf("⚠️  复位失败: %v，请按一下 EN 键运行程序\n", err)
+	}
+
+	if flashSave {
+		cfg.Port, cfg.Baud = port, baud
+		if err := config.SaveConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("💾 串口设置已保存到配置文件\n")
+	}
+	return nil
+}
+
+// flashPortSettings 返回串口和波特率：命令行参数优先，其次为配置文件
+func flashPortSettings(cfg *config.UserConfig) (string, int, error) {
+	port := flashPort
+	if port == "" {
+		port = cfg.Port
+	}
+	if port == "" {
+		return "", 0, fmt.Errorf("未指定串口，请使用 --port（加 --save 保存到配置文件）")
+	}
+	baud := flashBaud
+	if baud == 0 {
+		baud = cfg.Baud
+	}
+	if baud == 0 {
+		baud = DefaultFlashBaud
+	}
+	return port, baud, nil
+}
+
+// loadFlashImage 解析 文件@地址 并读取镜像；地址可以是分区名称。
+// 写入 FW 分区的固件没有启动头时使用 loader 中的配置加上启动头
+func loadFlashImage(spec string, table *partition.Table, loader []byte) (flasher.Image, error) {
+	at := strings.LastIndex(spec, "@")
+	if at <= 0 || at == len(spec)-1 {
+		return flasher.Image{}, fmt.Errorf("无效的镜像 %q，格式为 文件@地址，如 app.bin@0x10000 或 app.bin@FW", spec)
+	}
+	path, target := spec[:at], spec[at+1:]
+
+	var address uint32
+	limit := 0
+	if v, err := strconv.ParseUint(target, 0, 32); err == nil {
+		address = uint32(v)
+	} else {
+		entry, ok := findPartition(table, target)
+		if !ok {
+			if table == nil {
+				return flasher.Image{}, fmt.Errorf("无效的地址 %q（当前目录没有 %s，不能使用分区名称）", target, generator.PartitionFile)
+			}
+			return flasher.Image{}, fmt.Errorf("无效的地址 %q：既不是数字也不是分区名称", target)
+		}
+		address, limit = uint32(entry.Address0), entry.Size0
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return flasher.Image{}, fmt.Errorf("读取镜像失败: %v", err)
+	}
+	if isFirmwareAddress(table, address) && !flasher.HasBootHeader(data) {
+		if data, err = flasher.FirmwareImage(loader, data); err != nil {
+			return flasher.Image{}, fmt.Errorf("为 %s 加上启动头失败: %v", path, err)
+		}
+	}
+	if limit > 0 && len(data) > limit {
+		return flasher.Image{}, fmt.Errorf("镜像 %s（%s）超出分区 %s 的大小 %s", path, formatSize(len(data)), target, partition.FormatSize(limit))
+	}
+	return flasher.Image{Name: filepath.Base(path), Address: address, Data: data}, nil
+}
+
+// isFirmwareAddress 判断地址是否为固件槽的起始地址；没有分区表时使用默认的固件地址
+func isFirmwareAddress(table *partition.Table, address uint32) bool {
+	if table == nil {
+		return address == partition.FirmwareAddress
+	}
+	fw, ok := table.Find(partition.FirmwareName)
+	return ok && (address == uint32(fw.Address0) || (fw.Size1 > 0 && address == uint32(fw.Address1)))
+}
+
+// projectFlashImages 返回烧录整个项目的镜像：带启动头的固件、两份二进制分区表和设备树。
+// 项目没有 board.dts（如导入的项目）时不写入 factory 分区
+func projectFlashImages(projectDir string, manifest *config.Manifest, table *partition.Table, loader []byte) ([]flasher.Image, error) {
+	if table == nil {
+		return nil, fmt.Errorf("项目中没有 %s，请指定要烧录的镜像（文件@地址）", generator.PartitionFile)
+	}
+	fw, ok := table.Find(partition.FirmwareName)
+	if !ok {
+		return nil, fmt.Errorf("分区表中没有固件分区 %s", partition.FirmwareName)
+	}
+
+	name := manifest.Name + ".bin"
+	firmware, err := os.ReadFile(filepath.Join(projectDir, build.BuildDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("找不到 %s/%s，请先运行 wb2-cli build", build.BuildDir, name)
+		}
+		return nil, fmt.Errorf("读取固件失败: %v", err)
+	}
+	if !flasher.HasBootHeader(firmware) {
+		if firmware, err = flasher.FirmwareImage(loader, firmware); err != nil {
+			return nil, fmt.Errorf("为 %s 加上启动头失败: %v", name, err)
+		}
+	}
+	if len(firmware) > fw.Size0 {
+		return nil, fmt.Errorf("固件 %s（%s，含启动头）超出分区 %s 的大小 %s", name, formatSize(len(firmware)), fw.Name, partition.FormatSize(fw.Size0))
+	}
+
+	pt := partition.Encode(table)
+	images := []flasher.Image{
+		{Name: "pt_table[0]", Address: uint32(table.Address0), Data: pt},
+		{Name: "pt_table[1]", Address: uint32(table.Address1), Data: pt},
+		{Name: name, Address: uint32(fw.Address0), Data: firmware},
+	}
+
+	src, err := os.ReadFile(filepath.Join(projectDir, generator.DTSFile))
+	if os.IsNotExist(err) {
+		fmt.Printf("ℹ️  项目中没有 %s，保留模组上已有的设备树\n", generator.DTSFile)
+		return images, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("读取设备树失败: %v", err)
+	}
+	tree, err := dtb.Compile(src)
+	if err != nil {
+		return nil, fmt.Errorf("编译 %s 失败: %v", generator.DTSFile, err)
+	}
+	factory := findPartitionType(table, partition.TypeFactory)
+	if factory == nil {
+		return nil, fmt.Errorf("分区表中没有存放设备树的 factory 分区")
+	}
+	if len(tree) > factory.Size0 {
+		return nil, fmt.Errorf("设备树（%s）超出分区 %s 的大小 %s", formatSize(len(tree)), factory.Name, partition.FormatSize(factory.Size0))
+	}
+	return append(images, flasher.Image{Name: generator.DTSFile, Address: uint32(factory.Address0), Data: tree}), nil
+}
+
+// findPartitionType 返回第一个指定类型的分区，没有时返回 nil
+func findPartitionType(table *partition.Table, typ int) *partition.Entry {
+	for i := range table.Entries {
+		if table.Entries[i].Type == typ {
+			return &table.Entries[i]
+		}
+	}
+	return nil
+}
+
+// findPartition 按名称查找分区，忽略大小写
+func findPartition(table *partition.Table, name string) (*partition.Entry, bool) {
+	if table == nil {
+		return nil, false
+	}
+	if entry, ok := table.Find(name); ok {
+		return entry, true
+	}
+	for i := range table.Entries {
+		if strings.EqualFold(table.Entries[i].Name, name) {
+			return &table.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// loadFlashLoader 读取 eflash_loader：--loader 优先，否则按项目模组的晶振从 SDK 中查找
+func loadFlashLoader(manifest *config.Manifest) ([]byte, error) {
+	path := flashLoader
+	if path == "" {
+		if manifest == nil {
+			return nil, fmt.Errorf("不在项目目录中，请使用 --loader 指定 eflash_loader 镜像")
+		}
+		projectDir, err := filepath.Abs(flashPath)
+		if err != nil {
+			return nil, fmt.Errorf("解析项目路径失败: %v", err)
+		}
+		sdk, err := projectSDKPath(manifest, projectDir)
+		if err != nil {
+			return nil, err
+		}
+		board, err := loadBoard(manifest.Board)
+		if err != nil {
+			return nil, err
+		}
+		path = flasher.LoaderPath(sdk, board.Crystal)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 eflash_loader 失败: %v", err)
+	}
+	return data, nil
+}
+
+// flashProgress 在一行中刷新当前镜像的进度，阶段变化时换行
+type flashProgress struct {
+	w    io.Writer
+	last flasher.Progress
+	open bool
+}
+
+func (p *flashProgress) update(pr flasher.Progress) {
+	if p.open && (pr.Stage != p.last.Stage || pr.Image != p.last.Image) {
+		fmt.Fprintln(p.w)
+	}
+	percent := 100
+	if pr.Total > 0 {
+		percent = pr.Done * 100 / pr.Total
+	}
+	fmt.Fprintf(p.w, "\r  %s %s %3d%%（%s / %s）", pr.Stage, pr.Image, percent, formatSize(pr.Done), formatSize(pr.Total))
+	p.last, p.open = pr, true
+}
+
+// finish 结束当前进度行
+func (p *flashProgress) finish() {
+	if p.open {
+		fmt.Fprintln(p.w)
+		p.open = false
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/flasher"
+	"wb2-cli/internal/partition"
+)
+
+// testFlashLoader 生成带 Flash 和时钟配置的 eflash_loader 启动头
+func testFlashLoader() []byte {
+	loader := make([]byte, flasher.BootHeaderSize+flasher.SegmentHeaderSize)
+	copy(loader, "BFNP")
+	copy(loader[8:], "FCFG")
+	copy(loader[100:], "PCFG")
+	return loader
+}
+
+func TestLoadFlashImage(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "romfs.bin")
+	os.WriteFile(small, make([]byte, 100), 0644)
+	large := filepath.Join(dir, "large.bin")
+	os.WriteFile(large, make([]byte, 0x3000), 0644)
+	headered, _ := flasher.FirmwareImage(testFlashLoader(), make([]byte, 100))
+	ota := filepath.Join(dir, "FW_OTA.bin")
+	os.WriteFile(ota, headered, 0644)
+
+	table := &partition.Table{Entries: []partition.Entry{
+		{Type: partition.TypeFirmware, Name: "FW", Address0: 0x10000, Size0: 0x100000, Address1: 0x110000, Size1: 0x1000},
+		{Type: partition.TypeMedia, Name: "media", Address0: 0x1A0000, Size0: 0x2000},
+	}}
+
+	tests := []struct {
+		spec    string
+		table   *partition.Table
+		address uint32
+		size    int
+		wantErr string
+	}{
+		{small + "@0x11000", nil, 0x11000, 100, ""},
+		{small + "@4096", nil, 0x1000, 100, ""},
+		{small + "@MEDIA", table, 0x1A0000, 100, ""},
+		// 写入固件槽的固件自动加上启动头，已有启动头的不再重复添加
+		{small + "@fw", table, 0x10000, 0x1000 + 100, ""},
+		{small + "@0x10000", nil, 0x10000, 0x1000 + 100, ""},
+		{ota + "@FW", table, 0x10000, len(headered), ""},
+		{small + "@0x110000", table, 0x110000, 0x1000 + 100, ""},
+		{large + "@media", table, 0, 0, "超出分区 media 的大小 8K"},
+		{small + "@storage", table, 0, 0, "既不是数字也不是分区名称"},
+		{small + "@media", nil, 0, 0, "不能使用分区名称"},
+		{small, nil, 0, 0, "格式为 文件@地址"},
+		{filepath.Join(dir, "missing.bin") + "@0x0", nil, 0, 0, "读取镜像失败"},
+	}
+	for _, tt := range tests {
+		img, err := loadFlashImage(tt.spec, tt.table, testFlashLoader())
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadFlashImage(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("loadFlashImage(%q) error = %v", tt.spec, err)
+			continue
+		}
+		if img.Address != tt.address || len(img.Data) != tt.size {
+			t.Errorf("loadFlashImage(%q) = %s@0x%X (%d bytes), want 0x%X (%d bytes)", tt.spec, img.Name, img.Address, len(img.Data), tt.address, tt.size)
+		}
+	}
+}
+
+func TestProjectFlashImages(t *testing.T) {
+	dir := t.TempDir()
+	manifest := &config.Manifest{Name: "demo"}
+	table, err := partition.Layout(2*1024*1024, nil)
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	if _, err := projectFlashImages(dir, manifest, table, testFlashLoader()); err == nil || !strings.Contains(err.Error(), "wb2-cli build") {
+		t.Errorf("Expected missing firmware error, got %v", err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "build_out"), 0755)
+	os.WriteFile(filepath.Join(dir, "build_out", "demo.bin"), bytes.Repeat([]byte{1}, 1000), 0644)
+
+	// 没有设备树时只写入分区表和固件
+	images, err := projectFlashImages(dir, manifest, table, testFlashLoader())
+	if err != nil {
+		t.Fatalf("projectFlashImages failed: %v", err)
+	}
+	if len(images) != 3 {
+		t.Fatalf("Expected 3 images without board.dts, got %d", len(images))
+	}
+
+	os.WriteFile(filepath.Join(dir, "board.dts"), []byte("/dts-v1/;\n/ {\n    model = \"test\";\n};\n"), 0644)
+	images, err = projectFlashImages(dir, manifest, table, testFlashLoader())
+	if err != nil {
+		t.Fatalf("projectFlashImages failed: %v", err)
+	}
+	if err := flasher.ValidateImages(images); err != nil {
+		t.Fatalf("Invalid images: %v", err)
+	}
+
+	fw, _ := table.Find("FW")
+	factory, _ := table.Find("factory")
+	pt := partition.Encode(table)
+	want := []struct {
+		name    string
+		address int
+	}{
+		{"pt_table[0]", partition.TableAddress0},
+		{"pt_table[1]", partition.TableAddress1},
+		{"demo.bin", fw.Address0},
+		{"board.dts", factory.Address0},
+	}
+	if len(images) != len(want) {
+		t.Fatalf("Expected %d images, got %d", len(want), len(images))
+	}
+	for i, w := range want {
+		if images[i].Name != w.name || int(images[i].Address) != w.address {
+			t.Errorf("Image %d = %s@0x%X, want %s@0x%X", i, images[i].Name, images[i].Address, w.name, w.address)
+		}
+	}
+	if !bytes.Equal(images[0].Data, pt) || !bytes.Equal(images[1].Data, pt) {
+		t.Errorf("Expected encoded partition table")
+	}
+	if !flasher.HasBootHeader(images[2].Data) || len(images[2].Data) != 0x1000+1000 {
+		t.Errorf("Expected firmware with boot header, got %d bytes", len(images[2].Data))
+	}
+	if !bytes.HasPrefix(images[3].Data, []byte{0xD0, 0x0D, 0xFE, 0xED}) {
+		t.Errorf("Expected DTB, got % X", images[3].Data[:4])
+	}
+
+	// 固件加上启动头后超出分区
+	small := *table
+	small.Entries = append([]partition.Entry(nil), table.Entries...)
+	small.Entries[0].Size0 = 0x1000
+	if _, err := projectFlashImages(dir, manifest, &small, testFlashLoader()); err == nil || !strings.Contains(err.Error(), "含启动头") {
+		t.Errorf("Expected firmware size error, got %v", err)
+	}
+}
+
+func TestFlashPortSettings(t *testing.T) {
+	defer func() { flashPort, flashBaud = "", 0 }()
+
+	if _, _, err := flashPortSettings(&config.UserConfig{}); err == nil {
+		t.Error("flashPortSettings() error = nil, want missing port error")
+	}
+
+	port, baud, err := flashPortSettings(&config.UserConfig{Port: "/dev/ttyUSB1"})
+	if err != nil || port != "/dev/ttyUSB1" || baud != DefaultFlashBaud {
+		t.Errorf("flashPortSettings() = %q, %d, %v", port, baud, err)
+	}
+
+	flashPort, flashBaud = "/dev/ttyACM0", 2000000
+	port, baud, _ = flashPortSettings(&config.UserConfig{Port: "/dev/ttyUSB1", Baud: 460800})
+	if port != "/dev/ttyACM0" || baud != 2000000 {
+		t.Errorf("flashPortSettings() = %q, %d, want flags to override config", port, baud)
+	}
+}
+
+func TestFlashProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := &flashProgress{w: &buf}
+	p.update(flasher.Progress{Stage: flasher.StageErase, Image: "app.bin", Total: 8192})
+	p.update(flasher.Progress{Stage: flasher.StageWrite, Image: "app.bin", Done: 4096, Total: 8192})
+	p.update(flasher.Progress{Stage: flasher.StageWrite, Image: "app.bin", Done: 8192, Total: 8192})
+	p.finish()
+
+	want := "\r  擦除 app.bin   0%（0 B / 8.0 KB）\n" +
+		"\r  写入 app.bin  50%（4.0 KB / 8.0 KB）" +
+		"\r  写入 app.bin 100%（8.0 KB / 8.0 KB）\n"
+	if buf.String() != want {
+		t.Errorf("progress output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/importer"
+)
+
+var importForce bool
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <path>",
+	Short: "导入已有的 SDK 项目",
+	Long: `解析已有项目（如 SDK applications/ 下的示例）的 Makefile 和 proj_config.mk，
+将其中的 SDK 组件映射回组件目录，并在项目根目录写入项目清单 wb2.yaml。
+
+无法映射的 SDK 组件会作为额外组件保留，与模板默认值不同的配置项会作为配置覆盖保留。
+导入不会修改项目中已有的文件。导入后可以使用 build、size、flash、monitor、
+partitions、romfs、kv、ota 和 compdb 等读取项目清单的命令；目前还不能通过
+add、remove 重新选择组件，也不能从清单重新生成项目文件。
+
+示例:
+  wb2-cli import ./applications/wifi/sdk_app_wifi
+  wb2-cli import . --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().BoolVar(&importForce, "force", false, "覆盖已存在的项目清单")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	projectDir, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("解析项目路径失败: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(projectDir, "Makefile")); err != nil {
+		return fmt.Errorf("找不到项目 Makefile: %s", filepath.Join(projectDir, "Makefile"))
+	}
+
+	if _, err := os.Stat(filepath.Join(projectDir, config.ManifestFile)); err == nil && !importForce {
+		return fmt.Errorf("项目清单已存在: %s（使用 --force 覆盖）", filepath.Join(projectDir, config.ManifestFile))
+	}
+
+	// SDK 路径是可选的，找不到时清单中只引用环境变量
+	sdkPath, err := getSDKPath()
+	if err != nil || !isValidSDKPath(sdkPath) {
+		fmt.Printf("⚠️  警告: 未找到有效的 SDK 路径，清单将使用环境变量 BL60X_SDK_PATH\n")
+		sdkPath = ""
+	} else if sdkPath, err = filepath.Abs(sdkPath); err != nil {
+		return fmt.Errorf("解析 SDK 路径失败: %v", err)
+	}
+
+	components, err := config.LoadComponents()
+	if err != nil {
+		return fmt.Errorf("加载组件配置失败: %v", err)
+	}
+
+	boards, err := config.LoadBoards()
+	if err != nil {
+		return fmt.Errorf("加载模组配置失败: %v", err)
+	}
+
+	result, err := importer.Import(projectDir, components, boards, sdkPath)
+	if err != nil {
+		return fmt.Errorf("导入项目失败: %v", err)
+	}
+
+	if err := config.SaveManifest(projectDir, result.Manifest); err != nil {
+		return err
+	}
+
+	manifest := result.Manifest
+	fmt.Printf("\n✅ 项目导入成功！\n")
+	fmt.Printf("📁 项目路径: %s\n", projectDir)
+	fmt.Printf("📛 项目名称: %s\n", manifest.Name)
+	if manifest.Board != "" {
+		fmt.Printf("🔧 目标模组: %s\n", manifest.Board)
+	}
+	if len(manifest.Components) > 0 {
+		fmt.Printf("📦 已映射组件: %s\n", strings.Join(manifest.Components, ", "))
+	} else {
+		fmt.Printf("📦 已映射组件: （无）\n")
+	}
+
+	if !manifest.Extras.IsEmpty() {
+		fmt.Printf("🧩 额外 SDK 组件:\n")
+		printExtras("INCLUDE_COMPONENTS", manifest.Extras.Include)
+		printExtras("COMPONENTS_NETWORK", manifest.Extras.Network)
+		printExtras("COMPONENTS_BLSYS", manifest.Extras.BLSys)
+		printExtras("COMPONENTS_VFS", manifest.Extras.VFS)
+		printExtras("COMPONENTS_MQTT", manifest.Extras.MQTT)
+	}
+
+	if len(manifest.ConfigFlags) > 0 {
+		keys := make([]string, 0, len(manifest.ConfigFlags))
+		for key := range manifest.ConfigFlags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Printf("⚙️  配置覆盖:\n")
+		for _, key := range keys {
+			fmt.Printf("  %s:=%s\n", key, manifest.ConfigFlags[key])
+		}
+	}
+
+	fmt.Printf("\n📝 项目清单: %s\n", filepath.Join(projectDir, config.ManifestFile))
+	return nil
+}
+
+// printExtras 输出一组额外组件
+func printExtras(list string, names []string) {
+	if len(names) > 0 {
+		fmt.Printf("  %s: %s\n", list, strings.Join(names, " "))
+	}
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/generator"
+)
+
+var (
+	initName         string
+	initForce        bool
+	initSkipExisting bool
+)
+
+// conflictPolicy 已存在文件的处理策略
+type conflictPolicy int
+
+const (
+	policyAsk       conflictPolicy = iota // 逐个询问
+	policyOverwrite                       // 全部覆盖
+	policySkip                            // 全部跳过
+)
+
+// conflictAction 对单个已存在文件采取的动作
+type conflictAction int
+
+const (
+	actionSkip      conflictAction = iota // 保留原文件
+	actionOverwrite                       // 覆盖原文件
+	actionWriteNew                        // 写入 <文件>.new
+)
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "在当前目录中初始化 WB2 项目",
+	Long: `在当前目录中生成 WB2 项目文件，适用于已有的 git 仓库或从 SDK applications/ 复制的示例。
+
+项目名称默认取当前目录名。已存在且内容不同的文件会逐个询问处理方式
+（跳过、覆盖或写入 .new 文件），也可以使用 --force 或 --skip-existing 统一处理。
+
+示例:
+  wb2-cli init
+  wb2-cli init --name my_project
+  wb2-cli init --skip-existing`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringVar(&initName, "name", "", "项目名称（默认为当前目录名）")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "覆盖所有已存在的文件")
+	initCmd.Flags().BoolVar(&initSkipExisting, "skip-existing", false, "跳过所有已存在的文件")
+	initCmd.Flags().BoolVarP(&interactive, "interactive", "i", true, "交互式选择组件（默认启用）")
+	initCmd.Flags().StringVar(&componentList, "components", "", "以逗号分隔的组件列表（或 all），指定后不再交互选择")
+	initCmd.Flags().StringVar(&presetName, "preset", "", "使用预设的组件和选项（--components 中的组件会追加到预设中）")
+	initCmd.Flags().StringVar(&boardName, "board", "", "目标模组，如 ai-wb2-12f、ai-wb2-01s（默认为 ai-wb2-12f）")
+	initCmd.Flags().StringArrayVar(&pinList, "pin", nil, "手动指定外设引脚，格式为 NAME=GPIO（如 SPI_CS=5），可重复使用")
+	initCmd.Flags().StringArrayVar(&optionList, "option", nil, "设置组件选项，格式为 组件.选项=值（如 uart.baudrate=9600），可重复使用")
+	initCmd.Flags().StringVar(&sdkPathMode, "sdk-path-mode", "absolute", "Makefile 中 SDK 路径的写法: absolute、relative、env 或 submodule")
+	initCmd.Flags().StringVar(&submoduleDir, "sdk-submodule-dir", generator.DefaultSubmoduleDir, "submodule 模式下 SDK 子模块在项目中的目录（SDK 不在项目内时使用）")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	if initForce && initSkipExisting {
+		return fmt.Errorf("--force 和 --skip-existing 不能同时使用")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("获取当前目录失败: %v", err)
+	}
+
+	// 从目录名推断项目名称
+	projectName := initName
+	if projectName == "" {
+		projectName = filepath.Base(cwd)
+	}
+	if !isValidProjectName(projectName) {
+		return fmt.Errorf("无效的项目名称: %s (只能包含字母、数字、下划线和连字符，可使用 --name 指定)", projectName)
+	}
+
+	setup, err := prepareProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	// 先在内存中渲染，再按策略写入当前目录
+	out := generator.NewMemoryOutput()
+	setup.gen.SetOutput(out)
+	if err := setup.gen.GenerateProject(projectName, cwd, setup.resolved); err != nil {
+		return fmt.Errorf("生成项目失败: %v", err)
+	}
+	printGenWarnings(os.Stdout, setup.gen)
+
+	policy := policyAsk
+	if initForce {
+		policy = policyOverwrite
+	} else if initSkipExisting {
+		policy = policySkip
+	}
+
+	if err := applyGenerated(os.Stdout, stdin, out, cwd, policy); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✅ 项目初始化完成！\n")
+	fmt.Printf("📁 项目路径: %s\n", cwd)
+	fmt.Printf("🔧 目标模组: %s\n", setup.board.Module)
+	fmt.Printf("📦 已选择组件: %s\n", strings.Join(setup.selected, ", "))
+	fmt.Printf("\n下一步:\n")
+	printSubmoduleHint(setup.gen, cwd)
+	fmt.Printf("  wb2-cli build\n")
+
+	return nil
+}
+
+// applyGenerated 将内存中的生成结果写入 root，已存在的文件按 policy 处理
+func applyGenerated(w io.Writer, in *bufio.Reader, out *generator.MemoryOutput, root string, policy conflictPolicy) error {
+	for _, dir := range out.Dirs() {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录失败: %v", err)
+		}
+	}
+
+	var created, overwritten, skipped, unchanged, newFiles int
+	for _, path := range out.Files() {
+		content := out.Content(path)
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+
+		existing, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			if err := writeGeneratedFile(path, content); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "  + %s\n", rel)
+			created++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %v", path, err)
+		}
+
+		if bytes.Equal(existing, content) {
+			unchanged++
+			continue
+		}
+
+		action := actionSkip
+		switch policy {
+		case policyOverwrite:
+			action = actionOverwrite
+		case policyAsk:
+			action, err = askConflict(w, in, rel, existing, content)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch action {
+		case actionOverwrite:
+			if err := writeGeneratedFile(path, content); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "  ~ %s（已覆盖）\n", rel)
+			overwritten++
+		case actionWriteNew:
+			if err := writeGeneratedFile(path+".new", content); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "  + %s.new\n", rel)
+			newFiles++
+		default:
+			fmt.Fprintf(w, "  = %s（已跳过）\n", rel)
+			skipped++
+		}
+	}
+
+	fmt.Fprintf(w, "\n新建 %d，覆盖 %d，写入 .new %d，跳过 %d，未变化 %d\n",
+		created, overwritten, newFiles, skipped, unchanged)
+	return nil
+}
+
+// askConflict 询问如何处理一个已存在且内容不同的文件
+func askConflict(w io.Writer, in *bufio.Reader, rel string, existing, content []byte) (conflictAction, error) {
+	for {
+		fmt.Fprintf(w, "文件已存在: %s  [s]跳过 / [o]覆盖 / [n]写入 .new / [d]查看差异（默认 s）: ", rel)
+		input, err := in.ReadString('\n')
+		if err != nil && input == "" {
+			if err == io.EOF {
+				// 没有更多输入时保留原文件
+				fmt.Fprintln(w)
+				return actionSkip, nil
+			}
+			return actionSkip, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "", "s":
+			return actionSkip, nil
+		case "o":
+			return actionOverwrite, nil
+		case "n":
+			return actionWriteNew, nil
+		case "d":
+			fmt.Fprint(w, generator.UnifiedDiff("a/"+rel, "b/"+rel, string(existing), string(content)))
+		}
+	}
+}
+
+// writeGeneratedFile 写入生成的文件
+func writeGeneratedFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", path, err)
+	}
+	return nil
+}
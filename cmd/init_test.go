@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/generator"
+)
+
+// setupInitTest 创建一个包含已有文件的目录和对应的生成结果
+func setupInitTest(t *testing.T) (string, *generator.MemoryOutput) {
+	t.Helper()
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "Makefile"), []byte("# hand written\n"), 0644)
+	os.WriteFile(filepath.Join(root, "README.md"), []byte("# demo\n"), 0644)
+
+	out := generator.NewMemoryOutput()
+	out.MkdirAll(filepath.Join(root, "demo", "include"))
+	out.WriteFile(filepath.Join(root, "Makefile"), []byte("# generated\n"))
+	out.WriteFile(filepath.Join(root, "README.md"), []byte("# demo\n"))
+	out.WriteFile(filepath.Join(root, "demo", "main.c"), []byte("void main() {}\n"))
+	return root, out
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestApplyGeneratedSkipExisting(t *testing.T) {
+	root, out := setupInitTest(t)
+
+	buf := &bytes.Buffer{}
+	if err := applyGenerated(buf, bufio.NewReader(strings.NewReader("")), out, root, policySkip); err != nil {
+		t.Fatalf("applyGenerated failed: %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(root, "Makefile")); got != "# hand written\n" {
+		t.Errorf("Expected Makefile to be preserved, got %q", got)
+	}
+	if got := readFile(t, filepath.Join(root, "demo", "main.c")); got != "void main() {}\n" {
+		t.Errorf("Expected main.c to be created, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "demo", "include")); err != nil {
+		t.Errorf("Expected include directory to be created: %v", err)
+	}
+	if !strings.Contains(buf.String(), "新建 1，覆盖 0，写入 .new 0，跳过 1，未变化 1") {
+		t.Errorf("Unexpected summary:\n%s", buf.String())
+	}
+}
+
+func TestApplyGeneratedForce(t *testing.T) {
+	root, out := setupInitTest(t)
+
+	buf := &bytes.Buffer{}
+	if err := applyGenerated(buf, bufio.NewReader(strings.NewReader("")), out, root, policyOverwrite); err != nil {
+		t.Fatalf("applyGenerated failed: %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(root, "Makefile")); got != "# generated\n" {
+		t.Errorf("Expected Makefile to be overwritten, got %q", got)
+	}
+}
+
+func TestApplyGeneratedAsk(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		makefile string
+		newFile  bool
+	}{
+		{"skip by default", "\n", "# hand written\n", false},
+		{"overwrite", "o\n", "# generated\n", false},
+		{"write .new", "n\n", "# hand written\n", true},
+		{"diff then overwrite", "d\no\n", "# generated\n", false},
+		{"no input", "", "# hand written\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, out := setupInitTest(t)
+
+			buf := &bytes.Buffer{}
+			if err := applyGenerated(buf, bufio.NewReader(strings.NewReader(tt.input)), out, root, policyAsk); err != nil {
+				t.Fatalf("applyGenerated failed: %v", err)
+			}
+
+			if got := readFile(t, filepath.Join(root, "Makefile")); got != tt.makefile {
+				t.Errorf("Expected Makefile %q, got %q", tt.makefile, got)
+			}
+
+			_, err := os.Stat(filepath.Join(root, "Makefile.new"))
+			if (err == nil) != tt.newFile {
+				t.Errorf("Expected Makefile.new exists=%v, got err=%v", tt.newFile, err)
+			}
+
+			if strings.HasPrefix(tt.input, "d") && !strings.Contains(buf.String(), "+# generated") {
+				t.Errorf("Expected diff output, got:\n%s", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// keyKind 按键类型
+type keyKind int
+
+const (
+	keyUnknown keyKind = iota
+	keyRune            // 普通字符，见 key.r
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyPgUp
+	keyPgDn
+	keyHome
+	keyEnd
+	keyCtrlC
+)
+
+// key 一次按键事件
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// escTimeout 读到 ESC 后等待后续字节的时间，超时则视为单独的 ESC 键
+const escTimeout = 50 * time.Millisecond
+
+// byteSource 按字节读取输入
+type byteSource interface {
+	// readByte 读取一个字节。timeout > 0 时最多等待 timeout，超时返回 ok=false；
+	// timeout 为 0 时一直等待
+	readByte(timeout time.Duration) (b byte, ok bool, err error)
+}
+
+// keyReader 将输入字节流解码为按键事件。
+// 整个菜单共用一个 keyReader，避免多个缓冲读取器之间丢失按键。
+type keyReader struct {
+	src     byteSource
+	timeout time.Duration
+	// 解码时多读的字节，下次优先返回
+	pending []byte
+}
+
+// newKeyReader 创建按键解码器
+func newKeyReader(src byteSource, timeout time.Duration) *keyReader {
+	return &keyReader{src: src, timeout: timeout}
+}
+
+// next 读取下一个字节；wait 为 false 时一直等待，否则最多等待 escTimeout
+func (kr *keyReader) next(wait bool) (byte, bool, error) {
+	if len(kr.pending) > 0 {
+		b := kr.pending[0]
+		kr.pending = kr.pending[1:]
+		return b, true, nil
+	}
+	if !wait {
+		return kr.src.readByte(0)
+	}
+	return kr.src.readByte(kr.timeout)
+}
+
+// unread 放回一个字节
+func (kr *keyReader) unread(b byte) {
+	kr.pending = append([]byte{b}, kr.pending...)
+}
+
+// readKey 读取一次按键
+func (kr *keyReader) readKey() (key, error) {
+	b, _, err := kr.next(false)
+	if err != nil {
+		return key{}, err
+	}
+
+	switch b {
+	case 0x1b:
+		return kr.readEscape()
+	case '\r', '\n':
+		return key{kind: keyEnter}, nil
+	case 127, 8:
+		return key{kind: keyBackspace}, nil
+	case 3:
+		return key{kind: keyCtrlC}, nil
+	}
+
+	if b < utf8.RuneSelf {
+		if b < 0x20 {
+			return key{kind: keyUnknown}, nil
+		}
+		return key{kind: keyRune, r: rune(b)}, nil
+	}
+	return kr.readUTF8(b)
+}
+
+// readEscape 解析 ESC 之后的序列：CSI（ESC [）、SS3（ESC O）或单独的 ESC
+func (kr *keyReader) readEscape() (key, error) {
+	b, ok, err := kr.next(true)
+	if err != nil && err != io.EOF {
+		return key{}, err
+	}
+	if !ok || err != nil {
+		return key{kind: keyEsc}, nil
+	}
+
+	switch b {
+	case '[':
+		return kr.readCSI()
+	case 'O':
+		final, ok, err := kr.next(true)
+		if err != nil && err != io.EOF {
+			return key{}, err
+		}
+		if !ok || err != nil {
+			return key{kind: keyUnknown}, nil
+		}
+		return finalKey(final), nil
+	}
+
+	// ESC 后紧跟其他按键（如快速连按），先返回 ESC，其余留给下次
+	kr.unread(b)
+	return key{kind: keyEsc}, nil
+}
+
+// readCSI 解析 CSI 序列：参数字节后跟一个 0x40-0x7E 的结束字节
+func (kr *keyReader) readCSI() (key, error) {
+	var params []byte
+	for {
+		b, ok, err := kr.next(true)
+		if err != nil && err != io.EOF {
+			return key{}, err
+		}
+		if !ok || err != nil {
+			return key{kind: keyUnknown}, nil
+		}
+		if b >= 0x40 && b <= 0x7e {
+			if b != '~' {
+				// 带修饰键的序列（如 ESC [1;5A）只看结束字节
+				return finalKey(b), nil
+			}
+			return tildeKey(string(params)), nil
+		}
+		params = append(params, b)
+	}
+}
+
+// finalKey 将 CSI/SS3 序列的结束字节映射为按键
+func finalKey(b byte) key {
+	switch b {
+	case 'A':
+		return key{kind: keyUp}
+	case 'B':
+		return key{kind: keyDown}
+	case 'C':
+		return key{kind: keyRight}
+	case 'D':
+		return key{kind: keyLeft}
+	case 'H':
+		return key{kind: keyHome}
+	case 'F':
+		return key{kind: keyEnd}
+	}
+	return key{kind: keyUnknown}
+}
+
+// tildeKey 将 ESC [n~ 形式的序列映射为按键
+func tildeKey(params string) key {
+	// 去掉修饰键参数，如 5;2
+	for i := 0; i < len(params); i++ {
+		if params[i] == ';' {
+			params = params[:i]
+			break
+		}
+	}
+	switch params {
+	case "1", "7":
+		return key{kind: keyHome}
+	case "4", "8":
+		return key{kind: keyEnd}
+	case "5":
+		return key{kind: keyPgUp}
+	case "6":
+		return key{kind: keyPgDn}
+	}
+	return key{kind: keyUnknown}
+}
+
+// readUTF8 读取多字节 UTF-8 字符的剩余字节
+func (kr *keyReader) readUTF8(first byte) (key, error) {
+	buf := []byte{first}
+	for !utf8.FullRune(buf) {
+		b, ok, err := kr.next(true)
+		if err != nil && err != io.EOF {
+			return key{}, err
+		}
+		if !ok || err != nil {
+			return key{kind: keyUnknown}, nil
+		}
+		buf = append(buf, b)
+	}
+	r, _ := utf8.DecodeRune(buf)
+	if r == utf8.RuneError {
+		return key{kind: keyUnknown}, nil
+	}
+	return key{kind: keyRune, r: r}, nil
+}
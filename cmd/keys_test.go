@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chunkSource 模拟终端输入：每个分块内的字节同时到达，
+// 分块之间的间隔超过等待超时
+type chunkSource struct {
+	chunks []string
+}
+
+func (c *chunkSource) readByte(timeout time.Duration) (byte, bool, error) {
+	for len(c.chunks) > 0 && c.chunks[0] == "" {
+		if timeout > 0 {
+			// 当前分块已读完，等待下一块会超时
+			c.chunks = c.chunks[1:]
+			return 0, false, nil
+		}
+		c.chunks = c.chunks[1:]
+	}
+	if len(c.chunks) == 0 {
+		return 0, false, io.EOF
+	}
+	b := c.chunks[0][0]
+	c.chunks[0] = c.chunks[0][1:]
+	return b, true, nil
+}
+
+// decodeKeys 解码所有分块中的按键
+func decodeKeys(t *testing.T, chunks ...string) []key {
+	t.Helper()
+	kr := newKeyReader(&chunkSource{chunks: chunks}, escTimeout)
+	var keys []key
+	for {
+		k, err := kr.readKey()
+		if err == io.EOF {
+			return keys
+		}
+		if err != nil {
+			t.Fatalf("readKey failed: %v", err)
+		}
+		keys = append(keys, k)
+	}
+}
+
+func TestKeyReaderSequences(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  keyKind
+	}{
+		{"CSI up", "\x1b[A", keyUp},
+		{"CSI down", "\x1b[B", keyDown},
+		{"CSI right", "\x1b[C", keyRight},
+		{"CSI left", "\x1b[D", keyLeft},
+		{"SS3 up", "\x1bOA", keyUp},
+		{"SS3 left", "\x1bOD", keyLeft},
+		{"CSI home", "\x1b[H", keyHome},
+		{"CSI end", "\x1b[F", keyEnd},
+		{"SS3 home", "\x1bOH", keyHome},
+		{"SS3 end", "\x1bOF", keyEnd},
+		{"tilde home", "\x1b[1~", keyHome},
+		{"tilde home rxvt", "\x1b[7~", keyHome},
+		{"tilde end", "\x1b[4~", keyEnd},
+		{"tilde end rxvt", "\x1b[8~", keyEnd},
+		{"page up", "\x1b[5~", keyPgUp},
+		{"page down", "\x1b[6~", keyPgDn},
+		{"modified arrow", "\x1b[1;5A", keyUp},
+		{"modified page down", "\x1b[6;2~", keyPgDn},
+		{"delete", "\x1b[3~", keyUnknown},
+		{"enter CR", "\r", keyEnter},
+		{"enter LF", "\n", keyEnter},
+		{"backspace DEL", "\x7f", keyBackspace},
+		{"backspace BS", "\b", keyBackspace},
+		{"ctrl-c", "\x03", keyCtrlC},
+		{"other control", "\x01", keyUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := decodeKeys(t, tt.input)
+			if len(keys) != 1 || keys[0].kind != tt.want {
+				t.Errorf("decode %q = %v, want single key of kind %d", tt.input, keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyReaderRunes(t *testing.T) {
+	keys := decodeKeys(t, "aé网🌐")
+	want := []key{{kind: keyRune, r: 'a'}, {kind: keyRune, r: 'é'}, {kind: keyRune, r: '网'}, {kind: keyRune, r: '🌐'}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expected %v, got %v", want, keys)
+	}
+}
+
+func TestKeyReaderBareEscape(t *testing.T) {
+	// 单独的 ESC 在超时后返回，后续输入不受影响
+	keys := decodeKeys(t, "\x1b", "[A")
+	want := []key{{kind: keyEsc}, {kind: keyRune, r: '['}, {kind: keyRune, r: 'A'}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expected %v, got %v", want, keys)
+	}
+
+	// 输入结束时的 ESC
+	if keys := decodeKeys(t, "\x1b"); !reflect.DeepEqual(keys, []key{{kind: keyEsc}}) {
+		t.Errorf("Expected single ESC, got %v", keys)
+	}
+
+	// ESC 后紧跟普通按键：两个按键都不丢失
+	keys = decodeKeys(t, "\x1bq")
+	want = []key{{kind: keyEsc}, {kind: keyRune, r: 'q'}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expected %v, got %v", want, keys)
+	}
+}
+
+func TestKeyReaderBurst(t *testing.T) {
+	// 快速连续的按键在同一次读取中到达，不能丢失
+	keys := decodeKeys(t, "\x1b[B\x1b[B \x1bOA\r")
+	var kinds []keyKind
+	for _, k := range keys {
+		kinds = append(kinds, k.kind)
+	}
+	want := []keyKind{keyDown, keyDown, keyRune, keyUp, keyEnter}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Expected %v, got %v", want, kinds)
+	}
+}
+
+func TestRunSelectorByteStream(t *testing.T) {
+	// 进入网络分类，用 vi 键下移选择 mqtt，返回后回车完成
+	kr := newKeyReader(&chunkSource{chunks: []string{"\x1b[Cj \x1bOD\r"}}, escTimeout)
+	var out strings.Builder
+	size := func() (int, int, error) { return 80, 20, nil }
+
+	selection, err := runSelector(newSelector(testSelectorComponents()), kr, &out, size)
+	if err != nil {
+		t.Fatalf("runSelector failed: %v", err)
+	}
+	if !reflect.DeepEqual(selection, []string{"mqtt"}) {
+		t.Errorf("Expected [mqtt], got %v", selection)
+	}
+	if !strings.Contains(out.String(), "WB2 组件选择菜单") {
+		t.Error("Expected menu to be rendered")
+	}
+
+	// 输入结束时返回错误
+	kr = newKeyReader(&chunkSource{chunks: []string{"j"}}, escTimeout)
+	if _, err := runSelector(newSelector(testSelectorComponents()), kr, &out, size); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+
+	// 单独的 ESC 不会取消，q 取消
+	kr = newKeyReader(&chunkSource{chunks: []string{"\x1b", "q"}}, escTimeout)
+	if _, err := runSelector(newSelector(testSelectorComponents()), kr, &out, size); err == nil || !strings.Contains(err.Error(), "取消") {
+		t.Errorf("Expected cancel error, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/kv"
+	"wb2-cli/internal/partition"
+)
+
+var (
+	kvPath  string
+	kvCheck bool
+)
+
+// kvPartition EasyFlash 使用的分区名称
+const kvPartition = "PSM"
+
+// kvCmd represents the kv command
+var kvCmd = &cobra.Command{
+	Use:   "kv",
+	Short: "检查 EasyFlash 默认键值并生成 C 代码",
+	Long: `读取项目根目录中的 kv_defaults.yaml（选择 storage 组件时生成），检查键名长度、
+值的类型和范围，并估算键值是否能放入 partition.toml 中的 PSM 分区，然后重新生成
+<项目名>/kv_defaults.c 和 include/kv_defaults.h。设备启动时 kv_defaults_init()
+写入 EasyFlash 中不存在的键，已有的值不会被覆盖。
+
+wb2-cli build 编译前也会重新生成。--check 只检查，生成的文件与 kv_defaults.yaml
+不一致时报错（适合 CI）。
+
+示例:
+  wb2-cli kv
+  wb2-cli kv --check`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runKV,
+}
+
+func init() {
+	rootCmd.AddCommand(kvCmd)
+
+	kvCmd.Flags().StringVarP(&kvPath, "path", "p", ".", "项目根目录")
+	kvCmd.Flags().BoolVar(&kvCheck, "check", false, "只检查，不写入生成的文件")
+}
+
+func runKV(cmd *cobra.Command, args []string) error {
+	manifest, err := config.LoadManifest(kvPath)
+	if err != nil {
+		return err
+	}
+	entries, err := loadKVDefaults(kvPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🔑 %s: %d 个键\n\n", kv.File, len(entries))
+	printKVDefaults(os.Stdout, entries)
+
+	size, ok, err := kvPartitionSize(kvPath)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Printf("\n⚠️  分区表中没有 %s 分区，EasyFlash 无法使用，请选择 storage 组件\n", kvPartition)
+	} else {
+		used, err := kv.Check(entries, size)
+		if err != nil {
+			return err
+		}
+		capacity := kv.Capacity(size)
+		fmt.Printf("\n%s 分区: 约 %s / %s 可用（%.1f%%）\n", kvPartition, formatSize(used), formatSize(capacity), float64(used)*100/float64(max(capacity, 1)))
+	}
+	for _, warning := range kv.Warnings(entries) {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+
+	files := kvGeneratedFiles(kvPath, manifest.Name, entries)
+	if kvCheck {
+		for path, content := range files {
+			if existing, err := os.ReadFile(path); err != nil || !bytes.Equal(existing, content) {
+				return fmt.Errorf("%s 与 %s 不一致，请运行 wb2-cli kv 重新生成", path, kv.File)
+			}
+		}
+		fmt.Printf("✅ 默认键值有效，生成的文件是最新的\n")
+		return nil
+	}
+	changed, err := writeKVFiles(files)
+	if err != nil {
+		return err
+	}
+	if changed {
+		fmt.Printf("✅ 已生成 %s\n", filepath.Join(manifest.Name, kv.SourceFile))
+	} else {
+		fmt.Printf("✅ 默认键值有效，生成的文件是最新的\n")
+	}
+	return nil
+}
+
+// loadKVDefaults 读取并解析项目的 kv_defaults.yaml
+func loadKVDefaults(projectDir string) ([]kv.Entry, error) {
+	data, err := os.ReadFile(filepath.Join(projectDir, kv.File))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("项目中没有 %s（选择 storage 组件时生成）", kv.File)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return kv.Parse(data)
+}
+
+// kvPartitionSize 返回 EasyFlash 分区（按名称或类型查找）的大小，没有分区表或分区时返回 false
+func kvPartitionSize(projectDir string) (int, bool, error) {
+	data, err := os.ReadFile(filepath.Join(projectDir, generator.PartitionFile))
+	if err != nil {
+		return 0, false, nil
+	}
+	table, err := partition.Parse(data)
+	if err != nil {
+		return 0, false, fmt.Errorf("解析分区表失败: %v", err)
+	}
+	if entry, ok := findPartition(table, kvPartition); ok {
+		return entry.Size0, true, nil
+	}
+	for _, entry := range table.Entries {
+		if entry.Type == partition.TypePSM {
+			return entry.Size0, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// kvGeneratedFiles 返回由默认键值生成的文件（路径 -> 内容）
+func kvGeneratedFiles(projectDir, projectName string, entries []kv.Entry) map[string][]byte {
+	srcDir := filepath.Join(projectDir, projectName)
+	return map[string][]byte{
+		filepath.Join(srcDir, kv.SourceFile):            kv.Source(entries),
+		filepath.Join(srcDir, "include", kv.HeaderFile): kv.Header(entries),
+	}
+}
+
+// writeKVFiles 写入内容有变化的文件，返回是否写入了文件
+func writeKVFiles(files map[string][]byte) (bool, error) {
+	changed := false
+	for path, content := range files {
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, content) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return changed, err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return changed, fmt.Errorf("写入 %s 失败: %v", path, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// syncKVDefaults 编译前根据 kv_defaults.yaml 重新生成 C 代码，项目中没有该文件时不做任何事
+func syncKVDefaults(projectDir, projectName string) error {
+	if _, err := os.Stat(filepath.Join(projectDir, kv.File)); err != nil {
+		return nil
+	}
+	entries, err := loadKVDefaults(projectDir)
+	if err != nil {
+		return err
+	}
+	if size, ok, err := kvPartitionSize(projectDir); err == nil && ok {
+		if _, err := kv.Check(entries, size); err != nil {
+			return err
+		}
+	}
+	changed, err := writeKVFiles(kvGeneratedFiles(projectDir, projectName, entries))
+	if changed {
+		fmt.Printf("🔑 已根据 %s 重新生成 %s\n", kv.File, filepath.Join(projectName, kv.SourceFile))
+	}
+	return err
+}
+
+// printKVDefaults 列出键、类型、值和估算的占用
+func printKVDefaults(w io.Writer, entries []kv.Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	width := 4
+	for _, e := range entries {
+		width = max(width, len(e.Key))
+	}
+	row := func(key, typ, value, size string) {
+		fmt.Fprintf(w, "%s %s %s %s\n", padRight(key, width), padRight(typ, 6), padRight(value, 24), size)
+	}
+	row("键", "类型", "值", "占用")
+	for _, e := range entries {
+		row(e.Key, string(e.Type), truncate(e.Value, 24), fmt.Sprintf("%d B", kv.NodeSize(e)))
+	}
+}
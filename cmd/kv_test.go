@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/kv"
+	"wb2-cli/internal/partition"
+)
+
+func TestSyncKVDefaults(t *testing.T) {
+	dir := t.TempDir()
+	// 没有 kv_defaults.yaml 时不生成
+	if err := syncKVDefaults(dir, "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "demo", kv.SourceFile)); err == nil {
+		t.Errorf("Expected no %s without %s", kv.SourceFile, kv.File)
+	}
+
+	os.WriteFile(filepath.Join(dir, kv.File), []byte("device_name: demo\n"), 0644)
+	if err := syncKVDefaults(dir, "demo"); err != nil {
+		t.Fatal(err)
+	}
+	source, err := os.ReadFile(filepath.Join(dir, "demo", kv.SourceFile))
+	if err != nil || !strings.Contains(string(source), "{KV_DEVICE_NAME, (void *)kv_value_0, 4}") {
+		t.Errorf("%s = %q, %v", kv.SourceFile, source, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "demo", "include", kv.HeaderFile)); err != nil {
+		t.Errorf("Expected include/%s: %v", kv.HeaderFile, err)
+	}
+
+	// 超出分区时报错
+	table := &partition.Table{Address0: partition.TableAddress0, Address1: partition.TableAddress1, Entries: []partition.Entry{
+		{Type: partition.TypePSM, Name: "PSM", Address0: 0x1F0000, Size0: 2 * kv.SectorSize},
+	}}
+	os.WriteFile(filepath.Join(dir, "partition.toml"), partition.Format(table, "test"), 0644)
+	os.WriteFile(filepath.Join(dir, kv.File), []byte("cert:\n  type: hex\n  value: "+strings.Repeat("00", 3000)+"\nkey:\n  type: hex\n  value: "+strings.Repeat("00", 3000)+"\n"), 0644)
+	if err := syncKVDefaults(dir, "demo"); err == nil || !strings.Contains(err.Error(), "超过分区") {
+		t.Errorf("syncKVDefaults() error = %v, want partition overflow", err)
+	}
+}
+
+func TestPrintKVDefaults(t *testing.T) {
+	entries, err := kv.Parse([]byte("device_name: demo\nboot_times:\n  type: u32\n  value: 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	printKVDefaults(&buf, entries)
+	want := "键          类型   值                       占用\n" +
+		"device_name string demo                     40 B\n" +
+		"boot_times  u32    0                        40 B\n"
+	if buf.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
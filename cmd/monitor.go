@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"wb2-cli/internal/build"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/crash"
+	"wb2-cli/internal/flasher"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/monitor"
+	"wb2-cli/internal/serial"
+	"wb2-cli/internal/size"
+)
+
+var (
+	monitorPath      string
+	monitorPort      string
+	monitorBaud      int
+	monitorNoReset   bool
+	monitorFilter    []string
+	monitorLevel     string
+	monitorTimestamp bool
+	monitorLog       string
+	monitorNoColor   bool
+	monitorELF       string
+)
+
+// DefaultMonitorBaud 生成的设备树中控制台 UART0 的波特率
+const DefaultMonitorBaud = 2000000
+
+// 监视时的快捷键
+const (
+	monitorResetKey = 0x12 // Ctrl+R
+	monitorExitKey  = 0x1d // Ctrl+]
+	monitorIntrKey  = 0x03 // Ctrl+C
+)
+
+// monitorPollInterval 等待键盘输入的超时，用于检查监视是否已结束
+const monitorPollInterval = 100 * time.Millisecond
+
+// monitorCmd represents the monitor command
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "打开串口监视设备输出",
+	Long: `打开串口，通过 DTR/RTS 复位芯片后持续显示设备输出。
+
+识别 SDK blog_info/blog_warn/blog_error 等宏输出的日志行（[节拍][级别: 文件:行号] 正文），
+按级别着色，并高亮生成的 main.c 输出的 [APP] [EVT] 事件。键盘输入会转发给设备的命令行。
+
+快捷键:
+  Ctrl+R  复位设备
+  Ctrl+]  退出（Ctrl+C 同样退出）
+
+串口默认取自配置文件中的 port（与 wb2-cli flash 共用），波特率默认取自项目
+board.dts 中 UART0 的 baudrate，否则为 2000000。
+
+--filter 按 SDK 组件过滤：组件名称与日志行所属的组件（根据 build_out 中的 map 文件
+判断）、源文件名或正文开头的 [标签] 匹配。--log 把所有输出（不过滤）带时间戳写入文件。
+
+异常信息、回溯和 coredump 不受过滤影响，其中的地址根据项目的 ELF 文件解析为
+函数、源文件和行号；收到的 coredump 保存在 build_out 中，可以用 wb2-cli decode 重新解析。
+
+示例:
+  wb2-cli monitor --port /dev/ttyUSB0
+  wb2-cli monitor --filter my_project,APP --level warn
+  wb2-cli monitor -t --log serial.log`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runMonitor,
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+
+	monitorCmd.Flags().StringVarP(&monitorPath, "path", "p", ".", "项目根目录（用于波特率和组件过滤）")
+	monitorCmd.Flags().StringVar(&monitorPort, "port", "", "串口设备（默认取自配置文件）")
+	monitorCmd.Flags().IntVarP(&monitorBaud, "baud", "b", 0, fmt.Sprintf("波特率（默认取自 board.dts，否则为 %d）", DefaultMonitorBaud))
+	monitorCmd.Flags().BoolVar(&monitorNoReset, "no-reset", false, "打开串口后不复位设备")
+	monitorCmd.Flags().StringSliceVarP(&monitorFilter, "filter", "f", nil, "只显示这些组件的日志（逗号分隔）")
+	monitorCmd.Flags().StringVarP(&monitorLevel, "level", "l", "", "只显示不低于该级别的日志（debug、info、warn、error）")
+	monitorCmd.Flags().BoolVarP(&monitorTimestamp, "timestamp", "t", false, "在每行前显示本机时间")
+	monitorCmd.Flags().StringVar(&monitorLog, "log", "", "将输出追加到日志文件")
+	monitorCmd.Flags().BoolVar(&monitorNoColor, "no-color", false, "不着色")
+	monitorCmd.Flags().StringVar(&monitorELF, "elf", "", "解析崩溃地址使用的 ELF 文件（默认为 build_out/<项目名>.elf）")
+}
+
+func runMonitor(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	port := monitorPort
+	if port == "" {
+		port = cfg.Port
+	}
+	if port == "" {
+		return fmt.Errorf("未指定串口，请使用 --port（或通过 wb2-cli flash --save 保存到配置文件）")
+	}
+	baud := monitorBaud
+	if baud == 0 {
+		baud = dtsConsoleBaud(monitorPath)
+	}
+
+	opts := monitor.Options{
+		Color:     !monitorNoColor && term.IsTerminal(int(os.Stdout.Fd())),
+		Timestamp: monitorTimestamp,
+		Filter:    monitor.Filter{Components: monitorFilter},
+	}
+	if monitorLevel != "" {
+		level, ok := monitor.ParseLevel(monitorLevel)
+		if !ok {
+			return fmt.Errorf("无效的日志级别 %q，可选 debug、info、warn、error", monitorLevel)
+		}
+		opts.Filter.MinLevel = level
+	}
+	if len(monitorFilter) > 0 {
+		opts.Components = monitorComponents(monitorPath)
+	}
+	sym, _, err := loadSymbolizer(monitorPath, monitorELF)
+	if err != nil && monitorELF != "" {
+		return err
+	}
+	decoder := crash.NewDecoder(sym)
+	decoder.OnCoredump = func(dump *crash.Coredump) []string {
+		path, err := saveCoredump(monitorPath, dump, time.Now())
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return []string{fmt.Sprintf("已保存到 %s，可以用 wb2-cli decode 重新解析", path)}
+	}
+	opts.Annotator = decoder
+
+	if monitorLog != "" {
+		logFile, err := os.OpenFile(monitorLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("打开日志文件失败: %v", err)
+		}
+		defer logFile.Close()
+		opts.Log = logFile
+	}
+
+	p, err := serial.Open(port, baud)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	fmt.Printf("🔌 串口: %s（%d）", port, baud)
+	if interactive {
+		fmt.Printf("，Ctrl+R 复位设备，Ctrl+] 退出")
+	}
+	fmt.Println()
+
+	var out io.Writer = os.Stdout
+	if interactive {
+		restore, err := setupTerminal(os.Stdin, os.Stdout)
+		if err != nil {
+			return fmt.Errorf("无法设置终端: %v", err)
+		}
+		defer restore()
+		// raw 模式下终端不再把 \n 转换为 \r\n
+		out = crlfWriter{os.Stdout}
+	}
+	m := monitor.New(p, out, opts)
+
+	reset := func() error { return flasher.ResetRun(p) }
+	if !monitorNoReset {
+		if err := reset(); err != nil {
+			m.Notice(fmt.Sprintf("复位失败: %v", err))
+		}
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	if interactive {
+		go func() {
+			monitorInput(newTTYSource(os.Stdin), m, reset, done)
+			close(stop)
+		}()
+	} else {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		defer signal.Stop(signals)
+		go func() {
+			select {
+			case <-signals:
+				close(stop)
+			case <-done:
+			}
+		}()
+	}
+
+	err = m.Run(stop)
+	close(done)
+	return err
+}
+
+// monitorTarget 快捷键和键盘输入的处理对象，即 *monitor.Monitor
+type monitorTarget interface {
+	Send(data []byte) error
+	Notice(msg string)
+}
+
+// monitorInput 把键盘输入转发给设备并处理快捷键，用户退出或 done 关闭时返回
+func monitorInput(src byteSource, m monitorTarget, reset func() error, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		b, ok, err := src.readByte(monitorPollInterval)
+		if err != nil {
+			return
+		}
+		if !ok {
+			continue
+		}
+		switch b {
+		case monitorExitKey, monitorIntrKey:
+			return
+		case monitorResetKey:
+			if err := reset(); err != nil {
+				m.Notice(fmt.Sprintf("复位失败: %v", err))
+			} else {
+				m.Notice("已复位设备")
+			}
+		default:
+			if err := m.Send([]byte{b}); err != nil {
+				m.Notice(fmt.Sprintf("发送失败: %v", err))
+			}
+		}
+	}
+}
+
+// crlfWriter 把 \n 转换为 \r\n
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// dtsBaudrate 匹配设备树中的 baudrate 属性
+var dtsBaudrate = regexp.MustCompile(`baudrate\s*=\s*<\s*(\d+)\s*>`)
+
+// dtsConsoleBaud 返回项目 board.dts 中控制台 UART0（第一个 UART 节点）的波特率
+func dtsConsoleBaud(projectDir string) int {
+	data, err := os.ReadFile(filepath.Join(projectDir, generator.DTSFile))
+	if err != nil {
+		return DefaultMonitorBaud
+	}
+	src := string(data)
+	if i := strings.Index(src, "uart@"); i >= 0 {
+		src = src[i:]
+	}
+	if m := dtsBaudrate.FindStringSubmatch(src); m != nil {
+		if baud, err := strconv.Atoi(m[1]); err == nil && baud > 0 {
+			return baud
+		}
+	}
+	return DefaultMonitorBaud
+}
+
+// monitorComponents 根据项目编译生成的 map 文件判断日志行属于哪个组件；
+// 没有 map 文件时只能按源文件名和标签过滤
+func monitorComponents(projectDir string) map[string]string {
+	manifest, err := config.LoadManifest(projectDir)
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(projectDir, build.BuildDir, manifest.Name+".map"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	m, err := size.ParseMap(f)
+	if err != nil {
+		return nil
+	}
+	return monitor.SourceComponents(m)
+}
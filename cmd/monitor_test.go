@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeMonitor 记录发送给设备的数据和提示
+type fakeMonitor struct {
+	sent    []byte
+	notices []string
+}
+
+func (f *fakeMonitor) Send(data []byte) error {
+	f.sent = append(f.sent, data...)
+	return nil
+}
+
+func (f *fakeMonitor) Notice(msg string) {
+	f.notices = append(f.notices, msg)
+}
+
+func TestMonitorInput(t *testing.T) {
+	m := &fakeMonitor{}
+	resets := 0
+	reset := func() error {
+		resets++
+		if resets > 1 {
+			return errors.New("设置 RTS 失败")
+		}
+		return nil
+	}
+
+	// Ctrl+] 之后的输入不再处理
+	src := &chunkSource{chunks: []string{"ls\r", "\x12", "\x12x", "\x1dhelp\r"}}
+	monitorInput(src, m, reset, make(chan struct{}))
+
+	if string(m.sent) != "ls\rx" {
+		t.Errorf("sent %q, want %q", m.sent, "ls\rx")
+	}
+	want := []string{"已复位设备", "复位失败: 设置 RTS 失败"}
+	if !reflect.DeepEqual(m.notices, want) {
+		t.Errorf("notices = %q, want %q", m.notices, want)
+	}
+
+	// 输入结束时返回
+	m = &fakeMonitor{}
+	monitorInput(&chunkSource{chunks: []string{"a"}}, m, reset, make(chan struct{}))
+	if string(m.sent) != "a" {
+		t.Errorf("sent %q, want %q", m.sent, "a")
+	}
+}
+
+func TestDTSConsoleBaud(t *testing.T) {
+	dir := t.TempDir()
+	if got := dtsConsoleBaud(dir); got != DefaultMonitorBaud {
+		t.Errorf("dtsConsoleBaud() without board.dts = %d, want %d", got, DefaultMonitorBaud)
+	}
+
+	dts := `/ {
+    model = "baudrate = <9600>";
+    uart {
+        uart@4000A000 {
+            id = <0>;
+            baudrate = <115200>;
+        };
+        uart@4000A100 {
+            id = <1>;
+            baudrate = <2000000>;
+        };
+    };
+};`
+	os.WriteFile(filepath.Join(dir, "board.dts"), []byte(dts), 0644)
+	if got := dtsConsoleBaud(dir); got != 115200 {
+		t.Errorf("dtsConsoleBaud() = %d, want 115200", got)
+	}
+}
+
+func TestCRLFWriter(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := crlfWriter{&buf}.Write([]byte("a\nb\n"))
+	if n != 4 || err != nil || buf.String() != "a\r\nb\r\n" {
+		t.Errorf("Write() = %d, %v, output %q", n, err, buf.String())
+	}
+}
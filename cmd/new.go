@@ -3,9 +3,9 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -17,6 +17,23 @@ import (
 var (
 	projectPath string
 	interactive bool
+	sdkPathMode string
+	dryRun      bool
+	showContent bool
+	diffDir     string
+	keepOnError bool
+	// 逗号分隔的组件列表，非空时跳过交互选择
+	componentList string
+	// 预设名称，非空时使用预设中的组件和选项
+	presetName string
+	// 模组名称，为空时使用默认模组
+	boardName string
+	// 手动指定的引脚，格式为 NAME=GPIO
+	pinList []string
+	// 覆盖默认值的组件选项，格式为 组件.选项=值
+	optionList []string
+	// submodule 模式下 SDK 子模块在项目中的目录
+	submoduleDir string
 )
 
 // clearScreen 跨平台清屏函数
@@ -33,7 +50,15 @@ var newCmd = &cobra.Command{
 示例:
   wb2-cli new my_project
   wb2-cli new my_project --path ./projects
-  wb2-cli new my_project --sdk-path /path/to/sdk`,
+  wb2-cli new my_project --sdk-path /path/to/sdk
+  wb2-cli new my_project --sdk-path-mode relative
+  wb2-cli new my_project --sdk-path-mode submodule --sdk-submodule-dir sdk
+  wb2-cli new my_project --components wifi,mqtt
+  wb2-cli new my_project --preset mqtt-gateway
+  wb2-cli new my_project --board ai-wb2-32s
+  wb2-cli new my_project --components spi --pin SPI_CS=5
+  wb2-cli new my_project --components uart --option uart.baudrate=9600
+  wb2-cli new my_project --dry-run --diff ./my_project`,
 	Args: cobra.ExactArgs(1),
 	RunE: runNew,
 }
@@ -43,6 +68,17 @@ func init() {
 
 	newCmd.Flags().StringVarP(&projectPath, "path", "p", ".", "项目创建路径（默认为当前目录）")
 	newCmd.Flags().BoolVarP(&interactive, "interactive", "i", true, "交互式选择组件（默认启用）")
+	newCmd.Flags().StringVar(&componentList, "components", "", "以逗号分隔的组件列表（或 all），指定后不再交互选择")
+	newCmd.Flags().StringVar(&presetName, "preset", "", "使用预设的组件和选项（--components 中的组件会追加到预设中）")
+	newCmd.Flags().StringVar(&boardName, "board", "", "目标模组，如 ai-wb2-12f、ai-wb2-01s（默认为 ai-wb2-12f）")
+	newCmd.Flags().StringArrayVar(&pinList, "pin", nil, "手动指定外设引脚，格式为 NAME=GPIO（如 SPI_CS=5），可重复使用")
+	newCmd.Flags().StringArrayVar(&optionList, "option", nil, "设置组件选项，格式为 组件.选项=值（如 uart.baudrate=9600），可重复使用")
+	newCmd.Flags().StringVar(&sdkPathMode, "sdk-path-mode", "absolute", "Makefile 中 SDK 路径的写法: absolute、relative、env 或 submodule")
+	newCmd.Flags().StringVar(&submoduleDir, "sdk-submodule-dir", generator.DefaultSubmoduleDir, "submodule 模式下 SDK 子模块在项目中的目录（SDK 不在项目内时使用）")
+	newCmd.Flags().BoolVar(&dryRun, "dry-run", false, "只预览将要生成的文件，不写入磁盘")
+	newCmd.Flags().BoolVar(&showContent, "show-content", false, "dry-run 时输出每个文件的完整内容（需要 --dry-run）")
+	newCmd.Flags().StringVar(&diffDir, "diff", "", "dry-run 时与指定的已有目录进行 diff 对比（需要 --dry-run）")
+	newCmd.Flags().BoolVar(&keepOnError, "keep-on-error", false, "生成失败时保留未完成的输出，便于调试")
 }
 
 func runNew(cmd *cobra.Command, args []string) error {
@@ -53,58 +89,190 @@ func runNew(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("无效的项目名称: %s (只能包含字母、数字、下划线和连字符)", projectName)
 	}
 
+	if err := checkDryRunFlags(dryRun, showContent, diffDir); err != nil {
+		return err
+	}
+
+	setup, err := prepareProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	// 生成项目路径
+	fullProjectPath := filepath.Join(projectPath, projectName)
+
+	// dry-run：在内存中渲染并预览
+	if dryRun {
+		out := generator.NewMemoryOutput()
+		setup.gen.SetOutput(out)
+		if err := setup.gen.GenerateProject(projectName, fullProjectPath, setup.resolved); err != nil {
+			return fmt.Errorf("生成项目失败: %v", err)
+		}
+		printGenWarnings(os.Stdout, setup.gen)
+		return printDryRun(os.Stdout, out, fullProjectPath, showContent, diffDir)
+	}
+
+	// 检查目录是否已存在
+	if _, err := os.Stat(fullProjectPath); err == nil {
+		return fmt.Errorf("项目目录已存在: %s", fullProjectPath)
+	}
+
+	// 创建项目
+	err = setup.gen.GenerateProject(projectName, fullProjectPath, setup.resolved)
+	if err != nil {
+		return fmt.Errorf("生成项目失败: %v", err)
+	}
+	printGenWarnings(os.Stdout, setup.gen)
+
+	fmt.Printf("\n✅ 项目创建成功！\n")
+	fmt.Printf("📁 项目路径: %s\n", fullProjectPath)
+	fmt.Printf("🔧 目标模组: %s\n", setup.board.Module)
+	fmt.Printf("📦 已选择组件: %s\n", strings.Join(setup.selected, ", "))
+	fmt.Printf("\n下一步:\n")
+	fmt.Printf("  cd %s\n", fullProjectPath)
+	printSubmoduleHint(setup.gen, fullProjectPath)
+	fmt.Printf("  wb2-cli build\n")
+
+	return nil
+}
+
+// printGenWarnings 输出生成器在生成过程中记录的警告
+func printGenWarnings(w io.Writer, gen *generator.Generator) {
+	for _, warning := range gen.Warnings() {
+		fmt.Fprintf(w, "⚠️  警告: %s\n", warning)
+	}
+}
+
+// printSubmoduleHint submodule 模式下项目中还没有 SDK 子模块时，提示添加子模块
+func printSubmoduleHint(gen *generator.Generator, projectPath string) {
+	if dir := gen.MissingSubmodule(projectPath); dir != "" {
+		fmt.Printf("  git init  # 如果还不是 git 仓库\n")
+		fmt.Printf("  git submodule add %s %s\n", generator.SDKRepository, dir)
+	}
+}
+
+// projectSetup new 和 init 共用的准备结果
+type projectSetup struct {
+	selected []string
+	resolved []config.Component
+	board    *config.Board
+	gen      *generator.Generator
+}
+
+// prepareProject 获取并验证 SDK 路径、选择组件并解析依赖，返回配置好的生成器
+func prepareProject(cmd *cobra.Command) (*projectSetup, error) {
 	// 获取 SDK 路径
 	sdkPath, err := getSDKPath()
 	if err != nil {
-		return fmt.Errorf("获取 SDK 路径失败: %v", err)
+		return nil, fmt.Errorf("获取 SDK 路径失败: %v", err)
 	}
 
 	// 验证 SDK 路径
 	if !isValidSDKPath(sdkPath) {
-		return fmt.Errorf("无效的 SDK 路径: %s", sdkPath)
+		return nil, fmt.Errorf("无效的 SDK 路径: %s", sdkPath)
 	}
 
 	// 加载组件配置
 	components, err := config.LoadComponents()
 	if err != nil {
-		return fmt.Errorf("加载组件配置失败: %v", err)
+		return nil, fmt.Errorf("加载组件配置失败: %v", err)
 	}
 
-	// 交互式选择组件
-	selectedComponents, err := selectComponents(components)
+	presets, err := config.LoadPresets()
 	if err != nil {
-		return fmt.Errorf("选择组件失败: %v", err)
+		return nil, fmt.Errorf("加载预设失败: %v", err)
 	}
 
-	// 解析组件依赖
-	resolvedComponents, err := resolveDependencies(components, selectedComponents)
+	pins, err := parsePinOverrides(pinList)
 	if err != nil {
-		return fmt.Errorf("解析组件依赖失败: %v", err)
+		return nil, err
 	}
 
-	// 生成项目路径
-	fullProjectPath := filepath.Join(projectPath, projectName)
+	options, err := parseOptionOverrides(optionList)
+	if err != nil {
+		return nil, err
+	}
 
-	// 检查目录是否已存在
-	if _, err := os.Stat(fullProjectPath); err == nil {
-		return fmt.Errorf("项目目录已存在: %s", fullProjectPath)
+	// 使用预设，或交互式选择组件（菜单中也可以选择预设）
+	var selectedComponents []string
+	var preset *config.Preset
+	if presetName != "" {
+		found, ok := config.FindPreset(presets, presetName)
+		if !ok {
+			return nil, fmt.Errorf("预设不存在: %s（可用预设: %s）", presetName, strings.Join(presetNames(presets), ", "))
+		}
+		preset = found
+		if selectedComponents, err = presetComponents(components, preset, componentList); err != nil {
+			return nil, err
+		}
+	} else if selectedComponents, preset, err = selectComponents(components, presets, overridePreset(pins, options)); err != nil {
+		return nil, fmt.Errorf("选择组件失败: %v", err)
 	}
 
-	// 创建项目
-	gen := generator.New(sdkPath)
-	err = gen.GenerateProject(projectName, fullProjectPath, resolvedComponents)
+	if preset != nil {
+		if err := applyPresetOptions(cmd.Flags(), preset); err != nil {
+			return nil, err
+		}
+		if pins, err = presetPinOverrides(preset, pins); err != nil {
+			return nil, err
+		}
+		if options, err = presetComponentOptions(preset, options); err != nil {
+			return nil, err
+		}
+	}
+
+	// 解析 SDK 路径模式（预设可能设置了该选项）
+	mode, err := generator.ParseSDKPathMode(sdkPathMode)
 	if err != nil {
-		return fmt.Errorf("生成项目失败: %v", err)
+		return nil, err
 	}
 
-	fmt.Printf("\n✅ 项目创建成功！\n")
-	fmt.Printf("📁 项目路径: %s\n", fullProjectPath)
-	fmt.Printf("📦 已选择组件: %s\n", strings.Join(selectedComponents, ", "))
-	fmt.Printf("\n下一步:\n")
-	fmt.Printf("  cd %s\n", fullProjectPath)
-	fmt.Printf("  make -j8\n")
+	board, err := loadBoard(boardName)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	// 解析组件依赖
+	resolvedComponents, err := resolveDependencies(components, selectedComponents)
+	if err != nil {
+		return nil, fmt.Errorf("解析组件依赖失败: %v", err)
+	}
+
+	gen := generator.New(sdkPath)
+	gen.SetSDKPathMode(mode)
+	gen.SetSubmoduleDir(submoduleDir)
+	gen.SetBoard(*board)
+	gen.SetPinOverrides(pins)
+	gen.SetOptions(options)
+	gen.SetKeepOnError(keepOnError)
+
+	return &projectSetup{
+		selected: selectedComponents,
+		resolved: resolvedComponents,
+		board:    board,
+		gen:      gen,
+	}, nil
+}
+
+// loadBoard 从模组目录中查找模组，name 为空时返回默认模组
+func loadBoard(name string) (*config.Board, error) {
+	boards, err := config.LoadBoards()
+	if err != nil {
+		return nil, fmt.Errorf("加载模组配置失败: %v", err)
+	}
+
+	board, ok := config.FindBoard(boards, name)
+	if !ok {
+		names := make([]string, 0, len(boards))
+		for _, b := range boards {
+			names = append(names, b.Name)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("模组配置中没有默认模组，请使用 --board 指定（可用模组: %s）", strings.Join(names, ", "))
+		}
+		return nil, fmt.Errorf("模组不存在: %s（可用模组: %s）", name, strings.Join(names, ", "))
+	}
+	return board, nil
 }
 
 func isValidProjectName(name string) bool {
@@ -146,15 +314,14 @@ func autoDetectSDKPath() (string, error) {
 		return "", err
 	}
 
-	// 检查当前目录是否是 SDK 根目录
-	if isValidSDKPath(cwd) {
-		return cwd, nil
-	}
-
-	// 检查父目录
-	parent := filepath.Dir(cwd)
-	if isValidSDKPath(parent) {
-		return parent, nil
+	// 逐级检查当前目录及其上级目录（如 SDK 的 applications/ 下的示例项目）
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		if isValidSDKPath(dir) {
+			return dir, nil
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
 	}
 
 	return "", fmt.Errorf("无法自动检测 SDK 路径，请使用 --sdk-path 参数指定")
@@ -178,315 +345,147 @@ func isValidSDKPath(path string) bool {
 	return true
 }
 
-// selectComponentsWindows Windows版本的组件选择（简化版）
-func selectComponentsWindows(allComponents []config.Component) ([]string, error) {
-	fmt.Println("🌟 wb2-cli - 组件选择器")
-	fmt.Println("========================")
-	fmt.Println()
-	fmt.Println("在Windows环境下，我们提供简化的组件选择方式。")
-	fmt.Println("您可以输入组件名称（多个用逗号分隔），或者输入'all'选择所有组件。")
-	fmt.Println()
-
-	// 按分类显示可用组件
-	categories := make(map[string][]config.Component)
-	for _, comp := range allComponents {
-		categories[comp.Category] = append(categories[comp.Category], comp)
-	}
-
-	for category, comps := range categories {
-		fmt.Printf("📁 %s:\n", category)
-		for _, comp := range comps {
-			fmt.Printf("  - %s: %s\n", comp.Name, comp.Description)
-		}
-		fmt.Println()
-	}
-
-	fmt.Print("请输入要选择的组件（用逗号分隔，或输入'all'选择全部，或按回车跳过）: ")
-
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return nil, err
-	}
+// stdin 按行读取标准输入时共用的缓冲读取器，避免多个读取器之间丢失输入
+var stdin = bufio.NewReader(os.Stdin)
 
+// parseComponentList 解析逗号分隔的组件列表，"all" 表示全部组件；
+// 返回存在的组件和不存在的名称
+func parseComponentList(allComponents []config.Component, input string) ([]string, []string) {
 	input = strings.TrimSpace(input)
-	if input == "" {
-		return []string{}, nil
-	}
-
 	if input == "all" {
-		var allNames []string
+		allNames := []string{}
 		for _, comp := range allComponents {
 			allNames = append(allNames, comp.Name)
 		}
 		return allNames, nil
 	}
 
-	// 解析用户输入的组件名称
-	selectedNames := strings.Split(input, ",")
-	var validSelections []string
+	known := make(map[string]bool)
+	for _, comp := range allComponents {
+		known[comp.Name] = true
+	}
 
-	for _, name := range selectedNames {
+	validSelections := []string{}
+	var unknown []string
+	for _, name := range strings.Split(input, ",") {
 		name = strings.TrimSpace(name)
 		if name == "" {
 			continue
 		}
-
-		// 检查组件是否存在
-		found := false
-		for _, comp := range allComponents {
-			if comp.Name == name {
-				validSelections = append(validSelections, name)
-				found = true
-				break
-			}
+		if known[name] {
+			validSelections = append(validSelections, name)
+		} else {
+			unknown = append(unknown, name)
 		}
+	}
+	return validSelections, unknown
+}
 
-		if !found {
-			fmt.Printf("⚠️  警告: 组件 '%s' 不存在，已跳过\n", name)
+// selectComponentsLine 按行选择组件：列出所有组件后读取一行逗号分隔的组件名称。
+// 用于无法显示交互菜单的环境（如 Windows 控制台、管道输入）
+func selectComponentsLine(in *bufio.Reader, w io.Writer, allComponents []config.Component) ([]string, error) {
+	fmt.Fprintln(w, "🌟 wb2-cli - 组件选择器")
+	fmt.Fprintln(w, "========================")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "您可以输入组件名称（多个用逗号分隔），或者输入'all'选择所有组件。")
+	fmt.Fprintln(w)
+
+	// 按分类显示可用组件，顺序与交互菜单一致
+	s := newSelector(allComponents)
+	for _, category := range s.categories {
+		fmt.Fprintf(w, "📁 %s:\n", categoryDisplayName(category))
+		for _, comp := range s.byCategory[category] {
+			fmt.Fprintf(w, "  - %s: %s\n", comp.Name, comp.Description)
 		}
+		fmt.Fprintln(w)
 	}
 
-	return validSelections, nil
-}
+	fmt.Fprint(w, "请输入要选择的组件（用逗号分隔，或输入'all'选择全部，或按回车跳过）: ")
 
-func selectComponents(allComponents []config.Component) ([]string, error) {
-	if !interactive {
-		// 非交互模式，返回空列表（只包含基础组件）
-		return []string{}, nil
+	input, err := in.ReadString('\n')
+	if err == io.EOF && strings.TrimSpace(input) == "" {
+		fmt.Fprintln(w)
+		return nil, fmt.Errorf("标准输入已结束，没有读取到组件选择；非交互环境请使用 --components 指定组件，或使用 --interactive=false 只包含基础组件")
 	}
-
-	// 根据操作系统选择不同的交互方式
-	if runtime.GOOS == "windows" {
-		return selectComponentsWindows(allComponents)
+	if err != nil && err != io.EOF {
+		return nil, err
 	}
 
-	// Unix/Linux 版本使用原始终端交互
-
-	// 按分类组织组件
-	componentsByCategory := make(map[string][]config.Component)
-	categoryNames := map[string]string{
-		"network":    "🌐 网络组件",
-		"peripheral": "🔌 外设组件",
-		"3rdparty":   "📦 第三方组件",
-		"audio":      "🔊 音频组件",
-		"fs":         "💾 文件系统组件",
-		"multimedia": "🎬 多媒体组件",
-		"system":     "⚙️  系统组件",
-		"other":      "📋 其他组件",
+	validSelections, unknown := parseComponentList(allComponents, input)
+	for _, name := range unknown {
+		fmt.Fprintf(w, "⚠️  警告: 组件 '%s' 不存在，已跳过\n", name)
 	}
+	return validSelections, nil
+}
 
-	// 将组件按分类分组
-	for _, comp := range allComponents {
-		category := comp.Category
-		if category == "" {
-			category = "other"
+// selectComponents 选择组件；在交互菜单中应用了预设时同时返回该预设。
+// overrides 为命令行指定的组件选项和引脚，在菜单中保存预设时一并保存
+func selectComponents(allComponents []config.Component, presets []config.Preset, overrides config.Preset) ([]string, *config.Preset, error) {
+	// 命令行指定了组件列表时不再交互
+	if componentList != "" {
+		selected, unknown := parseComponentList(allComponents, componentList)
+		if len(unknown) > 0 {
+			return nil, nil, fmt.Errorf("组件不存在: %s", strings.Join(unknown, ", "))
 		}
-		componentsByCategory[category] = append(componentsByCategory[category], comp)
+		return selected, nil, nil
 	}
 
-	// 已选择的组件集合
-	selectedSet := make(map[string]bool)
-	categoryOrder := []string{"network", "peripheral", "3rdparty", "audio", "fs", "multimedia", "system", "other"}
-
-	// 当前菜单状态
-	currentCategory := ""
-	selectedIndex := 0
-	componentIndex := 0
-
-	// 计算有效分类列表（只计算一次，避免重复计算）
-	validCategories := []string{}
-	for _, cat := range categoryOrder {
-		if len(componentsByCategory[cat]) > 0 {
-			validCategories = append(validCategories, cat)
-		}
+	if !interactive {
+		// 非交互模式，返回空列表（只包含基础组件）
+		return []string{}, nil, nil
 	}
 
-	// 主循环
-	for {
-		// 清屏
-		clearScreen()
-
-		if currentCategory == "" {
-			// 显示主菜单（分类列表）
-			fmt.Println(strings.Repeat("=", 70))
-			fmt.Println("           WB2 组件选择菜单 (类似 menuconfig)")
-			fmt.Println(strings.Repeat("=", 70))
-			fmt.Println()
-
-			// 显示已选择的组件
-			if len(selectedSet) > 0 {
-				fmt.Println("已选择的组件:")
-				selectedList := make([]string, 0, len(selectedSet))
-				for name := range selectedSet {
-					selectedList = append(selectedList, name)
-				}
-				// 排序
-				for i := 0; i < len(selectedList)-1; i++ {
-					for j := i + 1; j < len(selectedList); j++ {
-						if selectedList[i] > selectedList[j] {
-							selectedList[i], selectedList[j] = selectedList[j], selectedList[i]
-						}
-					}
-				}
-				for _, name := range selectedList {
-					fmt.Printf("  ✓ %s\n", name)
-				}
-				fmt.Println()
-			}
+	// 没有终端（管道输入、CI）时无法显示菜单，改为从标准输入按行读取
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		selected, err := selectComponentsLine(stdin, os.Stdout, allComponents)
+		return selected, nil, err
+	}
 
-			// 显示分类列表
-			fmt.Println("请选择分类:")
-			for i, cat := range validCategories {
-				comps := componentsByCategory[cat]
-				catDisplayName := categoryNames[cat]
-				if catDisplayName == "" {
-					catDisplayName = cat
-				}
-
-				prefix := "  "
-				if i == selectedIndex {
-					prefix = "> "
-				}
-				fmt.Printf("%s▶ %s (%d)\n", prefix, catDisplayName, len(comps))
-			}
-			fmt.Println()
-			fmt.Println("操作: ↑↓ 导航 | → 进入 | 回车 完成选择")
+	// 整个菜单期间保持 raw 模式；终端设置与平台相关，见 terminal_*.go
+	restore, err := setupTerminal(os.Stdin, os.Stdout)
+	if err != nil {
+		// 旧版 Windows 控制台不支持虚拟终端序列
+		fmt.Printf("⚠️  警告: 当前终端不支持交互菜单（%v），改为按行选择\n", err)
+		selected, err := selectComponentsLine(stdin, os.Stdout, allComponents)
+		return selected, nil, err
+	}
+	defer restore()
+
+	fmt.Print("\033[?25l") // 隐藏光标
+	defer fmt.Print("\033[?25h")
+	clearScreen()
+
+	size := func() (int, int, error) {
+		return term.GetSize(int(os.Stdout.Fd()))
+	}
+	s := newSelector(allComponents)
+	s.setPresets(presets)
+	s.savePreset = saveUserPreset
+	s.overrides = overrides
+	selection, err := runSelector(s, newKeyReader(newTTYSource(os.Stdin), escTimeout), os.Stdout, size)
+	clearScreen()
+	return selection, s.preset, err
+}
 
-		} else {
-			// 显示分类内的组件列表
-			comps := componentsByCategory[currentCategory]
-			catDisplayName := categoryNames[currentCategory]
-
-			fmt.Println(strings.Repeat("=", 70))
-			fmt.Printf("  %s\n", catDisplayName)
-			fmt.Println(strings.Repeat("=", 70))
-			fmt.Println()
-
-			fmt.Println("请选择组件:")
-			for i, comp := range comps {
-				prefix := "  "
-				if i == componentIndex {
-					prefix = "> "
-				}
-
-				status := " "
-				if selectedSet[comp.Name] {
-					status = "✓"
-				}
-
-				fmt.Printf("%s[%s] %s - %s\n", prefix, status, comp.Name, comp.Description)
-			}
-			fmt.Println()
-			fmt.Println("操作: ↑↓ 导航 | 空格 选择/取消 | ← 返回 | 回车 返回")
+// runSelector 运行选择菜单主循环：绘制、读取按键、更新状态，直到完成或取消
+func runSelector(s *selector, keys *keyReader, w io.Writer, size func() (int, int, error)) ([]string, error) {
+	for {
+		if width, height, err := size(); err == nil {
+			s.width, s.height = width, height
 		}
+		fmt.Fprint(w, s.render())
 
-		// 读取按键
-		oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+		k, err := keys.readKey()
 		if err != nil {
 			return nil, err
 		}
 
-		reader := bufio.NewReader(os.Stdin)
-		char, _, err := reader.ReadRune()
-		
-		if err != nil {
-			term.Restore(int(os.Stdin.Fd()), oldState)
-			return nil, err
+		s.handleKey(k)
+		if s.cancelled {
+			return nil, fmt.Errorf("用户取消")
 		}
-
-		// 处理按键
-		if currentCategory == "" {
-			// 主菜单模式
-			compsCount := len(validCategories)
-
-			switch char {
-			case 27: // ESC 序列
-				buf := make([]byte, 2)
-				reader.Read(buf)
-				term.Restore(int(os.Stdin.Fd()), oldState)
-				if buf[0] == '[' {
-					switch buf[1] {
-					case 'A': // ↑
-						if selectedIndex > 0 {
-							selectedIndex--
-						}
-					case 'B': // ↓
-						if selectedIndex < compsCount-1 {
-							selectedIndex++
-						}
-					case 'C': // →
-						// 进入选中的分类
-						if selectedIndex < len(validCategories) {
-							currentCategory = validCategories[selectedIndex]
-							componentIndex = 0
-						}
-					}
-				}
-				continue
-			case '\n', '\r': // 回车 - 完成选择
-				// 转换为组件名称列表
-				selectedComponents := make([]string, 0, len(selectedSet))
-				for name := range selectedSet {
-					selectedComponents = append(selectedComponents, name)
-				}
-				term.Restore(int(os.Stdin.Fd()), oldState)
-				clearScreen() // 清屏
-				return selectedComponents, nil
-			case 'q', 'Q':
-				// 退出
-				term.Restore(int(os.Stdin.Fd()), oldState)
-				clearScreen() // 清屏
-				return nil, fmt.Errorf("用户取消")
-			default:
-				term.Restore(int(os.Stdin.Fd()), oldState)
-			}
-		} else {
-			// 组件列表模式
-			comps := componentsByCategory[currentCategory]
-
-			switch char {
-			case 27: // ESC 序列
-				buf := make([]byte, 2)
-				reader.Read(buf)
-				term.Restore(int(os.Stdin.Fd()), oldState)
-				if buf[0] == '[' {
-					switch buf[1] {
-					case 'A': // ↑
-						if componentIndex > 0 {
-							componentIndex--
-						}
-					case 'B': // ↓
-						if componentIndex < len(comps)-1 {
-							componentIndex++
-						}
-					case 'D': // ←
-						// 返回主菜单
-						currentCategory = ""
-						selectedIndex = 0
-					}
-				}
-				continue
-			case ' ': // 空格 - 切换选择状态
-				if componentIndex < len(comps) {
-					comp := comps[componentIndex]
-					selectedSet[comp.Name] = !selectedSet[comp.Name]
-				}
-				term.Restore(int(os.Stdin.Fd()), oldState)
-				continue
-			case '\n', '\r': // 回车 - 返回上一级
-				currentCategory = ""
-				selectedIndex = 0
-				term.Restore(int(os.Stdin.Fd()), oldState)
-				continue
-			case 'q', 'Q':
-				// 退出
-				term.Restore(int(os.Stdin.Fd()), oldState)
-				clearScreen() // 清屏
-				return nil, fmt.Errorf("用户取消")
-			default:
-				term.Restore(int(os.Stdin.Fd()), oldState)
-			}
+		if s.done {
+			return s.selection(), nil
 		}
 	}
 }
@@ -526,10 +525,22 @@ func resolveDependencies(allComponents []config.Component, selected []string) ([
 		}
 	}
 
-	// 转换为组件列表
+	// 检查组件冲突
+	conflicts := conflictMap(allComponents)
+	for _, comp := range allComponents {
+		for _, other := range allComponents {
+			if conflicts[comp.Name][other.Name] && resolved[comp.Name] && resolved[other.Name] {
+				return nil, fmt.Errorf("组件冲突: %s 与 %s 不能同时使用", comp.Name, other.Name)
+			}
+		}
+	}
+
+	// 转换为组件列表，按组件目录中的顺序，使生成结果与选择顺序无关
 	result := make([]config.Component, 0, len(resolved))
-	for name := range resolved {
-		result = append(result, componentMap[name])
+	for _, comp := range allComponents {
+		if resolved[comp.Name] {
+			result = append(result, comp)
+		}
 	}
 
 	return result, nil
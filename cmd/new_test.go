@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"bufio"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"gopkg.in/yaml.v3"
 	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
 )
 
 func TestIsValidProjectName(t *testing.T) {
@@ -197,6 +202,30 @@ func TestResolveDependencies(t *testing.T) {
 	}
 }
 
+func TestResolveDependenciesOrder(t *testing.T) {
+	components := []config.Component{
+		{Name: "wifi"},
+		{Name: "mqtt", Dependencies: []string{"wifi"}},
+		{Name: "gpio"},
+		{Name: "spi"},
+	}
+
+	// 结果按组件目录的顺序，与选择顺序无关
+	for _, selected := range [][]string{{"spi", "gpio", "mqtt"}, {"gpio", "mqtt", "spi"}, {"mqtt", "spi", "gpio"}} {
+		result, err := resolveDependencies(components, selected)
+		if err != nil {
+			t.Fatalf("resolveDependencies failed: %v", err)
+		}
+		var names []string
+		for _, comp := range result {
+			names = append(names, comp.Name)
+		}
+		if got := strings.Join(names, ","); got != "wifi,mqtt,gpio,spi" {
+			t.Errorf("selected %v: expected catalog order, got %s", selected, got)
+		}
+	}
+}
+
 func TestResolveDependenciesNonExistent(t *testing.T) {
 	components := []config.Component{
 		{Name: "wifi", Description: "WiFi component"},
@@ -240,4 +269,179 @@ func TestSelectComponentsWindows(t *testing.T) {
 
 	// Skip the actual function call since it requires stdin input
 	t.Skip("Skipping interactive test - requires stdin mocking")
-}
\ No newline at end of file
+}
+func TestParseComponentList(t *testing.T) {
+	components := []config.Component{
+		{Name: "wifi", Category: "network"},
+		{Name: "mqtt", Category: "network"},
+	}
+
+	selected, unknown := parseComponentList(components, " mqtt, ,nope,wifi ")
+	if !reflect.DeepEqual(selected, []string{"mqtt", "wifi"}) {
+		t.Errorf("Expected [mqtt wifi], got %v", selected)
+	}
+	if !reflect.DeepEqual(unknown, []string{"nope"}) {
+		t.Errorf("Expected unknown [nope], got %v", unknown)
+	}
+
+	selected, _ = parseComponentList(components, "all")
+	if !reflect.DeepEqual(selected, []string{"wifi", "mqtt"}) {
+		t.Errorf("Expected all components, got %v", selected)
+	}
+}
+
+func TestSelectComponentsLine(t *testing.T) {
+	components := []config.Component{
+		{Name: "gpio", Description: "GPIO", Category: "peripheral"},
+		{Name: "wifi", Description: "WiFi", Category: "network"},
+		{Name: "mqtt", Description: "MQTT", Category: "network"},
+	}
+
+	var out strings.Builder
+	selected, err := selectComponentsLine(bufio.NewReader(strings.NewReader("wifi,missing,gpio\n")), &out, components)
+	if err != nil {
+		t.Fatalf("selectComponentsLine failed: %v", err)
+	}
+	if !reflect.DeepEqual(selected, []string{"wifi", "gpio"}) {
+		t.Errorf("Expected [wifi gpio], got %v", selected)
+	}
+	if !strings.Contains(out.String(), "'missing' 不存在") {
+		t.Errorf("Expected warning for unknown component, got:\n%s", out.String())
+	}
+	// 分类按固定顺序显示
+	if strings.Index(out.String(), "网络组件") > strings.Index(out.String(), "外设组件") {
+		t.Errorf("Expected network category before peripheral, got:\n%s", out.String())
+	}
+
+	// 最后一行没有换行符
+	selected, err = selectComponentsLine(bufio.NewReader(strings.NewReader("mqtt")), &out, components)
+	if err != nil || !reflect.DeepEqual(selected, []string{"mqtt"}) {
+		t.Errorf("Expected [mqtt], got %v, %v", selected, err)
+	}
+
+	// 空行表示只使用基础组件
+	selected, err = selectComponentsLine(bufio.NewReader(strings.NewReader("\n")), &out, components)
+	if err != nil || len(selected) != 0 {
+		t.Errorf("Expected empty selection, got %v, %v", selected, err)
+	}
+
+	// 输入已结束时提示使用 --components
+	_, err = selectComponentsLine(bufio.NewReader(strings.NewReader("")), &out, components)
+	if err == nil || !strings.Contains(err.Error(), "--components") {
+		t.Errorf("Expected error suggesting --components, got %v", err)
+	}
+}
+
+func TestSelectComponentsFromFlag(t *testing.T) {
+	components := []config.Component{
+		{Name: "wifi", Category: "network"},
+		{Name: "mqtt", Category: "network"},
+	}
+
+	oldList := componentList
+	defer func() { componentList = oldList }()
+
+	componentList = "mqtt,wifi"
+	selected, preset, err := selectComponents(components, nil, config.Preset{})
+	if err != nil || preset != nil || !reflect.DeepEqual(selected, []string{"mqtt", "wifi"}) {
+		t.Errorf("Expected [mqtt wifi], got %v, %v", selected, err)
+	}
+
+	componentList = "mqtt,nope"
+	if _, _, err := selectComponents(components, nil, config.Preset{}); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("Expected error for unknown component, got %v", err)
+	}
+}
+
+// setupPresetProject 准备 prepareProject 所需的组件目录、用户配置（含预设）和 SDK 目录
+func setupPresetProject(t *testing.T, preset config.Preset) {
+	t.Helper()
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(".."); err != nil {
+		t.Fatalf("Failed to change to repo root: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(oldWd) })
+
+	sdk := t.TempDir()
+	for _, dir := range []string{"applications", "components", "make_scripts_riscv"} {
+		os.MkdirAll(filepath.Join(sdk, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(sdk, "version.mk"), []byte("# Mock file"), 0644)
+
+	t.Setenv("HOME", t.TempDir())
+	if err := config.SaveConfig(&config.UserConfig{SDKPath: sdk, Presets: []config.Preset{preset}}); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	oldPreset, oldPins, oldOptions, oldComponents := presetName, pinList, optionList, componentList
+	t.Cleanup(func() {
+		presetName, pinList, optionList, componentList = oldPreset, oldPins, oldOptions, oldComponents
+	})
+	presetName, pinList, optionList, componentList = preset.Name, nil, nil, ""
+}
+
+// presetManifest 按预设准备项目并生成，返回项目清单
+func presetManifest(t *testing.T) *config.Manifest {
+	t.Helper()
+	setup, err := prepareProject(newCmd)
+	if err != nil {
+		t.Fatalf("prepareProject failed: %v", err)
+	}
+	out := generator.NewMemoryOutput()
+	setup.gen.SetOutput(out)
+	projectDir := filepath.Join(t.TempDir(), "demo")
+	if err := setup.gen.GenerateProject("demo", projectDir, setup.resolved); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+	var manifest config.Manifest
+	if err := yaml.Unmarshal(out.Content(filepath.Join(projectDir, config.ManifestFile)), &manifest); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	return &manifest
+}
+
+func TestPrepareProjectPresetValues(t *testing.T) {
+	setupPresetProject(t, config.Preset{
+		Name:             "serial",
+		Components:       []string{"uart"},
+		ComponentOptions: map[string]string{"uart.baudrate": "9600"},
+		Pins:             map[string]string{"uart1_tx": "IO3"},
+	})
+
+	manifest := presetManifest(t)
+	if manifest.Options["uart.baudrate"] != "9600" {
+		t.Errorf("Expected preset baudrate 9600, got %v", manifest.Options)
+	}
+	if manifest.Pins["UART1_TX"] != 3 {
+		t.Errorf("Expected preset UART1_TX on GPIO3, got %v", manifest.Pins)
+	}
+
+	// 命令行的 --option 和 --pin 优先于预设中的同名值
+	optionList = []string{"uart.baudrate=57600"}
+	pinList = []string{"UART1_TX=4"}
+	manifest = presetManifest(t)
+	if manifest.Options["uart.baudrate"] != "57600" {
+		t.Errorf("Expected --option to override preset baudrate, got %v", manifest.Options)
+	}
+	if manifest.Pins["UART1_TX"] != 4 {
+		t.Errorf("Expected --pin to override preset UART1_TX, got %v", manifest.Pins)
+	}
+}
+
+func TestPrepareProjectPresetInvalidValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset config.Preset
+	}{
+		{"invalid pin", config.Preset{Name: "bad", Components: []string{"uart"}, Pins: map[string]string{"UART1_TX": "x"}}},
+		{"invalid option", config.Preset{Name: "bad", Components: []string{"uart"}, ComponentOptions: map[string]string{"baudrate": "9600"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupPresetProject(t, tt.preset)
+			if _, err := prepareProject(newCmd); err == nil || !strings.Contains(err.Error(), "预设 bad") {
+				t.Errorf("Expected preset error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseOptionOverrides 解析 --option 组件.选项=值 参数
+func parseOptionOverrides(values []string) (map[string]string, error) {
+	options := make(map[string]string, len(values))
+	for _, value := range values {
+		key, optionValue, ok := strings.Cut(value, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		optionValue = strings.TrimSpace(optionValue)
+		comp, name, dotted := strings.Cut(key, ".")
+		if !ok || !dotted || comp == "" || name == "" || optionValue == "" {
+			return nil, fmt.Errorf("无效的组件选项 %q，格式为 组件.选项=值（如 uart.baudrate=9600）", value)
+		}
+		if _, exists := options[key]; exists {
+			return nil, fmt.Errorf("组件选项 %s 被重复指定", key)
+		}
+		options[key] = optionValue
+	}
+	return options, nil
+}
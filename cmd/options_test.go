@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOptionOverrides(t *testing.T) {
+	options, err := parseOptionOverrides([]string{"uart.baudrate=9600", " PWM.Duty = 25 "})
+	if err != nil {
+		t.Fatalf("parseOptionOverrides failed: %v", err)
+	}
+	expected := map[string]string{"uart.baudrate": "9600", "pwm.duty": "25"}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("Expected %v, got %v", expected, options)
+	}
+
+	for _, invalid := range [][]string{{"baudrate=9600"}, {"uart.baudrate"}, {"uart.=1"}, {"uart.baudrate="}, {"uart.baudrate=1", "UART.baudrate=2"}} {
+		if _, err := parseOptionOverrides(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
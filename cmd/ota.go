@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/build"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/ota"
+	"wb2-cli/internal/partition"
+)
+
+var (
+	otaPath     string
+	otaInput    string
+	otaOutput   string
+	otaXZ       bool
+	otaHardware string
+	otaSoftware string
+	otaDir      string
+	otaAddr     string
+)
+
+// otaFirmwarePattern SDK 编译时由烧录工具生成的带启动头的固件，即写入 FW 分区的内容
+const otaFirmwarePattern = "ota/*/FW_OTA.bin"
+
+// otaCmd represents the ota command
+var otaCmd = &cobra.Command{
+	Use:   "ota",
+	Short: "生成 OTA 镜像并在本地提供下载",
+}
+
+// otaPackCmd represents the ota pack command
+var otaPackCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "把编译生成的固件打包为 OTA 镜像",
+	Long: `把固件打包为 SDK bl_sys_ota 使用的 OTA 镜像：512 字节的头（BL60X_OTA_Ver1.0、类型、
+长度、硬件和软件版本、固件的 SHA-256），之后是固件本身，--xz 时为 xz 压缩后的固件
+（需要系统中有 xz 命令）。
+
+固件默认为 SDK 编译时生成的 build_out/ota/*/FW_OTA.bin（带启动头，即写入 FW 分区的内容），
+并检查固件是否超出 partition.toml 中 FW 分区的第二个槽（选择 ota 组件时保留）。
+
+示例:
+  wb2-cli ota pack
+  wb2-cli ota pack --xz --sw-version 1.2.0
+  wb2-cli ota pack -i FW_OTA.bin -o app.ota`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runOTAPack,
+}
+
+// otaServeCmd represents the ota serve command
+var otaServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "通过 HTTP 提供 OTA 镜像下载（开发用）",
+	Long: `启动本地 HTTP 服务，提供目录中的文件下载，供开发时设备通过局域网获取 OTA 镜像。
+默认目录为项目的 build_out，启动时列出其中 OTA 镜像的下载地址，并记录每个请求。
+
+示例:
+  wb2-cli ota serve
+  wb2-cli ota serve --addr :8000 --dir ./release`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runOTAServe,
+}
+
+func init() {
+	rootCmd.AddCommand(otaCmd)
+	otaCmd.AddCommand(otaPackCmd)
+	otaCmd.AddCommand(otaServeCmd)
+
+	otaCmd.PersistentFlags().StringVarP(&otaPath, "path", "p", ".", "项目根目录")
+
+	otaPackCmd.Flags().StringVarP(&otaInput, "input", "i", "", "固件（默认为 build_out/"+otaFirmwarePattern+"）")
+	otaPackCmd.Flags().StringVarP(&otaOutput, "output", "o", "", "输出文件（默认为 build_out/<项目名>.ota 或 .xz.ota）")
+	otaPackCmd.Flags().BoolVar(&otaXZ, "xz", false, "使用 xz 压缩固件")
+	otaPackCmd.Flags().StringVar(&otaHardware, "hw-version", "", "硬件版本（最多 16 个字符）")
+	otaPackCmd.Flags().StringVar(&otaSoftware, "sw-version", "", "软件版本（最多 16 个字符）")
+
+	otaServeCmd.Flags().StringVar(&otaDir, "dir", "", "提供下载的目录（默认为项目的 build_out）")
+	otaServeCmd.Flags().StringVar(&otaAddr, "addr", ":8080", "监听地址")
+}
+
+func runOTAPack(cmd *cobra.Command, args []string) error {
+	manifest, _ := config.LoadManifest(otaPath)
+	input := otaInput
+	if input == "" {
+		if manifest == nil {
+			return fmt.Errorf("不在项目目录中，请使用 --input 指定固件")
+		}
+		var err error
+		if input, err = findOTAFirmware(otaPath); err != nil {
+			return err
+		}
+	}
+	firmware, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("读取固件失败: %v", err)
+	}
+	fmt.Printf("📦 固件: %s（%s）\n", input, formatSize(len(firmware)))
+
+	body := firmware
+	if otaXZ {
+		if body, err = ota.Compress(firmware); err != nil {
+			return err
+		}
+		fmt.Printf("🗜️  xz 压缩: %s → %s（%.0f%%）\n", formatSize(len(firmware)), formatSize(len(body)), float64(len(body))*100/float64(len(firmware)))
+	}
+	// 设备解析 OTA 头后只把固件（压缩后或原始固件，即头中的长度）写入第二个槽，
+	// 并用头中的长度检查槽大小，因此不计 512 字节的头
+	if manifest != nil {
+		slot, err := otaSlotSize(otaPath)
+		if err != nil {
+			return err
+		}
+		if slot == 0 {
+			fmt.Printf("⚠️  分区表中 FW 分区没有第二个槽，设备无法进行 OTA，请选择 ota 组件\n")
+		} else if err := checkOTASlot(len(body), slot); err != nil {
+			return err
+		}
+	}
+
+	image, err := ota.Pack(body, otaXZ, otaHardware, otaSoftware)
+	if err != nil {
+		return err
+	}
+
+	output := otaOutput
+	if output == "" {
+		output = defaultOTAOutput(otaPath, manifest, input, otaXZ)
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, image, 0644); err != nil {
+		return fmt.Errorf("写入 OTA 镜像失败: %v", err)
+	}
+	header, _, _ := ota.Parse(image)
+	fmt.Printf("✅ 已生成 OTA 镜像: %s（%s）\n", output, formatSize(len(image)))
+	fmt.Printf("   SHA-256: %x\n", header.SHA256)
+	return nil
+}
+
+// findOTAFirmware 查找 SDK 编译生成的 FW_OTA.bin，有多个时取最新的
+func findOTAFirmware(projectDir string) (string, error) {
+	matches, _ := filepath.Glob(filepath.Join(projectDir, build.BuildDir, otaFirmwarePattern))
+	if len(matches) == 0 {
+		return "", fmt.Errorf("找不到 %s/%s（由 SDK 编译时生成），请先运行 wb2-cli build 或使用 --input 指定固件", build.BuildDir, otaFirmwarePattern)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, _ := os.Stat(matches[i])
+		b, _ := os.Stat(matches[j])
+		return a.ModTime().After(b.ModTime())
+	})
+	return matches[0], nil
+}
+
+// checkOTASlot 检查 OTA 镜像中的固件（压缩后或原始固件）能否放入 FW 分区的第二个槽
+func checkOTASlot(bodySize, slot int) error {
+	if bodySize > slot {
+		return fmt.Errorf("OTA 固件（%s）超出 FW 分区第二个槽的大小 %s", formatSize(bodySize), partition.FormatSize(slot))
+	}
+	return nil
+}
+
+// otaSlotSize 返回 FW 分区第二个槽的大小，即 OTA 可以写入的最大固件；没有分区表时返回 0
+func otaSlotSize(projectDir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(projectDir, generator.PartitionFile))
+	if err != nil {
+		return 0, nil
+	}
+	table, err := partition.Parse(data)
+	if err != nil {
+		return 0, fmt.Errorf("解析分区表失败: %v", err)
+	}
+	if fw, ok := table.Find(partition.FirmwareName); ok {
+		return fw.Size1, nil
+	}
+	return 0, nil
+}
+
+// defaultOTAOutput 返回默认的输出路径：项目中为 build_out/<项目名>.ota，否则与固件同目录
+func defaultOTAOutput(projectDir string, manifest *config.Manifest, input string, compressed bool) string {
+	ext := ".ota"
+	if compressed {
+		ext = ".xz.ota"
+	}
+	if manifest != nil {
+		return filepath.Join(projectDir, build.BuildDir, manifest.Name+ext)
+	}
+	return strings.TrimSuffix(input, filepath.Ext(input)) + ext
+}
+
+func runOTAServe(cmd *cobra.Command, args []string) error {
+	dir := otaDir
+	if dir == "" {
+		if _, err := config.LoadManifest(otaPath); err != nil {
+			return fmt.Errorf("不在项目目录中，请使用 --dir 指定目录")
+		}
+		dir = filepath.Join(otaPath, build.BuildDir)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("目录 %s 不存在", dir)
+	}
+
+	listener, err := net.Listen("tcp", otaAddr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %v", otaAddr, err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	hosts := ota.LocalAddresses()
+	if !addr.IP.IsUnspecified() {
+		hosts = []string{addr.IP.String()}
+	}
+	fmt.Printf("🌐 OTA 服务: %s（端口 %d），按 Ctrl+C 停止\n", dir, addr.Port)
+	printOTAURLs(os.Stdout, dir, hosts, addr.Port)
+
+	server := &http.Server{Handler: ota.Handler(dir, os.Stdout)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// printOTAURLs 列出目录中 OTA 镜像在每个本机地址上的下载地址
+func printOTAURLs(w io.Writer, dir string, hosts []string, port int) {
+	images, _ := filepath.Glob(filepath.Join(dir, "*.ota"))
+	if len(images) == 0 {
+		fmt.Fprintf(w, "⚠️  目录中没有 OTA 镜像，请先运行 wb2-cli ota pack\n")
+		return
+	}
+	if len(hosts) == 0 {
+		hosts = []string{"127.0.0.1"}
+	}
+	for _, image := range images {
+		info, err := os.Stat(image)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(w, "  %s（%s）\n", filepath.Base(image), formatSize(int(info.Size())))
+		for _, host := range hosts {
+			fmt.Fprintf(w, "    http://%s/%s\n", net.JoinHostPort(host, fmt.Sprint(port)), filepath.Base(image))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/partition"
+)
+
+func TestFindOTAFirmware(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := findOTAFirmware(dir); err == nil || !strings.Contains(err.Error(), "FW_OTA.bin") {
+		t.Errorf("findOTAFirmware() error = %v", err)
+	}
+
+	old := filepath.Join(dir, "build_out", "ota", "dts40M_pt2M_boot2release_ef7015", "FW_OTA.bin")
+	cur := filepath.Join(dir, "build_out", "ota", "dts40M_pt4M_boot2release_ef7015", "FW_OTA.bin")
+	for _, path := range []string{old, cur} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("fw"), 0644)
+	}
+	os.Chtimes(old, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour))
+	if got, err := findOTAFirmware(dir); err != nil || got != cur {
+		t.Errorf("findOTAFirmware() = %q, %v, want newest %q", got, err, cur)
+	}
+}
+
+func TestDefaultOTAOutput(t *testing.T) {
+	manifest := &config.Manifest{Name: "demo"}
+	if got := defaultOTAOutput("proj", manifest, "x/FW_OTA.bin", true); got != filepath.Join("proj", "build_out", "demo.xz.ota") {
+		t.Errorf("defaultOTAOutput() = %q", got)
+	}
+	if got := defaultOTAOutput(".", nil, "out/app.bin", false); got != filepath.Join("out", "app.ota") {
+		t.Errorf("defaultOTAOutput() without project = %q", got)
+	}
+}
+
+func TestPrintOTAURLs(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	printOTAURLs(&buf, dir, nil, 8080)
+	if !strings.Contains(buf.String(), "没有 OTA 镜像") {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	os.WriteFile(filepath.Join(dir, "demo.xz.ota"), make([]byte, 2048), 0644)
+	os.WriteFile(filepath.Join(dir, "demo.bin"), []byte("fw"), 0644)
+	buf.Reset()
+	printOTAURLs(&buf, dir, []string{"192.168.1.10", "10.0.0.2"}, 8080)
+	want := "  demo.xz.ota（2.0 KB）\n" +
+		"    http://192.168.1.10:8080/demo.xz.ota\n" +
+		"    http://10.0.0.2:8080/demo.xz.ota\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRunOTAPackSlotSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := config.SaveManifest(dir, &config.Manifest{Name: "demo"}); err != nil {
+		t.Fatal(err)
+	}
+	table := &partition.Table{Address0: partition.TableAddress0, Address1: partition.TableAddress1, Entries: []partition.Entry{
+		{Type: partition.TypeFirmware, Name: partition.FirmwareName, Address0: 0x10000, Size0: 0x2000, Address1: 0x12000, Size1: 0x2000, Header: 1},
+	}}
+	os.WriteFile(filepath.Join(dir, generator.PartitionFile), partition.Format(table, "test"), 0644)
+
+	oldPath, oldInput, oldOutput, oldXZ := otaPath, otaInput, otaOutput, otaXZ
+	defer func() { otaPath, otaInput, otaOutput, otaXZ = oldPath, oldInput, oldOutput, oldXZ }()
+	otaPath, otaOutput = dir, filepath.Join(dir, "out.ota")
+
+	// 设备只把 OTA 头之后的固件写入槽：固件正好占满槽时可以放下，多一个字节则放不下
+	otaInput = filepath.Join(dir, "full.bin")
+	os.WriteFile(otaInput, bytes.Repeat([]byte{0x5A}, 0x2000), 0644)
+	otaXZ = false
+	if err := runOTAPack(nil, nil); err != nil {
+		t.Errorf("Expected firmware filling the slot to fit, got %v", err)
+	}
+	otaInput = filepath.Join(dir, "over.bin")
+	os.WriteFile(otaInput, bytes.Repeat([]byte{0x5A}, 0x2001), 0644)
+	if err := runOTAPack(nil, nil); err == nil || !strings.Contains(err.Error(), "第二个槽") {
+		t.Errorf("Expected slot size error for firmware larger than the slot, got %v", err)
+	}
+
+	// 原始固件超出槽大小，但 xz 压缩后的镜像可以放下
+	if _, err := exec.LookPath("xz"); err != nil {
+		t.Skip("xz not installed")
+	}
+	otaInput = filepath.Join(dir, "big.bin")
+	os.WriteFile(otaInput, make([]byte, 0x4000), 0644)
+	otaXZ = true
+	if err := runOTAPack(nil, nil); err != nil {
+		t.Errorf("Expected compressed image to fit the slot, got %v", err)
+	}
+}
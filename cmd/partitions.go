@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/partition"
+)
+
+var partitionsPath string
+
+// partitionsCmd represents the partitions command
+var partitionsCmd = &cobra.Command{
+	Use:   "partitions",
+	Short: "查看项目的 Flash 分区表",
+}
+
+// partitionsShowCmd represents the partitions show command
+var partitionsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "显示项目分区表的 Flash 布局",
+	Long: `读取项目根目录中的 partition.toml，按地址列出 boot2、分区表和所有分区，
+并按项目清单中模组的 Flash 容量检查分区是否对齐、重叠或超出容量。
+
+示例:
+  wb2-cli partitions show
+  wb2-cli partitions show --path ./my_project`,
+	Args: cobra.NoArgs,
+	RunE: runPartitionsShow,
+}
+
+func init() {
+	rootCmd.AddCommand(partitionsCmd)
+	partitionsCmd.AddCommand(partitionsShowCmd)
+
+	partitionsShowCmd.Flags().StringVarP(&partitionsPath, "path", "p", ".", "项目根目录")
+}
+
+func runPartitionsShow(cmd *cobra.Command, args []string) error {
+	manifest, err := config.LoadManifest(partitionsPath)
+	if err != nil {
+		return err
+	}
+	board, err := loadBoard(manifest.Board)
+	if err != nil {
+		return err
+	}
+
+	tablePath := filepath.Join(partitionsPath, generator.PartitionFile)
+	data, err := os.ReadFile(tablePath)
+	if err != nil {
+		return fmt.Errorf("读取分区表失败: %v", err)
+	}
+	table, err := partition.Parse(data)
+	if err != nil {
+		return fmt.Errorf("解析分区表 %s 失败: %v", tablePath, err)
+	}
+
+	fmt.Printf("📋 分区表: %s\n", tablePath)
+	fmt.Printf("🔧 目标模组: %s（%dMB Flash）\n\n", board.Module, board.FlashSize)
+	flashSize := board.FlashSize * 1024 * 1024
+	printPartitions(os.Stdout, table, flashSize)
+
+	if err := table.Validate(flashSize); err != nil {
+		return fmt.Errorf("分区表无效: %v", err)
+	}
+	fmt.Printf("\n✅ 分区表有效\n")
+	return nil
+}
+
+// printPartitions 按地址输出所有区域，区域之间的空隙显示为空闲
+func printPartitions(w io.Writer, table *partition.Table, flashSize int) {
+	row := func(name, typ, start, end, size string) {
+		fmt.Fprintf(w, "%s %s %s %s %s\n", padRight(name, 14), padRight(typ, 4), padRight(start, 10), padRight(end, 10), size)
+	}
+	row("名称", "类型", "起始地址", "结束地址", "大小")
+
+	used := 0
+	next := 0
+	printFree := func(end int) {
+		if end > next {
+			row("（空闲）", "", partition.FormatAddress(next), partition.FormatAddress(end), partition.FormatSize(end-next))
+		}
+	}
+	for _, r := range table.Regions() {
+		printFree(r.Address)
+		typ := "-"
+		if !r.Reserved {
+			typ = strconv.Itoa(r.Type)
+		}
+		row(r.Name, typ, partition.FormatAddress(r.Address), partition.FormatAddress(r.End()), partition.FormatSize(r.Size))
+		// 重叠的部分只计算一次
+		used += max(r.End()-max(r.Address, next), 0)
+		next = max(next, r.End())
+	}
+	printFree(flashSize)
+
+	fmt.Fprintf(w, "\n已使用 %s / %s（%.1f%%）\n", partition.FormatSize(used), partition.FormatSize(flashSize), float64(used)*100/float64(flashSize))
+}
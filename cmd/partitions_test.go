@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/partition"
+)
+
+func TestPrintPartitions(t *testing.T) {
+	table := &partition.Table{Address0: partition.TableAddress0, Address1: partition.TableAddress1, Entries: []partition.Entry{
+		{Type: partition.TypeFirmware, Name: "FW", Address0: 0x10000, Size0: 0x100000, Address1: 0x110000, Size1: 0x80000},
+		{Type: partition.TypePSM, Name: "PSM", Address0: 0x1F8000, Size0: 0x8000},
+	}}
+
+	var buf bytes.Buffer
+	printPartitions(&buf, table, 2*1024*1024)
+	output := buf.String()
+
+	for _, want := range []string{
+		"boot2          -    0x000000   0x00E000   56K",
+		"FW[0]          0    0x010000   0x110000   1M",
+		"FW[1]          0    0x110000   0x190000   512K",
+		"（空闲）            0x190000   0x1F8000   416K",
+		"PSM            4    0x1F8000   0x200000   32K",
+		"已使用 1632K / 2M（79.7%）",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsePinOverrides 解析 --pin NAME=GPIO 参数，GPIO 可写作 5、IO5 或 GPIO5
+func parsePinOverrides(values []string) (map[string]int, error) {
+	pins := make(map[string]int, len(values))
+	for _, value := range values {
+		name, pinText, ok := strings.Cut(value, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		pinText = strings.ToUpper(strings.TrimSpace(pinText))
+		pinText = strings.TrimPrefix(strings.TrimPrefix(pinText, "GPIO"), "IO")
+		pin, err := strconv.Atoi(pinText)
+		if !ok || name == "" || err != nil || pin < 0 {
+			return nil, fmt.Errorf("无效的引脚设置 %q，格式为 NAME=GPIO（如 SPI_CS=5）", value)
+		}
+		if _, exists := pins[name]; exists {
+			return nil, fmt.Errorf("引脚 %s 被重复指定", name)
+		}
+		pins[name] = pin
+	}
+	return pins, nil
+}
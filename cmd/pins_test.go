@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePinOverrides(t *testing.T) {
+	pins, err := parsePinOverrides([]string{"SPI_CS=5", "gpio_led=IO3", " I2C_SDA = GPIO21 "})
+	if err != nil {
+		t.Fatalf("parsePinOverrides failed: %v", err)
+	}
+	expected := map[string]int{"SPI_CS": 5, "GPIO_LED": 3, "I2C_SDA": 21}
+	if !reflect.DeepEqual(pins, expected) {
+		t.Errorf("Expected %v, got %v", expected, pins)
+	}
+
+	for _, invalid := range [][]string{{"SPI_CS"}, {"=5"}, {"SPI_CS=abc"}, {"SPI_CS=-1"}, {"SPI_CS=5", "spi_cs=6"}} {
+		if _, err := parsePinOverrides(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
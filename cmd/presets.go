@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"wb2-cli/internal/config"
+)
+
+// presetOptionFlags 预设中可以设置的命令行选项
+var presetOptionFlags = []string{"sdk-path-mode", "board"}
+
+// applyPresetOptions 将预设中的选项设置到命令行 flags，命令行已显式指定的选项不会被覆盖
+func applyPresetOptions(flags *pflag.FlagSet, preset *config.Preset) error {
+	names := make([]string, 0, len(preset.Options))
+	for name := range preset.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		allowed := false
+		for _, option := range presetOptionFlags {
+			if option == name {
+				allowed = true
+				break
+			}
+		}
+		flag := flags.Lookup(name)
+		if !allowed || flag == nil {
+			return fmt.Errorf("预设 %s 中的选项 %s 不受支持（可用选项: %s）", preset.Name, name, strings.Join(presetOptionFlags, ", "))
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := flags.Set(name, preset.Options[name]); err != nil {
+			return fmt.Errorf("预设 %s 中的选项 %s 无效: %v", preset.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// presetPinOverrides 合并预设中的引脚和 --pin 指定的引脚：预设中的值按 --pin 的格式解析，
+// 命令行指定的同名引脚优先
+func presetPinOverrides(preset *config.Preset, pins map[string]int) (map[string]int, error) {
+	merged, err := parsePinOverrides(presetValues(preset.Pins))
+	if err != nil {
+		return nil, fmt.Errorf("预设 %s: %v", preset.Name, err)
+	}
+	for name, pin := range pins {
+		merged[name] = pin
+	}
+	return merged, nil
+}
+
+// presetComponentOptions 合并预设中的组件选项和 --option 指定的选项：预设中的值按 --option 的格式解析，
+// 命令行指定的同名选项优先
+func presetComponentOptions(preset *config.Preset, options map[string]string) (map[string]string, error) {
+	merged, err := parseOptionOverrides(presetValues(preset.ComponentOptions))
+	if err != nil {
+		return nil, fmt.Errorf("预设 %s: %v", preset.Name, err)
+	}
+	for key, value := range options {
+		merged[key] = value
+	}
+	return merged, nil
+}
+
+// presetValues 将预设中的键值转换为 键=值 形式的命令行参数，按键排序
+func presetValues(values map[string]string) []string {
+	args := make([]string, 0, len(values))
+	for key, value := range values {
+		args = append(args, key+"="+value)
+	}
+	sort.Strings(args)
+	return args
+}
+
+// overridePreset 返回只包含命令行 --pin 和 --option 值的预设，保存预设时与已应用预设中的值合并
+func overridePreset(pins map[string]int, options map[string]string) config.Preset {
+	var preset config.Preset
+	for name, pin := range pins {
+		if preset.Pins == nil {
+			preset.Pins = make(map[string]string)
+		}
+		preset.Pins[name] = strconv.Itoa(pin)
+	}
+	for key, value := range options {
+		if preset.ComponentOptions == nil {
+			preset.ComponentOptions = make(map[string]string)
+		}
+		preset.ComponentOptions[key] = value
+	}
+	return preset
+}
+
+// mergePresetValues 合并预设中的键值，overrides 中的同名键（按 normalize 比较）优先；都为空时返回 nil
+func mergePresetValues(base, overrides map[string]string, normalize func(string) string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for key, value := range base {
+		merged[normalize(strings.TrimSpace(key))] = value
+	}
+	for key, value := range overrides {
+		merged[normalize(key)] = value
+	}
+	return merged
+}
+
+// presetComponents 返回预设中的组件，并追加 --components 指定的组件
+func presetComponents(allComponents []config.Component, preset *config.Preset, extra string) ([]string, error) {
+	selected, unknown := parseComponentList(allComponents, strings.Join(preset.Components, ","))
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("预设 %s 中的组件不存在: %s", preset.Name, strings.Join(unknown, ", "))
+	}
+
+	if extra != "" {
+		more, unknown := parseComponentList(allComponents, extra)
+		if len(unknown) > 0 {
+			return nil, fmt.Errorf("组件不存在: %s", strings.Join(unknown, ", "))
+		}
+		for _, name := range more {
+			if !containsString(selected, name) {
+				selected = append(selected, name)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// containsString 判断列表中是否包含 s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// presetNames 返回所有预设的名称
+func presetNames(presets []config.Preset) []string {
+	names := make([]string, 0, len(presets))
+	for _, preset := range presets {
+		names = append(names, preset.Name)
+	}
+	return names
+}
+
+// saveUserPreset 将预设保存到用户配置，替换同名的用户预设
+func saveUserPreset(preset config.Preset) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	cfg.Presets = config.MergePresets(cfg.Presets, []config.Preset{preset})
+	return config.SaveConfig(cfg)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"wb2-cli/internal/config"
+)
+
+func TestApplyPresetOptions(t *testing.T) {
+	newFlags := func() (*pflag.FlagSet, *string) {
+		flags := pflag.NewFlagSet("new", pflag.ContinueOnError)
+		mode := flags.String("sdk-path-mode", "absolute", "")
+		flags.Bool("dry-run", false, "")
+		return flags, mode
+	}
+	preset := &config.Preset{Name: "p", Options: map[string]string{"sdk-path-mode": "env"}}
+
+	flags, mode := newFlags()
+	if err := applyPresetOptions(flags, preset); err != nil {
+		t.Fatalf("applyPresetOptions failed: %v", err)
+	}
+	if *mode != "env" {
+		t.Errorf("Expected sdk-path-mode=env, got %s", *mode)
+	}
+
+	// 命令行显式指定的选项优先
+	flags, mode = newFlags()
+	flags.Parse([]string{"--sdk-path-mode", "relative"})
+	if err := applyPresetOptions(flags, preset); err != nil {
+		t.Fatalf("applyPresetOptions failed: %v", err)
+	}
+	if *mode != "relative" {
+		t.Errorf("Expected command line value to win, got %s", *mode)
+	}
+
+	// 不允许预设设置的选项
+	flags, _ = newFlags()
+	err := applyPresetOptions(flags, &config.Preset{Name: "p", Options: map[string]string{"dry-run": "true"}})
+	if err == nil || !strings.Contains(err.Error(), "dry-run") {
+		t.Errorf("Expected unsupported option error, got %v", err)
+	}
+}
+
+func TestPresetComponents(t *testing.T) {
+	components := []config.Component{{Name: "wifi"}, {Name: "mqtt"}, {Name: "gpio"}}
+	preset := &config.Preset{Name: "gateway", Components: []string{"wifi", "mqtt"}}
+
+	selected, err := presetComponents(components, preset, "gpio,mqtt")
+	if err != nil {
+		t.Fatalf("presetComponents failed: %v", err)
+	}
+	if !reflect.DeepEqual(selected, []string{"wifi", "mqtt", "gpio"}) {
+		t.Errorf("Expected [wifi mqtt gpio], got %v", selected)
+	}
+
+	_, err = presetComponents(components, &config.Preset{Name: "bad", Components: []string{"nope"}}, "")
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("Expected error for unknown preset component, got %v", err)
+	}
+}
+
+func TestSaveUserPreset(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := config.SaveConfig(&config.UserConfig{SDKPath: "/sdk"}); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	if err := saveUserPreset(config.Preset{Name: "mine", Components: []string{"wifi"}}); err != nil {
+		t.Fatalf("saveUserPreset failed: %v", err)
+	}
+	if err := saveUserPreset(config.Preset{Name: "mine", Components: []string{"gpio"}}); err != nil {
+		t.Fatalf("saveUserPreset failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.SDKPath != "/sdk" {
+		t.Errorf("Expected SDK path to be preserved, got %q", cfg.SDKPath)
+	}
+	if len(cfg.Presets) != 1 || !reflect.DeepEqual(cfg.Presets[0].Components, []string{"gpio"}) {
+		t.Errorf("Expected preset to be replaced, got %+v", cfg.Presets)
+	}
+}
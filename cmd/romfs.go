@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/build"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/partition"
+	"wb2-cli/internal/romfs"
+)
+
+var (
+	romfsPath   string
+	romfsDir    string
+	romfsOutput string
+	romfsVolume string
+)
+
+// romfsImage 默认的 ROMFS 镜像（位于 build_out）
+const romfsImage = "romfs.bin"
+
+// romfsPartition SDK 挂载 ROMFS 的分区名称
+const romfsPartition = "media"
+
+// romfsCmd represents the romfs command
+var romfsCmd = &cobra.Command{
+	Use:   "romfs",
+	Short: "生成和查看 ROMFS 镜像",
+}
+
+// romfsBuildCmd represents the romfs build command
+var romfsBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "把项目的 romfs 目录打包为 ROMFS 镜像",
+	Long: `把项目根目录中的 romfs 目录打包为 SDK romfs 组件使用的 ROMFS 镜像（与 genromfs 相同的
+-rom1fs- 格式），并检查镜像是否超出 partition.toml 中的 media 分区（没有 media 分区时报错）。设备上 romfs 目录
+挂载在 /romfs，例如 romfs/hello.txt 对应 /romfs/hello.txt。
+
+生成的镜像可以用 wb2-cli flash 写入 media 分区。
+
+示例:
+  wb2-cli romfs build
+  wb2-cli romfs build --dir ./www -o build_out/www.bin
+  wb2-cli flash build_out/romfs.bin@media`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runRomfsBuild,
+}
+
+// romfsLsCmd represents the romfs ls command
+var romfsLsCmd = &cobra.Command{
+	Use:   "ls [镜像]",
+	Short: "列出 ROMFS 镜像中的文件",
+	Long: `解析 ROMFS 镜像，校验超级块和文件头的校验和，并列出其中的目录和文件。
+默认读取项目的 build_out/romfs.bin。
+
+示例:
+  wb2-cli romfs ls
+  wb2-cli romfs ls media.bin`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runRomfsLs,
+}
+
+func init() {
+	rootCmd.AddCommand(romfsCmd)
+	romfsCmd.AddCommand(romfsBuildCmd)
+	romfsCmd.AddCommand(romfsLsCmd)
+
+	romfsCmd.PersistentFlags().StringVarP(&romfsPath, "path", "p", ".", "项目根目录")
+
+	romfsBuildCmd.Flags().StringVar(&romfsDir, "dir", "", "要打包的目录（默认为项目的 romfs 目录）")
+	romfsBuildCmd.Flags().StringVarP(&romfsOutput, "output", "o", "", "输出文件（默认为 build_out/romfs.bin）")
+	romfsBuildCmd.Flags().StringVar(&romfsVolume, "volume", "", "卷名（默认为项目名称）")
+}
+
+func runRomfsBuild(cmd *cobra.Command, args []string) error {
+	manifest, err := config.LoadManifest(romfsPath)
+	if err != nil && (romfsDir == "" || romfsOutput == "") {
+		return fmt.Errorf("不在项目目录中，请使用 --dir 和 --output 指定目录和输出文件")
+	}
+
+	dir := romfsDir
+	if dir == "" {
+		dir = filepath.Join(romfsPath, generator.RomfsDir)
+	}
+	volume := romfsVolume
+	if volume == "" {
+		volume = "romfs"
+		if manifest != nil {
+			volume = manifest.Name
+		}
+	}
+	image, err := romfs.Build(dir, volume)
+	if err != nil {
+		return err
+	}
+	parsed, err := romfs.Parse(image)
+	if err != nil {
+		return fmt.Errorf("生成的镜像无效: %v", err)
+	}
+
+	output := romfsOutput
+	if output == "" {
+		output = filepath.Join(romfsPath, build.BuildDir, romfsImage)
+	}
+	files, dirs, _ := romfsTotals(parsed)
+	fmt.Printf("📦 ROMFS: %s（%d 个文件，%d 个目录）→ %s（%s）\n", dir, files, dirs, output, formatSize(len(image)))
+
+	if manifest != nil {
+		limit, ok, err := romfsPartitionSize(romfsPath)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("   没有 %s，烧录时使用 SDK 默认分区表，跳过 %s 分区大小检查\n", generator.PartitionFile, romfsPartition)
+		} else {
+			fmt.Printf("   %s 分区: %s / %s（%.1f%%）\n", romfsPartition, formatSize(len(image)), partition.FormatSize(limit), float64(len(image))*100/float64(limit))
+			if len(image) > limit {
+				return fmt.Errorf("ROMFS 镜像（%s）超出 %s 分区的大小 %s", formatSize(len(image)), romfsPartition, partition.FormatSize(limit))
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, image, 0644); err != nil {
+		return fmt.Errorf("写入 ROMFS 镜像失败: %v", err)
+	}
+	fmt.Printf("✅ 已生成 ROMFS 镜像，烧录: wb2-cli flash %s@%s\n", output, romfsPartition)
+	return nil
+}
+
+// romfsPartitionSize 返回 media 分区（按名称或类型查找）的大小，没有分区表时返回 false；
+// 分区表中没有 media 分区时设备无法挂载 ROMFS，返回错误
+func romfsPartitionSize(projectDir string) (int, bool, error) {
+	data, err := os.ReadFile(filepath.Join(projectDir, generator.PartitionFile))
+	if err != nil {
+		return 0, false, nil
+	}
+	table, err := partition.Parse(data)
+	if err != nil {
+		return 0, false, fmt.Errorf("解析分区表失败: %v", err)
+	}
+	if entry, ok := findPartition(table, romfsPartition); ok {
+		return entry.Size0, true, nil
+	}
+	for _, entry := range table.Entries {
+		if entry.Type == partition.TypeMedia {
+			return entry.Size0, true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("%s 中没有 %s 分区，设备无法挂载 ROMFS，请在分区表中添加 %s 分区", generator.PartitionFile, romfsPartition, romfsPartition)
+}
+
+func runRomfsLs(cmd *cobra.Command, args []string) error {
+	path := filepath.Join(romfsPath, build.BuildDir, romfsImage)
+	if len(args) == 1 {
+		path = args[0]
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取镜像失败: %v", err)
+	}
+	img, err := romfs.Parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	printRomfs(os.Stdout, img)
+	return nil
+}
+
+// romfsTotals 统计文件数、目录数和文件总大小
+func romfsTotals(img *romfs.Image) (files, dirs, size int) {
+	for _, f := range img.Files {
+		if f.Type == romfs.TypeDir {
+			dirs++
+		} else {
+			files++
+			size += f.Size
+		}
+	}
+	return files, dirs, size
+}
+
+// printRomfs 列出镜像中的文件，目录以 / 结尾
+func printRomfs(w io.Writer, img *romfs.Image) {
+	fmt.Fprintf(w, "卷名: %s（%s）\n\n", img.Volume, formatSize(img.Size))
+	for _, f := range img.Files {
+		name, size := f.Path, fmt.Sprint(f.Size)
+		if f.Type == romfs.TypeDir {
+			name, size = name+"/", "-"
+		}
+		mode := "-"
+		if f.Exec && f.Type != romfs.TypeDir {
+			mode = "x"
+		}
+		fmt.Fprintf(w, "%s %s %10s  %s\n", padRight(f.Type.String(), 8), mode, size, name)
+	}
+	files, dirs, size := romfsTotals(img)
+	fmt.Fprintf(w, "\n%d 个文件，%d 个目录，文件共 %s\n", files, dirs, formatSize(size))
+}
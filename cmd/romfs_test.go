@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/romfs"
+)
+
+func TestRomfsPartitionSize(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok, err := romfsPartitionSize(dir); ok || err != nil {
+		t.Errorf("romfsPartitionSize() without table = %v, %v", ok, err)
+	}
+
+	table := `[pt_table]
+address0 = 0xE000
+address1 = 0xF000
+
+[[pt_entry]]
+type = 0
+name = "FW"
+device = 0
+address0 = 0x10000
+size0 = 0xC8000
+address1 = 0
+size1 = 0
+len = 0
+
+[[pt_entry]]
+type = 3
+name = "assets"
+device = 0
+address0 = 0x1A0000
+size0 = 0x57000
+address1 = 0
+size1 = 0
+len = 0
+`
+	// 分区表中没有 media 分区时报错
+	fwOnly := table[:strings.Index(table, "[[pt_entry]]\ntype = 3")]
+	os.WriteFile(filepath.Join(dir, "partition.toml"), []byte(fwOnly), 0644)
+	if _, _, err := romfsPartitionSize(dir); err == nil || !strings.Contains(err.Error(), "media") {
+		t.Errorf("romfsPartitionSize() without media partition error = %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "partition.toml"), []byte(table), 0644)
+	if size, ok, err := romfsPartitionSize(dir); !ok || err != nil || size != 0x57000 {
+		t.Errorf("romfsPartitionSize() = 0x%X, %v, %v, want media type partition", size, ok, err)
+	}
+}
+
+func TestPrintRomfs(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "www"), 0755)
+	os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "www", "index.html"), []byte("<html></html>"), 0644)
+	data, err := romfs.Build(dir, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := romfs.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	printRomfs(&buf, img)
+	out := buf.String()
+	for _, want := range []string{"卷名: demo", "hello.txt", "www/\n", "www/index.html", "2 个文件，1 个目录，文件共 19 B"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,545 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"wb2-cli/internal/config"
+)
+
+// categoryOrder 分类显示顺序
+var categoryOrder = []string{"network", "peripheral", "3rdparty", "audio", "fs", "multimedia", "system", "other"}
+
+// categoryNames 分类显示名称
+var categoryNames = map[string]string{
+	"network":    "🌐 网络组件",
+	"peripheral": "🔌 外设组件",
+	"3rdparty":   "📦 第三方组件",
+	"audio":      "🔊 音频组件",
+	"fs":         "💾 文件系统组件",
+	"multimedia": "🎬 多媒体组件",
+	"system":     "⚙️  系统组件",
+	"other":      "📋 其他组件",
+}
+
+// categoryDisplayName 返回分类的显示名称
+func categoryDisplayName(category string) string {
+	if name := categoryNames[category]; name != "" {
+		return name
+	}
+	return category
+}
+
+// selector 组件选择菜单的状态，与终端读写无关，便于测试
+type selector struct {
+	components []config.Component
+	categories []string
+	byCategory map[string][]config.Component
+	selected   map[string]bool
+
+	// 当前分类，为空时显示分类列表
+	category string
+	// 分类列表中的光标
+	catIndex int
+	// 组件列表（分类或搜索结果）中的光标
+	cursor int
+	// 列表视口的第一行
+	offset int
+
+	// searching 表示正在输入搜索词；query 非空时列表显示搜索结果
+	searching bool
+	query     string
+
+	// presets 可选的预设；presetScreen 表示正在显示预设列表（第一屏），
+	// presetIndex 为其中的光标，0 表示不使用预设
+	presets      []config.Preset
+	presetScreen bool
+	presetIndex  int
+	// 已应用的预设
+	preset *config.Preset
+
+	// naming 表示正在输入要保存的预设名称
+	naming     bool
+	presetName string
+	// savePreset 保存预设，为 nil 时不支持保存
+	savePreset func(config.Preset) error
+	// overrides 命令行 --option 和 --pin 的值，保存预设时覆盖已应用预设中的同名值
+	overrides config.Preset
+
+	// 最近一次操作的提示（如依赖变化、冲突）
+	message string
+
+	width  int
+	height int
+
+	done      bool
+	cancelled bool
+}
+
+// newSelector 创建组件选择菜单
+func newSelector(components []config.Component) *selector {
+	s := &selector{
+		components: components,
+		byCategory: make(map[string][]config.Component),
+		selected:   make(map[string]bool),
+		width:      80,
+		height:     24,
+	}
+
+	for _, comp := range components {
+		category := comp.Category
+		if category == "" {
+			category = "other"
+		}
+		s.byCategory[category] = append(s.byCategory[category], comp)
+	}
+
+	// 先按固定顺序，再追加未知分类
+	for _, cat := range categoryOrder {
+		if len(s.byCategory[cat]) > 0 {
+			s.categories = append(s.categories, cat)
+		}
+	}
+	var unknown []string
+	for cat := range s.byCategory {
+		if _, ok := categoryNames[cat]; !ok {
+			unknown = append(unknown, cat)
+		}
+	}
+	sort.Strings(unknown)
+	s.categories = append(s.categories, unknown...)
+
+	return s
+}
+
+// setPresets 设置可选的预设，有预设时第一屏显示预设列表
+func (s *selector) setPresets(presets []config.Preset) {
+	s.presets = presets
+	s.presetScreen = len(presets) > 0
+	s.presetIndex = 0
+}
+
+// inList 判断当前是否显示组件列表（分类内或搜索结果）
+func (s *selector) inList() bool {
+	return s.category != "" || s.searching || s.query != ""
+}
+
+// items 返回当前显示的组件列表
+func (s *selector) items() []config.Component {
+	if s.searching || s.query != "" {
+		return s.searchResults()
+	}
+	return s.byCategory[s.category]
+}
+
+// searchResults 在所有分类中模糊搜索组件
+func (s *selector) searchResults() []config.Component {
+	if s.query == "" {
+		return s.components
+	}
+
+	type match struct {
+		comp  config.Component
+		score int
+		index int
+	}
+	var matches []match
+	for i, comp := range s.components {
+		score, ok := fuzzyMatch(s.query, comp.Name)
+		if descScore, descOK := fuzzyMatch(s.query, comp.Description); descOK && (!ok || descScore/2 > score) {
+			// 描述匹配的权重低于名称匹配
+			score, ok = descScore/2, true
+		}
+		if ok {
+			matches = append(matches, match{comp, score, i})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	results := make([]config.Component, len(matches))
+	for i, m := range matches {
+		results[i] = m.comp
+	}
+	return results
+}
+
+// fuzzyMatch 判断 pattern 是否为 text 的子序列（忽略大小写），并给出匹配得分：
+// 连续匹配和从词首开始的匹配得分更高
+func fuzzyMatch(pattern, text string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	if len(p) == 0 {
+		return 0, true
+	}
+
+	score, pi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 5 // 连续匹配
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3 // 词首匹配
+		}
+		prev = ti
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	return score, true
+}
+
+// current 返回光标所在的组件
+func (s *selector) current() (config.Component, bool) {
+	items := s.items()
+	if s.cursor < 0 || s.cursor >= len(items) {
+		return config.Component{}, false
+	}
+	return items[s.cursor], true
+}
+
+// listHeight 返回列表视口的高度
+func (s *selector) listHeight() int {
+	// 标题、状态和分隔线占 3 行，底部分隔线和帮助占 2 行
+	return max(s.height-5, 3)
+}
+
+// moveCursor 移动光标并保持在视口内
+func (s *selector) moveCursor(delta int) {
+	count := len(s.items())
+	pos := &s.cursor
+	switch {
+	case s.presetScreen:
+		count = len(s.presets) + 1
+		pos = &s.presetIndex
+	case !s.inList():
+		count = len(s.categories)
+		pos = &s.catIndex
+	}
+	if count == 0 {
+		*pos = 0
+		return
+	}
+
+	*pos = min(max(*pos+delta, 0), count-1)
+	s.scrollTo(*pos)
+}
+
+// scrollTo 调整视口，使第 pos 行可见
+func (s *selector) scrollTo(pos int) {
+	height := s.listHeight()
+	if pos < s.offset {
+		s.offset = pos
+	}
+	if pos >= s.offset+height {
+		s.offset = pos - height + 1
+	}
+}
+
+// enterCategory 进入当前光标所在的分类
+func (s *selector) enterCategory() {
+	if s.catIndex < len(s.categories) {
+		s.category = s.categories[s.catIndex]
+		s.cursor = 0
+		s.offset = 0
+	}
+}
+
+// backToCategories 返回分类列表
+func (s *selector) backToCategories() {
+	s.category = ""
+	s.query = ""
+	s.searching = false
+	s.cursor = 0
+	s.offset = 0
+	s.scrollTo(s.catIndex)
+}
+
+// showPresets 回到预设列表
+func (s *selector) showPresets() {
+	s.backToCategories()
+	s.presetScreen = true
+	s.offset = 0
+	s.scrollTo(s.presetIndex)
+}
+
+// choosePreset 应用光标所在的预设并进入分类列表；选择“自定义”时保留当前选择
+func (s *selector) choosePreset() {
+	s.presetScreen = false
+	s.offset = 0
+	s.scrollTo(s.catIndex)
+
+	if s.presetIndex == 0 || s.presetIndex > len(s.presets) {
+		s.preset = nil
+		return
+	}
+
+	preset := s.presets[s.presetIndex-1]
+	known := make(map[string]bool)
+	for _, comp := range s.components {
+		known[comp.Name] = true
+	}
+	s.selected = make(map[string]bool)
+	var unknown []string
+	for _, name := range preset.Components {
+		if known[name] {
+			s.selected[name] = true
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	s.preset = &preset
+
+	s.message = fmt.Sprintf("✓ 已应用预设 %s，可继续调整组件", preset.Name)
+	if len(unknown) > 0 {
+		s.message += fmt.Sprintf("（忽略不存在的组件: %s）", strings.Join(unknown, ", "))
+	}
+}
+
+// saveCurrentPreset 将当前选择保存为预设，沿用已应用预设的选项和引脚，并加入命令行指定的组件选项和引脚
+func (s *selector) saveCurrentPreset() {
+	name := strings.TrimSpace(s.presetName)
+	s.naming = false
+	s.presetName = ""
+	if !isValidProjectName(name) {
+		s.message = "⚠ 预设名称只能包含字母、数字、下划线和连字符"
+		return
+	}
+
+	var applied config.Preset
+	if s.preset != nil {
+		applied = *s.preset
+	}
+	preset := config.Preset{
+		Name:             name,
+		Components:       s.selection(),
+		Options:          mergePresetValues(applied.Options, nil, strings.ToLower),
+		ComponentOptions: mergePresetValues(applied.ComponentOptions, s.overrides.ComponentOptions, strings.ToLower),
+		Pins:             mergePresetValues(applied.Pins, s.overrides.Pins, strings.ToUpper),
+	}
+
+	if err := s.savePreset(preset); err != nil {
+		s.message = fmt.Sprintf("⚠ 保存预设失败: %v", err)
+		return
+	}
+	s.presets = config.MergePresets(s.presets, []config.Preset{preset})
+	s.preset = &preset
+	s.message = fmt.Sprintf("✓ 已保存预设 %s（%d 个组件）", name, len(preset.Components))
+}
+
+// deps 返回当前选择的依赖解析结果
+func (s *selector) deps() *dependencyState {
+	return resolveSelection(s.components, s.selected)
+}
+
+// toggleCurrent 切换光标所在组件的选择状态，并提示依赖和冲突的变化
+func (s *selector) toggleCurrent() {
+	comp, ok := s.current()
+	if !ok {
+		return
+	}
+
+	before := s.deps()
+	s.selected[comp.Name] = !s.selected[comp.Name]
+	after := s.deps()
+
+	if !s.selected[comp.Name] {
+		if after.included[comp.Name] {
+			s.message = fmt.Sprintf("ℹ %s 仍被 %s 依赖，将自动包含", comp.Name, strings.Join(after.requiredBy[comp.Name], ", "))
+		}
+		return
+	}
+
+	if conflicts := after.conflicts[comp.Name]; len(conflicts) > 0 {
+		s.message = fmt.Sprintf("⚠ %s 与 %s 冲突", comp.Name, strings.Join(conflicts, ", "))
+		return
+	}
+
+	var added []string
+	for _, c := range s.components {
+		if after.auto[c.Name] && !before.included[c.Name] {
+			added = append(added, c.Name)
+		}
+	}
+	if len(added) > 0 {
+		s.message = fmt.Sprintf("✓ 已选择 %s，自动包含: %s", comp.Name, strings.Join(added, ", "))
+	}
+}
+
+// handleKey 处理一次按键
+func (s *selector) handleKey(k key) {
+	if k.kind == keyCtrlC {
+		s.cancelled = true
+		return
+	}
+	s.message = ""
+
+	if s.naming {
+		s.handleNameKey(k)
+		return
+	}
+
+	// 通用的滚动按键
+	switch k.kind {
+	case keyUp:
+		s.moveCursor(-1)
+		return
+	case keyDown:
+		s.moveCursor(1)
+		return
+	case keyPgUp:
+		s.moveCursor(-s.listHeight())
+		return
+	case keyPgDn:
+		s.moveCursor(s.listHeight())
+		return
+	case keyHome:
+		s.moveCursor(-1 << 30)
+		return
+	case keyEnd:
+		s.moveCursor(1 << 30)
+		return
+	}
+
+	if s.searching {
+		s.handleSearchKey(k)
+		return
+	}
+
+	// vi 风格导航键（搜索输入时作为普通字符）
+	if k.kind == keyRune {
+		switch k.r {
+		case 'k':
+			s.moveCursor(-1)
+			return
+		case 'j':
+			s.moveCursor(1)
+			return
+		case 'h':
+			k = key{kind: keyLeft}
+		case 'l':
+			k = key{kind: keyRight}
+		}
+	}
+
+	if s.presetScreen {
+		switch {
+		case k.kind == keyEnter, k.kind == keyRight:
+			s.choosePreset()
+		case k.kind == keyRune && (k.r == 'q' || k.r == 'Q'):
+			s.cancelled = true
+		}
+		return
+	}
+
+	if k.kind == keyRune && k.r == '/' {
+		// 开始增量搜索
+		s.searching = true
+		s.cursor = 0
+		s.offset = 0
+		return
+	}
+
+	if k.kind == keyRune && (k.r == 'q' || k.r == 'Q') {
+		s.cancelled = true
+		return
+	}
+
+	if k.kind == keyRune && k.r == 's' && s.savePreset != nil {
+		// 输入预设名称，保存当前选择
+		s.naming = true
+		s.presetName = ""
+		return
+	}
+
+	if k.kind == keyRune && k.r == 'p' && len(s.presets) > 0 {
+		s.showPresets()
+		return
+	}
+
+	if !s.inList() {
+		// 分类列表
+		switch {
+		case k.kind == keyRight:
+			s.enterCategory()
+		case k.kind == keyEnter:
+			if s.deps().hasConflicts() {
+				s.message = "⚠ 存在组件冲突，请先取消冲突的组件"
+				return
+			}
+			s.done = true
+		}
+		return
+	}
+
+	// 组件列表或搜索结果
+	switch {
+	case k.kind == keyRune && k.r == ' ':
+		s.toggleCurrent()
+	case k.kind == keyLeft, k.kind == keyEnter, k.kind == keyEsc:
+		s.backToCategories()
+	}
+}
+
+// handleSearchKey 处理输入搜索词时的按键
+func (s *selector) handleSearchKey(k key) {
+	switch k.kind {
+	case keyRune:
+		s.query += string(k.r)
+	case keyBackspace:
+		if runes := []rune(s.query); len(runes) > 0 {
+			s.query = string(runes[:len(runes)-1])
+		}
+	case keyEnter:
+		// 结束输入，保留搜索结果用于选择
+		s.searching = false
+		if s.query == "" {
+			s.backToCategories()
+			return
+		}
+	case keyEsc:
+		s.backToCategories()
+		return
+	default:
+		return
+	}
+	s.cursor = 0
+	s.offset = 0
+}
+
+// handleNameKey 处理输入预设名称时的按键
+func (s *selector) handleNameKey(k key) {
+	switch k.kind {
+	case keyRune:
+		s.presetName += string(k.r)
+	case keyBackspace:
+		if runes := []rune(s.presetName); len(runes) > 0 {
+			s.presetName = string(runes[:len(runes)-1])
+		}
+	case keyEnter:
+		s.saveCurrentPreset()
+	case keyEsc:
+		s.naming = false
+		s.presetName = ""
+	}
+}
+
+// selection 按组件目录顺序返回已选择的组件名称
+func (s *selector) selection() []string {
+	names := []string{}
+	for _, comp := range s.components {
+		if s.selected[comp.Name] {
+			names = append(names, comp.Name)
+		}
+	}
+	return names
+}
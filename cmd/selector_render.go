@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"wb2-cli/internal/config"
+)
+
+// paneMinWidth 终端宽度小于该值时不显示详情面板
+const paneMinWidth = 72
+
+// render 生成整屏内容：光标回到左上角后逐行覆盖，避免清屏闪烁
+func (s *selector) render() string {
+	width := max(s.width, 20)
+
+	deps := s.deps()
+
+	var lines []string
+	lines = append(lines, spread("  WB2 组件选择菜单", "✓ 已选  + 自动依赖  ! 冲突  ", width))
+	lines = append(lines, s.statusLine(deps, width))
+	lines = append(lines, strings.Repeat("─", width))
+
+	left := s.listLines(deps)
+	leftWidth := width
+	var right []string
+	if width >= paneMinWidth {
+		leftWidth = width * 55 / 100
+		right = s.detailLines(deps, width-leftWidth-3)
+	}
+
+	for i := 0; i < s.listHeight(); i++ {
+		line := ""
+		if i < len(left) {
+			line = left[i]
+		}
+		if right == nil {
+			lines = append(lines, truncate(line, leftWidth))
+			continue
+		}
+		paneLine := ""
+		if i < len(right) {
+			paneLine = right[i]
+		}
+		lines = append(lines, padRight(truncate(line, leftWidth), leftWidth)+" │ "+truncate(paneLine, width-leftWidth-3))
+	}
+
+	if s.message != "" {
+		lines = append(lines, truncate(s.message, width))
+	} else {
+		lines = append(lines, strings.Repeat("─", width))
+	}
+	lines = append(lines, truncate(s.helpLine(), width))
+
+	var sb strings.Builder
+	sb.WriteString("\033[H")
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(line)
+		sb.WriteString("\033[K")
+	}
+	sb.WriteString("\033[J")
+	return sb.String()
+}
+
+// spread 将 left 和 right 分别放在一行的两端，空间不足时只保留 left
+func spread(left, right string, width int) string {
+	gap := width - displayWidth(left) - displayWidth(right)
+	if gap < 1 {
+		return truncate(left, width)
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+// statusLine 返回当前位置、搜索词和已选数量
+func (s *selector) statusLine(deps *dependencyState, width int) string {
+	var location string
+	switch {
+	case s.naming:
+		location = fmt.Sprintf("  💾 保存为预设: %s▌", s.presetName)
+	case s.presetScreen:
+		location = "  预设"
+	case s.searching:
+		location = fmt.Sprintf("  🔍 搜索: %s▌ (%d 个结果)", s.query, len(s.items()))
+	case s.query != "":
+		location = fmt.Sprintf("  🔍 搜索: %s (%d 个结果)", s.query, len(s.items()))
+	case s.category != "":
+		location = "  分类 › " + categoryDisplayName(s.category)
+	default:
+		location = "  分类"
+	}
+	if s.preset != nil && !s.naming && !s.presetScreen {
+		location += "（预设: " + s.preset.Name + "）"
+	}
+
+	count := fmt.Sprintf("已选择 %d 个", len(s.selection()))
+	if len(deps.auto) > 0 {
+		count += fmt.Sprintf("，自动包含 %d 个", len(deps.auto))
+	}
+	count += "  "
+	if s.inList() && len(s.items()) > 0 {
+		count = fmt.Sprintf("%d/%d  ", s.cursor+1, len(s.items())) + count
+	}
+
+	return spread(location, count, width)
+}
+
+// helpLine 返回当前模式下的操作提示
+func (s *selector) helpLine() string {
+	var help string
+	switch {
+	case s.naming:
+		return "输入 预设名称 | 回车 保存 | ESC 取消"
+	case s.presetScreen:
+		return "↑↓/jk 导航 | 回车 应用预设 | q 退出"
+	case s.searching:
+		return "输入 搜索 | ↑↓ 导航 | 回车 确认 | ESC 取消搜索"
+	case s.inList():
+		help = "↑↓/jk PgUp/PgDn 导航 | 空格 选择/取消 | / 搜索 | ←/h 返回"
+	default:
+		help = "↑↓/jk 导航 | →/l 进入 | / 搜索 | 回车 完成选择"
+	}
+	if s.savePreset != nil {
+		help += " | s 保存预设"
+	}
+	if len(s.presets) > 0 {
+		help += " | p 预设"
+	}
+	return help + " | q 退出"
+}
+
+// componentStatus 返回组件在列表中的状态符号和附加说明
+func componentStatus(deps *dependencyState, selected bool, name string) (string, string) {
+	status, note := " ", ""
+	switch {
+	case selected:
+		status = "✓"
+	case deps.auto[name]:
+		status = "+"
+		note = " (由 " + strings.Join(deps.requiredBy[name], ", ") + " 引入)"
+	}
+	if conflicts := deps.conflicts[name]; len(conflicts) > 0 {
+		status = "!"
+		note = " ⚠ 与 " + strings.Join(conflicts, ", ") + " 冲突"
+	}
+	return status, note
+}
+
+// listLines 返回视口内的列表行
+func (s *selector) listLines(deps *dependencyState) []string {
+	var lines []string
+	height := s.listHeight()
+
+	if s.presetScreen {
+		for i := s.offset; i <= len(s.presets) && i < s.offset+height; i++ {
+			prefix := "  "
+			if i == s.presetIndex {
+				prefix = "> "
+			}
+			if i == 0 {
+				lines = append(lines, prefix+"✎ 自定义选择（不使用预设）")
+				continue
+			}
+			preset := s.presets[i-1]
+			line := prefix + "📋 " + preset.Name
+			if preset.Description != "" {
+				line += " - " + preset.Description
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	if !s.inList() {
+		for i := s.offset; i < len(s.categories) && i < s.offset+height; i++ {
+			cat := s.categories[i]
+			selectedCount, autoCount := 0, 0
+			for _, comp := range s.byCategory[cat] {
+				if s.selected[comp.Name] {
+					selectedCount++
+				} else if deps.auto[comp.Name] {
+					autoCount++
+				}
+			}
+
+			prefix := "  "
+			if i == s.catIndex {
+				prefix = "> "
+			}
+			line := fmt.Sprintf("%s▶ %s (%d)", prefix, categoryDisplayName(cat), len(s.byCategory[cat]))
+			if selectedCount > 0 || autoCount > 0 {
+				line += fmt.Sprintf(" [已选 %d", selectedCount)
+				if autoCount > 0 {
+					line += fmt.Sprintf(" +%d", autoCount)
+				}
+				line += "]"
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	items := s.items()
+	if len(items) == 0 {
+		return []string{"  （没有匹配的组件）"}
+	}
+	for i := s.offset; i < len(items) && i < s.offset+height; i++ {
+		comp := items[i]
+		prefix := "  "
+		if i == s.cursor {
+			prefix = "> "
+		}
+		status, note := componentStatus(deps, s.selected[comp.Name], comp.Name)
+		lines = append(lines, fmt.Sprintf("%s[%s] %s%s - %s", prefix, status, comp.Name, note, comp.Description))
+	}
+	return lines
+}
+
+// detailLines 返回详情面板内容：光标所在组件的描述、依赖和配置项
+func (s *selector) detailLines(deps *dependencyState, width int) []string {
+	if s.presetScreen {
+		if s.presetIndex == 0 || s.presetIndex > len(s.presets) {
+			return wrap("在分类中逐个选择组件，之后可按 s 将选择保存为预设", width)
+		}
+		preset := s.presets[s.presetIndex-1]
+		lines := []string{preset.Name}
+		if preset.Description != "" {
+			lines = append(lines, wrap(preset.Description, width)...)
+		}
+		lines = append(lines, "")
+		lines = append(lines, wrap("组件: "+joinOrNone(preset.Components), width)...)
+		if len(preset.Options) > 0 {
+			lines = append(lines, "选项:")
+			keys := make([]string, 0, len(preset.Options))
+			for k := range preset.Options {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				lines = append(lines, wrap(fmt.Sprintf("  --%s=%s", k, preset.Options[k]), width)...)
+			}
+		}
+		return lines
+	}
+
+	if !s.inList() {
+		if s.catIndex >= len(s.categories) {
+			return nil
+		}
+		cat := s.categories[s.catIndex]
+		lines := []string{categoryDisplayName(cat), ""}
+		for _, comp := range s.byCategory[cat] {
+			lines = append(lines, wrap("· "+comp.Name, width)...)
+		}
+		return lines
+	}
+
+	comp, ok := s.current()
+	if !ok {
+		return nil
+	}
+
+	lines := []string{comp.Name}
+	lines = append(lines, wrap(comp.Description, width)...)
+	lines = append(lines, "")
+	lines = append(lines, "分类: "+categoryDisplayName(comp.Category))
+
+	state := "未选择"
+	switch {
+	case s.selected[comp.Name]:
+		state = "已选择"
+	case deps.auto[comp.Name]:
+		state = "自动包含"
+	}
+	lines = append(lines, "状态: "+state)
+	lines = append(lines, wrap("依赖: "+joinOrNone(comp.Dependencies), width)...)
+	if dependents := deps.requiredBy[comp.Name]; len(dependents) > 0 {
+		lines = append(lines, wrap("被依赖: "+strings.Join(dependents, ", "), width)...)
+	}
+	if conflicts := conflictMap(s.components)[comp.Name]; len(conflicts) > 0 {
+		names := make([]string, 0, len(conflicts))
+		for name := range conflicts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines = append(lines, wrap("冲突: "+strings.Join(names, ", "), width)...)
+	}
+	lines = append(lines, wrap("SDK 组件: "+joinOrNone(sdkComponentNames(comp)), width)...)
+
+	if len(comp.ConfigFlags) > 0 {
+		lines = append(lines, "配置项:")
+		keys := make([]string, 0, len(comp.ConfigFlags))
+		for k := range comp.ConfigFlags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			lines = append(lines, wrap(fmt.Sprintf("  %s=%s", k, comp.ConfigFlags[k]), width)...)
+		}
+	}
+
+	return lines
+}
+
+// sdkComponentNames 返回组件引入的所有 SDK 组件
+func sdkComponentNames(comp config.Component) []string {
+	var names []string
+	for _, list := range [][]string{comp.IncludeComponents, comp.NetworkComponents, comp.BLSysComponents, comp.VFSComponents, comp.MQTTComponents} {
+		names = append(names, list...)
+	}
+	return names
+}
+
+// joinOrNone 用逗号连接，列表为空时返回“无”
+func joinOrNone(names []string) string {
+	if len(names) == 0 {
+		return "无"
+	}
+	return strings.Join(names, ", ")
+}
+
+// runeWidth 返回字符在终端中占用的列数
+func runeWidth(r rune) int {
+	switch {
+	case r == 0x200D || (r >= 0xFE00 && r <= 0xFE0F) || (r >= 0x0300 && r <= 0x036F):
+		return 0 // 零宽连接符、变体选择符和组合字符
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+	return 1
+}
+
+// displayWidth 返回字符串在终端中占用的列数
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// truncate 将字符串截断到指定列数，被截断时以“…”结尾
+func truncate(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		sb.WriteRune(r)
+		used += w
+	}
+	sb.WriteString("…")
+	return sb.String()
+}
+
+// padRight 用空格将字符串补齐到指定列数
+func padRight(s string, width int) string {
+	if gap := width - displayWidth(s); gap > 0 {
+		return s + strings.Repeat(" ", gap)
+	}
+	return s
+}
+
+// wrap 按列数折行
+func wrap(s string, width int) []string {
+	if width <= 0 || displayWidth(s) <= width {
+		return []string{s}
+	}
+
+	var lines []string
+	var sb strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > width {
+			lines = append(lines, sb.String())
+			sb.Reset()
+			used = 0
+		}
+		sb.WriteRune(r)
+		used += w
+	}
+	if sb.Len() > 0 {
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
@@ -0,0 +1,429 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+)
+
+func testSelectorComponents() []config.Component {
+	return []config.Component{
+		{Name: "wifi", Description: "Wi-Fi 连接功能", Category: "network"},
+		{Name: "mqtt", Description: "MQTT 客户端功能", Category: "network", Dependencies: []string{"wifi"},
+			IncludeComponents: []string{"httpc"}, ConfigFlags: map[string]string{"CONFIG_MQTT": "1"}},
+		{Name: "blufi", Description: "蓝牙配网功能", Category: "network", Dependencies: []string{"ble", "wifi"}},
+		{Name: "ble", Description: "BLE 蓝牙功能", Category: "network"},
+		{Name: "gpio", Description: "GPIO 外设功能", Category: "peripheral"},
+		{Name: "custom", Description: "自定义分类", Category: "zzz"},
+	}
+}
+
+// press 依次发送按键
+func press(s *selector, keys ...key) {
+	for _, k := range keys {
+		s.handleKey(k)
+	}
+}
+
+func runeKey(r rune) key { return key{kind: keyRune, r: r} }
+
+func typeText(s *selector, text string) {
+	for _, r := range text {
+		s.handleKey(runeKey(r))
+	}
+}
+
+func TestNewSelectorCategories(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+
+	expected := []string{"network", "peripheral", "zzz"}
+	if !reflect.DeepEqual(s.categories, expected) {
+		t.Errorf("Expected categories %v, got %v", expected, s.categories)
+	}
+}
+
+func TestSelectorNavigateAndToggle(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+
+	// 进入网络分类，选择第二个组件（mqtt）
+	press(s, key{kind: keyRight}, key{kind: keyDown}, runeKey(' '))
+	// 返回分类列表，进入外设分类选择 gpio
+	press(s, key{kind: keyLeft}, key{kind: keyDown}, key{kind: keyRight}, runeKey(' '))
+	// 取消再选中不改变结果
+	press(s, runeKey(' '), runeKey(' '))
+	press(s, key{kind: keyLeft}, key{kind: keyEnter})
+
+	if !s.done {
+		t.Fatal("Expected selector to be done")
+	}
+	if got := s.selection(); !reflect.DeepEqual(got, []string{"mqtt", "gpio"}) {
+		t.Errorf("Expected selection [mqtt gpio], got %v", got)
+	}
+}
+
+func TestSelectorCancel(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+	press(s, runeKey('q'))
+	if !s.cancelled {
+		t.Error("Expected q to cancel")
+	}
+
+	s = newSelector(testSelectorComponents())
+	press(s, key{kind: keyCtrlC})
+	if !s.cancelled {
+		t.Error("Expected Ctrl-C to cancel")
+	}
+}
+
+func TestSelectorSearch(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+
+	press(s, runeKey('/'))
+	if !s.searching {
+		t.Fatal("Expected / to start search")
+	}
+
+	// 搜索时 q 是普通字符
+	typeText(s, "blq")
+	if s.cancelled {
+		t.Fatal("Expected q to be part of the query while searching")
+	}
+	press(s, key{kind: keyBackspace})
+	if s.query != "bl" {
+		t.Fatalf("Expected query 'bl', got %q", s.query)
+	}
+
+	// 跨分类搜索，名称前缀匹配排在前面
+	items := s.items()
+	if len(items) < 2 || items[0].Name != "blufi" && items[0].Name != "ble" {
+		t.Fatalf("Unexpected search results: %v", items)
+	}
+
+	// 回车结束输入后可以选择搜索结果
+	press(s, key{kind: keyEnter}, key{kind: keyDown}, runeKey(' '))
+	if s.searching {
+		t.Error("Expected enter to finish typing")
+	}
+	if len(s.selection()) != 1 {
+		t.Errorf("Expected one selected component, got %v", s.selection())
+	}
+
+	// ESC 退出搜索
+	press(s, key{kind: keyEsc})
+	if s.query != "" || s.inList() {
+		t.Error("Expected ESC to clear search and return to categories")
+	}
+}
+
+func TestSelectorSearchNoResults(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+	press(s, runeKey('/'))
+	typeText(s, "xyz")
+
+	if len(s.items()) != 0 {
+		t.Errorf("Expected no results, got %v", s.items())
+	}
+	// 没有结果时空格不应出错
+	press(s, key{kind: keyEnter}, runeKey(' '))
+	if !strings.Contains(s.render(), "没有匹配的组件") {
+		t.Error("Expected empty result hint in render")
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		ok      bool
+	}{
+		{"mqt", "mqtt", true},
+		{"htc", "http_client", true},
+		{"MQTT", "mqtt", true},
+		{"", "anything", true},
+		{"xyz", "mqtt", false},
+		{"ttqm", "mqtt", false},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyMatch(tt.pattern, tt.text); ok != tt.ok {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.text, ok, tt.ok)
+		}
+	}
+
+	prefix, _ := fuzzyMatch("http", "http_client")
+	scattered, _ := fuzzyMatch("http", "hosal_tcp_thing_p")
+	if prefix <= scattered {
+		t.Errorf("Expected contiguous prefix match to score higher: %d <= %d", prefix, scattered)
+	}
+}
+
+func TestSelectorViewportScrolling(t *testing.T) {
+	var comps []config.Component
+	for i := 0; i < 30; i++ {
+		comps = append(comps, config.Component{Name: fmt.Sprintf("comp%02d", i), Category: "system"})
+	}
+	s := newSelector(comps)
+	s.height = 10 // 列表高度为 5
+
+	press(s, key{kind: keyRight})
+	for i := 0; i < 7; i++ {
+		press(s, key{kind: keyDown})
+	}
+	if s.cursor != 7 || s.offset != 3 {
+		t.Errorf("Expected cursor 7 offset 3, got cursor %d offset %d", s.cursor, s.offset)
+	}
+
+	press(s, key{kind: keyPgDn})
+	if s.cursor != 12 || s.offset != 8 {
+		t.Errorf("Expected cursor 12 offset 8 after PgDn, got cursor %d offset %d", s.cursor, s.offset)
+	}
+
+	press(s, key{kind: keyEnd})
+	if s.cursor != 29 || s.offset != 25 {
+		t.Errorf("Expected cursor 29 offset 25 after End, got cursor %d offset %d", s.cursor, s.offset)
+	}
+
+	press(s, key{kind: keyPgUp}, key{kind: keyHome})
+	if s.cursor != 0 || s.offset != 0 {
+		t.Errorf("Expected cursor 0 offset 0 after Home, got cursor %d offset %d", s.cursor, s.offset)
+	}
+
+	// 渲染的列表行数不超过终端高度
+	lines := strings.Split(s.render(), "\r\n")
+	if len(lines) != s.height {
+		t.Errorf("Expected %d rendered lines, got %d", s.height, len(lines))
+	}
+}
+
+func TestSelectorDetailPane(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+	s.width = 100
+
+	press(s, key{kind: keyRight}, key{kind: keyDown})
+	output := s.render()
+
+	for _, want := range []string{"依赖: wifi", "SDK 组件: httpc", "CONFIG_MQTT=1", " │ "} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected render to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	// 窄终端不显示详情面板
+	s.width = 40
+	if strings.Contains(s.render(), " │ ") {
+		t.Error("Expected no detail pane on narrow terminal")
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		input string
+		width int
+	}{
+		{"abc", 3},
+		{"网络", 4},
+		{"🌐 网络", 7},
+		{"⚙️ ", 2},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.input); got != tt.width {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.input, got, tt.width)
+		}
+	}
+
+	if got := truncate("网络组件", 5); got != "网络…" {
+		t.Errorf("truncate = %q, want %q", got, "网络…")
+	}
+	if got := padRight("网", 4); got != "网  " {
+		t.Errorf("padRight = %q", got)
+	}
+	if got := wrap("abcdef", 4); !reflect.DeepEqual(got, []string{"abcd", "ef"}) {
+		t.Errorf("wrap = %v", got)
+	}
+}
+
+func TestSelectorDependencyFeedback(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+
+	// 选择 blufi（网络分类第三个）会自动包含 ble 和 wifi
+	press(s, key{kind: keyRight}, key{kind: keyDown}, key{kind: keyDown}, runeKey(' '))
+	if !strings.Contains(s.message, "自动包含: wifi, ble") {
+		t.Errorf("Expected auto-include message, got %q", s.message)
+	}
+
+	output := s.render()
+	for _, want := range []string{"[+] wifi (由 blufi 引入)", "[+] ble (由 blufi 引入)", "[✓] blufi", "自动包含 2 个"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected render to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	// 选择再取消 wifi：仍被 blufi 依赖
+	press(s, key{kind: keyHome}, runeKey(' '), runeKey(' '))
+	if !strings.Contains(s.message, "wifi 仍被 blufi 依赖") {
+		t.Errorf("Expected dependents message, got %q", s.message)
+	}
+	if !strings.Contains(s.render(), "被依赖: blufi") {
+		t.Error("Expected detail pane to list dependents")
+	}
+
+	// 其他按键清除提示
+	press(s, key{kind: keyDown})
+	if s.message != "" {
+		t.Errorf("Expected message to be cleared, got %q", s.message)
+	}
+}
+
+func TestSelectorConflictBlocksDone(t *testing.T) {
+	components := append(testSelectorComponents(),
+		config.Component{Name: "smartconfig", Description: "一键配网", Category: "network", Conflicts: []string{"blufi"}})
+	s := newSelector(components)
+
+	// 依次选择 blufi 和 smartconfig
+	press(s, key{kind: keyRight}, key{kind: keyDown}, key{kind: keyDown}, runeKey(' '))
+	press(s, key{kind: keyEnd}, runeKey(' '))
+	if !strings.Contains(s.message, "smartconfig 与 blufi 冲突") {
+		t.Errorf("Expected conflict message, got %q", s.message)
+	}
+	if !strings.Contains(s.render(), "[!] smartconfig ⚠ 与 blufi 冲突") {
+		t.Errorf("Expected inline conflict marker, got:\n%s", s.render())
+	}
+
+	// 存在冲突时不能完成选择
+	press(s, key{kind: keyLeft}, key{kind: keyEnter})
+	if s.done {
+		t.Fatal("Expected conflicts to block finishing the selection")
+	}
+
+	// 取消冲突组件后可以完成
+	press(s, key{kind: keyRight}, key{kind: keyEnd}, runeKey(' '), key{kind: keyLeft}, key{kind: keyEnter})
+	if !s.done {
+		t.Error("Expected selector to be done after resolving conflict")
+	}
+}
+
+func TestSelectorViKeys(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+
+	// l 进入分类，j/k 移动，h 返回
+	press(s, runeKey('l'), runeKey('j'), runeKey('j'), runeKey('k'), runeKey(' '), runeKey('h'))
+	if s.inList() {
+		t.Error("Expected h to return to categories")
+	}
+	if got := s.selection(); !reflect.DeepEqual(got, []string{"mqtt"}) {
+		t.Errorf("Expected [mqtt], got %v", got)
+	}
+
+	// 搜索时 hjkl 作为普通字符输入
+	press(s, runeKey('/'))
+	typeText(s, "hjkl")
+	if s.query != "hjkl" {
+		t.Errorf("Expected query hjkl, got %q", s.query)
+	}
+}
+
+func TestSelectorPresetScreen(t *testing.T) {
+	s := newSelector(testSelectorComponents())
+	s.setPresets([]config.Preset{
+		{Name: "gateway", Description: "网关", Components: []string{"mqtt", "missing"}, Options: map[string]string{"sdk-path-mode": "env"}},
+		{Name: "beacon", Components: []string{"ble", "gpio"}},
+	})
+
+	if !s.presetScreen {
+		t.Fatal("Expected preset screen to be shown first")
+	}
+	s.width = 100
+	press(s, key{kind: keyDown})
+	output := s.render()
+	for _, want := range []string{"自定义选择", "📋 gateway - 网关", "组件: mqtt, missing", "--sdk-path-mode=env"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected render to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	// 应用预设后进入分类列表，未知组件被忽略
+	press(s, key{kind: keyEnter})
+	if s.presetScreen || s.preset == nil || s.preset.Name != "gateway" {
+		t.Fatalf("Expected gateway preset to be applied, got %+v", s.preset)
+	}
+	if got := s.selection(); !reflect.DeepEqual(got, []string{"mqtt"}) {
+		t.Errorf("Expected [mqtt], got %v", got)
+	}
+	if !strings.Contains(s.message, "missing") {
+		t.Errorf("Expected message about unknown component, got %q", s.message)
+	}
+
+	// p 回到预设列表，换成另一个预设
+	press(s, runeKey('p'), key{kind: keyDown}, key{kind: keyEnter})
+	if got := s.selection(); !reflect.DeepEqual(got, []string{"ble", "gpio"}) {
+		t.Errorf("Expected [ble gpio], got %v", got)
+	}
+
+	// 自定义选择保留当前选择并清除预设
+	press(s, runeKey('p'), key{kind: keyHome}, key{kind: keyEnter})
+	if s.preset != nil || len(s.selection()) != 2 {
+		t.Errorf("Expected custom choice to keep selection, got %v %+v", s.selection(), s.preset)
+	}
+}
+
+func TestSelectorSavePreset(t *testing.T) {
+	var saved []config.Preset
+	s := newSelector(testSelectorComponents())
+	s.setPresets([]config.Preset{{
+		Name:             "gateway",
+		Components:       []string{"mqtt"},
+		Options:          map[string]string{"sdk-path-mode": "env"},
+		ComponentOptions: map[string]string{"uart.baudrate": "9600"},
+		Pins:             map[string]string{"led": "IO5"},
+	}})
+	s.savePreset = func(p config.Preset) error {
+		saved = append(saved, p)
+		return nil
+	}
+	// 命令行指定的组件选项和引脚一并保存，覆盖预设中的同名值
+	s.overrides = overridePreset(map[string]int{"LED": 14}, map[string]string{"uart.parity": "even"})
+
+	// 应用预设，再加选 gpio 后保存为 my-node
+	press(s, key{kind: keyDown}, key{kind: keyEnter})
+	press(s, key{kind: keyDown}, key{kind: keyRight}, runeKey(' '), key{kind: keyLeft})
+	press(s, runeKey('s'))
+	typeText(s, "my-nodex")
+	press(s, key{kind: keyBackspace})
+	if !strings.Contains(s.render(), "保存为预设: my-node") {
+		t.Errorf("Expected name prompt, got:\n%s", s.render())
+	}
+	press(s, key{kind: keyEnter})
+
+	if len(saved) != 1 {
+		t.Fatalf("Expected one saved preset, got %d", len(saved))
+	}
+	want := config.Preset{
+		Name:             "my-node",
+		Components:       []string{"mqtt", "gpio"},
+		Options:          map[string]string{"sdk-path-mode": "env"},
+		ComponentOptions: map[string]string{"uart.baudrate": "9600", "uart.parity": "even"},
+		Pins:             map[string]string{"LED": "14"},
+	}
+	if !reflect.DeepEqual(saved[0], want) {
+		t.Errorf("Expected %+v, got %+v", want, saved[0])
+	}
+	if len(s.presets) != 2 || !strings.Contains(s.message, "已保存预设 my-node") {
+		t.Errorf("Expected preset list to be updated, got %v / %q", s.presets, s.message)
+	}
+
+	// 无效名称不保存
+	press(s, runeKey('s'))
+	typeText(s, "bad name")
+	press(s, key{kind: keyEnter})
+	if len(saved) != 1 || !strings.Contains(s.message, "预设名称") {
+		t.Errorf("Expected invalid name to be rejected, got %q", s.message)
+	}
+
+	// ESC 取消输入
+	press(s, runeKey('s'), runeKey('x'), key{kind: keyEsc})
+	if s.naming || len(saved) != 1 {
+		t.Error("Expected ESC to cancel naming")
+	}
+}
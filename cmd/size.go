@@ -0,0 +1,605 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"wb2-cli/internal/build"
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+	"wb2-cli/internal/importer"
+	"wb2-cli/internal/partition"
+	"wb2-cli/internal/size"
+)
+
+var (
+	sizePath         string
+	sizeELF          string
+	sizeTop          int
+	sizeDiff         bool
+	sizeBaseline     string
+	sizeSaveBaseline string
+	sizeJSON         bool
+	sizeMaxFlash     string
+	sizeMaxRAM       string
+	sizeMinHeap      string
+	sizeSymbols      bool
+	sizeBy           string
+)
+
+// 占用树的分组（size --symbols）
+const (
+	sizeGroupProject = "项目代码"
+	sizeGroupBase    = "基础组件"
+	sizeGroupShared  = "多个组件共用"
+	sizeGroupSDK     = "其他 SDK 组件"
+)
+
+// treemapChildren 占用树中每个组件下最多列出的子项数量
+const treemapChildren = 5
+
+// sizePreviousFile 上一次编译的大小报告，由 wb2-cli build 在编译前保存到 build_out
+const sizePreviousFile = ".wb2-size-prev.json"
+
+// sizeCmd represents the size command
+var sizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "分析固件的 Flash 和 RAM 占用",
+	Long: `读取 build_out 中的 ELF 和 map 文件，输出各段大小、各 SDK 组件和目标文件的占用，
+以及链接后剩余给 FreeRTOS 堆的 RAM。
+
+--diff 与上一次编译的结果比较（wb2-cli build 在编译前保存），--baseline 与
+--save-baseline 保存的基线比较。超出预算（--max-flash、--max-ram、--min-heap
+或项目清单中的 size_budget）时返回非零退出码，可用于 CI。
+
+--symbols 列出占用最大的符号，并按组件汇总为占用树：Makefile 中的每个 SDK 组件
+归入引入它的组件（移除该组件后可以节省的空间），基础组件和被多个组件共用的
+SDK 组件单独分组。
+
+示例:
+  wb2-cli size
+  wb2-cli size --diff
+  wb2-cli size --save-baseline size-baseline.json
+  wb2-cli size --baseline size-baseline.json --max-flash 1M --min-heap 64K
+  wb2-cli size --symbols --by ram
+  wb2-cli size --symbols --json > size.json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runSize,
+}
+
+func init() {
+	rootCmd.AddCommand(sizeCmd)
+
+	sizeCmd.Flags().StringVarP(&sizePath, "path", "p", ".", "项目根目录")
+	sizeCmd.Flags().StringVar(&sizeELF, "elf", "", "ELF 文件路径（默认为 build_out/<项目名>.elf）")
+	sizeCmd.Flags().IntVar(&sizeTop, "top", 15, "列出占用最大的组件和目标文件数量")
+	sizeCmd.Flags().BoolVar(&sizeDiff, "diff", false, "与上一次编译的结果比较")
+	sizeCmd.Flags().StringVar(&sizeBaseline, "baseline", "", "与保存的基线文件比较")
+	sizeCmd.Flags().StringVar(&sizeSaveBaseline, "save-baseline", "", "将本次结果保存为基线文件")
+	sizeCmd.Flags().BoolVar(&sizeJSON, "json", false, "以 JSON 格式输出报告")
+	sizeCmd.Flags().StringVar(&sizeMaxFlash, "max-flash", "", "Flash 占用上限（如 1M）")
+	sizeCmd.Flags().StringVar(&sizeMaxRAM, "max-ram", "", "静态 RAM 占用上限（如 96K）")
+	sizeCmd.Flags().StringVar(&sizeMinHeap, "min-heap", "", "可用堆下限（如 64K）")
+	sizeCmd.Flags().BoolVar(&sizeSymbols, "symbols", false, "列出最大的符号和按组件汇总的占用树")
+	sizeCmd.Flags().StringVar(&sizeBy, "by", "flash", "--symbols 的排序依据: flash 或 ram")
+}
+
+func runSize(cmd *cobra.Command, args []string) error {
+	if sizeDiff && sizeBaseline != "" {
+		return fmt.Errorf("--diff 和 --baseline 不能同时使用")
+	}
+	if _, err := sizeMetric(sizeBy); err != nil {
+		return err
+	}
+
+	projectDir, err := filepath.Abs(sizePath)
+	if err != nil {
+		return fmt.Errorf("解析项目路径失败: %v", err)
+	}
+	manifest, err := config.LoadManifest(projectDir)
+	if err != nil {
+		return err
+	}
+	budget, err := sizeBudget(manifest.SizeBudget)
+	if err != nil {
+		return err
+	}
+
+	elfPath := sizeELF
+	if elfPath == "" {
+		if elfPath, err = findELF(projectDir, manifest.Name); err != nil {
+			return err
+		}
+	}
+	report, mapErr, err := readSizeReport(elfPath, sizeSymbols)
+	if err != nil {
+		return err
+	}
+	if sizeSymbols {
+		if mapErr != nil {
+			return fmt.Errorf("--symbols 需要 map 文件: %v", mapErr)
+		}
+		report.Tree = size.SymbolTree(report.Symbols, sizeGroups(projectDir, manifest))
+	}
+
+	var previous *size.Report
+	switch {
+	case sizeDiff:
+		prevPath := filepath.Join(projectDir, build.BuildDir, sizePreviousFile)
+		if _, err := os.Stat(prevPath); os.IsNotExist(err) {
+			return fmt.Errorf("没有上一次编译的大小记录，请再次运行 wb2-cli build 后重试")
+		}
+		if previous, err = size.Load(prevPath); err != nil {
+			return err
+		}
+	case sizeBaseline != "":
+		if previous, err = size.Load(sizeBaseline); err != nil {
+			return err
+		}
+	}
+
+	if sizeJSON {
+		data, err := report.JSON()
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(data)
+	} else {
+		fmt.Printf("📦 ELF: %s\n", elfPath)
+		if mapErr != nil {
+			fmt.Printf("⚠️  %v，跳过组件和目标文件统计\n", mapErr)
+		}
+		fmt.Println()
+		if sizeSymbols {
+			printSymbolReport(os.Stdout, report, sizeBy, sizeTop)
+		} else {
+			printSizeReport(os.Stdout, report, previous, firmwareCapacity(projectDir), sizeTop)
+		}
+	}
+
+	if sizeSaveBaseline != "" {
+		if err := report.Save(sizeSaveBaseline); err != nil {
+			return err
+		}
+		if !sizeJSON {
+			fmt.Printf("\n💾 基线已保存到 %s\n", sizeSaveBaseline)
+		}
+	}
+
+	if violations := budget.Check(report); len(violations) > 0 {
+		for _, v := range violations {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", v)
+		}
+		return fmt.Errorf("固件大小超出预算")
+	}
+	return nil
+}
+
+// findELF 查找项目编译输出的 ELF：优先 build_out/<项目名>.elf，否则为 build_out 中唯一的 ELF
+func findELF(projectDir, projectName string) (string, error) {
+	buildDir := filepath.Join(projectDir, build.BuildDir)
+	path := filepath.Join(buildDir, projectName+".elf")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join(buildDir, "*.elf"))
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("在 %s 中找不到 ELF 文件，请先运行 wb2-cli build", buildDir)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("%s 中有多个 ELF 文件，请使用 --elf 指定", buildDir)
+}
+
+// readSizeReport 读取 ELF 和同名的 map 文件，symbols 为 true 时同时统计符号占用；
+// map 文件缺失或无效时通过 mapErr 返回，报告中只有段统计
+func readSizeReport(elfPath string, symbols bool) (report *size.Report, mapErr error, err error) {
+	report, err = size.ReadELF(elfPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	mapPath := strings.TrimSuffix(elfPath, filepath.Ext(elfPath)) + ".map"
+	f, err := os.Open(mapPath)
+	if err != nil {
+		return report, fmt.Errorf("找不到 map 文件 %s", mapPath), nil
+	}
+	defer f.Close()
+	m, err := size.ParseMap(f)
+	if err != nil {
+		return report, fmt.Errorf("解析 %s 失败: %v", mapPath, err), nil
+	}
+	report.AddMap(m)
+	if symbols {
+		report.Symbols = report.SymbolSizes(m)
+	}
+	return report, nil, nil
+}
+
+// saveSizeSnapshot 保存编译前的大小报告，供 wb2-cli size --diff 比较；
+// 还没有编译产物时不保存
+func saveSizeSnapshot(projectDir string, report *size.Report) {
+	if report == nil {
+		return
+	}
+	buildDir := filepath.Join(projectDir, build.BuildDir)
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		return
+	}
+	if err := report.Save(filepath.Join(buildDir, sizePreviousFile)); err != nil {
+		fmt.Printf("警告: %v\n", err)
+	}
+}
+
+// snapshotSize 读取当前编译产物的大小报告，没有编译产物时返回 nil
+func snapshotSize(projectDir, projectName string) *size.Report {
+	elfPath, err := findELF(projectDir, projectName)
+	if err != nil {
+		return nil
+	}
+	report, _, err := readSizeReport(elfPath, false)
+	if err != nil {
+		return nil
+	}
+	return report
+}
+
+// sizeBudget 合并项目清单和命令行中的大小预算，命令行优先
+func sizeBudget(manifestBudget config.SizeBudget) (size.Budget, error) {
+	var budget size.Budget
+	fields := []struct {
+		name, flag, manifest string
+		target               *int
+	}{
+		{"flash", sizeMaxFlash, manifestBudget.Flash, &budget.Flash},
+		{"ram", sizeMaxRAM, manifestBudget.RAM, &budget.RAM},
+		{"min_heap", sizeMinHeap, manifestBudget.MinHeap, &budget.MinHeap},
+	}
+	for _, f := range fields {
+		value := f.flag
+		if value == "" {
+			value = f.manifest
+		}
+		if value == "" {
+			continue
+		}
+		v, err := partition.ParseSize(value)
+		if err != nil {
+			return budget, fmt.Errorf("无效的大小预算 %s: %q", f.name, value)
+		}
+		*f.target = v
+	}
+	return budget, nil
+}
+
+// firmwareCapacity 返回项目分区表中固件分区的大小，没有分区表时返回 0
+func firmwareCapacity(projectDir string) int {
+	data, err := os.ReadFile(filepath.Join(projectDir, generator.PartitionFile))
+	if err != nil {
+		return 0
+	}
+	table, err := partition.Parse(data)
+	if err != nil {
+		return 0
+	}
+	if fw, ok := table.Find(partition.FirmwareName); ok {
+		return fw.Size0
+	}
+	return 0
+}
+
+// printSizeReport 输出大小报告；previous 不为 nil 时输出与之相比的变化
+func printSizeReport(w io.Writer, report, previous *size.Report, capacity, top int) {
+	width := 16
+	for _, s := range report.Sections {
+		width = max(width, displayWidth(s.Name))
+	}
+	fmt.Fprintf(w, "%s %s %s %s\n", padRight("段", width), padRight("地址", 10), padRight("大小", 9), "位置")
+	for _, s := range report.Sections {
+		location := "RAM"
+		if s.Flash && s.RAM {
+			location = "Flash+RAM"
+		} else if s.Flash {
+			location = "Flash"
+		}
+		fmt.Fprintf(w, "%s 0x%08X %s %s\n", padRight(s.Name, width), s.Address, padRight(size.FormatBytes(s.Size), 9), location)
+	}
+	fmt.Fprintln(w)
+
+	diff := func(cur, old int) string {
+		if previous == nil {
+			return ""
+		}
+		if d := size.FormatDiff(cur - old); d != "" {
+			return "（" + d + "）"
+		}
+		return "（无变化）"
+	}
+	var oldFlash, oldRAM, oldHeap int
+	if previous != nil {
+		oldFlash, oldRAM, oldHeap = previous.Flash, previous.RAM, previous.HeapSize()
+	}
+
+	flash := size.FormatBytes(report.Flash)
+	if capacity > 0 {
+		flash += fmt.Sprintf(" / %s（FW 分区 %.1f%%）", size.FormatBytes(capacity), float64(report.Flash)*100/float64(capacity))
+	}
+	fmt.Fprintf(w, "%s %s%s\n", padRight("Flash", 8), flash, diff(report.Flash, oldFlash))
+	fmt.Fprintf(w, "%s %s%s\n", padRight("RAM", 8), size.FormatBytes(report.RAM), diff(report.RAM, oldRAM))
+	if len(report.Heap) > 0 {
+		var parts []string
+		for _, h := range report.Heap {
+			parts = append(parts, fmt.Sprintf("%s %s", h.Name, size.FormatBytes(h.Size)))
+		}
+		fmt.Fprintf(w, "%s %s（%s）%s\n", padRight("可用堆", 8), size.FormatBytes(report.HeapSize()),
+			strings.Join(parts, " + "), diff(report.HeapSize(), oldHeap))
+	} else {
+		fmt.Fprintf(w, "%s 未知（ELF 中没有 _heap_start/_heap_size 符号）\n", padRight("可用堆", 8))
+	}
+
+	printContributions(w, "组件", report.Components, top)
+	printContributions(w, "目标文件", report.Objects, top)
+
+	if previous != nil {
+		printDeltas(w, "组件", size.Diff(previous.Components, report.Components), top)
+		printDeltas(w, "目标文件", size.Diff(previous.Objects, report.Objects), top)
+	}
+}
+
+// printContributions 输出占用最大的前 top 项
+func printContributions(w io.Writer, title string, list []size.Contribution, top int) {
+	if len(list) == 0 {
+		return
+	}
+	width := contributionWidth(len(list), top, func(i int) string { return list[i].Name })
+	fmt.Fprintf(w, "\n%s（前 %d 个，共 %d 个）:\n", title, min(top, len(list)), len(list))
+	fmt.Fprintf(w, "  %s %s %s\n", padRight("名称", width), padRight("Flash", 9), "RAM")
+	for i, c := range list {
+		if i == top {
+			break
+		}
+		fmt.Fprintf(w, "  %s %s %s\n", padRight(c.Name, width), padRight(size.FormatBytes(c.Flash), 9), size.FormatBytes(c.RAM))
+	}
+}
+
+// printDeltas 输出变化最大的前 top 项
+func printDeltas(w io.Writer, title string, deltas []size.Delta, top int) {
+	fmt.Fprintf(w, "\n%s变化:\n", title)
+	if len(deltas) == 0 {
+		fmt.Fprintf(w, "  （无变化）\n")
+		return
+	}
+	width := contributionWidth(len(deltas), top, func(i int) string { return deltas[i].Name })
+	for i, d := range deltas {
+		if i == top {
+			fmt.Fprintf(w, "  ... 还有 %d 项变化\n", len(deltas)-top)
+			break
+		}
+		note := ""
+		if d.Added {
+			note = " 新增"
+		} else if d.Removed {
+			note = " 移除"
+		}
+		line := fmt.Sprintf("  %s Flash %s RAM %s%s", padRight(d.Name, width),
+			padRight(orDash(size.FormatDiff(d.FlashDiff())), 9), padRight(orDash(size.FormatDiff(d.RAMDiff())), 9), note)
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
+
+// contributionWidth 返回名称列宽度
+func contributionWidth(n, top int, name func(int) string) int {
+	width := 4
+	for i := 0; i < n && i < top; i++ {
+		width = max(width, displayWidth(name(i)))
+	}
+	return width
+}
+
+// orDash 空字符串显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// sizeMetric 返回 --by 对应的统计量
+func sizeMetric(by string) (func(flash, ram int) int, error) {
+	switch strings.ToLower(by) {
+	case "flash":
+		return func(flash, ram int) int { return flash }, nil
+	case "ram":
+		return func(flash, ram int) int { return ram }, nil
+	}
+	return nil, fmt.Errorf("无效的排序依据 %q，可选: flash、ram", by)
+}
+
+// sizeMetricName 返回统计量的显示名称
+func sizeMetricName(by string) string {
+	if strings.EqualFold(by, "ram") {
+		return "RAM"
+	}
+	return "Flash"
+}
+
+// sizeGroups 读取项目 Makefile 中的组件列表和项目清单中的组件，返回 SDK 组件到占用树分组的映射
+func sizeGroups(projectDir string, manifest *config.Manifest) map[string]string {
+	var lists config.ExtraComponents
+	if content, err := os.ReadFile(filepath.Join(projectDir, "Makefile")); err == nil {
+		_, _, lists = importer.ParseMakefile(string(content))
+	}
+	var selected []config.Component
+	if catalog, err := config.LoadComponents(); err == nil {
+		byName := map[string]config.Component{}
+		for _, comp := range catalog {
+			byName[comp.Name] = comp
+		}
+		for _, name := range manifest.Components {
+			if comp, ok := byName[name]; ok {
+				selected = append(selected, comp)
+			}
+		}
+	}
+	return componentGroups(lists, selected, manifest.Name)
+}
+
+// componentGroups 将 SDK 组件映射到占用树的分组
+//
+// 只由一个组件引入的 SDK 组件归入该组件，即移除该组件后不再链接；
+// 基础组件、被多个组件共用的和 Makefile 中额外添加的 SDK 组件单独分组。
+func componentGroups(lists config.ExtraComponents, selected []config.Component, projectName string) map[string]string {
+	groups := map[string]string{}
+	for _, list := range [][]string{lists.Include, lists.Network, lists.BLSys, lists.VFS, lists.MQTT} {
+		for _, name := range list {
+			groups[name] = sizeGroupSDK
+		}
+	}
+
+	all := generator.SDKComponents(selected)
+	owners := map[string][]string{}
+	for i, comp := range selected {
+		rest := append(append([]config.Component{}, selected[:i]...), selected[i+1:]...)
+		remaining := map[string]bool{}
+		for _, name := range generator.SDKComponents(rest) {
+			remaining[name] = true
+		}
+		for _, name := range all {
+			if !remaining[name] {
+				owners[name] = append(owners[name], comp.Name)
+			}
+		}
+	}
+	base := map[string]bool{}
+	for _, list := range [][]string{generator.BaseIncludeComponents, generator.BaseBLSysComponents, generator.BaseVFSComponents} {
+		for _, name := range list {
+			base[name] = true
+		}
+	}
+	for _, name := range all {
+		switch {
+		case base[name]:
+			groups[name] = sizeGroupBase
+		case len(owners[name]) == 1:
+			groups[name] = owners[name][0]
+		default:
+			groups[name] = sizeGroupShared
+		}
+	}
+
+	groups[projectName] = sizeGroupProject
+	return groups
+}
+
+// printSymbolReport 按 Flash 或 RAM（by）输出最大的符号和占用树
+func printSymbolReport(w io.Writer, report *size.Report, by string, top int) {
+	metric, err := sizeMetric(by)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "Flash %s，RAM %s\n", size.FormatBytes(report.Flash), size.FormatBytes(report.RAM))
+
+	var symbols []size.SymbolSize
+	for _, s := range report.Symbols {
+		if metric(boolSize(s.Flash, s.Size), boolSize(s.RAM, s.Size)) > 0 {
+			symbols = append(symbols, s)
+		}
+		if len(symbols) == top {
+			break
+		}
+	}
+	fmt.Fprintf(w, "\n最大的符号（%s，前 %d 个）:\n", sizeMetricName(by), len(symbols))
+	nameWidth, sectionWidth, compWidth := 4, 4, 4
+	for _, s := range symbols {
+		nameWidth = max(nameWidth, displayWidth(s.Name))
+		sectionWidth = max(sectionWidth, displayWidth(s.Section))
+		compWidth = max(compWidth, displayWidth(s.Component))
+	}
+	fmt.Fprintf(w, "  %s %s %s %s %s\n", padRight("大小", 8), padRight("符号", nameWidth), padRight("段", sectionWidth), padRight("组件", compWidth), "目标文件")
+	for _, s := range symbols {
+		fmt.Fprintf(w, "  %s %s %s %s %s\n", padRight(size.FormatBytes(s.Size), 8), padRight(s.Name, nameWidth),
+			padRight(s.Section, sectionWidth), padRight(s.Component, compWidth), s.Object)
+	}
+
+	if report.Tree != nil {
+		fmt.Fprintf(w, "\n占用树（%s）:\n", sizeMetricName(by))
+		printTreemap(w, report.Tree, metric)
+	}
+}
+
+// boolSize 在 ok 时返回 n，否则返回 0
+func boolSize(ok bool, n int) int {
+	if ok {
+		return n
+	}
+	return 0
+}
+
+// treemapRow 占用树中的一行
+type treemapRow struct {
+	label string
+	value int
+}
+
+// printTreemap 以文本树输出 分组 / 组件 / 目标文件 的占用和占比
+func printTreemap(w io.Writer, root *size.Node, metric func(flash, ram int) int) {
+	total := metric(root.Flash, root.RAM)
+	if total == 0 {
+		return
+	}
+
+	var rows []treemapRow
+	var walk func(n *size.Node, prefix string, depth int)
+	walk = func(n *size.Node, prefix string, depth int) {
+		var children []*size.Node
+		for _, c := range n.Children {
+			if metric(c.Flash, c.RAM) > 0 {
+				children = append(children, c)
+			}
+		}
+		// 分组全部列出，以下各层按占用从大到小列出前几项
+		sort.SliceStable(children, func(i, j int) bool {
+			return metric(children[i].Flash, children[i].RAM) > metric(children[j].Flash, children[j].RAM)
+		})
+		shown, rest := children, 0
+		if depth > 0 && len(children) > treemapChildren {
+			shown = children[:treemapChildren]
+			for _, c := range children[treemapChildren:] {
+				rest += metric(c.Flash, c.RAM)
+			}
+		}
+		for i, c := range shown {
+			branch, indent := "├─ ", "│  "
+			if i == len(shown)-1 && rest == 0 {
+				branch, indent = "└─ ", "   "
+			}
+			rows = append(rows, treemapRow{prefix + branch + c.Name, metric(c.Flash, c.RAM)})
+			if depth < 2 {
+				walk(c, prefix+indent, depth+1)
+			}
+		}
+		if rest > 0 {
+			rows = append(rows, treemapRow{fmt.Sprintf("%s└─ … 其余 %d 项", prefix, len(children)-len(shown)), rest})
+		}
+	}
+	rows = append(rows, treemapRow{"全部", total})
+	walk(root, "", 0)
+
+	width := 0
+	for _, r := range rows {
+		width = max(width, displayWidth(r.label))
+	}
+	for _, r := range rows {
+		ratio := float64(r.value) / float64(total)
+		bar := strings.Repeat("█", int(ratio*20+0.5))
+		line := fmt.Sprintf("%s %s %s %s", padRight(r.label, width), padRight(size.FormatBytes(r.value), 8),
+			padRight(fmt.Sprintf("%.1f%%", ratio*100), 6), bar)
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/size"
+)
+
+func TestPrintSizeReport(t *testing.T) {
+	report := &size.Report{
+		Flash: 600 * 1024,
+		RAM:   80 * 1024,
+		Sections: []size.Section{
+			{Name: ".text", Address: 0x23000000, Size: 590 * 1024, Flash: true},
+			{Name: ".data", Address: 0x42020000, Size: 10 * 1024, Flash: true, RAM: true},
+			{Name: ".bss", Address: 0x42022800, Size: 70 * 1024, RAM: true},
+		},
+		Heap:       []size.HeapRegion{{Name: "heap", Size: 96 * 1024}, {Name: "heap_wifi", Size: 24 * 1024}},
+		Components: []size.Contribution{{Name: "lwip", Flash: 120 * 1024, RAM: 20 * 1024}, {Name: "demo", Flash: 2048}},
+	}
+	previous := &size.Report{
+		Flash:      590 * 1024,
+		RAM:        80 * 1024,
+		Heap:       []size.HeapRegion{{Name: "heap", Size: 96 * 1024}, {Name: "heap_wifi", Size: 24 * 1024}},
+		Components: []size.Contribution{{Name: "lwip", Flash: 110 * 1024, RAM: 20 * 1024}, {Name: "mbedtls", Flash: 1024}},
+	}
+
+	var buf bytes.Buffer
+	printSizeReport(&buf, report, previous, 1200*1024, 1)
+	output := buf.String()
+	for _, want := range []string{
+		".data            0x42020000 10.0K     Flash+RAM\n",
+		".bss             0x42022800 70.0K     RAM\n",
+		"Flash    600.0K / 1.17M（FW 分区 50.0%）（+10.0K）\n",
+		"RAM      80.0K（无变化）\n",
+		"可用堆   120.0K（heap 96.0K + heap_wifi 24.0K）（无变化）\n",
+		"组件（前 1 个，共 2 个）:\n  名称 Flash     RAM\n  lwip 120.0K    20.0K\n",
+		"组件变化:\n  lwip Flash +10.0K    RAM -\n  ... 还有 2 项变化\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "  demo ") {
+		t.Errorf("Expected only the top component, got:\n%s", output)
+	}
+}
+
+func TestSizeBudget(t *testing.T) {
+	defer func() { sizeMaxFlash, sizeMaxRAM, sizeMinHeap = "", "", "" }()
+
+	sizeMaxFlash = "1M"
+	budget, err := sizeBudget(config.SizeBudget{Flash: "512K", MinHeap: "64K"})
+	if err != nil {
+		t.Fatalf("sizeBudget() error = %v", err)
+	}
+	want := size.Budget{Flash: 1024 * 1024, MinHeap: 64 * 1024}
+	if budget != want {
+		t.Errorf("sizeBudget() = %+v, want %+v", budget, want)
+	}
+
+	sizeMaxFlash = ""
+	if _, err := sizeBudget(config.SizeBudget{RAM: "lots"}); err == nil || !strings.Contains(err.Error(), "ram") {
+		t.Errorf("sizeBudget() error = %v, want invalid ram budget", err)
+	}
+}
+
+func TestFindELF(t *testing.T) {
+	dir := t.TempDir()
+	buildDir := filepath.Join(dir, "build_out")
+	os.MkdirAll(buildDir, 0755)
+
+	if _, err := findELF(dir, "demo"); err == nil {
+		t.Error("findELF() error = nil, want error without ELF")
+	}
+
+	os.WriteFile(filepath.Join(buildDir, "other.elf"), nil, 0644)
+	if path, err := findELF(dir, "demo"); err != nil || filepath.Base(path) != "other.elf" {
+		t.Errorf("findELF() = %q, %v, want the only ELF", path, err)
+	}
+
+	os.WriteFile(filepath.Join(buildDir, "third.elf"), nil, 0644)
+	if _, err := findELF(dir, "demo"); err == nil {
+		t.Error("findELF() error = nil, want error with several ELFs")
+	}
+
+	os.WriteFile(filepath.Join(buildDir, "demo.elf"), nil, 0644)
+	if path, err := findELF(dir, "demo"); err != nil || filepath.Base(path) != "demo.elf" {
+		t.Errorf("findELF() = %q, %v, want demo.elf", path, err)
+	}
+}
+
+func TestComponentGroups(t *testing.T) {
+	selected := []config.Component{
+		{Name: "lvgl", IncludeComponents: []string{"lvgl", "fatfs"}},
+		{Name: "sdcard", IncludeComponents: []string{"fatfs", "sdh"}},
+		{Name: "mbedtls", IncludeComponents: []string{"mbedtls_lts"}},
+	}
+	lists := config.ExtraComponents{Include: []string{"freertos_riscv_ram", "lvgl", "fatfs", "sdh", "mbedtls_lts", "my_driver"}}
+	groups := componentGroups(lists, selected, "demo")
+
+	for comp, want := range map[string]string{
+		"lvgl":               "lvgl",
+		"sdh":                "sdcard",
+		"fatfs":              sizeGroupShared,
+		"mbedtls_lts":        sizeGroupBase, // 基础组件，移除 mbedtls 不会减少
+		"freertos_riscv_ram": sizeGroupBase,
+		"my_driver":          sizeGroupSDK,
+		"demo":               sizeGroupProject,
+	} {
+		if groups[comp] != want {
+			t.Errorf("groups[%s] = %q, want %q", comp, groups[comp], want)
+		}
+	}
+	if _, ok := groups["libc"]; ok {
+		t.Errorf("unexpected group for libc: %q", groups["libc"])
+	}
+}
+
+func TestPrintSymbolReport(t *testing.T) {
+	var symbols []size.SymbolSize
+	for i := 0; i < 8; i++ {
+		symbols = append(symbols, size.SymbolSize{Name: fmt.Sprintf("lv_obj_%d", i), Size: (8 - i) * 1024, Section: ".text",
+			Component: "lvgl", Object: fmt.Sprintf("liblvgl.a(obj%d.o)", i), Flash: true})
+	}
+	symbols = append(symbols, size.SymbolSize{Name: "lwip_heap", Size: 4096, Section: ".bss", Component: "lwip", Object: "liblwip.a(mem.o)", RAM: true})
+	report := &size.Report{Flash: 36 * 1024, RAM: 4096, Symbols: symbols}
+	report.Tree = size.SymbolTree(symbols, map[string]string{"lvgl": "lvgl", "lwip": sizeGroupBase})
+
+	var buf bytes.Buffer
+	printSymbolReport(&buf, report, "flash", 2)
+	output := buf.String()
+	for _, want := range []string{
+		"最大的符号（Flash，前 2 个）:\n",
+		"  8.0K     lv_obj_0 .text lvgl liblvgl.a(obj0.o)\n",
+		"占用树（Flash）:\n",
+		"全部                       36.0K    100.0% ████████████████████\n",
+		"└─ lvgl                    36.0K    100.0% ████████████████████\n",
+		"   └─ lvgl                 36.0K    100.0% ████████████████████\n",
+		"      ├─ liblvgl.a(obj0.o) 8.0K     22.2%  ████\n",
+		"      └─ … 其余 3 项       6.0K     16.7%  ███\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "lwip") {
+		t.Errorf("Expected RAM-only symbols to be hidden when ranking by flash, got:\n%s", output)
+	}
+
+	buf.Reset()
+	printSymbolReport(&buf, report, "ram", 5)
+	if !strings.Contains(buf.String(), "lwip_heap") || strings.Contains(buf.String(), "lv_obj_0") {
+		t.Errorf("Expected only RAM symbols when ranking by ram, got:\n%s", buf.String())
+	}
+}
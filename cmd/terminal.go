@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// ttySource 从终端读取字节，按需等待输入就绪以实现超时
+type ttySource struct {
+	file    *os.File
+	buf     [64]byte
+	pending []byte
+}
+
+// newTTYSource 创建终端字节源
+func newTTYSource(file *os.File) *ttySource {
+	return &ttySource{file: file}
+}
+
+func (t *ttySource) readByte(timeout time.Duration) (byte, bool, error) {
+	if len(t.pending) == 0 {
+		if timeout > 0 {
+			ready, err := waitReadable(t.file, timeout)
+			if err != nil {
+				return 0, false, err
+			}
+			if !ready {
+				return 0, false, nil
+			}
+		}
+		n, err := t.file.Read(t.buf[:])
+		if n == 0 {
+			if err == nil {
+				err = io.EOF
+			}
+			return 0, false, err
+		}
+		t.pending = t.buf[:n]
+	}
+
+	b := t.pending[0]
+	t.pending = t.pending[1:]
+	return b, true, nil
+}
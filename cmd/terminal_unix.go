@@ -0,0 +1,36 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// waitReadable 等待文件可读，超时返回 false
+func waitReadable(file *os.File, timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(file.Fd()), Events: unix.POLLIN}}
+	for {
+		n, err := unix.Poll(fds, int(timeout.Milliseconds()))
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return n > 0, nil
+	}
+}
+
+// setupTerminal 将输入切换到 raw 模式，返回恢复函数
+func setupTerminal(in, out *os.File) (func(), error) {
+	fd := int(in.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() { term.Restore(fd, oldState) }, nil
+}
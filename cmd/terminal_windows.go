@@ -0,0 +1,96 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/term"
+)
+
+var (
+	kernel32              = windows.NewLazySystemDLL("kernel32.dll")
+	procPeekConsoleInputW = kernel32.NewProc("PeekConsoleInputW")
+	procReadConsoleInputW = kernel32.NewProc("ReadConsoleInputW")
+)
+
+// keyEventType INPUT_RECORD 中的 KEY_EVENT 类型
+const keyEventType = 0x0001
+
+// inputRecord 对应 Windows 的 INPUT_RECORD，Event 联合体按 KEY_EVENT_RECORD 解析
+type inputRecord struct {
+	eventType uint16
+	_         uint16
+	keyDown   int32
+	repeat    uint16
+	keyCode   uint16
+	scanCode  uint16
+	char      uint16
+	control   uint32
+}
+
+// setupTerminal 开启控制台的虚拟终端输入/输出并切换到 raw 模式，返回恢复函数。
+// 旧版控制台不支持虚拟终端序列时返回错误
+func setupTerminal(in, out *os.File) (func(), error) {
+	outHandle := windows.Handle(out.Fd())
+	var outMode uint32
+	if err := windows.GetConsoleMode(outHandle, &outMode); err != nil {
+		return nil, err
+	}
+	vtMode := outMode | windows.ENABLE_PROCESSED_OUTPUT | windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
+	if err := windows.SetConsoleMode(outHandle, vtMode); err != nil {
+		return nil, err
+	}
+
+	// MakeRaw 会同时开启 ENABLE_VIRTUAL_TERMINAL_INPUT，方向键以 ESC 序列输入
+	fd := int(in.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		windows.SetConsoleMode(outHandle, outMode)
+		return nil, err
+	}
+
+	return func() {
+		term.Restore(fd, oldState)
+		windows.SetConsoleMode(outHandle, outMode)
+	}, nil
+}
+
+// waitReadable 等待控制台输入中出现字符，超时返回 false。
+// 控制台输入句柄在按键抬起、焦点变化等事件时也会就绪，这些事件会被丢弃
+func waitReadable(file *os.File, timeout time.Duration) (bool, error) {
+	handle := windows.Handle(file.Fd())
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := max(time.Until(deadline), 0)
+		event, err := windows.WaitForSingleObject(handle, uint32(remaining.Milliseconds()))
+		if err != nil {
+			return false, err
+		}
+		if event != windows.WAIT_OBJECT_0 {
+			return false, nil
+		}
+
+		records := make([]inputRecord, 16)
+		var n uint32
+		if r, _, err := procPeekConsoleInputW.Call(uintptr(handle), uintptr(unsafe.Pointer(&records[0])), uintptr(len(records)), uintptr(unsafe.Pointer(&n))); r == 0 {
+			return false, err
+		}
+		for _, rec := range records[:n] {
+			if rec.eventType == keyEventType && rec.keyDown != 0 && rec.char != 0 {
+				return true, nil
+			}
+		}
+
+		// 丢弃不产生字符的事件后继续等待
+		if r, _, err := procReadConsoleInputW.Call(uintptr(handle), uintptr(unsafe.Pointer(&records[0])), uintptr(n), uintptr(unsafe.Pointer(&n))); r == 0 {
+			return false, err
+		}
+		if remaining == 0 {
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package build
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildDir SDK 的编译输出目录（相对于项目根目录）
+const BuildDir = "build_out"
+
+// profileFile 记录上次编译使用的配置，切换配置时需要先清理
+const profileFile = ".wb2-profile"
+
+// Profile 编译配置
+type Profile string
+
+const (
+	// ProfileDefault 使用 proj_config.mk 中的设置
+	ProfileDefault Profile = ""
+	// ProfileRelease 开启 CONFIG_OPTIMIZATION_LEVEL_RELEASE（-Os）
+	ProfileRelease Profile = "release"
+	// ProfileDebug 关闭 CONFIG_OPTIMIZATION_LEVEL_RELEASE（-Og，便于调试）
+	ProfileDebug Profile = "debug"
+)
+
+// Options 编译选项
+type Options struct {
+	ProjectDir string
+	SDKPath    string
+	Jobs       int
+	Profile    Profile
+	// 实时输出 make 的输出，为 nil 时只收集
+	Output io.Writer
+	// make 命令，为空时使用 make
+	Make string
+}
+
+// Result 编译结果
+type Result struct {
+	Output      string
+	Diagnostics []Diagnostic
+	// make 的退出错误，编译成功时为 nil
+	Err      error
+	Duration time.Duration
+	// 切换编译配置时是否先执行了 make clean
+	Cleaned bool
+}
+
+// MakeArgs 返回传给 make 的参数
+func MakeArgs(jobs int, profile Profile) []string {
+	args := []string{"-j" + strconv.Itoa(jobs)}
+	switch profile {
+	case ProfileRelease:
+		args = append(args, "CONFIG_OPTIMIZATION_LEVEL_RELEASE=1")
+	case ProfileDebug:
+		// 命令行变量覆盖 proj_config.mk 中的设置，为空即关闭
+		args = append(args, "CONFIG_OPTIMIZATION_LEVEL_RELEASE=")
+	}
+	return args
+}
+
+// Run 在项目目录中执行 make；make 本身失败（如编译错误）时记录在 Result.Err 中，
+// 无法启动 make 等情况返回 error
+func Run(opts Options) (*Result, error) {
+	makeCmd := opts.Make
+	if makeCmd == "" {
+		makeCmd = "make"
+	}
+	if _, err := exec.LookPath(makeCmd); err != nil {
+		return nil, fmt.Errorf("找不到 %s 命令，请先安装 make 和 SDK 工具链", makeCmd)
+	}
+
+	result := &Result{}
+	start := time.Now()
+
+	// 编译配置变化时先清理，否则已编译的目标文件不会按新的优化级别重新编译
+	profilePath := filepath.Join(opts.ProjectDir, BuildDir, profileFile)
+	if last, err := os.ReadFile(profilePath); err == nil && Profile(strings.TrimSpace(string(last))) != opts.Profile {
+		if _, err := runMake(makeCmd, opts, []string{"clean"}); err != nil {
+			return nil, fmt.Errorf("切换编译配置时清理失败: %v", err)
+		}
+		result.Cleaned = true
+	}
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	if opts.Output != nil {
+		out = io.MultiWriter(&buf, opts.Output)
+	}
+	cmd := makeCommand(makeCmd, opts, MakeArgs(opts.Jobs, opts.Profile))
+	cmd.Stdout = out
+	cmd.Stderr = out
+	runErr := cmd.Run()
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return nil, fmt.Errorf("执行 %s 失败: %v", makeCmd, runErr)
+	}
+
+	result.Output = buf.String()
+	result.Diagnostics = ParseDiagnostics(result.Output, opts.ProjectDir)
+	result.Err = runErr
+	result.Duration = time.Since(start)
+
+	if runErr == nil {
+		// 记录本次的编译配置，忽略写入失败（只影响下次是否自动清理）
+		os.WriteFile(profilePath, []byte(string(opts.Profile)+"\n"), 0644)
+	}
+	return result, nil
+}
+
+// runMake 执行一次 make 并返回输出
+func runMake(makeCmd string, opts Options, args []string) ([]byte, error) {
+	return makeCommand(makeCmd, opts, args).CombinedOutput()
+}
+
+// makeCommand 构造在项目目录中执行的 make 命令，通过环境变量传递 SDK 路径
+func makeCommand(makeCmd string, opts Options, args []string) *exec.Cmd {
+	cmd := exec.Command(makeCmd, args...)
+	cmd.Dir = opts.ProjectDir
+	cmd.Env = os.Environ()
+	if opts.SDKPath != "" {
+		cmd.Env = append(cmd.Env, "BL60X_SDK_PATH="+opts.SDKPath)
+	}
+	return cmd
+}
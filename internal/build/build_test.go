@@ -0,0 +1,79 @@
+//go:build unix
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFakeMake 写入一个记录参数和 BL60X_SDK_PATH 的假 make，参数中没有 clean 时按 exitCode 退出
+func writeFakeMake(t *testing.T, dir string, exitCode string) string {
+	t.Helper()
+	script := `#!/bin/sh
+echo "$@ SDK=$BL60X_SDK_PATH" >> calls.log
+case "$1" in clean) exit 0;; esac
+echo "$PWD/demo/main.c:3:1: warning: unused function 'f' [-Wunused-function]" >&2
+exit ` + exitCode + "\n"
+	path := filepath.Join(dir, "fake-make")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake make: %v", err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	// 解析符号链接，使 make 中的 $PWD 与项目目录一致（如 macOS 的 /var -> /private/var）
+	projectDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	makeCmd := writeFakeMake(t, t.TempDir(), "0")
+	if err := os.MkdirAll(filepath.Join(projectDir, BuildDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{ProjectDir: projectDir, SDKPath: "/opt/sdk", Jobs: 2, Profile: ProfileRelease, Make: makeCmd}
+	result, err := Run(opts)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Err != nil || result.Cleaned {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].File != "demo/main.c" {
+		t.Errorf("Unexpected diagnostics: %+v", result.Diagnostics)
+	}
+
+	// 切换配置时先清理，失败时记录在 Result.Err 中
+	opts.Profile = ProfileDebug
+	opts.Make = writeFakeMake(t, t.TempDir(), "2")
+	result, err = Run(opts)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Err == nil || !result.Cleaned {
+		t.Errorf("Expected failed build after clean, got %+v", result)
+	}
+
+	calls, _ := os.ReadFile(filepath.Join(projectDir, "calls.log"))
+	expected := "-j2 CONFIG_OPTIMIZATION_LEVEL_RELEASE=1 SDK=/opt/sdk\nclean SDK=/opt/sdk\n-j2 CONFIG_OPTIMIZATION_LEVEL_RELEASE= SDK=/opt/sdk\n"
+	if string(calls) != expected {
+		t.Errorf("Unexpected make calls:\n%s\nexpected:\n%s", calls, expected)
+	}
+
+	// 失败的编译不更新记录的配置
+	profile, _ := os.ReadFile(filepath.Join(projectDir, BuildDir, profileFile))
+	if strings.TrimSpace(string(profile)) != string(ProfileRelease) {
+		t.Errorf("Expected recorded profile to stay release, got %q", profile)
+	}
+}
+
+func TestRunMissingMake(t *testing.T) {
+	_, err := Run(Options{ProjectDir: t.TempDir(), Make: "wb2-no-such-make"})
+	if err == nil || !strings.Contains(err.Error(), "找不到") {
+		t.Errorf("Expected missing make error, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package build 调用 SDK 的 make 编译项目，并从 GCC/链接器输出中提取错误和警告
+package build
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity 诊断级别
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic 一条编译错误或警告
+type Diagnostic struct {
+	File     string
+	Line     int // 链接错误等没有行号时为 0
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+// Location 返回 file:line:column 形式的位置
+func (d Diagnostic) Location() string {
+	loc := d.File
+	if d.Line > 0 {
+		loc += ":" + strconv.Itoa(d.Line)
+		if d.Column > 0 {
+			loc += ":" + strconv.Itoa(d.Column)
+		}
+	}
+	return loc
+}
+
+var (
+	// gccRe 匹配 GCC 诊断：file:line[:column]: (fatal error|error|warning): message
+	gccRe = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+))?: (fatal error|error|warning): (.*)$`)
+	// linkRe 匹配链接错误：file.o:(.text...): undefined reference to ... 或 ld 的 file:line: undefined reference
+	linkRe = regexp.MustCompile(`^(.+?):(?:(\d+):|\([^)]*\):) *(undefined reference to .*|multiple definition of .*)$`)
+	// ldRe 匹配链接器自身的错误，如 region `ram' overflowed
+	ldRe = regexp.MustCompile(`^\S*ld(?:\.exe)?: (.*(?:overflowed|will not fit|cannot find) .*)$`)
+)
+
+// ParseDiagnostics 从编译输出中提取错误和警告，去掉重复项（头文件中的警告会在每个源文件中重复出现）。
+// projectDir 非空时，项目内的文件路径转换为相对于项目目录的路径。
+func ParseDiagnostics(output, projectDir string) []Diagnostic {
+	var diags []Diagnostic
+	seen := map[Diagnostic]bool{}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		d, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		d.File = relativePath(d.File, projectDir)
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// parseLine 解析一行输出
+func parseLine(line string) (Diagnostic, bool) {
+	if m := gccRe.FindStringSubmatch(line); m != nil {
+		d := Diagnostic{File: m[1], Message: m[5], Severity: SeverityWarning}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		if m[4] != "warning" {
+			d.Severity = SeverityError
+		}
+		return d, true
+	}
+	if m := linkRe.FindStringSubmatch(line); m != nil {
+		d := Diagnostic{File: m[1], Message: m[3], Severity: SeverityError}
+		d.Line, _ = strconv.Atoi(m[2])
+		return d, true
+	}
+	if m := ldRe.FindStringSubmatch(line); m != nil {
+		return Diagnostic{File: "ld", Message: m[1], Severity: SeverityError}, true
+	}
+	return Diagnostic{}, false
+}
+
+// relativePath 将项目目录内的路径转换为相对路径
+func relativePath(path, projectDir string) string {
+	if projectDir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(projectDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Count 统计错误和警告数量
+func Count(diags []Diagnostic) (errors, warnings int) {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
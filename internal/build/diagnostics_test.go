@@ -0,0 +1,70 @@
+package build
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sampleOutput = `CC build_out/demo/main.o
+/home/u/demo/demo/main.c: In function 'main':
+/home/u/demo/demo/main.c:12:5: error: 'x' undeclared (first use in this function)
+   12 |     x = 1;
+      |     ^
+/home/u/demo/demo/main.c:20:9: warning: unused variable 'y' [-Wunused-variable]
+/sdk/components/hal/inc/bl_gpio.h:8:1: warning: function declaration isn't a prototype [-Wstrict-prototypes]
+/sdk/components/hal/inc/bl_gpio.h:8:1: warning: function declaration isn't a prototype [-Wstrict-prototypes]
+/home/u/demo/demo/app.c:3:10: fatal error: foo.h: No such file or directory
+/home/u/demo/demo/app.c:7: warning: "DEBUG" redefined
+riscv64-unknown-elf-ld: build_out/demo/libdemo.a(main.o): in function ` + "`main':" + `
+main.c:(.text.main+0x10): undefined reference to ` + "`missing_func'" + `
+/opt/toolchain/bin/riscv64-unknown-elf-ld: demo.elf section ` + "`.bss' will not fit in region `ram'" + `
+make[1]: *** [Makefile:10: build_out/demo/main.o] Error 1
+`
+
+func TestParseDiagnostics(t *testing.T) {
+	diags := ParseDiagnostics(sampleOutput, "/home/u/demo")
+
+	expected := []Diagnostic{
+		{File: "demo/main.c", Line: 12, Column: 5, Severity: SeverityError, Message: "'x' undeclared (first use in this function)"},
+		{File: "demo/main.c", Line: 20, Column: 9, Severity: SeverityWarning, Message: "unused variable 'y' [-Wunused-variable]"},
+		{File: "/sdk/components/hal/inc/bl_gpio.h", Line: 8, Column: 1, Severity: SeverityWarning, Message: "function declaration isn't a prototype [-Wstrict-prototypes]"},
+		{File: "demo/app.c", Line: 3, Column: 10, Severity: SeverityError, Message: "foo.h: No such file or directory"},
+		{File: "demo/app.c", Line: 7, Severity: SeverityWarning, Message: `"DEBUG" redefined`},
+		{File: "main.c", Severity: SeverityError, Message: "undefined reference to `missing_func'"},
+		{File: "ld", Severity: SeverityError, Message: "demo.elf section `.bss' will not fit in region `ram'"},
+	}
+	if !reflect.DeepEqual(diags, expected) {
+		t.Errorf("Unexpected diagnostics:\n%+v\nexpected:\n%+v", diags, expected)
+	}
+
+	errors, warnings := Count(diags)
+	if errors != 4 || warnings != 3 {
+		t.Errorf("Expected 4 errors and 3 warnings, got %d and %d", errors, warnings)
+	}
+
+	if loc := diags[0].Location(); loc != "demo/main.c:12:5" {
+		t.Errorf("Unexpected location: %s", loc)
+	}
+	if loc := diags[4].Location(); loc != "demo/app.c:7" {
+		t.Errorf("Unexpected location: %s", loc)
+	}
+	if loc := diags[5].Location(); loc != "main.c" {
+		t.Errorf("Unexpected location: %s", loc)
+	}
+}
+
+func TestMakeArgs(t *testing.T) {
+	tests := []struct {
+		profile Profile
+		want    []string
+	}{
+		{ProfileDefault, []string{"-j4"}},
+		{ProfileRelease, []string{"-j4", "CONFIG_OPTIMIZATION_LEVEL_RELEASE=1"}},
+		{ProfileDebug, []string{"-j4", "CONFIG_OPTIMIZATION_LEVEL_RELEASE="}},
+	}
+	for _, tt := range tests {
+		if got := MakeArgs(4, tt.profile); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MakeArgs(4, %q) = %v, want %v", tt.profile, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+// Package compdb 为 WB2 项目生成 clangd 等工具使用的 compile_commands.json：
+// 根据 SDK 组件的 bouffalo.mk 推导每个源文件的头文件目录和宏定义，或从 make -n 的输出中提取编译命令
+package compdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// File 编译数据库文件名（位于项目根目录）
+const File = "compile_commands.json"
+
+// ComponentMakefile SDK 组件的 makefile
+const ComponentMakefile = "bouffalo.mk"
+
+// projectMakefiles SDK 中对所有组件生效的 makefile（相对 SDK 根目录）：项目 Makefile include 的
+// project.mk，以及编译每个组件时使用的 component_wrapper.mk
+var projectMakefiles = []string{"make_scripts_riscv/project.mk", "make_scripts_riscv/component_wrapper.mk"}
+
+// DefaultToolPrefix SDK 工具链的前缀
+const DefaultToolPrefix = "riscv64-unknown-elf-"
+
+// archFlags BL602（RV32IMFC）的编译选项，与 SDK 的 make_scripts_riscv 一致
+var archFlags = []string{"-march=rv32imfc", "-mabi=ilp32f"}
+
+// sourceExts 编译的源文件扩展名
+var sourceExts = []string{".c", ".cpp", ".cc", ".S"}
+
+// Entry compile_commands.json 中的一项
+type Entry struct {
+	Directory string   `json:"directory"`
+	Arguments []string `json:"arguments"`
+	File      string   `json:"file"`
+	Output    string   `json:"output,omitempty"`
+}
+
+// Marshal 按文件排序并格式化为 JSON
+func Marshal(entries []Entry) ([]byte, error) {
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].File < sorted[j].File })
+	if sorted == nil {
+		sorted = []Entry{}
+	}
+	data, err := json.MarshalIndent(sorted, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Options 推导编译命令的参数
+type Options struct {
+	ProjectDir  string
+	ProjectName string
+	SDKPath     string
+	// Components 参与编译的 SDK 组件（不含项目本身）
+	Components []string
+	// Config proj_config.mk 中的配置项，用于 bouffalo.mk 中的条件
+	Config map[string]string
+}
+
+// Component 推导出的一个组件
+type Component struct {
+	Name string
+	Dir  string
+	// Includes 导出给所有组件的头文件目录（COMPONENT_ADD_INCLUDEDIRS），PrivIncludes 只用于本组件
+	Includes     []string
+	PrivIncludes []string
+	// Flags 本组件 CFLAGS/CPPFLAGS 中的 -D、-U、-I 和 -include
+	Flags   []string
+	Sources []string
+}
+
+// Result 推导结果
+type Result struct {
+	Entries    []Entry
+	Components []Component
+	// ProjectFlags SDK 的 project.mk 等对所有组件生效的 -D、-U、-I 和 -include
+	ProjectFlags []string
+	// Missing SDK 中找不到的组件
+	Missing []string
+}
+
+// Derive 根据组件的 bouffalo.mk 推导每个源文件的编译命令。只模拟 SDK make 的主要规则，
+// 结果可能与实际编译有出入，需要准确结果时使用 make -n（Capture）
+func Derive(opts Options) (*Result, error) {
+	dirs, err := FindComponents(opts.ProjectDir, opts.SDKPath)
+	if err != nil {
+		return nil, err
+	}
+	base := Vars{
+		"BL60X_SDK_PATH": opts.SDKPath,
+		"PROJECT_PATH":   opts.ProjectDir,
+		"PROJECT_NAME":   opts.ProjectName,
+	}
+	for k, v := range opts.Config {
+		base[k] = v
+	}
+
+	result := &Result{}
+	if result.ProjectFlags, err = projectFlags(opts.ProjectDir, opts.SDKPath, base); err != nil {
+		return nil, err
+	}
+	names := append(append([]string(nil), opts.Components...), opts.ProjectName)
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		dir, ok := dirs[name]
+		if name == opts.ProjectName {
+			dir, ok = filepath.Join(opts.ProjectDir, name), true
+		}
+		if !ok {
+			result.Missing = append(result.Missing, name)
+			continue
+		}
+		comp, err := loadComponent(name, dir, base)
+		if err != nil {
+			return nil, err
+		}
+		result.Components = append(result.Components, comp)
+	}
+
+	var global []string
+	for _, comp := range result.Components {
+		global = append(global, comp.Includes...)
+	}
+	global = unique(global)
+
+	prefix := toolPrefix(opts.SDKPath, opts.Config)
+	for _, comp := range result.Components {
+		for _, src := range comp.Sources {
+			result.Entries = append(result.Entries, entry(opts.ProjectDir, prefix, comp, global, result.ProjectFlags, src))
+		}
+	}
+	return result, nil
+}
+
+// entry 构造一个源文件的编译命令；project 为对所有组件生效的选项，在组件自己的选项之前
+func entry(projectDir, prefix string, comp Component, global, project []string, src string) Entry {
+	compiler := prefix + "gcc"
+	args := append([]string{}, archFlags...)
+	if ext := filepath.Ext(src); ext == ".cpp" || ext == ".cc" {
+		compiler = prefix + "g++"
+		args = append(args, "-std=c++11")
+	} else if ext == ".c" {
+		args = append(args, "-std=gnu99")
+	}
+	args = append([]string{compiler}, args...)
+	for _, dir := range unique(append(append([]string{}, comp.PrivIncludes...), global...)) {
+		args = append(args, "-I"+dir)
+	}
+	args = append(args, project...)
+	args = append(args, comp.Flags...)
+
+	rel, err := filepath.Rel(comp.Dir, src)
+	if err != nil {
+		rel = filepath.Base(src)
+	}
+	output := filepath.Join(projectDir, "build_out", comp.Name, strings.TrimSuffix(rel, filepath.Ext(rel))+".o")
+	args = append(args, "-c", src, "-o", output)
+	return Entry{Directory: projectDir, Arguments: args, File: src, Output: output}
+}
+
+// FindComponents 查找包含 bouffalo.mk 的组件目录（组件名 -> 目录）。项目 components 目录中的
+// 组件优先，其次为 SDK components 目录中层级较浅的
+func FindComponents(projectDir, sdkPath string) (map[string]string, error) {
+	root := filepath.Join(sdkPath, "components")
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("SDK 中找不到 components 目录: %s", root)
+	}
+	dirs := map[string]string{}
+	depth := map[string]int{}
+	for _, top := range []string{filepath.Join(projectDir, "components"), root} {
+		found := map[string]bool{}
+		filepath.WalkDir(top, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || d.Name() != ComponentMakefile {
+				return nil
+			}
+			dir := filepath.Dir(path)
+			name := filepath.Base(dir)
+			level := strings.Count(filepath.ToSlash(strings.TrimPrefix(dir, top)), "/")
+			if _, ok := dirs[name]; ok && !found[name] {
+				// 已在项目 components 目录中找到
+				return nil
+			}
+			if old, ok := depth[name]; ok && old <= level {
+				return nil
+			}
+			dirs[name], depth[name], found[name] = dir, level, true
+			return nil
+		})
+	}
+	return dirs, nil
+}
+
+// projectFlags 依次求值 SDK 的 projectMakefiles，返回其 CPPFLAGS/CFLAGS 中的 -D、-U、-I 和 -include。
+// 与组件的 bouffalo.mk 一样，其中的条件按 proj_config.mk 的配置项求值；相对路径按项目目录解析
+func projectFlags(projectDir, sdkPath string, base Vars) ([]string, error) {
+	vars := base.Clone()
+	vars["CFLAGS"], vars["CPPFLAGS"], vars["CXXFLAGS"] = "", "", ""
+	for _, name := range projectMakefiles {
+		data, err := os.ReadFile(filepath.Join(sdkPath, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		vars.Eval(string(data), projectDir)
+	}
+	return filterFlags(strings.Fields(vars["CPPFLAGS"]+" "+vars["CFLAGS"]), projectDir), nil
+}
+
+// loadComponent 求值组件的 bouffalo.mk，得到头文件目录、源文件和编译选项
+func loadComponent(name, dir string, base Vars) (Component, error) {
+	comp := Component{Name: name, Dir: dir}
+	vars := base.Clone()
+	vars["COMPONENT_PATH"] = dir
+	vars["COMPONENT_NAME"] = name
+	// SDK 的默认值（component_wrapper.mk）
+	vars["COMPONENT_ADD_INCLUDEDIRS"] = "include"
+	vars["COMPONENT_SRCDIRS"] = "."
+	vars["CFLAGS"], vars["CPPFLAGS"], vars["CXXFLAGS"] = "", "", ""
+
+	data, err := os.ReadFile(filepath.Join(dir, ComponentMakefile))
+	if err != nil && !os.IsNotExist(err) {
+		return comp, err
+	}
+	vars.Eval(string(data), dir)
+
+	existingDirs := func(list string) []string {
+		var out []string
+		for _, d := range strings.Fields(list) {
+			if !filepath.IsAbs(d) {
+				d = filepath.Join(dir, d)
+			}
+			if info, err := os.Stat(d); err == nil && info.IsDir() {
+				out = append(out, filepath.Clean(d))
+			}
+		}
+		return unique(out)
+	}
+	comp.Includes = existingDirs(vars["COMPONENT_ADD_INCLUDEDIRS"])
+	comp.PrivIncludes = existingDirs(vars["COMPONENT_PRIV_INCLUDEDIRS"])
+	comp.Flags = filterFlags(strings.Fields(vars["CPPFLAGS"]+" "+vars["CFLAGS"]), dir)
+	comp.Sources = componentSources(dir, vars)
+	return comp, nil
+}
+
+// componentSources 组件编译的源文件：COMPONENT_OBJS 对应的源文件，其次为 COMPONENT_SRCS，
+// 都没有时为 COMPONENT_SRCDIRS 中的所有源文件
+func componentSources(dir string, vars Vars) []string {
+	abs := func(p string) string {
+		if filepath.IsAbs(p) {
+			return filepath.Clean(p)
+		}
+		return filepath.Join(dir, p)
+	}
+	var sources []string
+	if objs := strings.Fields(vars["COMPONENT_OBJS"]); len(objs) > 0 {
+		for _, obj := range objs {
+			stem := strings.TrimSuffix(abs(obj), filepath.Ext(obj))
+			for _, ext := range sourceExts {
+				if _, err := os.Stat(stem + ext); err == nil {
+					sources = append(sources, stem+ext)
+					break
+				}
+			}
+		}
+	} else if srcs := strings.Fields(vars["COMPONENT_SRCS"]); len(srcs) > 0 {
+		for _, src := range srcs {
+			if isSource(src) {
+				sources = append(sources, abs(src))
+			}
+		}
+	} else {
+		for _, srcDir := range strings.Fields(vars["COMPONENT_SRCDIRS"]) {
+			entries, _ := os.ReadDir(abs(srcDir))
+			for _, e := range entries {
+				if !e.IsDir() && isSource(e.Name()) {
+					sources = append(sources, filepath.Join(abs(srcDir), e.Name()))
+				}
+			}
+		}
+	}
+	return unique(sources)
+}
+
+func isSource(name string) bool {
+	ext := filepath.Ext(name)
+	for _, e := range sourceExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// filterFlags 保留影响代码解析的选项（-D、-U、-I、-include），相对路径按组件目录解析
+func filterFlags(flags []string, dir string) []string {
+	var out []string
+	for i := 0; i < len(flags); i++ {
+		f := flags[i]
+		switch {
+		case strings.HasPrefix(f, "-D"), strings.HasPrefix(f, "-U"):
+			out = append(out, f)
+		case f == "-I" || f == "-include":
+			if i+1 < len(flags) {
+				i++
+				path := flags[i]
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				if f == "-I" {
+					out = append(out, "-I"+path)
+				} else {
+					out = append(out, f, path)
+				}
+			}
+		case strings.HasPrefix(f, "-I"):
+			path := f[2:]
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			out = append(out, "-I"+path)
+		}
+	}
+	return out
+}
+
+// toolPrefix 编译器前缀：proj_config.mk 中的 CONFIG_TOOLPREFIX，其次为 SDK 自带的工具链
+func toolPrefix(sdkPath string, config map[string]string) string {
+	if prefix := strings.TrimSpace(config["CONFIG_TOOLPREFIX"]); prefix != "" {
+		return prefix
+	}
+	host := map[string]string{"linux": "Linux", "darwin": "Darwin", "windows": "MSYS"}[runtime.GOOS]
+	prefix := filepath.Join(sdkPath, "toolchain", "riscv", host, "bin", DefaultToolPrefix)
+	if _, err := os.Stat(prefix + "gcc"); err == nil {
+		return prefix
+	}
+	return DefaultToolPrefix
+}
+
+func unique(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,147 @@
+package compdb
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles 在 root 中创建文件
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDerive(t *testing.T) {
+	sdk := t.TempDir()
+	project := t.TempDir()
+	writeFiles(t, sdk, map[string]string{
+		"components/stage/easyflash4/bouffalo.mk": `COMPONENT_ADD_INCLUDEDIRS += inc
+COMPONENT_PRIV_INCLUDEDIRS := port
+COMPONENT_SRCS := src/easyflash.c src/ef_env.c
+COMPONENT_OBJS := $(patsubst %.c,%.o, $(COMPONENT_SRCS))
+COMPONENT_SRCDIRS := src port
+ifeq ($(CONFIG_EASYFLASH_ENABLE),1)
+CPPFLAGS += -DEF_USING_ENV -I$(COMPONENT_PATH)/extra
+endif
+CFLAGS += -Wno-unused
+`,
+		"components/stage/easyflash4/inc/easyflash.h": "",
+		"components/stage/easyflash4/port/ef_cfg.h":   "",
+		"components/stage/easyflash4/src/easyflash.c": "",
+		"components/stage/easyflash4/src/ef_env.c":    "",
+		"components/stage/easyflash4/src/unused.c":    "",
+		"components/utils/bouffalo.mk":                "",
+		"components/utils/include/utils_log.h":        "",
+		"components/utils/utils_log.c":                "",
+		"components/utils/test/utils/bouffalo.mk":     "",
+	})
+	writeFiles(t, project, map[string]string{
+		"demo/bouffalo.mk":             "ifeq ($(CONFIG_ENABLE_VFS_ROMFS),1)\nCPPFLAGS += -DCONF_USER_ENABLE_VFS_ROMFS\nendif\n",
+		"demo/main.c":                  "",
+		"demo/include/main_board.h":    "",
+		"components/utils/bouffalo.mk": "COMPONENT_ADD_INCLUDEDIRS := .\n",
+		"components/utils/my_utils.c":  "",
+	})
+
+	result, err := Derive(Options{
+		ProjectDir:  project,
+		ProjectName: "demo",
+		SDKPath:     sdk,
+		Components:  []string{"easyflash4", "utils", "missing"},
+		Config:      map[string]string{"CONFIG_EASYFLASH_ENABLE": "1", "CONFIG_ENABLE_VFS_ROMFS": "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Missing, []string{"missing"}) {
+		t.Errorf("Missing = %v", result.Missing)
+	}
+
+	files := map[string][]string{}
+	for _, e := range result.Entries {
+		files[e.File] = e.Arguments
+	}
+	ef := filepath.Join(sdk, "components/stage/easyflash4")
+	if len(files) != 4 || files[filepath.Join(ef, "src/unused.c")] != nil {
+		t.Fatalf("Entries for %v, want easyflash.c, ef_env.c, my_utils.c and main.c", files)
+	}
+	// 项目 components 目录中的组件优先
+	if files[filepath.Join(project, "components/utils/my_utils.c")] == nil {
+		t.Errorf("Expected project utils component to override SDK one")
+	}
+
+	args := strings.Join(files[filepath.Join(ef, "src/ef_env.c")], " ")
+	for _, want := range []string{
+		DefaultToolPrefix + "gcc -march=rv32imfc -mabi=ilp32f -std=gnu99",
+		"-I" + filepath.Join(ef, "port"),
+		"-I" + filepath.Join(ef, "inc"),
+		"-I" + filepath.Join(project, "demo/include"),
+		"-I" + filepath.Join(project, "components/utils"),
+		"-DEF_USING_ENV -I" + filepath.Join(ef, "extra"),
+		"-c " + filepath.Join(ef, "src/ef_env.c") + " -o " + filepath.Join(project, "build_out/easyflash4/src/ef_env.o"),
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("ef_env.c arguments missing %q:\n%s", want, args)
+		}
+	}
+	if strings.Contains(args, "-Wno-unused") || strings.Contains(args, "CONF_USER_ENABLE_VFS_ROMFS") {
+		t.Errorf("ef_env.c arguments should only contain its own defines:\n%s", args)
+	}
+	// 私有头文件目录不导出给其他组件
+	if main := strings.Join(files[filepath.Join(project, "demo/main.c")], " "); strings.Contains(main, filepath.Join(ef, "port")) || !strings.Contains(main, "-DCONF_USER_ENABLE_VFS_ROMFS") {
+		t.Errorf("main.c arguments:\n%s", main)
+	}
+}
+
+func TestFindComponentsShallowest(t *testing.T) {
+	sdk := t.TempDir()
+	writeFiles(t, sdk, map[string]string{
+		"components/a/b/c/utils/bouffalo.mk": "",
+		"components/x/utils/bouffalo.mk":     "",
+	})
+	dirs, err := FindComponents(t.TempDir(), sdk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirs["utils"] != filepath.Join(sdk, "components/x/utils") {
+		t.Errorf("utils = %q", dirs["utils"])
+	}
+	if _, err := FindComponents(t.TempDir(), t.TempDir()); err == nil {
+		t.Errorf("Expected error without components directory")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	data, err := Marshal([]Entry{
+		{Directory: "/p", Arguments: []string{"gcc", "-c", "b.c"}, File: "/p/b.c"},
+		{Directory: "/p", Arguments: []string{"gcc", "-c", "a.c"}, File: "/p/a.c", Output: "/p/a.o"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[0]["file"] != "/p/a.c" || decoded[0]["output"] != "/p/a.o" {
+		t.Errorf("Marshal() = %s", data)
+	}
+	if _, ok := decoded[1]["output"]; ok {
+		t.Errorf("Expected output to be omitted when empty: %s", data)
+	}
+	if empty, _ := Marshal(nil); strings.TrimSpace(string(empty)) != "[]" {
+		t.Errorf("Marshal(nil) = %s", empty)
+	}
+}
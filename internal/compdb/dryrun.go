@@ -0,0 +1,224 @@
+package compdb
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// enteringRe make 进入子目录时的输出
+var enteringRe = regexp.MustCompile(`^\S*make(?:\[\d+\])?: Entering directory [` + "`" + `'"](.+)['"]$`)
+
+// Capture 在项目目录中执行 make -n -B（不实际编译，输出全部编译命令），从输出中提取编译命令
+func Capture(projectDir, sdkPath string) ([]Entry, error) {
+	if _, err := exec.LookPath("make"); err != nil {
+		return nil, fmt.Errorf("找不到 make 命令，请先安装 make 和 SDK 工具链")
+	}
+	cmd := exec.Command("make", "-n", "-B", "-w")
+	cmd.Dir = projectDir
+	cmd.Env = os.Environ()
+	if sdkPath != "" {
+		cmd.Env = append(cmd.Env, "BL60X_SDK_PATH="+sdkPath)
+	}
+	out, err := cmd.CombinedOutput()
+	entries := ParseMakeOutput(string(out), projectDir)
+	if err != nil && len(entries) == 0 {
+		return nil, fmt.Errorf("make -n 失败: %v\n%s", err, tail(string(out), 20))
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("make -n 的输出中没有编译命令")
+	}
+	return entries, nil
+}
+
+// ParseMakeOutput 从 make -n -w 的输出中提取编译命令；dir 为 make 的工作目录，
+// 子 make 的 "Entering directory" 会改变之后命令的目录
+func ParseMakeOutput(output, dir string) []Entry {
+	byFile := map[string]int{}
+	var entries []Entry
+	var dirs []string
+	current := dir
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if m := enteringRe.FindStringSubmatch(line); m != nil {
+			dirs = append(dirs, current)
+			current = m[1]
+			continue
+		}
+		if strings.Contains(line, ": Leaving directory ") {
+			if len(dirs) > 0 {
+				current, dirs = dirs[len(dirs)-1], dirs[:len(dirs)-1]
+			}
+			continue
+		}
+
+		cwd := current
+		for _, command := range splitCommands(line) {
+			words := shellWords(command)
+			if len(words) >= 2 && words[0] == "cd" {
+				cwd = resolve(cwd, words[1])
+				continue
+			}
+			e, ok := compileEntry(words, cwd)
+			if !ok {
+				continue
+			}
+			if i, ok := byFile[e.File]; ok {
+				entries[i] = e
+			} else {
+				byFile[e.File] = len(entries)
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries
+}
+
+// compileEntry 判断命令是否为编译单个源文件（编译器 + -c + 源文件）
+func compileEntry(words []string, dir string) (Entry, bool) {
+	start := -1
+	for i, w := range words {
+		if isCompiler(w) {
+			start = i
+			break
+		}
+		// 跳过 VAR=value 形式的环境变量和 ccache 等包装命令
+		if !strings.Contains(w, "=") && filepath.Base(w) != "ccache" {
+			return Entry{}, false
+		}
+	}
+	if start < 0 {
+		return Entry{}, false
+	}
+	args := words[start:]
+	compile := false
+	var file, output string
+	for i := 1; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "-c":
+			compile = true
+		case a == "-o" && i+1 < len(args):
+			i++
+			output = args[i]
+		case a == "-MF" || a == "-MT" || a == "-MQ" || a == "-include" || a == "-I" || a == "-x":
+			i++
+		case !strings.HasPrefix(a, "-") && isSource(a):
+			file = a
+		}
+	}
+	if !compile || file == "" {
+		return Entry{}, false
+	}
+	e := Entry{Directory: dir, Arguments: args, File: resolve(dir, file)}
+	if output != "" {
+		e.Output = resolve(dir, output)
+	}
+	return e, true
+}
+
+// isCompiler 判断命令是否为 C/C++ 编译器
+func isCompiler(word string) bool {
+	base := strings.TrimSuffix(filepath.Base(word), ".exe")
+	for _, name := range []string{"gcc", "g++", "cc", "c++", "clang", "clang++"} {
+		if base == name || strings.HasSuffix(base, "-"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolve(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
+
+// splitCommands 按 shell 的 &&、|| 和 ; 拆分命令行（不在引号内的）
+func splitCommands(line string) []string {
+	var commands []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ';':
+			commands = append(commands, line[start:i])
+			start = i + 1
+		case (c == '&' || c == '|') && i+1 < len(line) && line[i+1] == c:
+			commands = append(commands, line[start:i])
+			start = i + 2
+			i++
+		}
+	}
+	return append(commands, line[start:])
+}
+
+// shellWords 按 shell 规则拆分单词，处理引号和反斜杠
+func shellWords(s string) []string {
+	var words []string
+	var sb strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				sb.WriteByte(c)
+			}
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else if c == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) >= 0 {
+				i++
+				sb.WriteByte(s[i])
+			} else {
+				sb.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == '\\' && i+1 < len(s):
+			i++
+			sb.WriteByte(s[i])
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, sb.String())
+				sb.Reset()
+				inWord = false
+			}
+		default:
+			sb.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, sb.String())
+	}
+	return words
+}
+
+// tail 返回最后 n 行
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,46 @@
+package compdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMakeOutput(t *testing.T) {
+	output := `****** SDK PATH [/sdk]
+make[1]: Entering directory '/p/build_out/utils'
+echo CC utils_log.c
+riscv64-unknown-elf-gcc -std=gnu99 -DUTILS="a b" -I/sdk/components/utils/include -c /sdk/components/utils/utils_log.c -o utils_log.o
+make[2]: Entering directory '/p/build_out/demo'
+mkdir -p demo && riscv64-unknown-elf-gcc -MMD -MF demo/main.d -c ../../demo/main.c -o demo/main.o
+make[2]: Leaving directory '/p/build_out/demo'
+cd /p/build_out/cpp; ccache riscv64-unknown-elf-g++ -c app.cpp -o app.o
+riscv64-unknown-elf-gcc -E -P board.dts.in -o board.dts
+riscv64-unknown-elf-ar cru libutils.a utils_log.o
+make[1]: Leaving directory '/p/build_out/utils'
+riscv64-unknown-elf-gcc -c /sdk/components/utils/utils_log.c -DSECOND -o utils_log.o
+`
+	entries := ParseMakeOutput(output, "/p")
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries: %+v", len(entries), entries)
+	}
+
+	// 同一文件只保留最后一条命令
+	if e := entries[0]; e.File != "/sdk/components/utils/utils_log.c" || e.Directory != "/p" || e.Output != "/p/utils_log.o" ||
+		!reflect.DeepEqual(e.Arguments, []string{"riscv64-unknown-elf-gcc", "-c", "/sdk/components/utils/utils_log.c", "-DSECOND", "-o", "utils_log.o"}) {
+		t.Errorf("entry 0 = %+v", e)
+	}
+	if e := entries[1]; e.File != "/p/demo/main.c" || e.Directory != "/p/build_out/demo" || e.Output != "/p/build_out/demo/demo/main.o" {
+		t.Errorf("entry 1 = %+v", e)
+	}
+	if e := entries[2]; e.File != "/p/build_out/cpp/app.cpp" || e.Directory != "/p/build_out/cpp" || e.Arguments[0] != "riscv64-unknown-elf-g++" {
+		t.Errorf("entry 2 = %+v", e)
+	}
+}
+
+func TestShellWords(t *testing.T) {
+	got := shellWords(`gcc -DA="x y" '-DB=a\b' -DC=\"q\" "-DD=\"s\""`)
+	want := []string{"gcc", "-DA=x y", `-DB=a\b`, `-DC="q"`, `-DD="s"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shellWords() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,378 @@
+package compdb
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Vars make 变量。Eval 只实现 SDK 组件 bouffalo.mk 中常见的写法：变量赋值、
+// ifeq/ifneq/ifdef/ifndef 条件、include，以及少量函数（addprefix、addsuffix、patsubst、
+// subst、strip、wildcard、notdir、dir、filter-out）；其他函数展开为空
+type Vars map[string]string
+
+// assignRe 匹配变量赋值，= 和 := 都按立即展开处理
+var assignRe = regexp.MustCompile(`^(?:(?:export|override)\s+)*([A-Za-z_][A-Za-z0-9_.]*)\s*(:=|::=|\+=|\?=|=)\s*(.*)$`)
+
+// Clone 复制变量，用于为每个组件单独求值
+func (v Vars) Clone() Vars {
+	c := make(Vars, len(v))
+	for k, val := range v {
+		c[k] = val
+	}
+	return c
+}
+
+// Eval 按顺序执行 makefile 的内容；dir 为 include 和 wildcard 中相对路径的基准目录
+func (v Vars) Eval(content, dir string) {
+	v.eval(content, dir, 0)
+}
+
+// maxIncludeDepth include 的最大嵌套层数
+const maxIncludeDepth = 8
+
+func (v Vars) eval(content, dir string, depth int) {
+	// active 为每层条件是否成立，taken 为该层是否已有分支成立（用于 else）
+	var active, taken []bool
+	enabled := func() bool {
+		for _, a := range active {
+			if !a {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, line := range logicalLines(content) {
+		if strings.HasPrefix(line, "\t") {
+			// 规则的命令
+			continue
+		}
+		line = strings.TrimSpace(line)
+		word, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+
+		switch word {
+		case "ifeq", "ifneq", "ifdef", "ifndef":
+			cond := enabled() && v.condition(word, rest)
+			active = append(active, cond)
+			taken = append(taken, cond)
+			continue
+		case "else":
+			if len(active) == 0 {
+				continue
+			}
+			n := len(active) - 1
+			parentEnabled := true
+			for _, a := range active[:n] {
+				parentEnabled = parentEnabled && a
+			}
+			cond := !taken[n]
+			if w, r, _ := strings.Cut(rest, " "); cond && w != "" {
+				// else ifeq ...
+				cond = parentEnabled && v.condition(w, strings.TrimSpace(r))
+			}
+			active[n] = cond && parentEnabled
+			taken[n] = taken[n] || active[n]
+			continue
+		case "endif":
+			if len(active) > 0 {
+				active, taken = active[:len(active)-1], taken[:len(taken)-1]
+			}
+			continue
+		}
+		if !enabled() {
+			continue
+		}
+
+		if word == "include" || word == "-include" || word == "sinclude" {
+			if depth >= maxIncludeDepth {
+				continue
+			}
+			for _, path := range strings.Fields(v.Expand(rest, dir)) {
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				if data, err := os.ReadFile(path); err == nil {
+					v.eval(string(data), dir, depth+1)
+				}
+			}
+			continue
+		}
+
+		m := assignRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name, op, value := m[1], m[2], m[3]
+		switch op {
+		case "+=":
+			value = v.Expand(value, dir)
+			if old := v[name]; old != "" && value != "" {
+				value = old + " " + value
+			} else if old != "" {
+				value = old
+			}
+			v[name] = value
+		case "?=":
+			if _, ok := v[name]; !ok {
+				v[name] = v.Expand(value, dir)
+			}
+		default:
+			v[name] = v.Expand(value, dir)
+		}
+	}
+}
+
+// condition 求值条件指令
+func (v Vars) condition(directive, args string) bool {
+	switch directive {
+	case "ifdef", "ifndef":
+		defined := strings.TrimSpace(v[strings.TrimSpace(v.Expand(args, ""))]) != ""
+		return defined == (directive == "ifdef")
+	}
+	a, b, ok := splitCondition(args)
+	if !ok {
+		return false
+	}
+	equal := strings.TrimSpace(v.Expand(a, "")) == strings.TrimSpace(v.Expand(b, ""))
+	return equal == (directive == "ifeq")
+}
+
+// splitCondition 拆分 (a,b) 或 "a" "b" 形式的比较参数
+func splitCondition(args string) (string, string, bool) {
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, "(") && strings.HasSuffix(args, ")") {
+		parts := splitArgs(args[1 : len(args)-1])
+		if len(parts) != 2 {
+			return "", "", false
+		}
+		return parts[0], parts[1], true
+	}
+	var quoted []string
+	for len(args) > 0 && len(quoted) < 2 {
+		q := args[0]
+		if q != '"' && q != '\'' {
+			return "", "", false
+		}
+		end := strings.IndexByte(args[1:], q)
+		if end < 0 {
+			return "", "", false
+		}
+		quoted = append(quoted, args[1:end+1])
+		args = strings.TrimSpace(args[end+2:])
+	}
+	if len(quoted) != 2 {
+		return "", "", false
+	}
+	return quoted[0], quoted[1], true
+}
+
+// Expand 展开 $(VAR)、${VAR} 和支持的函数
+func (v Vars) Expand(s, dir string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '$' || i+1 >= len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		next := s[i+1]
+		switch next {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '(', '{':
+			end := matchParen(s, i+1)
+			if end < 0 {
+				sb.WriteString(s[i:])
+				return sb.String()
+			}
+			sb.WriteString(v.reference(s[i+2:end], dir))
+			i = end
+		default:
+			// $X 单字符变量
+			sb.WriteString(v[string(next)])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// matchParen 返回与 s[open] 匹配的右括号位置
+func matchParen(s string, open int) int {
+	closer := byte(')')
+	if s[open] == '{' {
+		closer = '}'
+	}
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case s[open]:
+			depth++
+		case closer:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// reference 展开 $(...) 中的内容：变量引用、替换引用或函数调用
+func (v Vars) reference(ref, dir string) string {
+	name, args, isCall := strings.Cut(ref, " ")
+	if !isCall {
+		// $(VAR:.c=.o)
+		if varName, subst, ok := strings.Cut(ref, ":"); ok {
+			if from, to, ok := strings.Cut(subst, "="); ok {
+				if !strings.Contains(from, "%") {
+					from, to = "%"+from, "%"+to
+				}
+				return patsubst(from, to, v[v.Expand(varName, dir)])
+			}
+		}
+		return v[v.Expand(ref, dir)]
+	}
+
+	parts := splitArgs(args)
+	arg := func(i int) string {
+		if i < len(parts) {
+			return v.Expand(parts[i], dir)
+		}
+		return ""
+	}
+	switch name {
+	case "addprefix", "addsuffix":
+		var out []string
+		for _, w := range strings.Fields(arg(1)) {
+			if name == "addprefix" {
+				out = append(out, arg(0)+w)
+			} else {
+				out = append(out, w+arg(0))
+			}
+		}
+		return strings.Join(out, " ")
+	case "patsubst":
+		return patsubst(strings.TrimSpace(arg(0)), strings.TrimSpace(arg(1)), arg(2))
+	case "subst":
+		return strings.ReplaceAll(arg(2), arg(0), arg(1))
+	case "strip":
+		return strings.Join(strings.Fields(arg(0)), " ")
+	case "notdir", "dir":
+		var out []string
+		for _, w := range strings.Fields(arg(0)) {
+			i := strings.LastIndex(w, "/")
+			if name == "notdir" {
+				out = append(out, w[i+1:])
+			} else if i < 0 {
+				out = append(out, "./")
+			} else {
+				out = append(out, w[:i+1])
+			}
+		}
+		return strings.Join(out, " ")
+	case "filter-out":
+		exclude := map[string]bool{}
+		for _, w := range strings.Fields(arg(0)) {
+			exclude[w] = true
+		}
+		var out []string
+		for _, w := range strings.Fields(arg(1)) {
+			if !exclude[w] {
+				out = append(out, w)
+			}
+		}
+		return strings.Join(out, " ")
+	case "wildcard":
+		var out []string
+		for _, pattern := range strings.Fields(arg(0)) {
+			abs := pattern
+			if !filepath.IsAbs(pattern) {
+				abs = filepath.Join(dir, pattern)
+			}
+			matches, _ := filepath.Glob(abs)
+			sort.Strings(matches)
+			for _, match := range matches {
+				if !filepath.IsAbs(pattern) {
+					match, _ = filepath.Rel(dir, match)
+				}
+				out = append(out, filepath.ToSlash(match))
+			}
+		}
+		return strings.Join(out, " ")
+	}
+	return ""
+}
+
+// splitArgs 按顶层的逗号拆分函数参数
+func splitArgs(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '{':
+			depth++
+		case ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// patsubst 实现 $(patsubst pattern,replacement,text)
+func patsubst(pattern, replacement, text string) string {
+	prefix, suffix, wild := strings.Cut(pattern, "%")
+	var out []string
+	for _, w := range strings.Fields(text) {
+		switch {
+		case !wild:
+			if w == pattern {
+				w = replacement
+			}
+		case len(w) >= len(prefix)+len(suffix) && strings.HasPrefix(w, prefix) && strings.HasSuffix(w, suffix):
+			stem := w[len(prefix) : len(w)-len(suffix)]
+			w = strings.Replace(replacement, "%", stem, 1)
+		}
+		out = append(out, w)
+	}
+	return strings.Join(out, " ")
+}
+
+// logicalLines 合并续行并去掉注释和空行，保留行首的 tab（规则的命令）
+func logicalLines(content string) []string {
+	var lines []string
+	var current strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if current.Len() > 0 {
+			// 续行与上一行之间只保留一个空格
+			line = strings.TrimLeft(line, " \t")
+		}
+		if idx := strings.Index(line, "#"); idx >= 0 && !strings.HasPrefix(line, "\t") {
+			line = strings.TrimRight(line[:idx], " \t")
+		}
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimRight(strings.TrimSuffix(line, "\\"), " \t"))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(line)
+		if joined := current.String(); strings.TrimSpace(joined) != "" {
+			lines = append(lines, joined)
+		}
+		current.Reset()
+	}
+	if joined := current.String(); strings.TrimSpace(joined) != "" {
+		lines = append(lines, joined)
+	}
+	return lines
+}
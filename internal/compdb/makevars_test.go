@@ -0,0 +1,133 @@
+package compdb
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVarsEval(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	for _, name := range []string{"a.c", "b.c", "b.h"} {
+		os.WriteFile(filepath.Join(dir, "src", name), nil, 0644)
+	}
+	os.WriteFile(filepath.Join(dir, "common.mk"), []byte("CPPFLAGS += -DFROM_INCLUDE\n"), 0644)
+
+	vars := Vars{"CONFIG_A": "1", "CONFIG_B": "0", "CPPFLAGS": ""}
+	vars.Eval(`
+# 注释
+COMPONENT_ADD_INCLUDEDIRS += include \
+                             port
+COMPONENT_SRCS := $(wildcard src/*.c)
+COMPONENT_OBJS := $(patsubst %.c,%.o, $(COMPONENT_SRCS))
+NAMES := $(notdir $(COMPONENT_SRCS:.c=.o))
+LIST = $(addprefix lib/,x.c y.c)
+UNSET ?= default
+CONFIG_A ?= 2
+
+ifeq ($(CONFIG_A),1)
+CPPFLAGS += -DA
+ifneq ($(CONFIG_B),1)
+CPPFLAGS += -DNOT_B
+else
+CPPFLAGS += -DB
+endif
+else ifeq ($(CONFIG_A),2)
+CPPFLAGS += -DA2
+else
+CPPFLAGS += -DNO_A
+endif
+ifdef CONFIG_MISSING
+CPPFLAGS += -DMISSING
+endif
+ifndef CONFIG_MISSING
+CPPFLAGS += -DNOT_MISSING
+endif
+ifeq "$(CONFIG_B)" "0"
+CPPFLAGS += -DQUOTED
+endif
+include $(COMPONENT_PATH)common.mk
+
+all:
+	CPPFLAGS += -DRECIPE
+`, dir)
+
+	want := map[string]string{
+		"COMPONENT_ADD_INCLUDEDIRS": "include port",
+		"COMPONENT_SRCS":            "src/a.c src/b.c",
+		"COMPONENT_OBJS":            "src/a.o src/b.o",
+		"NAMES":                     "a.o b.o",
+		"LIST":                      "lib/x.c lib/y.c",
+		"UNSET":                     "default",
+		"CONFIG_A":                  "1",
+		"CPPFLAGS":                  "-DA -DNOT_B -DNOT_MISSING -DQUOTED -DFROM_INCLUDE",
+	}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("%s = %q, want %q", k, vars[k], v)
+		}
+	}
+}
+
+func TestVarsElseIf(t *testing.T) {
+	vars := Vars{"CONFIG_A": "2"}
+	vars.Eval("ifeq ($(CONFIG_A),1)\nX := one\nelse ifeq ($(CONFIG_A),2)\nX := two\nelse\nX := other\nendif\n", "")
+	if vars["X"] != "two" {
+		t.Errorf("X = %q, want two", vars["X"])
+	}
+}
+
+func TestProjectFlags(t *testing.T) {
+	sdk := t.TempDir()
+	project := t.TempDir()
+	writeFiles(t, sdk, map[string]string{
+		"make_scripts_riscv/project.mk": `CPPFLAGS := -DPROJECT_NAME=$(PROJECT_NAME) $(CPPFLAGS)
+ifeq ($(CONFIG_SYS_VFS_ENABLE),1)
+CPPFLAGS += -DSYS_VFS_ENABLE
+endif
+ifeq ($(CONFIG_SYS_BLE_ENABLE),1)
+CPPFLAGS += -DCFG_BLE_ENABLE
+endif
+include $(BL60X_SDK_PATH)/make_scripts_riscv/common.mk
+CFLAGS += -Os -ffunction-sections -Iinclude
+`,
+		"make_scripts_riscv/common.mk":            "CPPFLAGS += -DARCH_RISCV\n",
+		"make_scripts_riscv/component_wrapper.mk": "CPPFLAGS += -DBL_SDK_VER=$(CONFIG_SDK_VER)\n",
+		"components/utils/bouffalo.mk":            "CPPFLAGS += -DUTILS\n",
+		"components/utils/utils_log.c":            "",
+	})
+	writeFiles(t, project, map[string]string{"demo/main.c": ""})
+
+	result, err := Derive(Options{
+		ProjectDir:  project,
+		ProjectName: "demo",
+		SDKPath:     sdk,
+		Components:  []string{"utils"},
+		Config:      map[string]string{"CONFIG_SYS_VFS_ENABLE": "1", "CONFIG_SYS_BLE_ENABLE": "0", "CONFIG_SDK_VER": "1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// CPPFLAGS 在 CFLAGS 之前
+	want := []string{"-DPROJECT_NAME=demo", "-DSYS_VFS_ENABLE", "-DARCH_RISCV", "-DBL_SDK_VER=1.0", "-I" + filepath.Join(project, "include")}
+	if !reflect.DeepEqual(result.ProjectFlags, want) {
+		t.Errorf("ProjectFlags = %v, want %v", result.ProjectFlags, want)
+	}
+
+	// 项目级的选项用于所有组件，在组件自己的选项之前
+	if len(result.Entries) != 2 {
+		t.Fatalf("Expected entries for utils_log.c and main.c, got %v", result.Entries)
+	}
+	for _, e := range result.Entries {
+		args := strings.Join(e.Arguments, " ")
+		if !strings.Contains(args, strings.Join(want, " ")) {
+			t.Errorf("%s arguments missing project flags:\n%s", e.File, args)
+		}
+		if strings.HasSuffix(e.File, "utils_log.c") && !strings.HasSuffix(args, strings.Join(want, " ")+" -DUTILS -c "+e.File+" -o "+filepath.Join(project, "build_out/utils/utils_log.o")) {
+			t.Errorf("utils_log.c arguments:\n%s", args)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Board 模组/开发板的硬件信息
+type Board struct {
+	Name        string `yaml:"name"`
+	Module      string `yaml:"module"`
+	Description string `yaml:"description,omitempty"`
+	// 未指定 --board 时使用的模组
+	Default bool `yaml:"default,omitempty"`
+	// Makefile 中的 PROJECT_BOARD
+	SDKBoard string `yaml:"sdk_board"`
+	// Flash 容量（MB）
+	FlashSize int `yaml:"flash_size"`
+	// 晶振频率（MHz）
+	Crystal int `yaml:"crystal"`
+	// 可供应用使用的 GPIO
+	Pins []int `yaml:"pins"`
+	// 板载 LED 和按键，没有时为 nil
+	LEDPin    *int `yaml:"led_pin,omitempty"`
+	ButtonPin *int `yaml:"button_pin,omitempty"`
+	// UART0 引脚
+	UARTTxPin int `yaml:"uart_tx_pin"`
+	UARTRxPin int `yaml:"uart_rx_pin"`
+	// 按 GPIO 覆盖芯片默认的复用功能（见 ChipPinFunctions）
+	PinFunctions map[int][]string `yaml:"pin_functions,omitempty"`
+}
+
+// BoardsConfig 模组目录文件结构
+type BoardsConfig struct {
+	Boards []Board `yaml:"boards"`
+}
+
+// LoadBoards 从 assets/boards.yaml 加载模组目录
+func LoadBoards() ([]Board, error) {
+	boardsPath, err := findAsset("boards.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("找不到模组配置文件 boards.yaml，请确保文件存在于 assets/ 目录下")
+	}
+
+	data, err := os.ReadFile(boardsPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取模组配置文件失败: %v", err)
+	}
+
+	var config BoardsConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析模组配置文件失败: %v", err)
+	}
+
+	for _, board := range config.Boards {
+		if board.Name == "" || board.FlashSize <= 0 || len(board.Pins) < 2 {
+			return nil, fmt.Errorf("模组配置无效: %q 需要 name、flash_size 和至少 2 个 pins", board.Name)
+		}
+		for pin, functions := range board.PinFunctions {
+			if !board.HasPin(pin) {
+				return nil, fmt.Errorf("模组配置无效: %q 的 pin_functions 包含未引出的 IO%d", board.Name, pin)
+			}
+			for _, function := range functions {
+				if !IsPinFunction(function) {
+					return nil, fmt.Errorf("模组配置无效: %q 的 IO%d 使用了未知的引脚功能 %q", board.Name, pin, function)
+				}
+			}
+		}
+	}
+
+	return config.Boards, nil
+}
+
+// FindBoard 按名称或模组型号查找模组（忽略大小写）；name 为空时返回默认模组
+func FindBoard(boards []Board, name string) (*Board, bool) {
+	for i := range boards {
+		board := &boards[i]
+		if name == "" && board.Default {
+			return board, true
+		}
+		if name != "" && (strings.EqualFold(board.Name, name) || strings.EqualFold(board.Module, name)) {
+			return board, true
+		}
+	}
+	return nil, false
+}
+
+// HasPin 判断模组是否引出了该 GPIO
+func (b *Board) HasPin(pin int) bool {
+	for _, p := range b.Pins {
+		if p == pin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeBoards 在临时目录的 assets/ 中写入 boards.yaml 并切换到该目录
+func writeBoards(t *testing.T, content string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	assetsDir := filepath.Join(tempDir, "assets")
+	if err := os.MkdirAll(assetsDir, 0755); err != nil {
+		t.Fatalf("Failed to create assets directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(assetsDir, "boards.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write boards.yaml: %v", err)
+	}
+
+	oldWd, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(oldWd) })
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+}
+
+func TestLoadBoards(t *testing.T) {
+	writeBoards(t, `boards:
+  - name: ai-wb2-12f
+    module: Ai-WB2-12F
+    default: true
+    sdk_board: evb
+    flash_size: 2
+    crystal: 40
+    pins: [3, 8, 14]
+    led_pin: 14
+    button_pin: 0
+  - name: ai-wb2-32s
+    module: Ai-WB2-32S
+    sdk_board: evb
+    flash_size: 4
+    pins: [5, 8]`)
+
+	boards, err := LoadBoards()
+	if err != nil {
+		t.Fatalf("LoadBoards failed: %v", err)
+	}
+	if len(boards) != 2 {
+		t.Fatalf("Expected 2 boards, got %d", len(boards))
+	}
+
+	board, ok := FindBoard(boards, "")
+	if !ok || board.Name != "ai-wb2-12f" {
+		t.Fatalf("Expected default board ai-wb2-12f, got %+v", board)
+	}
+	if board.LEDPin == nil || *board.LEDPin != 14 {
+		t.Errorf("Expected LED pin 14, got %v", board.LEDPin)
+	}
+	// GPIO0 也是有效的按键引脚
+	if board.ButtonPin == nil || *board.ButtonPin != 0 {
+		t.Errorf("Expected button pin 0, got %v", board.ButtonPin)
+	}
+	if !board.HasPin(8) || board.HasPin(9) {
+		t.Error("Unexpected HasPin result")
+	}
+
+	// 按模组型号查找，忽略大小写
+	board, ok = FindBoard(boards, "AI-WB2-32S")
+	if !ok || board.FlashSize != 4 || board.LEDPin != nil {
+		t.Errorf("Expected Ai-WB2-32S without LED, got %+v", board)
+	}
+
+	if _, ok := FindBoard(boards, "esp32"); ok {
+		t.Error("Expected unknown board not to be found")
+	}
+}
+
+func TestLoadBoardsInvalid(t *testing.T) {
+	writeBoards(t, `boards:
+  - name: broken
+    module: Broken
+    pins: [1, 2]`)
+
+	_, err := LoadBoards()
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected invalid board error, got %v", err)
+	}
+}
+
+func TestBoardPinFunctions(t *testing.T) {
+	writeBoards(t, `boards:
+  - name: custom
+    module: Custom
+    sdk_board: evb
+    flash_size: 2
+    pins: [3, 4, 12]
+    pin_functions:
+      12: [gpio]`)
+
+	boards, err := LoadBoards()
+	if err != nil {
+		t.Fatalf("LoadBoards failed: %v", err)
+	}
+	board := &boards[0]
+
+	// 芯片默认复用功能
+	if !board.Supports(3, PinSPISCLK) || board.Supports(3, PinI2CSCL) || !board.Supports(3, PinI2CSDA) {
+		t.Errorf("Unexpected functions for IO3: %v", board.Functions(3))
+	}
+	if !board.Supports(4, PinADC) || !board.Supports(4, PinSPIMISO) {
+		t.Errorf("Unexpected functions for IO4: %v", board.Functions(4))
+	}
+	// 模组覆盖
+	if board.Supports(12, PinADC) || !board.Supports(12, PinGPIO) {
+		t.Errorf("Expected IO12 to be gpio only, got %v", board.Functions(12))
+	}
+	// 未引出的 GPIO
+	if board.Functions(5) != nil {
+		t.Errorf("Expected no functions for IO5, got %v", board.Functions(5))
+	}
+}
+
+func TestLoadBoardsInvalidPinFunctions(t *testing.T) {
+	writeBoards(t, `boards:
+  - name: custom
+    module: Custom
+    flash_size: 2
+    pins: [3, 4]
+    pin_functions:
+      3: [jtag]`)
+
+	_, err := LoadBoards()
+	if err == nil || !strings.Contains(err.Error(), "jtag") {
+		t.Errorf("Expected unknown function error, got %v", err)
+	}
+}
@@ -14,6 +14,8 @@ type Component struct {
 	Description  string   `yaml:"description"`
 	Category     string   `yaml:"category,omitempty"` // 组件分类（如：network, peripheral, 3rdparty 等）
 	Dependencies []string `yaml:"dependencies,omitempty"`
+	// 不能与本组件同时使用的组件
+	Conflicts []string `yaml:"conflicts,omitempty"`
 	// 组件在 SDK 中的路径（用于 Makefile）
 	SDKComponents []string `yaml:"sdk_components,omitempty"`
 	// 需要添加到 INCLUDE_COMPONENTS 的组件
@@ -30,56 +32,119 @@ type Component struct {
 	ConfigFlags map[string]string `yaml:"config_flags,omitempty"`
 	// 模板文件路径（相对于 templates/components/）
 	TemplateFiles []string `yaml:"template_files,omitempty"`
+	// 组件需要的引脚，由生成器分配到模组的 GPIO
+	Pins []PinRequest `yaml:"pins,omitempty"`
+	// 组件选项及默认值（如 uart 的 baudrate），可用 --option 组件.选项=值 覆盖
+	Options map[string]string `yaml:"options,omitempty"`
+	// 组件需要在 Flash 中保留的分区
+	Partitions []PartitionRequest `yaml:"partitions,omitempty"`
+}
+
+// PartitionRequest 组件对 Flash 分区的需求
+type PartitionRequest struct {
+	// 分区名称；FW 表示 OTA 的第二个固件槽
+	Name string `yaml:"name"`
+	// 分区类型（见 SDK 的 partition_cfg_*.toml）
+	Type int `yaml:"type,omitempty"`
+	// 分区大小，如 348K、1M 或 0x57000
+	Size string `yaml:"size"`
+}
+
+// PinRequest 组件对一个引脚功能的需求
+type PinRequest struct {
+	// 引脚名称，生成 main_board.h 中的 <NAME>_PIN 宏
+	Name string `yaml:"name"`
+	// 引脚功能（见 PinFunctions）
+	Function string `yaml:"function"`
+	// 优先使用的 GPIO，模组没有引出或已被占用时自动分配
+	Default *int `yaml:"default,omitempty"`
+	// 优先使用模组的板载器件：led 或 button
+	Board string `yaml:"board,omitempty"`
+}
+
+// Preset 预设：一组常用的组件、组件选项、引脚和命令行选项
+type Preset struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Components  []string `yaml:"components"`
+	// 命令行选项（不含 --），仅在命令行未显式指定时生效
+	Options map[string]string `yaml:"options,omitempty"`
+	// 组件选项（组件.选项 -> 值，同 --option），命令行指定的同名选项优先
+	ComponentOptions map[string]string `yaml:"component_options,omitempty"`
+	// 引脚（名称 -> GPIO，同 --pin），命令行指定的同名引脚优先
+	Pins map[string]string `yaml:"pins,omitempty"`
 }
 
 // ComponentsConfig 组件配置文件结构
 type ComponentsConfig struct {
 	Components []Component `yaml:"components"`
+	Presets    []Preset    `yaml:"presets,omitempty"`
 }
 
 // UserConfig 用户配置文件结构
 type UserConfig struct {
-	SDKPath string `yaml:"sdk_path"`
+	SDKPath string   `yaml:"sdk_path"`
+	Presets []Preset `yaml:"presets,omitempty"`
+	// 烧录使用的串口和波特率
+	Port string `yaml:"port,omitempty"`
+	Baud int    `yaml:"baud,omitempty"`
 }
 
 // LoadComponents 从 assets/components.yaml 加载组件配置
 func LoadComponents() ([]Component, error) {
-	// 尝试从多个位置查找配置文件
-	var configPath string
-	var found bool
-
-	// 1. 尝试从可执行文件所在目录（安装模式）
-	exePath, err := os.Executable()
-	if err == nil {
-		exeDir := filepath.Dir(exePath)
-		path := filepath.Join(exeDir, "assets", "components.yaml")
-		if _, err := os.Stat(path); err == nil {
-			configPath = path
-			found = true
-		}
+	catalog, err := LoadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Components, nil
+}
+
+// LoadPresets 加载组件目录和用户配置中的预设，用户预设覆盖同名的目录预设
+func LoadPresets() ([]Preset, error) {
+	catalog, err := LoadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
 	}
+	return MergePresets(catalog.Presets, cfg.Presets), nil
+}
 
-	// 2. 尝试从当前工作目录（开发模式）
-	if !found {
-		cwd, err := os.Getwd()
-		if err == nil {
-			// 尝试直接在 cwd
-			path := filepath.Join(cwd, "assets", "components.yaml")
-			if _, err := os.Stat(path); err == nil {
-				configPath = path
-				found = true
-			} else {
-				// 尝试在 wb2-cli 子目录
-				path = filepath.Join(cwd, "wb2-cli", "assets", "components.yaml")
-				if _, err := os.Stat(path); err == nil {
-					configPath = path
-					found = true
-				}
+// MergePresets 合并预设：overrides 中的同名预设替换 base 中的预设，其余追加在后
+func MergePresets(base, overrides []Preset) []Preset {
+	merged := append([]Preset{}, base...)
+	for _, preset := range overrides {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == preset.Name {
+				merged[i] = preset
+				replaced = true
+				break
 			}
 		}
+		if !replaced {
+			merged = append(merged, preset)
+		}
+	}
+	return merged
+}
+
+// FindPreset 按名称查找预设
+func FindPreset(presets []Preset, name string) (*Preset, bool) {
+	for i := range presets {
+		if presets[i].Name == name {
+			return &presets[i], true
+		}
 	}
+	return nil, false
+}
 
-	if !found {
+// LoadCatalog 从 assets/components.yaml 加载组件目录（组件和预设）
+func LoadCatalog() (*ComponentsConfig, error) {
+	configPath, err := findAsset("components.yaml")
+	if err != nil {
 		return nil, fmt.Errorf("找不到组件配置文件 components.yaml，请确保文件存在于 assets/ 目录下")
 	}
 
@@ -93,7 +158,28 @@ func LoadComponents() ([]Component, error) {
 		return nil, fmt.Errorf("解析组件配置文件失败: %v", err)
 	}
 
-	return config.Components, nil
+	return &config, nil
+}
+
+// findAsset 查找 assets/ 目录中的文件：先找可执行文件所在目录（安装模式），
+// 再找当前工作目录及其 wb2-cli 子目录（开发模式）
+func findAsset(name string) (string, error) {
+	var candidates []string
+	if exePath, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exePath), "assets", name))
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		candidates = append(candidates,
+			filepath.Join(cwd, "assets", name),
+			filepath.Join(cwd, "wb2-cli", "assets", name))
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("找不到 %s", name)
 }
 
 // LoadConfig 加载用户配置文件
@@ -215,4 +215,65 @@ func TestLoadConfigFileNotFound(t *testing.T) {
 	if config.SDKPath != "" {
 		t.Errorf("Expected empty SDK path for missing config, got '%s'", config.SDKPath)
 	}
-}
\ No newline at end of file
+}
+func TestLoadPresets(t *testing.T) {
+	tempDir := t.TempDir()
+	assetsDir := filepath.Join(tempDir, "assets")
+	if err := os.MkdirAll(assetsDir, 0755); err != nil {
+		t.Fatalf("Failed to create assets directory: %v", err)
+	}
+	catalog := `components:
+  - name: wifi
+    description: WiFi
+presets:
+  - name: gateway
+    description: 目录中的网关
+    components: [wifi]
+  - name: beacon
+    components: [ble]`
+	if err := os.WriteFile(filepath.Join(assetsDir, "components.yaml"), []byte(catalog), 0644); err != nil {
+		t.Fatalf("Failed to write catalog: %v", err)
+	}
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	userConfig := &UserConfig{Presets: []Preset{
+		{Name: "gateway", Description: "用户的网关", Components: []string{"wifi", "mqtt"}, Options: map[string]string{"sdk-path-mode": "env"}},
+		{Name: "mine", Components: []string{"gpio"}},
+	}}
+	if err := SaveConfig(userConfig); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	presets, err := LoadPresets()
+	if err != nil {
+		t.Fatalf("LoadPresets failed: %v", err)
+	}
+
+	var names []string
+	for _, p := range presets {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "gateway,beacon,mine" {
+		t.Errorf("Expected presets gateway,beacon,mine, got %v", names)
+	}
+
+	// 用户预设覆盖同名的目录预设
+	gateway, ok := FindPreset(presets, "gateway")
+	if !ok {
+		t.Fatal("Expected to find preset gateway")
+	}
+	if gateway.Description != "用户的网关" || gateway.Options["sdk-path-mode"] != "env" {
+		t.Errorf("Expected user preset to override catalog preset, got %+v", gateway)
+	}
+
+	if _, ok := FindPreset(presets, "missing"); ok {
+		t.Error("Expected missing preset not to be found")
+	}
+}
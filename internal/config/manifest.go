@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile 项目清单文件名（位于项目根目录）
+const ManifestFile = "wb2.yaml"
+
+// ExtraComponents 无法映射到组件目录的 SDK 组件，按 Makefile 中的列表分组保存
+type ExtraComponents struct {
+	Include []string `yaml:"include,omitempty"`
+	Network []string `yaml:"network,omitempty"`
+	BLSys   []string `yaml:"blsys,omitempty"`
+	VFS     []string `yaml:"vfs,omitempty"`
+	MQTT    []string `yaml:"mqtt,omitempty"`
+}
+
+// IsEmpty 判断是否没有任何额外组件
+func (e ExtraComponents) IsEmpty() bool {
+	return len(e.Include) == 0 && len(e.Network) == 0 && len(e.BLSys) == 0 &&
+		len(e.VFS) == 0 && len(e.MQTT) == 0
+}
+
+// Manifest 项目清单，记录项目使用的 SDK 和组件
+type Manifest struct {
+	Name string `yaml:"name"`
+	// SDK 路径，相对路径相对于项目根目录；为空时使用环境变量 BL60X_SDK_PATH
+	SDKPath     string `yaml:"sdk_path,omitempty"`
+	SDKPathMode string `yaml:"sdk_path_mode,omitempty"`
+	// 模组名称（assets/boards.yaml）
+	Board string `yaml:"board,omitempty"`
+	// 组件目录中的组件名称
+	Components []string `yaml:"components"`
+	// 引脚分配（引脚名称 -> GPIO）
+	Pins map[string]int `yaml:"pins,omitempty"`
+	// 组件选项（组件.选项 -> 值）
+	Options map[string]string `yaml:"options,omitempty"`
+	// 无法映射到组件目录的 SDK 组件
+	Extras ExtraComponents `yaml:"extras,omitempty"`
+	// 覆盖模板默认值的 proj_config.mk 配置项
+	ConfigFlags map[string]string `yaml:"config_flags,omitempty"`
+	// wb2-cli size 检查的大小预算
+	SizeBudget SizeBudget `yaml:"size_budget,omitempty"`
+}
+
+// SizeBudget 固件大小预算，大小格式同分区表（如 1M、96K），为空表示不限制
+type SizeBudget struct {
+	Flash   string `yaml:"flash,omitempty"`
+	RAM     string `yaml:"ram,omitempty"`
+	MinHeap string `yaml:"min_heap,omitempty"`
+}
+
+// LoadManifest 从项目目录加载项目清单
+func LoadManifest(projectDir string) (*Manifest, error) {
+	manifestPath := filepath.Join(projectDir, ManifestFile)
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("找不到项目清单 %s，请在项目根目录中运行", manifestPath)
+		}
+		return nil, fmt.Errorf("读取项目清单失败: %v", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析项目清单失败: %v", err)
+	}
+
+	return &manifest, nil
+}
+
+// MarshalManifest 序列化项目清单
+func MarshalManifest(manifest *Manifest) ([]byte, error) {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("序列化项目清单失败: %v", err)
+	}
+	return append([]byte("# wb2-cli 项目清单\n"), data...), nil
+}
+
+// SaveManifest 将项目清单保存到项目目录
+func SaveManifest(projectDir string, manifest *Manifest) error {
+	data, err := MarshalManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(projectDir, ManifestFile), data, 0644); err != nil {
+		return fmt.Errorf("写入项目清单失败: %v", err)
+	}
+
+	return nil
+}
+
+// ResolveSDKPath 返回项目清单中 SDK 的绝对路径，相对路径以项目目录为基准
+func (m *Manifest) ResolveSDKPath(projectDir string) string {
+	if m.SDKPath == "" || filepath.IsAbs(m.SDKPath) {
+		return m.SDKPath
+	}
+	return filepath.Join(projectDir, filepath.FromSlash(m.SDKPath))
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	manifest := &Manifest{
+		Name:        "demo",
+		SDKPath:     "../sdk",
+		SDKPathMode: "relative",
+		Components:  []string{"mqtt", "wifi"},
+		Extras:      ExtraComponents{Include: []string{"my_driver"}},
+		ConfigFlags: map[string]string{"CONFIG_CUSTOM": "1"},
+	}
+
+	if err := SaveManifest(dir, manifest); err != nil {
+		t.Fatalf("SaveManifest failed: %v", err)
+	}
+
+	loaded, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded, manifest) {
+		t.Errorf("Expected %+v, got %+v", manifest, loaded)
+	}
+
+	if got := loaded.ResolveSDKPath(dir); got != filepath.Join(dir, "..", "sdk") {
+		t.Errorf("Unexpected resolved SDK path: %s", got)
+	}
+}
+
+func TestLoadManifestNotFound(t *testing.T) {
+	_, err := LoadManifest(t.TempDir())
+	if err == nil {
+		t.Fatal("Expected error when manifest doesn't exist")
+	}
+	if !strings.Contains(err.Error(), "找不到项目清单") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMarshalManifestOmitsEmptyExtras(t *testing.T) {
+	data, err := MarshalManifest(&Manifest{Name: "demo", Components: []string{}})
+	if err != nil {
+		t.Fatalf("MarshalManifest failed: %v", err)
+	}
+	if strings.Contains(string(data), "extras") {
+		t.Errorf("Expected empty extras to be omitted, got:\n%s", data)
+	}
+}
+
+func TestExtraComponentsIsEmpty(t *testing.T) {
+	if !(ExtraComponents{}).IsEmpty() {
+		t.Error("Expected zero value to be empty")
+	}
+	if (ExtraComponents{VFS: []string{"x"}}).IsEmpty() {
+		t.Error("Expected non-empty extras")
+	}
+}
+
+func TestResolveSDKPathAbsolute(t *testing.T) {
+	m := &Manifest{SDKPath: "/opt/sdk"}
+	if got := m.ResolveSDKPath("/work/demo"); got != "/opt/sdk" {
+		t.Errorf("Expected /opt/sdk, got %s", got)
+	}
+	m.SDKPath = ""
+	if got := m.ResolveSDKPath("/work/demo"); got != "" {
+		t.Errorf("Expected empty path, got %s", got)
+	}
+}
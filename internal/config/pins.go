@@ -0,0 +1,99 @@
+package config
+
+// 引脚功能
+const (
+	PinGPIO    = "gpio"
+	PinUARTTx  = "uart_tx"
+	PinUARTRx  = "uart_rx"
+	PinSPISCLK = "spi_sclk"
+	PinSPIMOSI = "spi_mosi"
+	PinSPIMISO = "spi_miso"
+	PinSPISS   = "spi_ss"
+	PinI2CSCL  = "i2c_scl"
+	PinI2CSDA  = "i2c_sda"
+	PinPWM     = "pwm"
+	PinADC     = "adc"
+)
+
+// PinFunctions 所有引脚功能
+var PinFunctions = []string{
+	PinGPIO, PinUARTTx, PinUARTRx, PinSPISCLK, PinSPIMOSI, PinSPIMISO, PinSPISS,
+	PinI2CSCL, PinI2CSDA, PinPWM, PinADC,
+}
+
+// bl602ADCPins 带 ADC 通道的 GPIO
+var bl602ADCPins = map[int]bool{4: true, 5: true, 6: true, 9: true, 10: true, 11: true, 12: true, 13: true, 14: true, 15: true}
+
+// IsPinFunction 判断是否为已知的引脚功能
+func IsPinFunction(function string) bool {
+	for _, f := range PinFunctions {
+		if f == function {
+			return true
+		}
+	}
+	return false
+}
+
+// UARTSignals BL602 的 UART 信号数量
+const UARTSignals = 8
+
+// UARTSignal 返回 GPIO 使用的 UART 信号（编号模 8）
+//
+// 每个 UART 信号只能映射为一个 UART 功能（如 UART0_TX），编号模 8 相同的 GPIO
+// 共用同一个信号，不能同时用作不同的 UART 引脚。
+func UARTSignal(pin int) int {
+	return pin % UARTSignals
+}
+
+// IsUARTFunction 判断功能是否占用 UART 信号
+func IsUARTFunction(function string) bool {
+	return function == PinUARTTx || function == PinUARTRx
+}
+
+// ChipPinFunctions 返回 BL602 上该 GPIO 可复用的功能
+//
+// UART 功能可用于任意 GPIO，但编号模 8 相同的 GPIO 共用 UART 信号（见 UARTSignal）；
+// SPI 按 GPIO 编号模 4 固定为 MISO/MOSI（可互换）、SS、SCLK；I2C 偶数为 SCL、
+// 奇数为 SDA；PWM 通道为编号模 5；ADC 只在部分 GPIO 上。
+func ChipPinFunctions(pin int) []string {
+	functions := []string{PinGPIO, PinUARTTx, PinUARTRx}
+	switch pin % 4 {
+	case 0, 1:
+		functions = append(functions, PinSPIMISO, PinSPIMOSI)
+	case 2:
+		functions = append(functions, PinSPISS)
+	case 3:
+		functions = append(functions, PinSPISCLK)
+	}
+	if pin%2 == 0 {
+		functions = append(functions, PinI2CSCL)
+	} else {
+		functions = append(functions, PinI2CSDA)
+	}
+	functions = append(functions, PinPWM)
+	if bl602ADCPins[pin] {
+		functions = append(functions, PinADC)
+	}
+	return functions
+}
+
+// Functions 返回模组上该 GPIO 可用的功能；模组没有引出该 GPIO 时返回 nil
+func (b *Board) Functions(pin int) []string {
+	if !b.HasPin(pin) {
+		return nil
+	}
+	if functions, ok := b.PinFunctions[pin]; ok {
+		return functions
+	}
+	return ChipPinFunctions(pin)
+}
+
+// Supports 判断模组上该 GPIO 能否用作 function
+func (b *Board) Supports(pin int, function string) bool {
+	for _, f := range b.Functions(pin) {
+		if f == function {
+			return true
+		}
+	}
+	return false
+}
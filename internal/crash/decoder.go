@@ -0,0 +1,288 @@
+package crash
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SDK 异常处理（bl_irq.c）和回溯（utils_backtrace.c）的输出，例如:
+//
+//	Exception Entry--->>>
+//	mcause 30000007, mepc 23008a2c, mtval 00000000
+//	Exception code: 7
+//	  msg: Store/AMO access fault
+//	=== backtrace start ===
+//	backtrace: 0x23008a2c
+//	=== backtrace end ===
+var (
+	mcauseLine    = regexp.MustCompile(`mcause\s+(?:0x)?([0-9a-fA-F]+),\s*mepc\s+(?:0x)?([0-9a-fA-F]+),\s*mtval\s+(?:0x)?([0-9a-fA-F]+)`)
+	backtraceLine = regexp.MustCompile(`backtrace:\s*(?:0x)?([0-9a-fA-F]{8})\b`)
+	// hexWord 崩溃信息中其他可能是地址的数，例如寄存器转储
+	hexWord = regexp.MustCompile(`\b(?:0x)?([0-9a-fA-F]{8})\b`)
+	// blogLine 正常的 blog 日志行，出现时崩溃信息已结束
+	blogLine = regexp.MustCompile(`^\[\s*\d+\]\[`)
+)
+
+// crashStarts 崩溃信息开始的标志
+var crashStarts = []string{
+	"Exception Entry",
+	"=== backtrace start ===",
+	"Stack Overflow",
+	"vAssertCalled",
+}
+
+// crashEnd 回溯结束的标志
+const crashEnd = "=== backtrace end ==="
+
+// coredump 组件的输出：标题行之后是若干内存区域，每个区域以
+// "------ DATA BEGIN 地址@长度@名称 ------" 开始，内容为 base64，
+// 以 "------ DATA END CRC32 ------" 结束
+var (
+	coredumpStart = regexp.MustCompile(`-\+-\+-\+-\s*(.*COREDUMP.*?)\s*\+-\+-\+-`)
+	coredumpEnd   = regexp.MustCompile(`-\+-\+-\+-\s*END\b`)
+	dataBegin     = regexp.MustCompile(`-+\s*DATA BEGIN\s+(?:0x)?([0-9a-fA-F]+)@(?:0x)?([0-9a-fA-F]+)@(\S*)\s*-+`)
+	dataEnd       = regexp.MustCompile(`-+\s*DATA END\s+(?:0x)?([0-9a-fA-F]+)\s*-+`)
+)
+
+// causeNames RISC-V 同步异常的 mcause 编号
+var causeNames = map[uint64]string{
+	0:  "指令地址未对齐",
+	1:  "取指令访问错误",
+	2:  "非法指令",
+	3:  "断点",
+	4:  "读地址未对齐",
+	5:  "读访问错误",
+	6:  "写地址未对齐",
+	7:  "写访问错误",
+	8:  "用户模式 ecall",
+	11: "机器模式 ecall",
+}
+
+// maxStackAddresses coredump 中每个栈区域最多列出的代码地址数
+const maxStackAddresses = 16
+
+// Region coredump 中的一个内存区域
+type Region struct {
+	Name    string
+	Address uint32
+	// Length 设备声明的长度，Data 为实际解码出的数据
+	Length int
+	Data   []byte
+	// CRC 设备计算的 CRC32，CRCOK 表示与解码出的数据一致
+	CRC   uint32
+	CRCOK bool
+}
+
+// Coredump 从串口输出中收集的 coredump
+type Coredump struct {
+	Title   string
+	Regions []Region
+	// Text 原始输出，可以保存后用 wb2-cli decode 重新解析
+	Text []string
+}
+
+// Decoder 逐行识别崩溃信息和 coredump，为其中的地址生成说明
+type Decoder struct {
+	sym *Symbolizer
+	// OnCoredump 收到完整的 coredump 时调用，返回的说明追加到摘要之后
+	OnCoredump func(*Coredump) []string
+
+	inCrash bool
+	// 未加载 ELF 时只提示一次
+	warned bool
+	dump   *Coredump
+	region *Region
+	b64    strings.Builder
+}
+
+// NewDecoder 创建解析器；sym 为 nil 时只识别崩溃信息，不解析地址
+func NewDecoder(sym *Symbolizer) *Decoder {
+	return &Decoder{sym: sym}
+}
+
+// Annotate 处理一行输出，返回应显示在该行之后的说明；important 表示该行属于
+// 崩溃信息或 coredump，即使被过滤也应该显示
+func (d *Decoder) Annotate(text string) (notes []string, important bool) {
+	if d.dump != nil {
+		return d.coredumpLine(text), true
+	}
+	if m := coredumpStart.FindStringSubmatch(text); m != nil {
+		d.dump = &Coredump{Title: m[1], Text: []string{text}}
+		return nil, true
+	}
+
+	if containsAny(text, crashStarts) {
+		if !d.inCrash {
+			d.warned = false
+		}
+		d.inCrash = true
+	} else if d.inCrash && blogLine.MatchString(text) {
+		d.inCrash = false
+	}
+	if !d.inCrash {
+		return nil, false
+	}
+	if strings.Contains(text, crashEnd) {
+		d.inCrash = false
+	}
+	return d.crashLine(text), true
+}
+
+// crashLine 为崩溃信息中的一行生成说明
+func (d *Decoder) crashLine(text string) []string {
+	if m := mcauseLine.FindStringSubmatch(text); m != nil {
+		cause, _ := strconv.ParseUint(m[1], 16, 64)
+		epc, _ := strconv.ParseUint(m[2], 16, 64)
+		tval, _ := strconv.ParseUint(m[3], 16, 64)
+		var notes []string
+		if name, ok := causeNames[cause&0x3ff]; ok && cause>>31 == 0 {
+			note := "异常原因: " + name
+			switch cause & 0x3ff {
+			case 4, 5, 6, 7:
+				note += fmt.Sprintf("，访问的地址为 0x%08x", tval)
+			case 2:
+				note += fmt.Sprintf("，指令为 0x%08x", tval)
+			}
+			notes = append(notes, note)
+		}
+		return append(notes, d.describe("mepc", epc, false)...)
+	}
+	if m := backtraceLine.FindStringSubmatch(text); m != nil {
+		addr, _ := strconv.ParseUint(m[1], 16, 64)
+		return d.describe("", addr, true)
+	}
+
+	var notes []string
+	for _, m := range hexWord.FindAllStringSubmatch(text, -1) {
+		addr, _ := strconv.ParseUint(m[1], 16, 64)
+		if d.sym != nil && d.sym.IsCode(addr) {
+			notes = append(notes, d.describe("", addr, true)...)
+		}
+	}
+	return notes
+}
+
+// describe 解析一个地址；ret 表示地址是返回地址
+func (d *Decoder) describe(label string, addr uint64, ret bool) []string {
+	if d.sym == nil {
+		if d.warned {
+			return nil
+		}
+		d.warned = true
+		return []string{"（没有 ELF 文件，无法解析地址）"}
+	}
+	var loc Location
+	var ok bool
+	if ret {
+		loc, ok = d.sym.LookupReturn(addr)
+	} else {
+		loc, ok = d.sym.Lookup(addr)
+	}
+	if !ok {
+		return nil
+	}
+	prefix := fmt.Sprintf("0x%08x", addr)
+	if label != "" {
+		prefix = label + " " + prefix
+	}
+	return []string{prefix + " " + loc.String()}
+}
+
+// coredumpLine 处理 coredump 中的一行
+func (d *Decoder) coredumpLine(text string) []string {
+	d.dump.Text = append(d.dump.Text, text)
+
+	if m := dataBegin.FindStringSubmatch(text); m != nil {
+		addr, _ := strconv.ParseUint(m[1], 16, 32)
+		length, _ := strconv.ParseUint(m[2], 16, 32)
+		d.region = &Region{Name: m[3], Address: uint32(addr), Length: int(length)}
+		d.b64.Reset()
+		return nil
+	}
+	if m := dataEnd.FindStringSubmatch(text); m != nil && d.region != nil {
+		crc, _ := strconv.ParseUint(m[1], 16, 32)
+		r := d.region
+		data, err := base64.StdEncoding.DecodeString(d.b64.String())
+		d.region = nil
+		if err != nil {
+			return []string{fmt.Sprintf("区域 %s 的数据无法解码: %v", r.Name, err)}
+		}
+		r.Data, r.CRC = data, uint32(crc)
+		r.CRCOK = crc32.ChecksumIEEE(data) == r.CRC
+		d.dump.Regions = append(d.dump.Regions, *r)
+		return nil
+	}
+	if coredumpEnd.MatchString(text) {
+		dump := d.dump
+		d.dump, d.region = nil, nil
+		notes := d.summary(dump)
+		if d.OnCoredump != nil {
+			notes = append(notes, d.OnCoredump(dump)...)
+		}
+		return notes
+	}
+	if d.region != nil {
+		d.b64.WriteString(strings.TrimSpace(text))
+	}
+	return nil
+}
+
+// summary 返回 coredump 的摘要：各区域的地址、大小和校验结果，以及栈区域中的代码地址
+func (d *Decoder) summary(dump *Coredump) []string {
+	notes := []string{fmt.Sprintf("%s：%d 个内存区域", dump.Title, len(dump.Regions))}
+	for _, r := range dump.Regions {
+		status := "CRC 正确"
+		if !r.CRCOK {
+			status = "CRC 不一致"
+		}
+		if len(r.Data) != r.Length {
+			status += fmt.Sprintf("，实际收到 %d 字节", len(r.Data))
+		}
+		notes = append(notes, fmt.Sprintf("  %-16s 0x%08x  %6d 字节  %s", r.Name, r.Address, r.Length, status))
+	}
+	if d.sym == nil {
+		return notes
+	}
+	for _, r := range dump.Regions {
+		if !strings.Contains(strings.ToLower(r.Name), "stack") {
+			continue
+		}
+		addrs := d.stackAddresses(r)
+		if len(addrs) == 0 {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("%s 中可能的返回地址:", r.Name))
+		for _, loc := range addrs {
+			notes = append(notes, fmt.Sprintf("  0x%08x %s", loc.Address, loc))
+		}
+	}
+	return notes
+}
+
+// stackAddresses 在栈区域中查找指向代码的字（可能的返回地址），最多 maxStackAddresses 个
+func (d *Decoder) stackAddresses(r Region) []Location {
+	var locs []Location
+	for off := 0; off+4 <= len(r.Data) && len(locs) < maxStackAddresses; off += 4 {
+		addr := uint64(r.Data[off]) | uint64(r.Data[off+1])<<8 | uint64(r.Data[off+2])<<16 | uint64(r.Data[off+3])<<24
+		if !d.sym.IsCode(addr) {
+			continue
+		}
+		if loc, ok := d.sym.LookupReturn(addr); ok {
+			locs = append(locs, loc)
+		}
+	}
+	return locs
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
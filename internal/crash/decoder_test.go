@@ -0,0 +1,158 @@
+package crash
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// annotateAll 依次处理所有行，返回每行的说明和是否属于崩溃信息
+func annotateAll(d *Decoder, lines []string) ([][]string, []bool) {
+	var notes [][]string
+	var important []bool
+	for _, line := range lines {
+		n, imp := d.Annotate(line)
+		notes = append(notes, n)
+		important = append(important, imp)
+	}
+	return notes, important
+}
+
+func TestDecoderException(t *testing.T) {
+	sym, err := LoadELF(writeTestELF(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{
+		"[      10][INFO  : main.c:  87] [APP] [EVT] INIT DONE 10",
+		"Exception Entry--->>>",
+		"mcause 30000007, mepc 2300001a, mtval 00000004",
+		"Exception code: 7",
+		"  msg: Store/AMO access fault",
+		"=== backtrace start ===",
+		"backtrace: 0x23000024",
+		"backtrace: 0x42020000",
+		"=== backtrace end ===",
+		"ra 0x23000048 sp 0x42021000",
+	}
+	notes, important := annotateAll(NewDecoder(sym), lines)
+
+	wantImportant := []bool{false, true, true, true, true, true, true, true, true, false}
+	if !reflect.DeepEqual(important, wantImportant) {
+		t.Errorf("important = %v, want %v", important, wantImportant)
+	}
+	wantNotes := map[int][]string{
+		2: {"异常原因: 写访问错误，访问的地址为 0x00000004", "mepc 0x2300001a app_main+0xa (main.c:42)"},
+		6: {"0x23000024 app_main+0x14 (main.c:42)"},
+	}
+	for i := range lines {
+		if !reflect.DeepEqual(notes[i], wantNotes[i]) {
+			t.Errorf("notes for %q = %q, want %q", lines[i], notes[i], wantNotes[i])
+		}
+	}
+}
+
+func TestDecoderRegisterDump(t *testing.T) {
+	sym, err := LoadELF(writeTestELF(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(sym)
+	lines := []string{
+		"Stack Overflow checked",
+		"x1 ra: 23000048  x2 sp: 42021000",
+		"[    100][INFO  : main.c:  90] back to normal 23000048",
+	}
+	notes, important := annotateAll(d, lines)
+	if want := []string{"0x23000048 helper+0x8 (main.c:60)"}; !reflect.DeepEqual(notes[1], want) {
+		t.Errorf("notes = %q, want %q", notes[1], want)
+	}
+	if important[2] || notes[2] != nil {
+		t.Errorf("blog line after crash should end the crash block, got %v %q", important[2], notes[2])
+	}
+}
+
+func TestDecoderWithoutELF(t *testing.T) {
+	notes, _ := annotateAll(NewDecoder(nil), []string{
+		"Exception Entry--->>>",
+		"mcause 00000002, mepc 2300001a, mtval 0000abcd",
+		"backtrace: 0x23000024",
+	})
+	want := []string{"异常原因: 非法指令，指令为 0x0000abcd", "（没有 ELF 文件，无法解析地址）"}
+	if !reflect.DeepEqual(notes[1], want) || notes[2] != nil {
+		t.Errorf("notes = %q, %q, want %q and none", notes[1], notes[2], want)
+	}
+}
+
+// coredumpText 生成 coredump 输出，栈区域中包含一个返回地址
+func coredumpText(corrupt bool) []string {
+	stack := make([]byte, 16)
+	binary.LittleEndian.PutUint32(stack[4:], 0x23000024)
+	binary.LittleEndian.PutUint32(stack[8:], 0x42021000)
+	crc := crc32.ChecksumIEEE(stack)
+	if corrupt {
+		crc++
+	}
+	encoded := base64.StdEncoding.EncodeToString(stack)
+	regs := base64.StdEncoding.EncodeToString(make([]byte, 8))
+	return []string{
+		"-+-+-+- BFLB COREDUMP v0.0.1 +-+-+-+",
+		"------ DATA BEGIN 42021000@00000010@task_stack ------",
+		encoded[:10],
+		encoded[10:],
+		fmt.Sprintf("------ DATA END %08X ------", crc),
+		"------ DATA BEGIN 42000000@00000010@regs ------",
+		regs,
+		fmt.Sprintf("------ DATA END %08X ------", crc32.ChecksumIEEE(make([]byte, 8))),
+		"-+-+-+- END -+-+-+-",
+	}
+}
+
+func TestDecoderCoredump(t *testing.T) {
+	sym, err := LoadELF(writeTestELF(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(sym)
+	var saved *Coredump
+	d.OnCoredump = func(dump *Coredump) []string {
+		saved = dump
+		return []string{"saved"}
+	}
+
+	lines := coredumpText(false)
+	notes, important := annotateAll(d, lines)
+	for i, imp := range important {
+		if !imp {
+			t.Errorf("line %q should be important", lines[i])
+		}
+	}
+	want := []string{
+		"BFLB COREDUMP v0.0.1：2 个内存区域",
+		"  task_stack       0x42021000      16 字节  CRC 正确",
+		"  regs             0x42000000      16 字节  CRC 正确，实际收到 8 字节",
+		"task_stack 中可能的返回地址:",
+		"  0x23000024 app_main+0x14 (main.c:42)",
+		"saved",
+	}
+	if got := notes[len(notes)-1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("summary =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if saved == nil || !reflect.DeepEqual(saved.Text, lines) || len(saved.Regions) != 2 {
+		t.Errorf("saved coredump = %+v", saved)
+	}
+
+	// 之后的行不再属于 coredump
+	if _, imp := d.Annotate("Booting BL602 Chip..."); imp {
+		t.Error("line after coredump should not be important")
+	}
+
+	notes, _ = annotateAll(NewDecoder(nil), coredumpText(true))
+	if got := notes[len(notes)-1]; len(got) != 3 || !strings.Contains(got[1], "CRC 不一致") {
+		t.Errorf("summary with bad CRC = %q", got)
+	}
+}
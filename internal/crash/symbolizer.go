@@ -0,0 +1,181 @@
+// Package crash 识别串口输出中的异常信息、回溯和 coredump，并根据 ELF 文件
+// 把 RISC-V 地址解析为函数、源文件和行号
+package crash
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// Location 地址对应的源码位置
+type Location struct {
+	Address uint64
+	// Function 所在函数，Offset 为地址相对函数入口的偏移
+	Function string
+	Offset   uint64
+	// File、Line 来自 DWARF 行号表，编译时没有调试信息则为空
+	File string
+	Line int
+}
+
+// String 返回 "函数+0x偏移 (文件:行号)" 形式的描述
+func (l Location) String() string {
+	s := l.Function
+	if s == "" {
+		s = "??"
+	} else if l.Offset > 0 {
+		s += fmt.Sprintf("+0x%x", l.Offset)
+	}
+	if l.File != "" {
+		s += fmt.Sprintf(" (%s:%d)", filepath.Base(l.File), l.Line)
+	}
+	return s
+}
+
+// function ELF 符号表中的函数
+type function struct {
+	name  string
+	start uint64
+	size  uint64
+}
+
+// lineRow DWARF 行号表中的一行，end 表示一段连续指令的结束
+type lineRow struct {
+	address uint64
+	file    string
+	line    int
+	end     bool
+}
+
+// codeRange 可执行段的地址范围
+type codeRange struct {
+	start, end uint64
+}
+
+// Symbolizer 根据 ELF 文件的符号表和 DWARF 行号表解析地址
+type Symbolizer struct {
+	functions []function
+	lines     []lineRow
+	code      []codeRange
+}
+
+// LoadELF 读取 ELF 文件的函数符号和行号表；没有调试信息时只能解析到函数
+func LoadELF(path string) (*Symbolizer, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 ELF 文件失败: %v", err)
+	}
+	defer f.Close()
+
+	s := &Symbolizer{}
+	for _, sec := range f.Sections {
+		if sec.Flags&elf.SHF_EXECINSTR != 0 && sec.Flags&elf.SHF_ALLOC != 0 && sec.Size > 0 {
+			s.code = append(s.code, codeRange{sec.Addr, sec.Addr + sec.Size})
+		}
+	}
+
+	symbols, err := f.Symbols()
+	if err != nil && err != elf.ErrNoSymbols {
+		return nil, fmt.Errorf("读取符号表失败: %v", err)
+	}
+	for _, sym := range symbols {
+		if elf.ST_TYPE(sym.Info) == elf.STT_FUNC && sym.Value != 0 {
+			s.functions = append(s.functions, function{sym.Name, sym.Value, sym.Size})
+		}
+	}
+	sort.SliceStable(s.functions, func(i, j int) bool { return s.functions[i].start < s.functions[j].start })
+
+	if d, err := f.DWARF(); err == nil {
+		s.lines = readLines(d)
+	}
+	return s, nil
+}
+
+// readLines 读取所有编译单元的行号表，按地址排序
+func readLines(d *dwarf.Data) []lineRow {
+	var rows []lineRow
+	r := d.Reader()
+	for {
+		entry, err := r.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		if entry.Tag != dwarf.TagCompileUnit {
+			r.SkipChildren()
+			continue
+		}
+		lr, err := d.LineReader(entry)
+		if err == nil && lr != nil {
+			var le dwarf.LineEntry
+			for lr.Next(&le) == nil {
+				row := lineRow{address: le.Address, line: le.Line, end: le.EndSequence}
+				if le.File != nil {
+					row.file = le.File.Name
+				}
+				rows = append(rows, row)
+			}
+		}
+		r.SkipChildren()
+	}
+	// 同一地址上结束的序列排在开始的序列之前
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].address != rows[j].address {
+			return rows[i].address < rows[j].address
+		}
+		return rows[i].end && !rows[j].end
+	})
+	return rows
+}
+
+// IsCode 判断地址是否位于可执行段中
+func (s *Symbolizer) IsCode(addr uint64) bool {
+	for _, r := range s.code {
+		if addr >= r.start && addr < r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup 解析地址，地址不在任何函数中时返回 false
+func (s *Symbolizer) Lookup(addr uint64) (Location, bool) {
+	loc := Location{Address: addr}
+	if i := sort.Search(len(s.functions), func(i int) bool { return s.functions[i].start > addr }) - 1; i >= 0 {
+		// 同一地址可能有多个别名，优先取大小覆盖该地址的符号；汇编标签没有大小
+		var match *function
+		for start := s.functions[i].start; i >= 0 && s.functions[i].start == start; i-- {
+			fn := &s.functions[i]
+			if addr < fn.start+fn.size {
+				match = fn
+				break
+			}
+			if fn.size == 0 && match == nil && s.IsCode(addr) {
+				match = fn
+			}
+		}
+		if match != nil {
+			loc.Function, loc.Offset = match.name, addr-match.start
+		}
+	}
+	if j := sort.Search(len(s.lines), func(j int) bool { return s.lines[j].address > addr }) - 1; j >= 0 && !s.lines[j].end {
+		loc.File, loc.Line = s.lines[j].file, s.lines[j].line
+	}
+	return loc, loc.Function != "" || loc.File != ""
+}
+
+// LookupReturn 解析返回地址：返回地址指向调用指令的下一条，按前一个字节查找
+// 源码位置，得到调用所在的行
+func (s *Symbolizer) LookupReturn(addr uint64) (Location, bool) {
+	if addr == 0 {
+		return Location{}, false
+	}
+	loc, ok := s.Lookup(addr - 1)
+	loc.Address = addr
+	if loc.Function != "" {
+		loc.Offset++
+	}
+	return loc, ok
+}
@@ -0,0 +1,258 @@
+package crash
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testFunc 测试 ELF 中的函数符号
+type testFunc struct {
+	name       string
+	addr, size uint32
+}
+
+// testRow 测试行号表中的一行，line 为 0 表示序列结束
+type testRow struct {
+	addr uint32
+	line int
+}
+
+// 测试 ELF 的代码段
+const (
+	testTextAddr = 0x23000000
+	testTextSize = 0x100
+)
+
+var testFuncs = []testFunc{
+	{"app_main", 0x23000010, 0x20},
+	{"app_main_alias", 0x23000010, 0},
+	{"helper", 0x23000040, 0x10},
+	{"_start", 0x23000000, 0},
+}
+
+var testRows = []testRow{
+	{0x23000010, 40},
+	{0x23000018, 42},
+	{0x23000024, 45},
+	{0x23000030, 0},
+	{0x23000040, 60},
+	{0x23000050, 0},
+}
+
+// writeTestELF 生成带函数符号和 DWARF 行号表（main.c）的 RISC-V ELF 文件
+func writeTestELF(t *testing.T) string {
+	t.Helper()
+	le := binary.LittleEndian
+
+	strtab := func(names []string) ([]byte, []uint32) {
+		buf := []byte{0}
+		offsets := make([]uint32, len(names))
+		for i, name := range names {
+			offsets[i] = uint32(len(buf))
+			buf = append(append(buf, name...), 0)
+		}
+		return buf, offsets
+	}
+
+	// .debug_abbrev：编译单元带名称和行号表偏移，没有子节点
+	abbrev := []byte{1, 0x11, 0, 0x03, 0x08, 0x10, 0x06, 0, 0, 0}
+	// .debug_info：DWARF 2 编译单元
+	var info bytes.Buffer
+	binary.Write(&info, le, uint32(0))
+	binary.Write(&info, le, uint16(2))
+	binary.Write(&info, le, uint32(0))
+	info.WriteByte(4)
+	info.WriteByte(1)
+	info.WriteString("main.c\x00")
+	binary.Write(&info, le, uint32(0))
+	le.PutUint32(info.Bytes(), uint32(info.Len()-4))
+
+	// .debug_line：DWARF 2 行号程序
+	var program bytes.Buffer
+	line := 1
+	for i, row := range testRows {
+		if i == 0 || testRows[i-1].line == 0 {
+			program.Write([]byte{0, 5, 2})
+			binary.Write(&program, le, row.addr)
+		} else {
+			program.WriteByte(2) // DW_LNS_advance_pc
+			writeULEB(&program, uint64(row.addr-testRows[i-1].addr))
+		}
+		if row.line == 0 {
+			program.Write([]byte{0, 1, 1}) // DW_LNE_end_sequence
+			line = 1
+			continue
+		}
+		program.WriteByte(3) // DW_LNS_advance_line
+		writeSLEB(&program, int64(row.line-line))
+		program.WriteByte(1) // DW_LNS_copy
+		line = row.line
+	}
+	var header bytes.Buffer
+	header.Write([]byte{1, 1, 0xfb, 14, 13})
+	header.Write([]byte{0, 1, 1, 1, 1, 0, 0, 0, 1, 0, 0, 1})
+	header.WriteByte(0) // 没有包含目录
+	header.WriteString("main.c\x00\x00\x00\x00")
+	header.WriteByte(0)
+	var debugLine bytes.Buffer
+	binary.Write(&debugLine, le, uint32(2+4+header.Len()+program.Len()))
+	binary.Write(&debugLine, le, uint16(2))
+	binary.Write(&debugLine, le, uint32(header.Len()))
+	debugLine.Write(header.Bytes())
+	debugLine.Write(program.Bytes())
+
+	type section struct {
+		name  string
+		typ   elf.SectionType
+		flags elf.SectionFlag
+		addr  uint32
+		data  []byte
+	}
+	sections := []section{
+		{".text", elf.SHT_PROGBITS, elf.SHF_ALLOC | elf.SHF_EXECINSTR, testTextAddr, make([]byte, testTextSize)},
+		{".data", elf.SHT_PROGBITS, elf.SHF_ALLOC | elf.SHF_WRITE, 0x42020000, make([]byte, 0x40)},
+		{".debug_abbrev", elf.SHT_PROGBITS, 0, 0, abbrev},
+		{".debug_info", elf.SHT_PROGBITS, 0, 0, info.Bytes()},
+		{".debug_line", elf.SHT_PROGBITS, 0, 0, debugLine.Bytes()},
+	}
+
+	var secNames []string
+	for _, s := range sections {
+		secNames = append(secNames, s.name)
+	}
+	secNames = append(secNames, ".symtab", ".strtab", ".shstrtab")
+	shstr, shOff := strtab(secNames)
+
+	var symNames []string
+	for _, f := range testFuncs {
+		symNames = append(symNames, f.name)
+	}
+	symstr, symOff := strtab(symNames)
+	symtab := make([]byte, 16)
+	for i, f := range testFuncs {
+		entry := make([]byte, 16)
+		le.PutUint32(entry[0:], symOff[i])
+		le.PutUint32(entry[4:], f.addr)
+		le.PutUint32(entry[8:], f.size)
+		entry[12] = byte(elf.STB_GLOBAL)<<4 | byte(elf.STT_FUNC)
+		le.PutUint16(entry[14:], 1)
+		symtab = append(symtab, entry...)
+	}
+
+	var body bytes.Buffer
+	body.Write(make([]byte, 52))
+	type header32 struct {
+		name, typ, flags, addr, off, size, link, info, align, entsize uint32
+	}
+	headers := []header32{{}}
+	for i, s := range sections {
+		headers = append(headers, header32{name: shOff[i], typ: uint32(s.typ), flags: uint32(s.flags), addr: s.addr, off: uint32(body.Len()), size: uint32(len(s.data)), align: 1})
+		body.Write(s.data)
+	}
+	n := len(sections)
+	headers = append(headers,
+		header32{name: shOff[n], typ: uint32(elf.SHT_SYMTAB), off: uint32(body.Len()), size: uint32(len(symtab)), link: uint32(n + 2), info: 1, align: 4, entsize: 16})
+	body.Write(symtab)
+	headers = append(headers, header32{name: shOff[n+1], typ: uint32(elf.SHT_STRTAB), off: uint32(body.Len()), size: uint32(len(symstr)), align: 1})
+	body.Write(symstr)
+	headers = append(headers, header32{name: shOff[n+2], typ: uint32(elf.SHT_STRTAB), off: uint32(body.Len()), size: uint32(len(shstr)), align: 1})
+	body.Write(shstr)
+
+	shoff := uint32(body.Len())
+	for _, h := range headers {
+		for _, v := range []uint32{h.name, h.typ, h.flags, h.addr, h.off, h.size, h.link, h.info, h.align, h.entsize} {
+			binary.Write(&body, le, v)
+		}
+	}
+
+	data := body.Bytes()
+	copy(data, []byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS32), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)})
+	le.PutUint16(data[16:], uint16(elf.ET_EXEC))
+	le.PutUint16(data[18:], uint16(elf.EM_RISCV))
+	le.PutUint32(data[20:], uint32(elf.EV_CURRENT))
+	le.PutUint32(data[32:], shoff)
+	le.PutUint16(data[40:], 52)
+	le.PutUint16(data[46:], 40)
+	le.PutUint16(data[48:], uint16(len(headers)))
+	le.PutUint16(data[50:], uint16(len(headers)-1))
+
+	path := filepath.Join(t.TempDir(), "demo.elf")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeULEB(buf *bytes.Buffer, v uint64) {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			b |= 0x80
+		}
+		buf.WriteByte(b)
+		if v == 0 {
+			return
+		}
+	}
+}
+
+func writeSLEB(buf *bytes.Buffer, v int64) {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		done := (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0)
+		if !done {
+			b |= 0x80
+		}
+		buf.WriteByte(b)
+		if done {
+			return
+		}
+	}
+}
+
+func TestSymbolizerLookup(t *testing.T) {
+	sym, err := LoadELF(writeTestELF(t))
+	if err != nil {
+		t.Fatalf("LoadELF() error = %v", err)
+	}
+
+	tests := []struct {
+		addr uint64
+		ret  bool
+		want string
+		ok   bool
+	}{
+		{0x23000010, false, "app_main (main.c:40)", true},
+		{0x2300001a, false, "app_main+0xa (main.c:42)", true},
+		{0x2300002c, false, "app_main+0x1c (main.c:45)", true},
+		// 返回地址按前一条指令所在的行解析
+		{0x23000024, true, "app_main+0x14 (main.c:42)", true},
+		{0x23000048, false, "helper+0x8 (main.c:60)", true},
+		// 没有大小的汇编标签，没有行号信息
+		{0x23000004, false, "_start+0x4", true},
+		{0x42020000, false, "", false},
+		{0x22fffff0, false, "", false},
+	}
+	for _, tt := range tests {
+		var loc Location
+		var ok bool
+		if tt.ret {
+			loc, ok = sym.LookupReturn(tt.addr)
+		} else {
+			loc, ok = sym.Lookup(tt.addr)
+		}
+		if ok != tt.ok || (ok && loc.String() != tt.want) {
+			t.Errorf("Lookup(0x%x, ret=%v) = %q, %v, want %q, %v", tt.addr, tt.ret, loc.String(), ok, tt.want, tt.ok)
+		}
+	}
+
+	if !sym.IsCode(0x230000ff) || sym.IsCode(0x23000100) || sym.IsCode(0x42020000) {
+		t.Error("IsCode() should only accept addresses in .text")
+	}
+}
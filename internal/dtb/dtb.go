@@ -0,0 +1,464 @@
+// Package dtb 将项目的设备树源文件（board.dts）编译为写入 factory 分区的 DTB
+//
+// 只支持 wb2-cli 生成的设备树和 SDK 默认设备树用到的语法：/dts-v1/、注释、标签、
+// 字符串（列表）、<数字> 单元、[字节] 和空属性。引用（&label）、表达式和 /include/
+// 等指令会返回错误。
+package dtb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DTB（版本 17）的格式，所有数值为大端序：
+//
+//	文件头    magic、总大小、结构块/字符串块/保留内存表的偏移、版本、兼容版本、
+//	          启动 CPU、字符串块大小、结构块大小
+//	保留内存表 以全 0 的表项结束
+//	结构块    BEGIN_NODE 名称、PROP 长度 名称偏移 值、END_NODE，最后为 END，均按 4 字节对齐
+//	字符串块  属性名称（以 0 结尾）
+const (
+	Magic = 0xD00DFEED
+
+	version        = 17
+	lastCompatible = 16
+	headerSize     = 40
+	reserveMapSize = 16
+
+	tokenBeginNode = 1
+	tokenEndNode   = 2
+	tokenProp      = 3
+	tokenEnd       = 9
+)
+
+// node 设备树节点；属性和子节点保持源文件中的顺序
+type node struct {
+	name     string
+	props    []prop
+	children []*node
+}
+
+type prop struct {
+	name  string
+	value []byte
+}
+
+// Compile 编译设备树源文件
+func Compile(src []byte) ([]byte, error) {
+	p := &parser{lex: &lexer{src: string(src), line: 1}}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return encode(root), nil
+}
+
+// setProp 设置属性，同名属性以后出现的为准
+func (n *node) setProp(name string, value []byte) {
+	for i := range n.props {
+		if n.props[i].name == name {
+			n.props[i].value = value
+			return
+		}
+	}
+	n.props = append(n.props, prop{name, value})
+}
+
+// child 返回同名子节点，没有时新建；同名节点的内容合并
+func (n *node) child(name string) *node {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &node{name: name}
+	n.children = append(n.children, c)
+	return c
+}
+
+// encode 生成 DTB
+func encode(root *node) []byte {
+	var structure []byte
+	var strs []byte
+	offsets := map[string]int{}
+
+	u32 := func(v uint32) {
+		structure = binary.BigEndian.AppendUint32(structure, v)
+	}
+	pad := func() {
+		for len(structure)%4 != 0 {
+			structure = append(structure, 0)
+		}
+	}
+	var walk func(n *node)
+	walk = func(n *node) {
+		u32(tokenBeginNode)
+		structure = append(structure, n.name...)
+		structure = append(structure, 0)
+		pad()
+		for _, p := range n.props {
+			off, ok := offsets[p.name]
+			if !ok {
+				off = len(strs)
+				offsets[p.name] = off
+				strs = append(append(strs, p.name...), 0)
+			}
+			u32(tokenProp)
+			u32(uint32(len(p.value)))
+			u32(uint32(off))
+			structure = append(structure, p.value...)
+			pad()
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+		u32(tokenEndNode)
+	}
+	walk(root)
+	u32(tokenEnd)
+
+	structOffset := headerSize + reserveMapSize
+	stringsOffset := structOffset + len(structure)
+	total := stringsOffset + len(strs)
+
+	data := make([]byte, headerSize+reserveMapSize, total)
+	for i, v := range []int{Magic, total, structOffset, stringsOffset, headerSize, version, lastCompatible, 0, len(strs), len(structure)} {
+		binary.BigEndian.PutUint32(data[4*i:], uint32(v))
+	}
+	data = append(data, structure...)
+	return append(data, strs...)
+}
+
+// parser 递归下降解析设备树源文件
+type parser struct {
+	lex  *lexer
+	peek *token
+}
+
+func (p *parser) next() (token, error) {
+	if p.peek != nil {
+		t := *p.peek
+		p.peek = nil
+		return t, nil
+	}
+	return p.lex.next()
+}
+
+func (p *parser) lookahead() (token, error) {
+	if p.peek == nil {
+		t, err := p.lex.next()
+		if err != nil {
+			return t, err
+		}
+		p.peek = &t
+	}
+	return *p.peek, nil
+}
+
+// expect 读取下一个记号并检查其内容
+func (p *parser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != tokPunct || t.text != text {
+		return fmt.Errorf("第 %d 行: 应为 %q，实际为 %q", t.line, text, t.text)
+	}
+	return nil
+}
+
+func (p *parser) parse() (*node, error) {
+	root := &node{}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.kind == tokEOF:
+			return root, nil
+		case t.kind == tokDirective && t.text == "/dts-v1/":
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case t.kind == tokPunct && t.text == "/":
+			if err := p.parseBody(root); err != nil {
+				return nil, err
+			}
+		case t.kind == tokDirective:
+			return nil, fmt.Errorf("第 %d 行: 不支持的指令 %s", t.line, t.text)
+		default:
+			return nil, fmt.Errorf("第 %d 行: 应为根节点 /，实际为 %q", t.line, t.text)
+		}
+	}
+}
+
+// parseBody 解析 { ... }; 中的属性和子节点
+func (p *parser) parseBody(n *node) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind == tokLabel {
+			continue
+		}
+		if t.kind == tokPunct && t.text == "}" {
+			return p.expect(";")
+		}
+		if t.kind != tokWord {
+			return fmt.Errorf("第 %d 行: 应为属性或节点名称，实际为 %q", t.line, t.text)
+		}
+
+		la, err := p.lookahead()
+		if err != nil {
+			return err
+		}
+		switch {
+		case la.kind == tokPunct && la.text == "{":
+			if err := p.parseBody(n.child(t.text)); err != nil {
+				return err
+			}
+		case la.kind == tokPunct && la.text == ";":
+			p.next()
+			n.setProp(t.text, nil)
+		case la.kind == tokPunct && la.text == "=":
+			p.next()
+			value, err := p.parseValue()
+			if err != nil {
+				return fmt.Errorf("属性 %s: %v", t.text, err)
+			}
+			n.setProp(t.text, value)
+		default:
+			return fmt.Errorf("第 %d 行: %s 之后应为 =、; 或 {，实际为 %q", la.line, t.text, la.text)
+		}
+	}
+}
+
+// parseValue 解析以逗号分隔的属性值，直到 ;
+func (p *parser) parseValue() ([]byte, error) {
+	var value []byte
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.kind == tokString:
+			value = append(append(value, t.text...), 0)
+		case t.kind == tokPunct && t.text == "<":
+			cells, err := p.parseCells()
+			if err != nil {
+				return nil, err
+			}
+			value = append(value, cells...)
+		case t.kind == tokPunct && t.text == "[":
+			data, err := p.parseBytes()
+			if err != nil {
+				return nil, err
+			}
+			value = append(value, data...)
+		default:
+			return nil, fmt.Errorf("第 %d 行: 无效的属性值 %q", t.line, t.text)
+		}
+
+		t, err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokPunct && t.text == ";" {
+			return value, nil
+		}
+		if t.kind != tokPunct || t.text != "," {
+			return nil, fmt.Errorf("第 %d 行: 应为 , 或 ;，实际为 %q", t.line, t.text)
+		}
+	}
+}
+
+// parseCells 解析 <...> 中的 32 位数字
+func (p *parser) parseCells() ([]byte, error) {
+	var data []byte
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokPunct && t.text == ">" {
+			return data, nil
+		}
+		if t.kind != tokWord {
+			return nil, fmt.Errorf("第 %d 行: 不支持的单元 %q（只支持数字）", t.line, t.text)
+		}
+		v, err := strconv.ParseUint(t.text, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: 无效的数字 %q", t.line, t.text)
+		}
+		data = binary.BigEndian.AppendUint32(data, uint32(v))
+	}
+}
+
+// parseBytes 解析 [...] 中的十六进制字节，字节之间的空格可以省略
+func (p *parser) parseBytes() ([]byte, error) {
+	var data []byte
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokPunct && t.text == "]" {
+			return data, nil
+		}
+		if t.kind != tokWord || len(t.text)%2 != 0 {
+			return nil, fmt.Errorf("第 %d 行: 无效的字节 %q", t.line, t.text)
+		}
+		for i := 0; i < len(t.text); i += 2 {
+			v, err := strconv.ParseUint(t.text[i:i+2], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 行: 无效的字节 %q", t.line, t.text)
+			}
+			data = append(data, byte(v))
+		}
+	}
+}
+
+// 记号类型
+const (
+	tokEOF = iota
+	tokWord
+	tokString
+	tokLabel
+	tokDirective
+	tokPunct
+)
+
+type token struct {
+	kind int
+	text string
+	line int
+}
+
+// lexer 将源文件切分为记号，跳过空白和注释
+type lexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte(",._+*#?@-", c) >= 0
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skip(); err != nil {
+		return token{}, err
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, line: l.line}, nil
+	}
+
+	start, c := l.pos, l.src[l.pos]
+	switch {
+	case c == '"':
+		return l.string()
+	case c == '/':
+		// /dts-v1/ 等指令，否则为根节点名称
+		end := l.pos + 1
+		for end < len(l.src) && (isWordChar(l.src[end]) && l.src[end] != ',') {
+			end++
+		}
+		if end > l.pos+1 && end < len(l.src) && l.src[end] == '/' {
+			l.pos = end + 1
+			return token{kind: tokDirective, text: l.src[start:l.pos], line: l.line}, nil
+		}
+		l.pos++
+		return token{kind: tokPunct, text: "/", line: l.line}, nil
+	case c == '&' || c == '(':
+		return token{}, fmt.Errorf("第 %d 行: 不支持引用和表达式", l.line)
+	case isWordChar(c) && c != ',':
+		for l.pos < len(l.src) && isWordChar(l.src[l.pos]) {
+			l.pos++
+		}
+		text := l.src[start:l.pos]
+		if l.pos < len(l.src) && l.src[l.pos] == ':' {
+			l.pos++
+			return token{kind: tokLabel, text: text, line: l.line}, nil
+		}
+		return token{kind: tokWord, text: text, line: l.line}, nil
+	case strings.IndexByte("{};=<>[],", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, text: string(c), line: l.line}, nil
+	}
+	return token{}, fmt.Errorf("第 %d 行: 无效的字符 %q", l.line, c)
+}
+
+// skip 跳过空白和注释
+func (l *lexer) skip() error {
+	for l.pos < len(l.src) {
+		switch {
+		case l.src[l.pos] == '\n':
+			l.line++
+			l.pos++
+		case l.src[l.pos] == ' ' || l.src[l.pos] == '\t' || l.src[l.pos] == '\r':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				l.pos = len(l.src)
+			} else {
+				l.pos += end
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return fmt.Errorf("第 %d 行: 注释没有结束", l.line)
+			}
+			l.line += strings.Count(l.src[l.pos:l.pos+2+end], "\n")
+			l.pos += 2 + end + 2
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// string 读取带转义的字符串
+func (l *lexer) string() (token, error) {
+	line := l.line
+	var b strings.Builder
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return token{kind: tokString, text: b.String(), line: line}, nil
+		case '\n':
+			return token{}, fmt.Errorf("第 %d 行: 字符串没有结束", line)
+		case '\\':
+			l.pos++
+			if l.pos >= len(l.src) {
+				break
+			}
+			switch e := l.src[l.pos]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '0':
+				b.WriteByte(0)
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return token{}, fmt.Errorf("第 %d 行: 字符串没有结束", line)
+}
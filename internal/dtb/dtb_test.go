@@ -0,0 +1,145 @@
+package dtb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testSource = `/dts-v1/;
+/* 注释
+ * 多行 */
+/ {
+    model = "Ai-WB2-12F"; // 行尾注释
+    compatible = "bl,bl602-sample", "bl,bl602-common";
+    #address-cells = <0x1>;
+    gpio {
+        max_num = <40>;
+        gpio0 {
+            pin = <3>;
+            hbn_use = "disable";
+        };
+    };
+    uart {
+        uart0: uart@4000A000 {
+            reg = <0x4000A000 0x100>;
+            empty;
+        };
+    };
+    wifi {
+        mac {
+            mac_addr = [C8 43 57 82 73 40];
+            packed = [0102];
+        };
+    };
+};
+`
+
+// dump 解析 DTB 的结构块，按层级输出节点和属性
+func dump(t *testing.T, data []byte) string {
+	be := binary.BigEndian
+	if be.Uint32(data) != Magic || int(be.Uint32(data[4:])) != len(data) || be.Uint32(data[20:]) != version {
+		t.Fatalf("Unexpected header % X", data[:headerSize])
+	}
+	off := int(be.Uint32(data[8:]))
+	strs := data[be.Uint32(data[12:]):]
+	cstring := func(b []byte) string { return string(b[:bytes.IndexByte(b, 0)]) }
+
+	var out strings.Builder
+	depth := 0
+	for {
+		tok := be.Uint32(data[off:])
+		off += 4
+		switch tok {
+		case tokenBeginNode:
+			name := cstring(data[off:])
+			off += (len(name) + 4) &^ 3
+			fmt.Fprintf(&out, "%s%s {\n", strings.Repeat("  ", depth), name)
+			depth++
+		case tokenProp:
+			size, nameOff := int(be.Uint32(data[off:])), int(be.Uint32(data[off+4:]))
+			off += 8
+			fmt.Fprintf(&out, "%s%s = % X\n", strings.Repeat("  ", depth), cstring(strs[nameOff:]), data[off:off+size])
+			off += (size + 3) &^ 3
+		case tokenEndNode:
+			depth--
+			fmt.Fprintf(&out, "%s}\n", strings.Repeat("  ", depth))
+		case tokenEnd:
+			return out.String()
+		default:
+			t.Fatalf("Unexpected token %d at %d", tok, off-4)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	data, err := Compile([]byte(testSource))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	want := ` {
+  model = 41 69 2D 57 42 32 2D 31 32 46 00
+  compatible = 62 6C 2C 62 6C 36 30 32 2D 73 61 6D 70 6C 65 00 62 6C 2C 62 6C 36 30 32 2D 63 6F 6D 6D 6F 6E 00
+  #address-cells = 00 00 00 01
+  gpio {
+    max_num = 00 00 00 28
+    gpio0 {
+      pin = 00 00 00 03
+      hbn_use = 64 69 73 61 62 6C 65 00
+    }
+  }
+  uart {
+    uart@4000A000 {
+      reg = 40 00 A0 00 00 00 01 00
+      empty = 
+    }
+  }
+  wifi {
+    mac {
+      mac_addr = C8 43 57 82 73 40
+      packed = 01 02
+    }
+  }
+}
+`
+	if got := dump(t, data); got != want {
+		t.Errorf("Unexpected tree:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCompileMerge(t *testing.T) {
+	data, err := Compile([]byte(`/dts-v1/;
+/ { a { x = <1>; }; };
+/ { a { x = <2>; y; }; };
+`))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if got, want := dump(t, data), " {\n  a {\n    x = 00 00 00 02\n    y = \n  }\n}\n"; got != want {
+		t.Errorf("Unexpected tree:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"reference", `/ { a = <&gpio>; };`, "引用"},
+		{"include", `/include/ "a.dtsi"`, "不支持的指令"},
+		{"unterminated", `/ { a = "x; };`, "字符串没有结束"},
+		{"comment", `/ { /* a };`, "注释没有结束"},
+		{"number", `/ { a = <abc>; };`, "无效的数字"},
+		{"byte", `/ { a = [123]; };`, "无效的字节"},
+		{"missing semicolon", "/ {\n a = <1>\n};", "第 3 行"},
+	}
+	for _, tt := range tests {
+		if _, err := Compile([]byte(tt.src)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package flasher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// 写入 FW 分区的固件由 4K 的启动头扇区和固件组成。启动头的所有数值为小端序：
+//
+//	0    magic "BFNP"、版本
+//	8    Flash 配置（"FCFG"、参数和 CRC32，共 92 字节）
+//	100  时钟配置（"PCFG"、参数和 CRC32，共 16 字节）
+//	116  启动配置位、固件长度、入口地址、固件在分区中的偏移
+//	132  固件的 SHA-256
+//	172  前 172 字节的 CRC32
+//
+// 格式见 SDK boot2 的 customer_app/bl602_boot2/bl602_boot2/blsp_bootinfo.h（Boot_Header_Config）。
+// Flash 和时钟配置与模组的晶振有关，从同一晶振的 eflash_loader 的启动头中复制。
+// TestFirmwareImageSDK 用 SDK 编译生成的 FW_OTA.bin 逐字节校验启动头。
+const (
+	bootMagic     = 0x504E4642
+	flashCfgMagic = "FCFG"
+	clkCfgMagic   = "PCFG"
+
+	flashCfgOffset = 8
+	clkCfgOffset   = 100
+	bootCfgOffset  = 116
+	imgLenOffset   = 120
+	imgStartOffset = 128
+	hashOffset     = 132
+	crcOffset      = 172
+
+	// FirmwareOffset 固件在 FW 分区中的偏移（启动头的 imgStart），之前为启动头扇区。
+	// 与 SDK 编译生成的 build_out/ota/*/FW_OTA.bin 的布局一致：启动头补齐到 4K 后是固件
+	FirmwareOffset = 0x1000
+)
+
+// 启动配置位（Boot_Header_Config.bootCfg）：sign、encrypt_type、key_sel 各 2 位均为 0，
+// no_segment 为位 8，cache_enable 为位 9，cache_way_disable 为位 12-15。取值与 SDK 烧录工具
+// tools/flash_tool/chips/bl602/img_create_iot/efuse_bootheader_cfg.conf 的 [FW_CFG] 一致：
+// 不分段、启用 cache，关闭 cache 的 way 0 和 1
+const (
+	bootCfgNoSegment   = 1 << 8
+	bootCfgCacheEnable = 1 << 9
+	bootCfgCacheWayDis = 0x3 << 12
+)
+
+// HasBootHeader 判断镜像是否以启动头开始
+func HasBootHeader(data []byte) bool {
+	return len(data) >= BootHeaderSize && binary.LittleEndian.Uint32(data) == bootMagic
+}
+
+// FirmwareImage 为 SDK 编译的固件（build_out/<项目名>.bin）加上启动头，
+// 返回写入 FW 分区的镜像；loader 为与模组晶振对应的 eflash_loader 镜像
+func FirmwareImage(loader, firmware []byte) ([]byte, error) {
+	if !HasBootHeader(loader) ||
+		!bytes.Equal(loader[flashCfgOffset:flashCfgOffset+4], []byte(flashCfgMagic)) ||
+		!bytes.Equal(loader[clkCfgOffset:clkCfgOffset+4], []byte(clkCfgMagic)) {
+		return nil, fmt.Errorf("eflash_loader 镜像没有有效的启动头，无法取得 Flash 和时钟配置")
+	}
+	if len(firmware) == 0 {
+		return nil, fmt.Errorf("固件为空")
+	}
+
+	image := make([]byte, FirmwareOffset+len(firmware))
+	header := image[:BootHeaderSize]
+	binary.LittleEndian.PutUint32(header[0:], bootMagic)
+	binary.LittleEndian.PutUint32(header[4:], 1)
+	copy(header[flashCfgOffset:bootCfgOffset], loader[flashCfgOffset:bootCfgOffset])
+	binary.LittleEndian.PutUint32(header[bootCfgOffset:], bootCfgNoSegment|bootCfgCacheEnable|bootCfgCacheWayDis)
+	binary.LittleEndian.PutUint32(header[imgLenOffset:], uint32(len(firmware)))
+	binary.LittleEndian.PutUint32(header[imgStartOffset:], FirmwareOffset)
+	hash := sha256.Sum256(firmware)
+	copy(header[hashOffset:], hash[:])
+	binary.LittleEndian.PutUint32(header[crcOffset:], crc32.ChecksumIEEE(header[:crcOffset]))
+
+	for i := BootHeaderSize; i < FirmwareOffset; i++ {
+		image[i] = 0xFF
+	}
+	copy(image[FirmwareOffset:], firmware)
+	return image, nil
+}
@@ -0,0 +1,94 @@
+package flasher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"testing"
+)
+
+func TestFirmwareImage(t *testing.T) {
+	loader := testLoader(64)
+	firmware := bytes.Repeat([]byte{0x5A, 0xA5}, 1000)
+
+	image, err := FirmwareImage(loader, firmware)
+	if err != nil {
+		t.Fatalf("FirmwareImage failed: %v", err)
+	}
+	if len(image) != FirmwareOffset+len(firmware) || !bytes.Equal(image[FirmwareOffset:], firmware) {
+		t.Fatalf("Expected firmware at 0x%X, got %d bytes", FirmwareOffset, len(image))
+	}
+	if !HasBootHeader(image) {
+		t.Fatalf("Expected boot header")
+	}
+
+	le := binary.LittleEndian
+	header := image[:BootHeaderSize]
+	if !bytes.Equal(header[flashCfgOffset:bootCfgOffset], loader[flashCfgOffset:bootCfgOffset]) {
+		t.Errorf("Expected flash and clock config copied from loader")
+	}
+	if cfg := le.Uint32(header[bootCfgOffset:]); cfg&bootCfgNoSegment == 0 || cfg&bootCfgCacheEnable == 0 {
+		t.Errorf("Unexpected boot config 0x%08X", cfg)
+	}
+	if le.Uint32(header[imgLenOffset:]) != uint32(len(firmware)) || le.Uint32(header[imgStartOffset:]) != FirmwareOffset {
+		t.Errorf("Unexpected image length or start % X", header[imgLenOffset:hashOffset])
+	}
+	if hash := sha256.Sum256(firmware); !bytes.Equal(header[hashOffset:hashOffset+32], hash[:]) {
+		t.Errorf("Firmware hash mismatch")
+	}
+	if le.Uint32(header[crcOffset:]) != crc32.ChecksumIEEE(header[:crcOffset]) {
+		t.Errorf("Header CRC mismatch")
+	}
+	if bytes.Count(image[BootHeaderSize:FirmwareOffset], []byte{0xFF}) != FirmwareOffset-BootHeaderSize {
+		t.Errorf("Expected header sector padded with 0xFF")
+	}
+}
+
+func TestFirmwareImageBadLoader(t *testing.T) {
+	loader := testLoader(64)
+	copy(loader[clkCfgOffset:], "XXXX")
+	if _, err := FirmwareImage(loader, []byte{1, 2, 3}); err == nil {
+		t.Errorf("Expected error for loader without clock config")
+	}
+	if _, err := FirmwareImage(testLoader(64), nil); err == nil {
+		t.Errorf("Expected error for empty firmware")
+	}
+}
+
+// TestFirmwareImageSDK 与 SDK 编译生成的带启动头的固件逐字节比较：用 SDK 镜像中的 Flash 和时钟配置
+// 为其中的固件重新加上启动头，结果应与 SDK 的启动头相同。需要将 WB2_SDK_FW_IMAGE 设置为
+// SDK 编译生成的 build_out/ota/*/FW_OTA.bin
+func TestFirmwareImageSDK(t *testing.T) {
+	path := os.Getenv("WB2_SDK_FW_IMAGE")
+	if path == "" {
+		t.Skip("未设置 WB2_SDK_FW_IMAGE（SDK 编译生成的 FW_OTA.bin）")
+	}
+	sdk, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取 SDK 镜像失败: %v", err)
+	}
+	if !HasBootHeader(sdk) {
+		t.Fatalf("%s 没有启动头", path)
+	}
+
+	le := binary.LittleEndian
+	start, size := int(le.Uint32(sdk[imgStartOffset:])), int(le.Uint32(sdk[imgLenOffset:]))
+	if start != FirmwareOffset {
+		t.Errorf("SDK 镜像的 imgStart 为 0x%X，FirmwareOffset 为 0x%X", start, FirmwareOffset)
+	}
+	if start+size > len(sdk) {
+		t.Fatalf("SDK 镜像长度 %d 小于 imgStart + imgLen（0x%X + %d）", len(sdk), start, size)
+	}
+
+	image, err := FirmwareImage(sdk, sdk[start:start+size])
+	if err != nil {
+		t.Fatalf("FirmwareImage failed: %v", err)
+	}
+	for i := 0; i < BootHeaderSize; i += 4 {
+		if got, want := le.Uint32(image[i:]), le.Uint32(sdk[i:]); got != want {
+			t.Errorf("启动头偏移 %d: 0x%08X，SDK 为 0x%08X", i, got, want)
+		}
+	}
+}
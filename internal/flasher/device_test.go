@@ -0,0 +1,186 @@
+package flasher
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// 模拟设备的错误码
+const (
+	simErrChecksum = 0x0004
+	simErrCommand  = 0x0001
+	simErrImage    = 0x0204
+)
+
+// simDevice 模拟 BL602 的 Boot ROM 和 eflash_loader，通过伪终端主端与烧录器通信
+type simDevice struct {
+	rw       io.ReadWriter
+	bootInfo []byte
+	flash    []byte
+
+	// 故障注入：不应答握手；写入时翻转一位
+	silent  bool
+	corrupt bool
+
+	mu       sync.Mutex
+	loader   bool
+	segLen   int
+	segment  []byte
+	commands []byte
+	resets   int
+}
+
+func newSimDevice(rw io.ReadWriter, flashSize int) *simDevice {
+	info := make([]byte, 20)
+	binary.LittleEndian.PutUint32(info, 1)
+	// eFuse 中的 MAC（小端存放）
+	copy(info[12:18], []byte{0x66, 0x55, 0x44, 0x33, 0x22, 0x11})
+	flash := make([]byte, flashSize)
+	for i := range flash {
+		flash[i] = 0xFF
+	}
+	return &simDevice{rw: rw, bootInfo: info, flash: flash}
+}
+
+// run 处理命令，直到伪终端关闭
+func (d *simDevice) run() {
+	r := bufio.NewReader(d.rw)
+	handshaken, draining, count := false, false, 0
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		if !handshaken || (draining && b == handshakeByte) {
+			if b != handshakeByte {
+				count = 0
+				continue
+			}
+			if count++; count >= 8 && !handshaken && !d.silent {
+				d.rw.Write([]byte(statusOK))
+				handshaken, draining = true, true
+			}
+			continue
+		}
+		draining = false
+
+		header := make([]byte, 3)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return
+		}
+		data := make([]byte, int(binary.LittleEndian.Uint16(header[1:])))
+		if _, err := io.ReadFull(r, data); err != nil {
+			return
+		}
+		d.mu.Lock()
+		d.commands = append(d.commands, b)
+		d.mu.Unlock()
+		if checksum(append(header[1:], data...)) != header[0] {
+			d.fail(simErrChecksum)
+			continue
+		}
+		if d.handle(b, data) {
+			// eflash_loader 启动或芯片复位后需要重新握手
+			handshaken, count = false, 0
+		}
+	}
+}
+
+// handle 执行命令，返回 true 表示需要重新握手
+func (d *simDevice) handle(cmd byte, data []byte) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch {
+	case cmd == cmdGetBootInfo:
+		d.ok(d.bootInfo)
+	case cmd == cmdLoadBootHeader && !d.loader && len(data) == BootHeaderSize:
+		d.ok(nil)
+	case cmd == cmdLoadSegmentHeader && !d.loader && len(data) == SegmentHeaderSize:
+		d.segLen, d.segment = segmentLength(data), nil
+		d.ok(data)
+	case cmd == cmdLoadSegmentData && !d.loader:
+		d.segment = append(d.segment, data...)
+		d.ok(nil)
+	case cmd == cmdCheckImage && !d.loader:
+		if len(d.segment) != d.segLen {
+			d.fail(simErrImage)
+			break
+		}
+		d.ok(nil)
+	case cmd == cmdRunImage && !d.loader:
+		d.ok(nil)
+		d.loader = true
+		return true
+	case cmd == cmdFlashErase && d.loader:
+		start := binary.LittleEndian.Uint32(data) / SectorSize * SectorSize
+		end := (binary.LittleEndian.Uint32(data[4:])/SectorSize + 1) * SectorSize
+		for i := start; i < end; i++ {
+			d.flash[i] = 0xFF
+		}
+		d.rw.Write([]byte(statusPending))
+		d.ok(nil)
+	case cmd == cmdFlashWrite && d.loader:
+		addr := binary.LittleEndian.Uint32(data)
+		for i, b := range data[4:] {
+			// NOR Flash 只能把 1 写成 0
+			d.flash[int(addr)+i] &= b
+		}
+		if d.corrupt {
+			d.flash[addr] ^= 0x01
+		}
+		d.ok(nil)
+	case cmd == cmdFlashWriteCheck && d.loader:
+		d.ok(nil)
+	case cmd == cmdFlashReadSHA && d.loader:
+		addr := binary.LittleEndian.Uint32(data)
+		n := binary.LittleEndian.Uint32(data[4:])
+		digest := sha256.Sum256(d.flash[addr : addr+n])
+		d.ok(digest[:])
+	case cmd == cmdReset && d.loader:
+		d.ok(nil)
+		d.loader = false
+		d.resets++
+		return true
+	default:
+		d.fail(simErrCommand)
+	}
+	return false
+}
+
+// ok 回复 OK，data 不为 nil 时附带长度和数据
+func (d *simDevice) ok(data []byte) {
+	reply := []byte(statusOK)
+	if data != nil {
+		reply = binary.LittleEndian.AppendUint16(reply, uint16(len(data)))
+		reply = append(reply, data...)
+	}
+	d.rw.Write(reply)
+}
+
+// fail 回复错误码
+func (d *simDevice) fail(code uint16) {
+	d.rw.Write(binary.LittleEndian.AppendUint16([]byte(statusFail), code))
+}
+
+// testLoader 生成 eflash_loader 镜像：启动头、段头和 n 字节段数据
+func testLoader(n int) []byte {
+	loader := make([]byte, BootHeaderSize+SegmentHeaderSize+n)
+	copy(loader, "BFNP")
+	copy(loader[flashCfgOffset:], flashCfgMagic)
+	copy(loader[clkCfgOffset:], clkCfgMagic)
+	for i := flashCfgOffset + 4; i < bootCfgOffset; i++ {
+		if i < clkCfgOffset || i >= clkCfgOffset+4 {
+			loader[i] = byte(i)
+		}
+	}
+	binary.LittleEndian.PutUint32(loader[BootHeaderSize:], 0x22010000)
+	binary.LittleEndian.PutUint32(loader[BootHeaderSize+4:], uint32(n))
+	for i := range n {
+		loader[BootHeaderSize+SegmentHeaderSize+i] = byte(i)
+	}
+	return loader
+}
@@ -0,0 +1,368 @@
+package flasher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"wb2-cli/internal/serial"
+)
+
+// ROMBaud 与 Boot ROM 通信并加载 eflash_loader 的波特率
+const ROMBaud = 500000
+
+// SectorSize Flash 擦除的最小单位，镜像地址需要按此对齐
+const SectorSize = 0x1000
+
+// LoaderPath 返回 SDK 中与晶振频率对应的 eflash_loader 镜像路径
+func LoaderPath(sdkPath string, crystal int) string {
+	return filepath.Join(sdkPath, "tools", "flash_tool", "chips", "bl602", "eflash_loader",
+		fmt.Sprintf("eflash_loader_%dm.bin", crystal))
+}
+
+// 每条命令携带的数据量
+const (
+	loaderChunkSize = 4080
+	writeChunkSize  = 4096
+)
+
+// 超时
+var (
+	// 每次握手等待应答的时间
+	handshakeTimeout  = 100 * time.Millisecond
+	handshakeAttempts = 5
+	// eflash_loader 启动需要的时间
+	loaderStartDelay = 100 * time.Millisecond
+	commandTimeout   = 2 * time.Second
+	// 擦除期间设备定期回复 PD，每次等待的时间
+	eraseTimeout = 10 * time.Second
+)
+
+// 进度阶段
+const (
+	StageErase  = "擦除"
+	StageWrite  = "写入"
+	StageVerify = "校验"
+)
+
+// Progress 烧录进度
+type Progress struct {
+	Stage string
+	Image string
+	Done  int
+	Total int
+}
+
+// Image 需要写入 Flash 的镜像
+type Image struct {
+	Name    string
+	Address uint32
+	Data    []byte
+}
+
+// End 返回镜像结束地址（不含）
+func (img Image) End() uint32 {
+	return img.Address + uint32(len(img.Data))
+}
+
+// Options 烧录选项
+type Options struct {
+	// eflash_loader 阶段的波特率
+	Baud int
+	// eflash_loader 镜像（SDK 中的 eflash_loader_<晶振>m.bin）
+	Loader []byte
+	// 写入后是否读取 SHA-256 校验
+	Verify bool
+	// 进度回调，可以为 nil
+	Progress func(Progress)
+}
+
+// Flasher 通过串口烧录 BL602
+type Flasher struct {
+	port serial.Port
+	opts Options
+}
+
+// New 创建烧录器
+func New(port serial.Port, opts Options) *Flasher {
+	return &Flasher{port: port, opts: opts}
+}
+
+// ValidateImages 检查镜像地址是否按扇区对齐、是否重叠
+func ValidateImages(images []Image) error {
+	sorted := append([]Image{}, images...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Address < sorted[j].Address })
+	for i, img := range sorted {
+		if len(img.Data) == 0 {
+			return fmt.Errorf("镜像 %s 为空", img.Name)
+		}
+		if img.Address%SectorSize != 0 {
+			return fmt.Errorf("镜像 %s 的地址 0x%06X 没有按 4K 对齐", img.Name, img.Address)
+		}
+		if i > 0 && sorted[i-1].End() > img.Address {
+			return fmt.Errorf("镜像 %s 和 %s 的地址范围重叠", sorted[i-1].Name, img.Name)
+		}
+	}
+	return nil
+}
+
+// EnterBootloader 通过 DTR/RTS 复位进入下载模式：DTR 拉高 BOOT（IO8），RTS 控制 EN
+func (f *Flasher) EnterBootloader() error {
+	steps := []func() error{
+		func() error { return f.port.SetDTR(true) },
+		func() error { return f.port.SetRTS(true) },
+		func() error { time.Sleep(50 * time.Millisecond); return f.port.SetRTS(false) },
+		func() error { time.Sleep(100 * time.Millisecond); return f.port.SetDTR(false) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return fmt.Errorf("设置 DTR/RTS 失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// ResetRun 通过 RTS 复位芯片，运行 Flash 中的程序（DTR 保持低电平，不进入下载模式）
+func ResetRun(port serial.Port) error {
+	if err := port.SetDTR(false); err != nil {
+		return fmt.Errorf("设置 DTR 失败: %v", err)
+	}
+	if err := port.SetRTS(true); err != nil {
+		return fmt.Errorf("设置 RTS 失败: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := port.SetRTS(false); err != nil {
+		return fmt.Errorf("设置 RTS 失败: %v", err)
+	}
+	return nil
+}
+
+// Connect 与 Boot ROM 握手，读取启动信息，加载并启动 eflash_loader
+func (f *Flasher) Connect() (*BootInfo, error) {
+	if err := f.port.SetBaud(ROMBaud); err != nil {
+		return nil, err
+	}
+	if err := f.handshake(ROMBaud); err != nil {
+		return nil, fmt.Errorf("无法与 Boot ROM 握手，请确认模组已进入下载模式（按住 BOOT 键再按一下 EN 键）: %w", err)
+	}
+	data, err := f.command(cmdGetBootInfo, nil, true, commandTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("读取启动信息失败: %v", err)
+	}
+	info, err := parseBootInfo(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.loadLoader(); err != nil {
+		return nil, fmt.Errorf("加载 eflash_loader 失败: %v", err)
+	}
+	time.Sleep(loaderStartDelay)
+	if err := f.port.SetBaud(f.opts.Baud); err != nil {
+		return nil, err
+	}
+	if err := f.handshake(f.opts.Baud); err != nil {
+		return nil, fmt.Errorf("无法与 eflash_loader 握手（波特率 %d），可以尝试降低波特率: %w", f.opts.Baud, err)
+	}
+	return info, nil
+}
+
+// loadLoader 将 eflash_loader 加载到 RAM 并运行
+func (f *Flasher) loadLoader() error {
+	loader := f.opts.Loader
+	if len(loader) < BootHeaderSize+SegmentHeaderSize {
+		return fmt.Errorf("eflash_loader 镜像只有 %d 字节", len(loader))
+	}
+	segHeader := loader[BootHeaderSize : BootHeaderSize+SegmentHeaderSize]
+	segment := loader[BootHeaderSize+SegmentHeaderSize:]
+	n := segmentLength(segHeader)
+	if n <= 0 || n > len(segment) {
+		return fmt.Errorf("eflash_loader 镜像的段长度 %d 无效", n)
+	}
+	segment = segment[:n]
+
+	if _, err := f.command(cmdLoadBootHeader, loader[:BootHeaderSize], false, commandTimeout); err != nil {
+		return err
+	}
+	if _, err := f.command(cmdLoadSegmentHeader, segHeader, true, commandTimeout); err != nil {
+		return err
+	}
+	for off := 0; off < len(segment); off += loaderChunkSize {
+		end := min(off+loaderChunkSize, len(segment))
+		if _, err := f.command(cmdLoadSegmentData, segment[off:end], false, commandTimeout); err != nil {
+			return err
+		}
+	}
+	if _, err := f.command(cmdCheckImage, nil, false, commandTimeout); err != nil {
+		return err
+	}
+	_, err := f.command(cmdRunImage, nil, false, commandTimeout)
+	return err
+}
+
+// Flash 依次擦除、写入和校验镜像
+func (f *Flasher) Flash(images []Image) error {
+	if err := ValidateImages(images); err != nil {
+		return err
+	}
+	for _, img := range images {
+		total := len(img.Data)
+		f.progress(StageErase, img.Name, 0, total)
+		if err := f.Erase(img.Address, uint32(total)); err != nil {
+			return fmt.Errorf("擦除 %s 失败: %v", img.Name, err)
+		}
+		if err := f.Write(img.Address, img.Data, func(done int) {
+			f.progress(StageWrite, img.Name, done, total)
+		}); err != nil {
+			return fmt.Errorf("写入 %s 失败: %v", img.Name, err)
+		}
+		if _, err := f.command(cmdFlashWriteCheck, nil, false, commandTimeout); err != nil {
+			return fmt.Errorf("写入 %s 失败: %v", img.Name, err)
+		}
+		if f.opts.Verify {
+			f.progress(StageVerify, img.Name, 0, total)
+			if err := f.Verify(img.Address, img.Data); err != nil {
+				return fmt.Errorf("校验 %s 失败: %v", img.Name, err)
+			}
+			f.progress(StageVerify, img.Name, total, total)
+		}
+	}
+	return nil
+}
+
+// Erase 擦除 [addr, addr+size) 所在的扇区
+func (f *Flasher) Erase(addr, size uint32) error {
+	_, err := f.command(cmdFlashErase, addressRange(addr, addr+size-1), false, eraseTimeout)
+	return err
+}
+
+// Write 分块写入数据，每块写入后回调已写入的字节数
+func (f *Flasher) Write(addr uint32, data []byte, done func(int)) error {
+	for off := 0; off < len(data); off += writeChunkSize {
+		end := min(off+writeChunkSize, len(data))
+		payload := make([]byte, 4+end-off)
+		binary.LittleEndian.PutUint32(payload, addr+uint32(off))
+		copy(payload[4:], data[off:end])
+		if _, err := f.command(cmdFlashWrite, payload, false, commandTimeout); err != nil {
+			return fmt.Errorf("地址 0x%06X: %v", addr+uint32(off), err)
+		}
+		if done != nil {
+			done(end)
+		}
+	}
+	return nil
+}
+
+// Verify 读取 Flash 中数据的 SHA-256 并与 data 比较
+func (f *Flasher) Verify(addr uint32, data []byte) error {
+	digest, err := f.command(cmdFlashReadSHA, addressRange(addr, uint32(len(data))), true, eraseTimeout)
+	if err != nil {
+		return err
+	}
+	want := sha256.Sum256(data)
+	if !bytes.Equal(digest, want[:]) {
+		return fmt.Errorf("SHA-256 不一致（设备 %x，本地 %x）", digest, want)
+	}
+	return nil
+}
+
+// Reset 让 eflash_loader 复位芯片
+func (f *Flasher) Reset() error {
+	_, err := f.command(cmdReset, nil, false, commandTimeout)
+	return err
+}
+
+// progress 报告进度
+func (f *Flasher) progress(stage, image string, done, total int) {
+	if f.opts.Progress != nil {
+		f.opts.Progress(Progress{Stage: stage, Image: image, Done: done, Total: total})
+	}
+}
+
+// handshake 发送约 5ms 的 0x55 供设备识别波特率，等待 OK
+func (f *Flasher) handshake(baud int) error {
+	burst := bytes.Repeat([]byte{handshakeByte}, max(baud/10*5/1000, 16))
+	var err error
+	for i := 0; i < handshakeAttempts; i++ {
+		if err = f.port.ResetInput(); err != nil {
+			return err
+		}
+		if _, err = f.port.Write(burst); err != nil {
+			return err
+		}
+		var status []byte
+		status, err = f.read(2, handshakeTimeout)
+		if err == nil && string(status) == statusOK {
+			return nil
+		}
+		if err != nil && !errors.Is(err, serial.ErrTimeout) {
+			return err
+		}
+	}
+	if err == nil {
+		err = errors.New("应答无效")
+	}
+	return err
+}
+
+// command 发送命令并读取应答；withData 为 true 时读取应答中的数据
+func (f *Flasher) command(cmd byte, data []byte, withData bool, timeout time.Duration) ([]byte, error) {
+	if _, err := f.port.Write(encodeCommand(cmd, data)); err != nil {
+		return nil, err
+	}
+	for {
+		status, err := f.read(2, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("等待命令 0x%02X 的应答: %w", cmd, err)
+		}
+		switch string(status) {
+		case statusOK:
+			if !withData {
+				return nil, nil
+			}
+			header, err := f.read(2, timeout)
+			if err != nil {
+				return nil, fmt.Errorf("读取命令 0x%02X 的应答: %w", cmd, err)
+			}
+			n := int(header[0]) | int(header[1])<<8
+			return f.read(n, timeout)
+		case statusPending:
+			// 擦除等耗时操作进行中
+			continue
+		case statusFail:
+			code, err := f.read(2, timeout)
+			if err != nil {
+				return nil, fmt.Errorf("读取命令 0x%02X 的错误码: %w", cmd, err)
+			}
+			return nil, &DeviceError{Command: cmd, Code: uint16(code[0]) | uint16(code[1])<<8}
+		default:
+			return nil, fmt.Errorf("命令 0x%02X 的应答无效: % X", cmd, status)
+		}
+	}
+}
+
+// read 在超时前读取 n 个字节
+func (f *Flasher) read(n int, timeout time.Duration) ([]byte, error) {
+	buf := make([]byte, n)
+	deadline := time.Now().Add(timeout)
+	for got := 0; got < n; {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, serial.ErrTimeout
+		}
+		if err := f.port.SetReadTimeout(remaining); err != nil {
+			return nil, err
+		}
+		m, err := f.port.Read(buf[got:])
+		got += m
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
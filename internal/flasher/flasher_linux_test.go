@@ -0,0 +1,158 @@
+package flasher
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"wb2-cli/internal/serial"
+)
+
+// startSimDevice 在伪终端上启动模拟设备，返回连接到从端的烧录器
+func startSimDevice(t *testing.T, setup func(*simDevice)) (*simDevice, *Flasher) {
+	t.Helper()
+	master, path, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("伪终端不可用: %v", err)
+	}
+	port, err := serial.Open(path, ROMBaud)
+	if err != nil {
+		master.Close()
+		t.Fatalf("serial.Open() error = %v", err)
+	}
+
+	device := newSimDevice(master, 2*1024*1024)
+	if setup != nil {
+		setup(device)
+	}
+	done := make(chan struct{})
+	go func() {
+		device.run()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		port.Close()
+		master.Close()
+		<-done
+	})
+
+	f := New(port, Options{Baud: 2000000, Loader: testLoader(10000), Verify: true})
+	return device, f
+}
+
+func testData(n int, seed int64) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func TestFlash(t *testing.T) {
+	device, f := startSimDevice(t, nil)
+	var progress []Progress
+	f.opts.Progress = func(p Progress) { progress = append(progress, p) }
+
+	info, err := f.Connect()
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if info.ROMVersion != 1 || info.ChipID() != "112233445566" {
+		t.Errorf("boot info = %+v, chip id %s", info, info.ChipID())
+	}
+
+	images := []Image{
+		{Name: "partition", Address: 0xE000, Data: testData(0x400, 1)},
+		{Name: "firmware", Address: 0x10000, Data: testData(3*writeChunkSize+123, 2)},
+	}
+	if err := f.Flash(images); err != nil {
+		t.Fatalf("Flash() error = %v", err)
+	}
+	if err := f.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	device.mu.Lock()
+	defer device.mu.Unlock()
+	for _, img := range images {
+		if !bytes.Equal(device.flash[img.Address:img.End()], img.Data) {
+			t.Errorf("flash content of %s differs", img.Name)
+		}
+	}
+	if device.flash[images[1].End()] != 0xFF {
+		t.Errorf("flash after firmware = 0x%02X, want erased", device.flash[images[1].End()])
+	}
+	if device.resets != 1 {
+		t.Errorf("resets = %d, want 1", device.resets)
+	}
+
+	last := progress[len(progress)-1]
+	if last.Stage != StageVerify || last.Image != "firmware" || last.Done != last.Total {
+		t.Errorf("last progress = %+v", last)
+	}
+	writes := 0
+	for _, p := range progress {
+		if p.Stage == StageWrite && p.Image == "firmware" {
+			writes++
+		}
+	}
+	if writes != 4 {
+		t.Errorf("firmware write progress reported %d times, want 4", writes)
+	}
+}
+
+func TestFlashVerifyMismatch(t *testing.T) {
+	_, f := startSimDevice(t, func(d *simDevice) { d.corrupt = true })
+	if _, err := f.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	err := f.Flash([]Image{{Name: "firmware", Address: 0x10000, Data: testData(100, 3)}})
+	if err == nil || !strings.Contains(err.Error(), "校验 firmware 失败: SHA-256 不一致") {
+		t.Errorf("Flash() error = %v, want verify error", err)
+	}
+}
+
+func TestCommandChecksumRejected(t *testing.T) {
+	_, f := startSimDevice(t, nil)
+	if _, err := f.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	packet := encodeCommand(cmdFlashWriteCheck, []byte{1, 2, 3})
+	packet[1]++
+	f.port.Write(packet)
+	_, err := f.command(cmdFlashWriteCheck, nil, false, commandTimeout)
+	// 第一个应答对应校验和错误的命令
+	var devErr *DeviceError
+	if !errors.As(err, &devErr) || devErr.Code != simErrChecksum {
+		t.Fatalf("command() error = %v, want checksum error", err)
+	}
+}
+
+func TestConnectNoResponse(t *testing.T) {
+	_, f := startSimDevice(t, func(d *simDevice) { d.silent = true })
+
+	start := time.Now()
+	_, err := f.Connect()
+	if err == nil || !strings.Contains(err.Error(), "无法与 Boot ROM 握手") {
+		t.Errorf("Connect() error = %v, want handshake error", err)
+	}
+	if !errors.Is(err, serial.ErrTimeout) {
+		t.Errorf("Connect() error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Connect() took %v", elapsed)
+	}
+}
+
+func TestConnectBadLoader(t *testing.T) {
+	_, f := startSimDevice(t, nil)
+	loader := testLoader(100)
+	// 段头中的长度超过实际数据
+	loader[BootHeaderSize+4] = 0xFF
+	f.opts.Loader = loader
+	if _, err := f.Connect(); err == nil || !strings.Contains(err.Error(), "段长度") {
+		t.Errorf("Connect() error = %v, want segment length error", err)
+	}
+}
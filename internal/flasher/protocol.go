@@ -0,0 +1,109 @@
+// Package flasher 实现 BL602 的 UART 烧录协议：与 Boot ROM 握手并加载
+// eflash_loader 到 RAM，再通过 eflash_loader 擦除、写入和校验 Flash
+package flasher
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// 命令：0x1x 由 Boot ROM 处理，0x2x、0x3x 由 eflash_loader 处理
+const (
+	cmdGetBootInfo       = 0x10
+	cmdLoadBootHeader    = 0x11
+	cmdLoadSegmentHeader = 0x17
+	cmdLoadSegmentData   = 0x18
+	cmdCheckImage        = 0x19
+	cmdRunImage          = 0x1A
+	cmdReset             = 0x21
+	cmdFlashErase        = 0x30
+	cmdFlashWrite        = 0x31
+	cmdFlashWriteCheck   = 0x3A
+	cmdFlashReadSHA      = 0x3D
+)
+
+// 应答状态
+const (
+	statusOK      = "OK"
+	statusFail    = "FL"
+	statusPending = "PD"
+)
+
+// handshakeByte 握手时连续发送的字节，设备据此识别波特率
+const handshakeByte = 0x55
+
+// 镜像格式：eflash_loader 镜像由启动头、段头和段数据组成
+const (
+	BootHeaderSize    = 176
+	SegmentHeaderSize = 16
+)
+
+// encodeCommand 编码命令：命令字、校验和、数据长度（小端）和数据。
+// 校验和为长度和数据所有字节之和的低 8 位
+func encodeCommand(cmd byte, data []byte) []byte {
+	packet := make([]byte, 4+len(data))
+	packet[0] = cmd
+	binary.LittleEndian.PutUint16(packet[2:], uint16(len(data)))
+	copy(packet[4:], data)
+	packet[1] = checksum(packet[2:])
+	return packet
+}
+
+// checksum 计算字节之和的低 8 位
+func checksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return sum
+}
+
+// DeviceError 设备返回的错误码
+type DeviceError struct {
+	Command byte
+	Code    uint16
+}
+
+func (e *DeviceError) Error() string {
+	return fmt.Sprintf("设备执行命令 0x%02X 失败，错误码 0x%04X", e.Command, e.Code)
+}
+
+// BootInfo Boot ROM 返回的启动信息
+type BootInfo struct {
+	ROMVersion uint32
+	// eFuse 中的配置信息
+	OTP [16]byte
+}
+
+// parseBootInfo 解析启动信息：4 字节 ROM 版本和 16 字节 eFuse 信息
+func parseBootInfo(data []byte) (*BootInfo, error) {
+	if len(data) < 20 {
+		return nil, fmt.Errorf("启动信息长度为 %d 字节，至少应为 20 字节", len(data))
+	}
+	info := &BootInfo{ROMVersion: binary.LittleEndian.Uint32(data)}
+	copy(info.OTP[:], data[4:20])
+	return info, nil
+}
+
+// ChipID 返回芯片 ID，即 eFuse 中烧录的 MAC 地址
+func (b *BootInfo) ChipID() string {
+	id := make([]byte, 6)
+	for i := range id {
+		id[i] = b.OTP[13-i]
+	}
+	return hex.EncodeToString(id)
+}
+
+// segmentLength 返回 eflash_loader 镜像段头中的段数据长度
+func segmentLength(header []byte) int {
+	return int(binary.LittleEndian.Uint32(header[4:8]))
+}
+
+// addressRange 编码地址和长度参数
+func addressRange(a, b uint32) []byte {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint32(data, a)
+	binary.LittleEndian.PutUint32(data[4:], b)
+	return data
+}
@@ -0,0 +1,61 @@
+package flasher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodeCommand(t *testing.T) {
+	got := encodeCommand(cmdFlashErase, []byte{0x00, 0x00, 0x01, 0x00, 0xFF, 0x0F, 0x01, 0x00})
+	want := []byte{0x30, 0x18, 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0xFF, 0x0F, 0x01, 0x00}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeCommand() = % X, want % X", got, want)
+	}
+	if got := encodeCommand(cmdGetBootInfo, nil); !bytes.Equal(got, []byte{0x10, 0x00, 0x00, 0x00}) {
+		t.Errorf("encodeCommand(no data) = % X", got)
+	}
+}
+
+func TestParseBootInfo(t *testing.T) {
+	data := make([]byte, 20)
+	data[0] = 1
+	copy(data[12:18], []byte{0x9d, 0x2c, 0x61, 0x4f, 0x0c, 0xb4})
+	info, err := parseBootInfo(data)
+	if err != nil {
+		t.Fatalf("parseBootInfo() error = %v", err)
+	}
+	if info.ROMVersion != 1 || info.ChipID() != "b40c4f612c9d" {
+		t.Errorf("ROMVersion = %d, ChipID() = %s", info.ROMVersion, info.ChipID())
+	}
+	if _, err := parseBootInfo(data[:10]); err == nil {
+		t.Error("parseBootInfo(short) error = nil, want error")
+	}
+}
+
+func TestValidateImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		images  []Image
+		wantErr string
+	}{
+		{"ok", []Image{{Name: "a", Address: 0x10000, Data: make([]byte, 0x1000)}, {Name: "b", Address: 0x11000, Data: []byte{1}}}, ""},
+		{"unaligned", []Image{{Name: "a", Address: 0x10010, Data: []byte{1}}}, "没有按 4K 对齐"},
+		{"overlap", []Image{{Name: "b", Address: 0x11000, Data: []byte{1}}, {Name: "a", Address: 0x10000, Data: make([]byte, 0x1001)}}, "a 和 b 的地址范围重叠"},
+		{"empty", []Image{{Name: "a", Address: 0x10000}}, "为空"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateImages(tt.images)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateImages() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateImages() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
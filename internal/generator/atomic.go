@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// writeProjectAtomic 在 projectPath 的同级临时目录中生成项目，成功后重命名到位
+func (g *Generator) writeProjectAtomic(projectPath string, data *ProjectData) error {
+	if _, err := os.Stat(projectPath); err == nil {
+		return fmt.Errorf("项目目录已存在: %s", projectPath)
+	}
+
+	parent := filepath.Dir(projectPath)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("创建上级目录失败: %v", err)
+	}
+
+	// 临时目录与目标位于同一目录下，保证重命名在同一文件系统内完成
+	stagingDir, err := os.MkdirTemp(parent, "."+filepath.Base(projectPath)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	// MkdirTemp 创建的目录权限为 0700，改为直接创建目录时的权限（0777 去掉 umask）
+	mode, err := newDirMode(stagingDir)
+	if err == nil {
+		err = os.Chmod(stagingDir, mode)
+	}
+	if err != nil {
+		os.RemoveAll(stagingDir)
+		return fmt.Errorf("设置临时目录权限失败: %v", err)
+	}
+
+	if err := g.writeProject(stagingDir, data); err != nil {
+		if g.keepOnError {
+			return fmt.Errorf("%v（未完成的输出已保留在 %s）", err, stagingDir)
+		}
+		os.RemoveAll(stagingDir)
+		return err
+	}
+
+	if err := os.Rename(stagingDir, projectPath); err != nil {
+		if !g.keepOnError {
+			os.RemoveAll(stagingDir)
+		}
+		return fmt.Errorf("移动项目目录失败: %v", err)
+	}
+
+	return nil
+}
+
+// newDirMode 返回在 dir 中用 os.Mkdir(0777) 新建的目录的权限，即受 umask 影响后的默认权限
+func newDirMode(dir string) (os.FileMode, error) {
+	probe := filepath.Join(dir, ".mode")
+	if err := os.Mkdir(probe, 0777); err != nil {
+		return 0, err
+	}
+	defer os.Remove(probe)
+	info, err := os.Stat(probe)
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode().Perm(), nil
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stagingDirs 返回 parent 下遗留的临时目录
+func stagingDirs(t *testing.T, parent string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", parent, err)
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	return dirs
+}
+
+func TestGenerateProjectAtomic(t *testing.T) {
+	chdirRepoRoot(t)
+
+	parent := t.TempDir()
+	projectDir := filepath.Join(parent, "demo")
+
+	gen := New(t.TempDir())
+	if err := gen.GenerateProject("demo", projectDir, nil); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(projectDir, "demo", "main.c"))
+	if err != nil {
+		t.Fatalf("Expected main.c to exist: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Error("Expected main.c to have content")
+	}
+
+	// 项目目录的权限与直接创建的目录相同（受 umask 影响）
+	reference := filepath.Join(parent, "reference")
+	if err := os.Mkdir(reference, 0777); err != nil {
+		t.Fatal(err)
+	}
+	refInfo, _ := os.Stat(reference)
+	dirInfo, _ := os.Stat(projectDir)
+	if dirInfo.Mode().Perm() != refInfo.Mode().Perm() {
+		t.Errorf("Expected project directory mode %v, got %v", refInfo.Mode().Perm(), dirInfo.Mode().Perm())
+	}
+	os.Remove(reference)
+
+	if dirs := stagingDirs(t, parent); len(dirs) != 0 {
+		t.Errorf("Expected no staging directories, got %v", dirs)
+	}
+
+	// 目录已存在时拒绝覆盖
+	if err := gen.GenerateProject("demo", projectDir, nil); err == nil {
+		t.Error("Expected error when project directory already exists")
+	}
+}
+
+func TestGenerateProjectRollback(t *testing.T) {
+	// 不切换到仓库根目录，模板查找会失败，模拟生成中途出错
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(t.TempDir())
+
+	parent := t.TempDir()
+	projectDir := filepath.Join(parent, "demo")
+
+	gen := New(t.TempDir())
+	if err := gen.GenerateProject("demo", projectDir, nil); err == nil {
+		t.Fatal("Expected GenerateProject to fail without templates")
+	}
+
+	if _, err := os.Stat(projectDir); !os.IsNotExist(err) {
+		t.Error("Expected project directory not to exist after failure")
+	}
+	if dirs := stagingDirs(t, parent); len(dirs) != 0 {
+		t.Errorf("Expected staging directory to be cleaned up, got %v", dirs)
+	}
+
+	// keep-on-error 保留未完成的输出
+	gen.SetKeepOnError(true)
+	err := gen.GenerateProject("demo", projectDir, nil)
+	if err == nil {
+		t.Fatal("Expected GenerateProject to fail without templates")
+	}
+	dirs := stagingDirs(t, parent)
+	if len(dirs) != 1 {
+		t.Fatalf("Expected one preserved staging directory, got %v", dirs)
+	}
+	if !strings.Contains(err.Error(), dirs[0]) {
+		t.Errorf("Expected error to mention preserved directory %s, got: %v", dirs[0], err)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestGenerateProjectAtomicUmask(t *testing.T) {
+	chdirRepoRoot(t)
+
+	old := syscall.Umask(027)
+	t.Cleanup(func() { syscall.Umask(old) })
+
+	projectDir := filepath.Join(t.TempDir(), "demo")
+	if err := New(t.TempDir()).GenerateProject("demo", projectDir, nil); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+	info, err := os.Stat(projectDir)
+	if err != nil {
+		t.Fatalf("Expected project directory: %v", err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("Expected project directory mode 0750 with umask 027, got %v", info.Mode().Perm())
+	}
+}
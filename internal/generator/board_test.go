@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+)
+
+func TestDefaultBoardMatchesCatalog(t *testing.T) {
+	chdirRepoRoot(t)
+
+	boards, err := config.LoadBoards()
+	if err != nil {
+		t.Fatalf("LoadBoards failed: %v", err)
+	}
+	board, ok := config.FindBoard(boards, "")
+	if !ok {
+		t.Fatal("Expected catalog to have a default board")
+	}
+
+	// 只比较硬件信息
+	board.Description = ""
+	board.Default = false
+	if !reflect.DeepEqual(*board, DefaultBoard) {
+		t.Errorf("DefaultBoard does not match catalog default:\n%+v\n%+v", DefaultBoard, *board)
+	}
+}
+
+func TestGenerateProjectBoard(t *testing.T) {
+	chdirRepoRoot(t)
+
+	projectDir := filepath.Join(t.TempDir(), "demo")
+	gpio := config.Component{Name: "gpio", Pins: []config.PinRequest{
+		{Name: "GPIO_LED", Function: config.PinGPIO, Board: "led"},
+		{Name: "GPIO_BUTTON", Function: config.PinGPIO, Board: "button"},
+	}}
+
+	// 默认模组：LED 和按键使用板载引脚
+	out := NewMemoryOutput()
+	gen := New(t.TempDir())
+	gen.SetOutput(out)
+	if err := gen.GenerateProject("demo", projectDir, []config.Component{gpio}); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	expectContains := func(rel string, wants ...string) {
+		t.Helper()
+		content := string(out.Content(filepath.Join(projectDir, rel)))
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", rel, want, content)
+			}
+		}
+	}
+
+	expectContains("Makefile", "PROJECT_BOARD := evb")
+	expectContains("proj_config.mk", "CONFIG_BOARD_FLASH_SIZE := 2")
+	expectContains("demo/include/main_board.h", `#define BOARD_NAME            "Ai-WB2-12F"`, "#define BOARD_LED_PIN         14", "#define BOARD_BUTTON_PIN      8", "IO0 IO1 IO2")
+	expectContains("demo/include/main_board.h", "#define GPIO_LED_PIN          14 /* gpio: gpio，板载 */", "#define GPIO_BUTTON_PIN       8")
+	expectContains("demo/main.c", `#include "main_board.h"`, "bl_gpio_enable_output(GPIO_LED_PIN, 0, 0);")
+	expectContains("wb2.yaml", "board: ai-wb2-12f", "GPIO_LED: 14")
+
+	// 没有板载 LED 的模组
+	out = NewMemoryOutput()
+	gen.SetOutput(out)
+	gen.SetBoard(config.Board{Name: "ai-wb2-01s", Module: "Ai-WB2-01S", SDKBoard: "evb", FlashSize: 4, Crystal: 40, Pins: []int{3, 7, 14, 16}, UARTTxPin: 16, UARTRxPin: 7})
+	if err := gen.GenerateProject("demo", projectDir, []config.Component{gpio}); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	expectContains("proj_config.mk", "CONFIG_BOARD_FLASH_SIZE := 4")
+	// 不占用 UART0（IO16/IO7），自动分配
+	expectContains("demo/include/main_board.h", "#define GPIO_LED_PIN          3  /* gpio: gpio，自动 */", "#define GPIO_BUTTON_PIN       14")
+	header := string(out.Content(filepath.Join(projectDir, "demo/include/main_board.h")))
+	if strings.Contains(header, "BOARD_LED_PIN") {
+		t.Errorf("Expected no LED define for board without LED, got:\n%s", header)
+	}
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffIdentical(t *testing.T) {
+	if diff := UnifiedDiff("a", "b", "same\n", "same\n"); diff != "" {
+		t.Errorf("Expected empty diff for identical text, got:\n%s", diff)
+	}
+}
+
+func TestUnifiedDiffChange(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	newText := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n"
+
+	diff := UnifiedDiff("a/file", "b/file", oldText, newText)
+	expected := `--- a/file
++++ b/file
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`
+	if diff != expected {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", diff, expected)
+	}
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	diff := UnifiedDiff("/dev/null", "b/new", "", "x\ny\n")
+	if !strings.Contains(diff, "@@ -0,0 +1,2 @@") {
+		t.Errorf("Expected new-file hunk header, got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "+x\n+y\n") {
+		t.Errorf("Expected added lines, got:\n%s", diff)
+	}
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 0; i < 30; i++ {
+		line := string(rune('a' + i%26))
+		oldLines = append(oldLines, line)
+		newLines = append(newLines, line)
+	}
+	newLines[1] = "X"
+	newLines[25] = "Y"
+
+	diff := UnifiedDiff("a", "b", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+	if strings.Count(diff, "@@ -") != 2 {
+		t.Errorf("Expected 2 hunks, got:\n%s", diff)
+	}
+}
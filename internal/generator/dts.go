@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DTSFile 生成的项目设备树（位于项目根目录）
+const DTSFile = "board.dts"
+
+// dtsRadioNodes 从 SDK 默认设备树中原样保留的射频校准节点
+var dtsRadioNodes = []string{"wifi", "bluetooth"}
+
+// sdkDTSPath 返回 SDK 中与晶振频率对应的默认设备树
+func sdkDTSPath(sdkPath string, crystal int) string {
+	return filepath.Join(sdkPath, "tools", "flash_tool", "chips", "bl602", "device_tree",
+		fmt.Sprintf("bl_factory_params_IoTKitA_%dM.dts", crystal))
+}
+
+// loadDTSRadio 从 SDK 默认设备树中取出 wifi、bluetooth 节点
+func (g *Generator) loadDTSRadio() (string, error) {
+	path := sdkDTSPath(g.sdkPath, g.board.Crystal)
+	src, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("找不到 SDK 默认设备树 %s", path)
+		}
+		return "", fmt.Errorf("读取 SDK 默认设备树失败: %v", err)
+	}
+
+	var nodes []string
+	for _, name := range dtsRadioNodes {
+		node, ok := dtsNode(string(src), name)
+		if !ok {
+			return "", fmt.Errorf("SDK 默认设备树 %s 中没有 %s 节点", path, name)
+		}
+		nodes = append(nodes, node)
+	}
+	return strings.Join(nodes, "\n"), nil
+}
+
+// dtsNode 取出设备树根节点下名为 name（可带 @地址）的子节点，
+// 从所在行的行首到结尾的 "};"
+func dtsNode(src, name string) (string, bool) {
+	depth := 0
+	// 当前语句在 src 中的起始位置（根节点内）
+	stmt := -1
+	// 目标节点的起始位置和开始时的深度
+	start := -1
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			if end := strings.IndexByte(src[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(src)
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			if end := strings.Index(src[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(src)
+			}
+			continue
+		case c == '"':
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '{':
+			if depth == 1 && start < 0 && stmt >= 0 {
+				label := strings.TrimSpace(src[stmt:i])
+				// 去掉标签，如 "uart0: uart@4000A000"
+				if idx := strings.LastIndex(label, ":"); idx >= 0 {
+					label = strings.TrimSpace(label[idx+1:])
+				}
+				if label == name || strings.HasPrefix(label, name+"@") {
+					start = strings.LastIndexByte(src[:stmt], '\n') + 1
+				}
+			}
+			depth++
+			stmt = -1
+		case '}':
+			depth--
+			stmt = -1
+			if start >= 0 && depth == 1 {
+				end := i + 1
+				if semi := strings.IndexByte(src[end:], ';'); semi >= 0 && strings.TrimSpace(src[end:end+semi]) == "" {
+					end += semi + 1
+				}
+				return src[start:end], true
+			}
+		case ';':
+			stmt = -1
+		default:
+			if stmt < 0 && depth == 1 && !isDTSSpace(c) {
+				stmt = i
+			}
+		}
+	}
+	return "", false
+}
+
+func isDTSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
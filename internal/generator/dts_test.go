@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/dtb"
+)
+
+const sampleSDKDTS = `/dts-v1/;
+/* wifi { 注释中的花括号 } */
+/ {
+    model = "bl bl602 AVB board {";
+    gpio {
+        max_num = <40>;
+    };
+    wifi {
+        #address-cells = <1>;
+        region {
+            country_code = <86>;
+        };
+        // 射频参数 };
+        brd_rf {
+            xtal = <36 36 0 60 0>;
+        };
+    };
+    bluetooth: bluetooth@0 {
+        brd_rf {
+            pwr_table_ble = <13>;
+        };
+    };
+};
+`
+
+func TestDTSNode(t *testing.T) {
+	node, ok := dtsNode(sampleSDKDTS, "wifi")
+	if !ok {
+		t.Fatal("Expected wifi node")
+	}
+	if !strings.HasPrefix(node, "    wifi {\n") || !strings.HasSuffix(node, "xtal = <36 36 0 60 0>;\n        };\n    };") {
+		t.Errorf("Unexpected wifi node:\n%s", node)
+	}
+
+	// 带标签和地址的节点
+	node, ok = dtsNode(sampleSDKDTS, "bluetooth")
+	if !ok || !strings.HasPrefix(node, "    bluetooth: bluetooth@0 {") || !strings.Contains(node, "pwr_table_ble") {
+		t.Errorf("Unexpected bluetooth node:\n%s", node)
+	}
+
+	// 只查找根节点的直接子节点
+	if _, ok := dtsNode(sampleSDKDTS, "brd_rf"); ok {
+		t.Error("Expected nested node not to be found")
+	}
+	if _, ok := dtsNode(sampleSDKDTS, "uart"); ok {
+		t.Error("Expected missing node not to be found")
+	}
+}
+
+func TestResolveOptions(t *testing.T) {
+	uart := config.Component{Name: "uart", Options: map[string]string{"baudrate": "115200", "parity": "none"}}
+
+	options, err := ResolveOptions([]config.Component{uart}, map[string]string{"uart.baudrate": "9600", "uart.parity": "even"})
+	if err != nil {
+		t.Fatalf("ResolveOptions failed: %v", err)
+	}
+	if options["uart.baudrate"] != "9600" || options["uart.parity"] != "even" {
+		t.Errorf("Unexpected options: %v", options)
+	}
+
+	if _, err := ResolveOptions([]config.Component{uart}, map[string]string{"uart.baudrate": "fast"}); err == nil || !strings.Contains(err.Error(), "整数") {
+		t.Errorf("Expected integer error, got %v", err)
+	}
+	if _, err := ResolveOptions([]config.Component{uart}, map[string]string{"spi.freq": "1"}); err == nil || !strings.Contains(err.Error(), "uart.baudrate") {
+		t.Errorf("Expected unknown option error listing options, got %v", err)
+	}
+}
+
+func TestGenerateProjectDTS(t *testing.T) {
+	chdirRepoRoot(t)
+
+	components, err := config.LoadComponents()
+	if err != nil {
+		t.Fatalf("LoadComponents failed: %v", err)
+	}
+	var selected []config.Component
+	for _, comp := range components {
+		switch comp.Name {
+		case "gpio", "uart", "spi", "pwm":
+			selected = append(selected, comp)
+		}
+	}
+
+	sdkDir := t.TempDir()
+	dtsPath := sdkDTSPath(sdkDir, DefaultBoard.Crystal)
+	if err := os.MkdirAll(filepath.Dir(dtsPath), 0755); err != nil {
+		t.Fatalf("Failed to create device_tree directory: %v", err)
+	}
+	if err := os.WriteFile(dtsPath, []byte(sampleSDKDTS), 0644); err != nil {
+		t.Fatalf("Failed to write SDK dts: %v", err)
+	}
+
+	projectDir := filepath.Join(t.TempDir(), "demo")
+	out := NewMemoryOutput()
+	gen := New(sdkDir)
+	gen.SetOutput(out)
+	gen.SetOptions(map[string]string{"uart.baudrate": "9600"})
+	if err := gen.GenerateProject("demo", projectDir, selected); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	dts := string(out.Content(filepath.Join(projectDir, DTSFile)))
+	pins, err := AssignPins(DefaultBoard, selected, nil)
+	if err != nil {
+		t.Fatalf("AssignPins failed: %v", err)
+	}
+	data := &ProjectData{Pins: pins}
+	pwm := data.Pin("PWM_OUT")
+	for _, want := range []string{
+		`model = "Ai-WB2-12F";`,
+		"pin = <14>;\n            feature = \"led\";",
+		"pin = <8>;\n            feature = \"button\";",
+		"rx = <7>;\n                tx = <16>;",
+		"baudrate = <9600>;",
+		"clk = <3>;",
+		"cs = <4>;",
+		"freq = <3000000>;",
+		fmt.Sprintf("pwm@%X {", pwm.PWMReg()),
+		fmt.Sprintf("id = <%d>;\n            pin = <%d>;\n            freq = <1000>;\n            duty = <50>;", pwm.PWMChannel(), pwm.Pin),
+		"pwr_table_ble = <13>;",
+		"xtal = <36 36 0 60 0>;",
+	} {
+		if !strings.Contains(dts, want) {
+			t.Errorf("Expected board.dts to contain %q, got:\n%s", want, dts)
+		}
+	}
+	if strings.Contains(dts, "i2c {") {
+		t.Errorf("Expected no i2c node without i2c component, got:\n%s", dts)
+	}
+	// wb2-cli flash 将设备树编译为 DTB 写入 factory 分区
+	if _, err := dtb.Compile([]byte(dts)); err != nil {
+		t.Errorf("Expected board.dts to compile, got %v", err)
+	}
+
+	makefile := string(out.Content(filepath.Join(projectDir, "Makefile")))
+	if !strings.Contains(makefile, "--dts=$(PROJECT_DTS)") {
+		t.Errorf("Expected Makefile to flash with project dts, got:\n%s", makefile)
+	}
+	manifest := string(out.Content(filepath.Join(projectDir, config.ManifestFile)))
+	if !strings.Contains(manifest, "uart.baudrate: \"9600\"") {
+		t.Errorf("Expected manifest to record options, got:\n%s", manifest)
+	}
+}
+
+func TestGenerateProjectDTSWarning(t *testing.T) {
+	chdirRepoRoot(t)
+
+	// SDK 中没有默认设备树时仍然生成，缺少射频参数作为警告返回给调用方
+	projectDir := filepath.Join(t.TempDir(), "demo")
+	gen := New(t.TempDir())
+	gen.SetOutput(NewMemoryOutput())
+	if err := gen.GenerateProject("demo", projectDir, nil); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	warnings := gen.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "wifi/bluetooth") {
+		t.Errorf("Expected one missing radio warning, got %q", warnings)
+	}
+}
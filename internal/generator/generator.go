@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,25 +9,118 @@ import (
 	"text/template"
 
 	"wb2-cli/internal/config"
+	"wb2-cli/internal/partition"
 )
 
+// BaseIncludeComponents 所有项目都包含的 INCLUDE_COMPONENTS
+var BaseIncludeComponents = []string{
+	"freertos_riscv_ram", "bl602", "bl602_std", "newlibc", "hosal",
+	"mbedtls_lts", "lwip", "vfs", "yloop", "utils", "cli",
+	"blog", "blog_testc", "coredump",
+}
+
+// BaseBLSysComponents 所有项目都包含的 COMPONENTS_BLSYS
+var BaseBLSysComponents = []string{"bltime", "blfdt", "blmtd", "bloop", "looprt", "loopset"}
+
+// BaseVFSComponents 所有项目都包含的 COMPONENTS_VFS
+var BaseVFSComponents = []string{"romfs"}
+
+// DefaultBoard 未设置模组时使用的模组（Ai-WB2-12F-Kit），与 assets/boards.yaml 中的默认模组一致
+var DefaultBoard = config.Board{
+	Name:      "ai-wb2-12f",
+	Module:    "Ai-WB2-12F",
+	SDKBoard:  "evb",
+	FlashSize: 2,
+	Crystal:   40,
+	Pins:      []int{0, 1, 2, 3, 4, 5, 7, 8, 11, 12, 14, 16, 17, 20, 21, 22},
+	LEDPin:    intPtr(14),
+	ButtonPin: intPtr(8),
+	UARTTxPin: 16,
+	UARTRxPin: 7,
+}
+
+func intPtr(v int) *int { return &v }
+
 // Generator 项目生成器
 type Generator struct {
-	sdkPath string
+	sdkPath      string
+	sdkPathMode  SDKPathMode
+	submoduleDir string
+	board        config.Board
+	pins         map[string]int
+	options      map[string]string
+	out          Output
+	keepOnError  bool
+	warnings     []string
 }
 
 // New 创建新的生成器实例
 func New(sdkPath string) *Generator {
 	return &Generator{
-		sdkPath: sdkPath,
+		sdkPath:      sdkPath,
+		sdkPathMode:  SDKPathAbsolute,
+		submoduleDir: DefaultSubmoduleDir,
+		board:        DefaultBoard,
+		out:          diskOutput{},
 	}
 }
 
+// SetBoard 设置目标模组
+func (g *Generator) SetBoard(board config.Board) {
+	g.board = board
+}
+
+// SetPinOverrides 设置手动指定的引脚（引脚名称 -> GPIO）
+func (g *Generator) SetPinOverrides(pins map[string]int) {
+	g.pins = pins
+}
+
+// SetOptions 设置覆盖默认值的组件选项（组件.选项 -> 值）
+func (g *Generator) SetOptions(options map[string]string) {
+	g.options = options
+}
+
+// SetKeepOnError 设置生成失败时是否保留未完成的输出，便于调试
+func (g *Generator) SetKeepOnError(keep bool) {
+	g.keepOnError = keep
+}
+
+// SetOutput 设置生成结果的输出目标（默认直接写入磁盘）
+func (g *Generator) SetOutput(out Output) {
+	g.out = out
+}
+
+// SetSDKPathMode 设置生成的 Makefile 引用 SDK 路径的方式
+func (g *Generator) SetSDKPathMode(mode SDKPathMode) {
+	g.sdkPathMode = mode
+}
+
+// SetSubmoduleDir 设置 submodule 模式下 SDK 子模块在项目中的目录（相对于项目目录）
+func (g *Generator) SetSubmoduleDir(dir string) {
+	g.submoduleDir = dir
+}
+
+// Warnings 返回最近一次 GenerateProject 产生的警告（不影响生成结果），由调用方决定如何输出
+func (g *Generator) Warnings() []string {
+	return g.warnings
+}
+
+// warnf 记录一条警告
+func (g *Generator) warnf(format string, args ...interface{}) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
 // ProjectData 传递给模板的数据结构
 type ProjectData struct {
 	ProjectName    string
 	SDKPath        string
+	SDKPathRef     string // Makefile 中 BL60X_SDK_PATH 的默认值，为空时只使用环境变量
+	Board          config.Board
 	Components     []config.Component
+	Pins           []PinAssignment
+	Options        map[string]string // 组件选项（组件.选项 -> 值）
+	DTSRadio       string            // SDK 默认设备树中的射频节点，找不到时为空
+	Partitions     *partition.Table
 	HasWifi        bool
 	HasMQTT        bool
 	HasBLE         bool
@@ -52,21 +146,67 @@ type ProjectData struct {
 }
 
 // GenerateProject 生成项目
+//
+// 写入磁盘时先在同级临时目录中生成，全部成功后再重命名到 projectPath，
+// 失败时清理未完成的输出（除非设置了 SetKeepOnError）。
 func (g *Generator) GenerateProject(projectName, projectPath string, components []config.Component) error {
+	g.warnings = nil
+
+	// 检查 SDK 路径引用能否从项目目录解析
+	sdkPathRef, err := g.sdkPathRef(projectPath)
+	if err != nil {
+		return err
+	}
+
+	// 分配组件需要的引脚
+	pins, err := AssignPins(g.board, components, g.pins)
+	if err != nil {
+		return err
+	}
+
+	options, err := ResolveOptions(components, g.options)
+	if err != nil {
+		return err
+	}
+
+	// 按模组 Flash 容量和组件需要的分区生成分区表
+	partitions, err := LayoutPartitions(g.board, components)
+	if err != nil {
+		return err
+	}
+
+	// 准备模板数据
+	data := g.prepareProjectData(projectName, components)
+	data.SDKPathRef = sdkPathRef
+	data.Pins = pins
+	data.Options = options
+	data.Partitions = partitions
+	if data.DTSRadio, err = g.loadDTSRadio(); err != nil {
+		// 设备树仍然生成，射频参数需要手动补充
+		g.warnf("%v，%s 中缺少 wifi/bluetooth 射频参数", err, DTSFile)
+	}
+
+	// 自定义输出目标（如 dry-run）直接写入，无需暂存
+	if _, ok := g.out.(diskOutput); !ok {
+		return g.writeProject(projectPath, data)
+	}
+
+	return g.writeProjectAtomic(projectPath, data)
+}
+
+// writeProject 将项目文件写入 projectPath
+func (g *Generator) writeProject(projectPath string, data *ProjectData) error {
 	// 创建项目目录
-	if err := os.MkdirAll(projectPath, 0755); err != nil {
+	if err := g.out.MkdirAll(projectPath); err != nil {
 		return fmt.Errorf("创建项目目录失败: %v", err)
 	}
 
 	// 创建项目子目录（与项目同名）
-	projectSubDir := filepath.Join(projectPath, projectName)
-	if err := os.MkdirAll(projectSubDir, 0755); err != nil {
+	projectSubDir := filepath.Join(projectPath, data.ProjectName)
+	if err := g.out.MkdirAll(projectSubDir); err != nil {
 		return fmt.Errorf("创建项目子目录失败: %v", err)
 	}
 
-	// 准备模板数据
-	data := g.prepareProjectData(projectName, components)
-
 	// 生成基础文件
 	if err := g.generateBaseFiles(projectPath, data); err != nil {
 		return err
@@ -77,13 +217,29 @@ func (g *Generator) GenerateProject(projectName, projectPath string, components
 		return err
 	}
 
+	// 生成 EasyFlash 默认键值
+	if err := g.generateKVDefaults(projectPath, projectSubDir, data); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// SDKComponents 返回所选组件引入的全部 SDK 组件（含基础组件），即 Makefile 中各组件列表的并集
+func SDKComponents(components []config.Component) []string {
+	data := New("").prepareProjectData("", components)
+	var names []string
+	for _, list := range [][]string{data.IncludeComps, data.NetworkComps, data.BLSysComps, data.VFSComps, data.MQTTComps} {
+		names = append(names, list...)
+	}
+	return uniqueStrings(names)
+}
+
 func (g *Generator) prepareProjectData(projectName string, components []config.Component) *ProjectData {
 	data := &ProjectData{
 		ProjectName:  projectName,
 		SDKPath:      g.sdkPath,
+		Board:        g.board,
 		Components:   components,
 		IncludeComps: []string{},
 		NetworkComps: []string{},
@@ -94,20 +250,9 @@ func (g *Generator) prepareProjectData(projectName string, components []config.C
 	}
 
 	// 基础组件（所有项目都需要）
-	baseIncludeComps := []string{
-		"freertos_riscv_ram", "bl602", "bl602_std", "newlibc", "hosal",
-		"mbedtls_lts", "lwip", "vfs", "yloop", "utils", "cli",
-		"blog", "blog_testc", "coredump",
-	}
-	data.IncludeComps = append(data.IncludeComps, baseIncludeComps...)
-
-	// 基础 BLSYS 组件
-	baseBLSysComps := []string{"bltime", "blfdt", "blmtd", "bloop", "looprt", "loopset"}
-	data.BLSysComps = append(data.BLSysComps, baseBLSysComps...)
-
-	// 基础 VFS 组件
-	baseVFSComps := []string{"romfs"}
-	data.VFSComps = append(data.VFSComps, baseVFSComps...)
+	data.IncludeComps = append(data.IncludeComps, BaseIncludeComponents...)
+	data.BLSysComps = append(data.BLSysComps, BaseBLSysComponents...)
+	data.VFSComps = append(data.VFSComps, BaseVFSComponents...)
 
 	// 处理每个组件
 	for _, comp := range components {
@@ -227,6 +372,26 @@ func (g *Generator) generateBaseFiles(projectPath string, data *ProjectData) err
 		return fmt.Errorf("生成 proj_config.mk 失败: %v", err)
 	}
 
+	// 生成设备树
+	if err := g.generateFileFromTemplate(
+		"board.dts.tmpl",
+		filepath.Join(projectPath, DTSFile),
+		data,
+	); err != nil {
+		return fmt.Errorf("生成 %s 失败: %v", DTSFile, err)
+	}
+
+	// 生成分区表
+	title := fmt.Sprintf("%s 分区表（%s，%dMB Flash），由 wb2-cli 生成\n修改后可用 wb2-cli partitions show 检查布局", data.ProjectName, data.Board.Module, data.Board.FlashSize)
+	if err := g.out.WriteFile(filepath.Join(projectPath, PartitionFile), partition.Format(data.Partitions, title)); err != nil {
+		return fmt.Errorf("生成 %s 失败: %v", PartitionFile, err)
+	}
+
+	// 生成 romfs 目录
+	if err := g.generateRomfs(projectPath, data); err != nil {
+		return err
+	}
+
 	// 生成 README.md
 	if err := g.generateFileFromTemplate(
 		"README.md.tmpl",
@@ -236,13 +401,22 @@ func (g *Generator) generateBaseFiles(projectPath string, data *ProjectData) err
 		return fmt.Errorf("生成 README.md 失败: %v", err)
 	}
 
+	// 生成项目清单
+	manifest, err := config.MarshalManifest(g.manifest(data))
+	if err != nil {
+		return err
+	}
+	if err := g.out.WriteFile(filepath.Join(projectPath, config.ManifestFile), manifest); err != nil {
+		return fmt.Errorf("生成 %s 失败: %v", config.ManifestFile, err)
+	}
+
 	return nil
 }
 
 func (g *Generator) generateComponentFiles(projectSubDir string, data *ProjectData) error {
 	// 创建 include 目录
 	includeDir := filepath.Join(projectSubDir, "include")
-	if err := os.MkdirAll(includeDir, 0755); err != nil {
+	if err := g.out.MkdirAll(includeDir); err != nil {
 		return fmt.Errorf("创建 include 目录失败: %v", err)
 	}
 
@@ -304,7 +478,7 @@ func (g *Generator) generateComponentSpecificFiles(projectSubDir string, comp co
 		outputDir := filepath.Join(projectSubDir, filepath.Dir(tmplFile))
 		
 		// 创建输出目录
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
+		if err := g.out.MkdirAll(outputDir); err != nil {
 			return fmt.Errorf("创建组件目录失败: %v", err)
 		}
 
@@ -313,7 +487,7 @@ func (g *Generator) generateComponentSpecificFiles(projectSubDir string, comp co
 		// 生成文件
 		if err := g.generateFileFromTemplate(tmplFile, outputPath, data); err != nil {
 			// 如果模板文件不存在，只记录警告，不中断流程
-			fmt.Printf("警告: 无法生成组件文件 %s: %v\n", outputPath, err)
+			g.warnf("无法生成组件文件 %s: %v", outputPath, err)
 			continue
 		}
 	}
@@ -340,18 +514,17 @@ func (g *Generator) generateFileFromTemplate(templateName, outputPath string, da
 		return fmt.Errorf("解析模板失败: %v", err)
 	}
 
-	// 创建输出文件
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("创建输出文件失败: %v", err)
-	}
-	defer file.Close()
-
 	// 渲染模板
-	if err := tmpl.Execute(file, data); err != nil {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
 		return fmt.Errorf("渲染模板失败: %v", err)
 	}
 
+	// 写入输出文件
+	if err := g.out.WriteFile(outputPath, buf.Bytes()); err != nil {
+		return fmt.Errorf("创建输出文件失败: %v", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"wb2-cli/internal/kv"
+)
+
+// generateKVDefaults 选择 storage 组件时生成 kv_defaults.yaml，以及由它生成的
+// kv_defaults.c 和 include/kv_defaults.h（之后由 wb2-cli kv 重新生成）
+func (g *Generator) generateKVDefaults(projectPath, projectSubDir string, data *ProjectData) error {
+	if !data.HasStorage {
+		return nil
+	}
+	content := kv.Default(data.ProjectName)
+	entries, err := kv.Parse(content)
+	if err != nil {
+		return err
+	}
+	if err := g.out.WriteFile(filepath.Join(projectPath, kv.File), content); err != nil {
+		return fmt.Errorf("生成 %s 失败: %v", kv.File, err)
+	}
+	if err := g.out.WriteFile(filepath.Join(projectSubDir, kv.SourceFile), kv.Source(entries)); err != nil {
+		return fmt.Errorf("生成 %s 失败: %v", kv.SourceFile, err)
+	}
+	if err := g.out.WriteFile(filepath.Join(projectSubDir, "include", kv.HeaderFile), kv.Header(entries)); err != nil {
+		return fmt.Errorf("生成 %s 失败: %v", kv.HeaderFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/kv"
+)
+
+func TestGenerateProjectKVDefaults(t *testing.T) {
+	chdirRepoRoot(t)
+
+	generate := func(components []config.Component) (*MemoryOutput, string) {
+		projectDir := filepath.Join(t.TempDir(), "demo")
+		out := NewMemoryOutput()
+		gen := New(t.TempDir())
+		gen.SetOutput(out)
+		if err := gen.GenerateProject("demo", projectDir, components); err != nil {
+			t.Fatalf("GenerateProject failed: %v", err)
+		}
+		return out, projectDir
+	}
+
+	out, projectDir := generate([]config.Component{{Name: "storage"}})
+	entries, err := kv.Parse(out.Content(filepath.Join(projectDir, kv.File)))
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("Expected default %s, got %v, %v", kv.File, entries, err)
+	}
+	source := out.Content(filepath.Join(projectDir, "demo", kv.SourceFile))
+	if string(source) != string(kv.Source(entries)) {
+		t.Errorf("Expected %s generated from %s", kv.SourceFile, kv.File)
+	}
+	if out.Content(filepath.Join(projectDir, "demo", "include", kv.HeaderFile)) == nil {
+		t.Errorf("Expected include/%s", kv.HeaderFile)
+	}
+	mainC := string(out.Content(filepath.Join(projectDir, "demo", "main.c")))
+	// SDK 启动时已初始化 EasyFlash，main.c 不再调用 easyflash_init()
+	if !strings.Contains(mainC, "kv_defaults_init();") || strings.Contains(mainC, "easyflash_init") {
+		t.Errorf("Expected main.c to apply default key-values:\n%s", mainC)
+	}
+
+	out, projectDir = generate([]config.Component{})
+	if out.Content(filepath.Join(projectDir, kv.File)) != nil {
+		t.Errorf("Expected no %s without storage component", kv.File)
+	}
+	if strings.Contains(string(out.Content(filepath.Join(projectDir, "demo", "main.c"))), "kv_defaults") {
+		t.Errorf("Expected main.c without kv_defaults")
+	}
+}
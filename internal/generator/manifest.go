@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"sort"
+
+	"wb2-cli/internal/config"
+)
+
+// manifest 根据模板数据构造项目清单
+func (g *Generator) manifest(data *ProjectData) *config.Manifest {
+	names := make([]string, 0, len(data.Components))
+	for _, comp := range data.Components {
+		names = append(names, comp.Name)
+	}
+	sort.Strings(names)
+
+	mode := g.sdkPathMode
+	if mode == "" {
+		mode = SDKPathAbsolute
+	}
+
+	var pins map[string]int
+	if len(data.Pins) > 0 {
+		pins = make(map[string]int, len(data.Pins))
+		for _, pin := range data.Pins {
+			pins[pin.Name] = pin.Pin
+		}
+	}
+
+	var options map[string]string
+	if len(data.Options) > 0 {
+		options = data.Options
+	}
+
+	return &config.Manifest{
+		Name:        data.ProjectName,
+		SDKPath:     manifestSDKPath(data.SDKPathRef),
+		SDKPathMode: string(mode),
+		Board:       data.Board.Name,
+		Components:  names,
+		Pins:        pins,
+		Options:     options,
+	}
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"wb2-cli/internal/config"
+)
+
+// ResolveOptions 合并组件选项的默认值和 overrides（组件.选项 -> 值）
+//
+// 只能覆盖所选组件声明过的选项；默认值为整数的选项，覆盖值也必须是整数。
+func ResolveOptions(components []config.Component, overrides map[string]string) (map[string]string, error) {
+	options := map[string]string{}
+	for _, comp := range components {
+		for key, value := range comp.Options {
+			options[comp.Name+"."+key] = value
+		}
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		def, ok := options[key]
+		if !ok {
+			if len(options) == 0 {
+				return nil, fmt.Errorf("未知的组件选项 %s，所选组件没有可设置的选项", key)
+			}
+			return nil, fmt.Errorf("未知的组件选项 %s，可用选项: %s", key, strings.Join(optionKeys(options), ", "))
+		}
+		value := overrides[key]
+		if _, err := strconv.Atoi(def); err == nil {
+			if _, err := strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("组件选项 %s 需要整数，得到 %q", key, value)
+			}
+		}
+		options[key] = value
+	}
+	return options, nil
+}
+
+// Option 返回组件选项的值，供模板使用
+func (d *ProjectData) Option(key string) string {
+	return d.Options[key]
+}
+
+// optionKeys 返回排序后的选项名称
+func optionKeys(options map[string]string) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Output 生成结果的输出目标
+type Output interface {
+	// MkdirAll 创建目录（包括所有上级目录）
+	MkdirAll(path string) error
+	// WriteFile 写入文件内容
+	WriteFile(path string, data []byte) error
+}
+
+// diskOutput 直接写入文件系统
+type diskOutput struct{}
+
+func (diskOutput) MkdirAll(path string) error {
+	return os.MkdirAll(path, 0755)
+}
+
+func (diskOutput) WriteFile(path string, data []byte) error {
+	return os.WriteFile(path, data, 0644)
+}
+
+// MemoryOutput 将生成结果保存在内存中，用于预览（dry-run）
+type MemoryOutput struct {
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+// NewMemoryOutput 创建内存输出目标
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{
+		files: make(map[string][]byte),
+		dirs:  make(map[string]bool),
+	}
+}
+
+// MkdirAll 记录目录
+func (m *MemoryOutput) MkdirAll(path string) error {
+	m.dirs[filepath.Clean(path)] = true
+	return nil
+}
+
+// WriteFile 记录文件内容
+func (m *MemoryOutput) WriteFile(path string, data []byte) error {
+	content := make([]byte, len(data))
+	copy(content, data)
+	m.files[filepath.Clean(path)] = content
+	return nil
+}
+
+// Files 返回所有已生成文件的路径（已排序）
+func (m *MemoryOutput) Files() []string {
+	paths := make([]string, 0, len(m.files))
+	for path := range m.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Dirs 返回所有已创建目录的路径（已排序）
+func (m *MemoryOutput) Dirs() []string {
+	paths := make([]string, 0, len(m.dirs))
+	for path := range m.dirs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Content 返回文件内容，文件不存在时返回 nil
+func (m *MemoryOutput) Content(path string) []byte {
+	return m.files[filepath.Clean(path)]
+}
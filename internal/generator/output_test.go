@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// chdirRepoRoot 切换到仓库根目录，使模板查找逻辑可以找到 templates/
+func chdirRepoRoot(t *testing.T) {
+	t.Helper()
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(filepath.Join("..", "..")); err != nil {
+		t.Fatalf("Failed to change to repo root: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(oldWd) })
+}
+
+func TestMemoryOutput(t *testing.T) {
+	out := NewMemoryOutput()
+
+	if err := out.MkdirAll("/proj/src"); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	data := []byte("hello")
+	if err := out.WriteFile("/proj/src/b.c", data); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	out.WriteFile("/proj/a.txt", []byte("a"))
+
+	// 写入后修改原切片不应影响已保存的内容
+	data[0] = 'j'
+	if string(out.Content("/proj/src/b.c")) != "hello" {
+		t.Errorf("Expected content 'hello', got %q", out.Content("/proj/src/b.c"))
+	}
+
+	files := out.Files()
+	if len(files) != 2 || files[0] != "/proj/a.txt" || files[1] != "/proj/src/b.c" {
+		t.Errorf("Unexpected files: %v", files)
+	}
+
+	dirs := out.Dirs()
+	if len(dirs) != 1 || dirs[0] != "/proj/src" {
+		t.Errorf("Unexpected dirs: %v", dirs)
+	}
+
+	if out.Content("/proj/missing") != nil {
+		t.Error("Expected nil content for missing file")
+	}
+}
+
+func TestGenerateProjectMemoryOutput(t *testing.T) {
+	chdirRepoRoot(t)
+
+	sdkDir := t.TempDir()
+	projectDir := filepath.Join(t.TempDir(), "demo")
+
+	gen := New(sdkDir)
+	out := NewMemoryOutput()
+	gen.SetOutput(out)
+
+	if err := gen.GenerateProject("demo", projectDir, nil); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	// 不应写入磁盘
+	if _, err := os.Stat(projectDir); !os.IsNotExist(err) {
+		t.Error("Expected project directory not to be created in memory mode")
+	}
+
+	for _, rel := range []string{"Makefile", "proj_config.mk", "README.md", "demo/main.c", "demo/bouffalo.mk", "demo/include/main_board.h"} {
+		content := out.Content(filepath.Join(projectDir, rel))
+		if content == nil {
+			t.Errorf("Expected %s to be generated", rel)
+		}
+	}
+
+	makefile := string(out.Content(filepath.Join(projectDir, "Makefile")))
+	if !strings.Contains(makefile, "PROJECT_NAME := demo") {
+		t.Errorf("Makefile missing project name:\n%s", makefile)
+	}
+}
@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"fmt"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/partition"
+)
+
+// PartitionFile 生成的项目分区表（位于项目根目录）
+const PartitionFile = "partition.toml"
+
+// LayoutPartitions 按模组的 Flash 容量和组件需要的分区生成分区表
+func LayoutPartitions(board config.Board, components []config.Component) (*partition.Table, error) {
+	var requests []partition.Request
+	for _, comp := range components {
+		for _, req := range comp.Partitions {
+			size, err := partition.ParseSize(req.Size)
+			if err != nil {
+				return nil, fmt.Errorf("组件 %s 的分区 %s: %v", comp.Name, req.Name, err)
+			}
+			requests = append(requests, partition.Request{Name: req.Name, Type: req.Type, Size: size})
+		}
+	}
+
+	table, err := partition.Layout(board.FlashSize*1024*1024, requests)
+	if err != nil {
+		return nil, fmt.Errorf("生成分区表失败: %v", err)
+	}
+	return table, nil
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/partition"
+)
+
+func TestGenerateProjectPartitions(t *testing.T) {
+	chdirRepoRoot(t)
+
+	ota := config.Component{Name: "ota", Partitions: []config.PartitionRequest{{Name: "FW", Size: "544K"}}}
+
+	projectDir := filepath.Join(t.TempDir(), "demo")
+	out := NewMemoryOutput()
+	gen := New(t.TempDir())
+	gen.SetOutput(out)
+	if err := gen.GenerateProject("demo", projectDir, []config.Component{ota}); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	table, err := partition.Parse(out.Content(filepath.Join(projectDir, PartitionFile)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := table.Validate(DefaultBoard.FlashSize * 1024 * 1024); err != nil {
+		t.Errorf("Expected generated table to be valid: %v", err)
+	}
+	if fw, _ := table.Find("FW"); fw.Size1 != 544*1024 {
+		t.Errorf("Expected OTA slot, got %+v", fw)
+	}
+	if _, ok := table.Find("media"); !ok {
+		t.Error("Expected media partition for the always-mounted romfs")
+	}
+
+	makefile := string(out.Content(filepath.Join(projectDir, "Makefile")))
+	if !strings.Contains(makefile, "PROJECT_PT ?= $(PROJECT_PATH)/partition.toml") {
+		t.Errorf("Expected Makefile to use project partition table, got:\n%s", makefile)
+	}
+
+	// 超出 Flash 容量时生成失败
+	huge := config.Component{Name: "huge", Partitions: []config.PartitionRequest{{Name: "media", Type: partition.TypeMedia, Size: "2M"}}}
+	gen.SetOutput(NewMemoryOutput())
+	if err := gen.GenerateProject("demo", projectDir, []config.Component{huge}); err == nil || !strings.Contains(err.Error(), "Flash 空间不足") {
+		t.Errorf("Expected flash overflow error, got %v", err)
+	}
+}
+
+func TestCatalogPartitionsFit(t *testing.T) {
+	chdirRepoRoot(t)
+
+	components, err := config.LoadComponents()
+	if err != nil {
+		t.Fatalf("LoadComponents failed: %v", err)
+	}
+	// 所有组件同时选择时也能放入 2MB Flash
+	if _, err := LayoutPartitions(DefaultBoard, components); err != nil {
+		t.Errorf("Expected catalog partitions to fit in 2MB: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"wb2-cli/internal/config"
+)
+
+// 引脚分配来源
+const (
+	PinSourceOverride = "指定"
+	PinSourceBoard    = "板载"
+	PinSourceDefault  = "默认"
+	PinSourceAuto     = "自动"
+)
+
+// PinAssignment 一个引脚的分配结果
+type PinAssignment struct {
+	// 引脚名称，对应 main_board.h 中的 <Name>_PIN
+	Name      string
+	Pin       int
+	Function  string
+	Component string
+	Source    string
+}
+
+// PWMChannel 返回引脚对应的 PWM 通道
+func (p PinAssignment) PWMChannel() int {
+	return p.Pin % 5
+}
+
+// PWMReg 返回引脚对应的 PWM 通道寄存器地址（设备树节点地址）
+func (p PinAssignment) PWMReg() int {
+	return 0x4000A420 + 0x20*p.PWMChannel()
+}
+
+// Pin 按名称查找引脚分配，供模板使用；没有时返回 nil
+func (d *ProjectData) Pin(name string) *PinAssignment {
+	for i := range d.Pins {
+		if d.Pins[i].Name == name {
+			return &d.Pins[i]
+		}
+	}
+	return nil
+}
+
+// pinRequest 带所属组件的引脚需求
+type pinRequest struct {
+	config.PinRequest
+	component string
+}
+
+// pinAssigner 记录分配过程中的引脚占用
+type pinAssigner struct {
+	board    *config.Board
+	owner    map[int]string
+	assigned map[string]PinAssignment
+	// UART 信号（GPIO 编号模 8）-> 占用它的 UART 引脚
+	uartSignal map[int]uartOwner
+}
+
+// uartOwner 占用 UART 信号的引脚
+type uartOwner struct {
+	name string
+	pin  int
+}
+
+// AssignPins 为组件声明的引脚分配模组的 GPIO
+//
+// UART0 的 TX/RX 及其 UART 信号保留给日志和烧录，UART 引脚之间不能共用信号（见
+// config.UARTSignal）。overrides（名称 -> GPIO）必须可用，否则返回错误；板载器件优先于组件的默认引脚，二者都只是优先选择，模组没有引出或
+// 已被占用时与其余需求一起自动分配，可选引脚少的需求优先。分配结果与组件顺序无关。
+func AssignPins(board config.Board, components []config.Component, overrides map[string]int) ([]PinAssignment, error) {
+	a := &pinAssigner{
+		board:      &board,
+		owner:      map[int]string{},
+		assigned:   map[string]PinAssignment{},
+		uartSignal: map[int]uartOwner{},
+	}
+	a.owner[board.UARTTxPin] = "UART0_TX"
+	a.owner[board.UARTRxPin] = "UART0_RX"
+	a.uartSignal[config.UARTSignal(board.UARTTxPin)] = uartOwner{"UART0_TX", board.UARTTxPin}
+	a.uartSignal[config.UARTSignal(board.UARTRxPin)] = uartOwner{"UART0_RX", board.UARTRxPin}
+
+	var requests []pinRequest
+	byName := map[string]pinRequest{}
+	for _, comp := range components {
+		for _, req := range comp.Pins {
+			if !config.IsPinFunction(req.Function) {
+				return nil, fmt.Errorf("组件 %s 的引脚 %s 使用了未知的功能 %q", comp.Name, req.Name, req.Function)
+			}
+			if other, ok := byName[req.Name]; ok {
+				return nil, fmt.Errorf("引脚 %s 被组件 %s 和 %s 重复声明", req.Name, other.component, comp.Name)
+			}
+			r := pinRequest{PinRequest: req, component: comp.Name}
+			requests = append(requests, r)
+			byName[req.Name] = r
+		}
+	}
+
+	// 命令行指定的引脚
+	overrideNames := make([]string, 0, len(overrides))
+	for name := range overrides {
+		overrideNames = append(overrideNames, name)
+	}
+	sort.Strings(overrideNames)
+	for _, name := range overrideNames {
+		req, ok := byName[name]
+		if !ok {
+			if len(requests) == 0 {
+				return nil, fmt.Errorf("未知的引脚 %s，所选组件没有需要分配的引脚", name)
+			}
+			return nil, fmt.Errorf("未知的引脚 %s，所选组件的引脚: %s", name, strings.Join(requestNames(requests), ", "))
+		}
+		if err := a.check(req, overrides[name]); err != nil {
+			return nil, err
+		}
+		a.take(req, overrides[name], PinSourceOverride)
+	}
+
+	// 处理顺序与组件的传入顺序无关：按组件名排序，组件内保持声明顺序
+	ordered := append([]pinRequest(nil), requests...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].component < ordered[j].component
+	})
+
+	// 板载器件优先于组件默认引脚，其余需求自动分配
+	var pending []pinRequest
+	for _, boardPass := range []bool{true, false} {
+		for _, req := range ordered {
+			if _, ok := a.assigned[req.Name]; ok || (req.Board != "") != boardPass {
+				continue
+			}
+			if pin, ok := a.preferred(req); ok && a.check(req, pin) == nil {
+				source := PinSourceDefault
+				if req.Board != "" {
+					source = PinSourceBoard
+				}
+				a.take(req, pin, source)
+				continue
+			}
+			pending = append(pending, req)
+		}
+	}
+
+	// 自动分配：可选引脚少的优先
+	sort.SliceStable(pending, func(i, j int) bool {
+		return len(a.candidates(pending[i])) < len(a.candidates(pending[j]))
+	})
+	for _, req := range pending {
+		candidates := a.candidates(req)
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("%s 上没有可用于 %s（%s，组件 %s）的空闲引脚，请减少外设组件或使用 --pin 调整",
+				a.board.Module, req.Name, req.Function, req.component)
+		}
+		a.take(req, candidates[0], PinSourceAuto)
+	}
+
+	result := make([]PinAssignment, 0, len(requests))
+	for _, req := range requests {
+		result = append(result, a.assigned[req.Name])
+	}
+	return result, nil
+}
+
+// preferred 返回需求优先使用的引脚
+func (a *pinAssigner) preferred(req pinRequest) (int, bool) {
+	switch req.Board {
+	case "led":
+		if a.board.LEDPin != nil {
+			return *a.board.LEDPin, true
+		}
+	case "button":
+		if a.board.ButtonPin != nil {
+			return *a.board.ButtonPin, true
+		}
+	}
+	if req.Default != nil {
+		return *req.Default, true
+	}
+	return 0, false
+}
+
+// check 检查引脚能否分配给需求
+func (a *pinAssigner) check(req pinRequest, pin int) error {
+	if !a.board.HasPin(pin) {
+		return fmt.Errorf("%s 没有引出 IO%d（%s）", a.board.Module, pin, req.Name)
+	}
+	if owner, ok := a.owner[pin]; ok {
+		return fmt.Errorf("IO%d 不能同时用作 %s 和 %s", pin, owner, req.Name)
+	}
+	if !a.board.Supports(pin, req.Function) {
+		return fmt.Errorf("IO%d 不支持 %s 需要的功能 %s（可用: %s）",
+			pin, req.Name, req.Function, strings.Join(a.board.Functions(pin), ", "))
+	}
+	if config.IsUARTFunction(req.Function) {
+		if other, ok := a.uartSignal[config.UARTSignal(pin)]; ok {
+			return fmt.Errorf("IO%d 与 IO%d（%s）共用 UART 信号 %d，不能用作 %s",
+				pin, other.pin, other.name, config.UARTSignal(pin), req.Name)
+		}
+	}
+	return nil
+}
+
+// candidates 返回需求可用的空闲引脚，按编号排序
+func (a *pinAssigner) candidates(req pinRequest) []int {
+	var pins []int
+	for _, pin := range a.board.Pins {
+		if a.check(req, pin) == nil {
+			pins = append(pins, pin)
+		}
+	}
+	sort.Ints(pins)
+	return pins
+}
+
+// take 占用引脚
+func (a *pinAssigner) take(req pinRequest, pin int, source string) {
+	a.owner[pin] = req.Name
+	if config.IsUARTFunction(req.Function) {
+		a.uartSignal[config.UARTSignal(pin)] = uartOwner{req.Name, pin}
+	}
+	a.assigned[req.Name] = PinAssignment{
+		Name:      req.Name,
+		Pin:       pin,
+		Function:  req.Function,
+		Component: req.component,
+		Source:    source,
+	}
+}
+
+// requestNames 返回所有引脚名称
+func requestNames(requests []pinRequest) []string {
+	names := make([]string, 0, len(requests))
+	for _, req := range requests {
+		names = append(names, req.Name)
+	}
+	return names
+}
@@ -0,0 +1,208 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+)
+
+func TestAssignPinsCatalog(t *testing.T) {
+	chdirRepoRoot(t)
+
+	components, err := config.LoadComponents()
+	if err != nil {
+		t.Fatalf("LoadComponents failed: %v", err)
+	}
+
+	// 默认模组上可以同时使用所有外设组件
+	pins, err := AssignPins(DefaultBoard, components, nil)
+	if err != nil {
+		t.Fatalf("AssignPins failed: %v", err)
+	}
+
+	used := map[int]string{DefaultBoard.UARTTxPin: "UART0_TX", DefaultBoard.UARTRxPin: "UART0_RX"}
+	byName := map[string]PinAssignment{}
+	for _, pin := range pins {
+		if owner, ok := used[pin.Pin]; ok {
+			t.Errorf("IO%d assigned to both %s and %s", pin.Pin, owner, pin.Name)
+		}
+		used[pin.Pin] = pin.Name
+		if !DefaultBoard.Supports(pin.Pin, pin.Function) {
+			t.Errorf("IO%d does not support %s (%s)", pin.Pin, pin.Function, pin.Name)
+		}
+		byName[pin.Name] = pin
+	}
+
+	// 板载器件和原模板中的 SPI 引脚保持不变
+	expected := map[string]int{"GPIO_LED": 14, "GPIO_BUTTON": 8, "SPI_CLK": 3, "SPI_MOSI": 12, "SPI_MISO": 17, "SPI_CS": 4}
+	for name, pin := range expected {
+		if byName[name].Pin != pin {
+			t.Errorf("Expected %s on IO%d, got %+v", name, pin, byName[name])
+		}
+	}
+	if byName["GPIO_LED"].Source != PinSourceBoard || byName["SPI_CS"].Source != PinSourceDefault || byName["ADC_IN"].Source != PinSourceAuto {
+		t.Errorf("Unexpected sources: %+v", pins)
+	}
+}
+
+func TestAssignPinsOverrides(t *testing.T) {
+	spi := config.Component{Name: "spi", Pins: []config.PinRequest{
+		{Name: "SPI_CLK", Function: config.PinSPISCLK, Default: intPtr(3)},
+		{Name: "SPI_CS", Function: config.PinGPIO, Default: intPtr(4)},
+	}}
+	led := config.Component{Name: "led", Pins: []config.PinRequest{
+		{Name: "LED", Function: config.PinGPIO, Default: intPtr(4)},
+	}}
+
+	// 指定的引脚优先，被占用的默认引脚改为自动分配
+	pins, err := AssignPins(DefaultBoard, []config.Component{spi, led}, map[string]int{"LED": 4})
+	if err != nil {
+		t.Fatalf("AssignPins failed: %v", err)
+	}
+	if pins[2].Pin != 4 || pins[2].Source != PinSourceOverride {
+		t.Errorf("Expected LED on IO4, got %+v", pins[2])
+	}
+	if pins[1].Pin == 4 || pins[1].Source != PinSourceAuto {
+		t.Errorf("Expected SPI_CS to be reassigned, got %+v", pins[1])
+	}
+
+	tests := []struct {
+		overrides map[string]int
+		want      string
+	}{
+		{map[string]int{"SPI_CLK": 2}, "不支持"},
+		{map[string]int{"SPI_CS": 6}, "没有引出 IO6"},
+		{map[string]int{"SPI_CS": 16}, "UART0_TX"},
+		{map[string]int{"SPI_CS": 5, "LED": 5}, "IO5 不能同时用作"},
+		{map[string]int{"SPI_MOSI": 12}, "未知的引脚 SPI_MOSI"},
+	}
+	for _, tt := range tests {
+		_, err := AssignPins(DefaultBoard, []config.Component{spi, led}, tt.overrides)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("overrides %v: expected error containing %q, got %v", tt.overrides, tt.want, err)
+		}
+	}
+}
+
+func TestAssignPinsUARTSignal(t *testing.T) {
+	chdirRepoRoot(t)
+
+	catalog, err := config.LoadComponents()
+	if err != nil {
+		t.Fatalf("LoadComponents failed: %v", err)
+	}
+	byName := map[string]config.Component{}
+	for _, comp := range catalog {
+		byName[comp.Name] = comp
+	}
+	var components []config.Component
+	for _, name := range []string{"wifi", "mqtt", "gpio", "spi", "storage", "uart", "romfs"} {
+		comp, ok := byName[name]
+		if !ok {
+			t.Fatalf("component %s not found", name)
+		}
+		components = append(components, comp)
+	}
+
+	// UART1 不能使用与 UART0（IO16、IO7）编号模 8 相同的 GPIO，如 IO0 与 IO16
+	pins, err := AssignPins(DefaultBoard, components, nil)
+	if err != nil {
+		t.Fatalf("AssignPins failed: %v", err)
+	}
+	signals := map[int]string{
+		config.UARTSignal(DefaultBoard.UARTTxPin): "UART0_TX",
+		config.UARTSignal(DefaultBoard.UARTRxPin): "UART0_RX",
+	}
+	for _, pin := range pins {
+		if !config.IsUARTFunction(pin.Function) {
+			continue
+		}
+		if owner, ok := signals[config.UARTSignal(pin.Pin)]; ok {
+			t.Errorf("%s on IO%d shares UART signal %d with %s", pin.Name, pin.Pin, config.UARTSignal(pin.Pin), owner)
+		}
+		signals[config.UARTSignal(pin.Pin)] = pin.Name
+	}
+
+	uart := byName["uart"]
+	tests := []struct {
+		overrides map[string]int
+		want      string
+	}{
+		{map[string]int{"UART1_TX": 0}, "IO0 与 IO16（UART0_TX）共用 UART 信号 0"},
+		{map[string]int{"UART1_RX": 15}, "没有引出"},
+		{map[string]int{"UART1_TX": 1, "UART1_RX": 17}, "共用 UART 信号 1"},
+	}
+	for _, tt := range tests {
+		_, err := AssignPins(DefaultBoard, []config.Component{uart}, tt.overrides)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("overrides %v: expected error containing %q, got %v", tt.overrides, tt.want, err)
+		}
+	}
+}
+
+func TestAssignPinsExhausted(t *testing.T) {
+	board := config.Board{Module: "Tiny", Pins: []int{3, 7, 16}, UARTTxPin: 16, UARTRxPin: 7}
+	gpio := config.Component{Name: "gpio", Pins: []config.PinRequest{
+		{Name: "GPIO_LED", Function: config.PinGPIO, Board: "led"},
+		{Name: "GPIO_BUTTON", Function: config.PinGPIO, Board: "button"},
+	}}
+
+	_, err := AssignPins(board, []config.Component{gpio}, nil)
+	if err == nil || !strings.Contains(err.Error(), "GPIO_BUTTON") {
+		t.Errorf("Expected no free pin error, got %v", err)
+	}
+
+	dup := config.Component{Name: "dup", Pins: []config.PinRequest{{Name: "GPIO_LED", Function: config.PinGPIO}}}
+	if _, err := AssignPins(DefaultBoard, []config.Component{gpio, dup}, nil); err == nil || !strings.Contains(err.Error(), "重复声明") {
+		t.Errorf("Expected duplicate declaration error, got %v", err)
+	}
+}
+
+func TestAssignPinsOrderIndependent(t *testing.T) {
+	chdirRepoRoot(t)
+
+	boards, err := config.LoadBoards()
+	if err != nil {
+		t.Fatalf("LoadBoards failed: %v", err)
+	}
+	board, ok := config.FindBoard(boards, "ai-wb2-13")
+	if !ok {
+		t.Fatal("ai-wb2-13 not found")
+	}
+	gpio := config.Component{Name: "gpio", Pins: []config.PinRequest{
+		{Name: "GPIO_LED", Function: config.PinGPIO, Board: "led"},
+		{Name: "GPIO_BUTTON", Function: config.PinGPIO, Board: "button"},
+	}}
+	spi := config.Component{Name: "spi", Pins: []config.PinRequest{
+		{Name: "SPI_CLK", Function: config.PinSPISCLK, Default: intPtr(3)},
+		{Name: "SPI_CS", Function: config.PinGPIO, Default: intPtr(4)},
+	}}
+	adc := config.Component{Name: "adc", Pins: []config.PinRequest{{Name: "ADC_IN", Function: config.PinADC}}}
+
+	assign := func(components ...config.Component) map[string]int {
+		pins, err := AssignPins(*board, components, nil)
+		if err != nil {
+			t.Fatalf("AssignPins failed: %v", err)
+		}
+		byName := map[string]int{}
+		for _, pin := range pins {
+			byName[pin.Name] = pin.Pin
+		}
+		return byName
+	}
+
+	want := assign(gpio, spi, adc)
+	// 板载 LED 优先于 SPI_CLK 的默认引脚（都是 IO3）
+	if want["GPIO_LED"] != *board.LEDPin {
+		t.Errorf("Expected GPIO_LED on board LED IO%d, got IO%d", *board.LEDPin, want["GPIO_LED"])
+	}
+	for _, components := range [][]config.Component{{spi, gpio, adc}, {adc, spi, gpio}, {spi, adc, gpio}} {
+		got := assign(components...)
+		for name, pin := range want {
+			if got[name] != pin {
+				t.Errorf("%s: expected IO%d regardless of component order, got IO%d", name, pin, got[name])
+			}
+		}
+	}
+}
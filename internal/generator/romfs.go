@@ -0,0 +1,23 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// RomfsDir 打包为 ROMFS 镜像的目录（位于项目根目录），由 wb2-cli romfs build 打包，
+// 设备上挂载在 /romfs
+const RomfsDir = "romfs"
+
+// generateRomfs 创建 romfs 目录和示例文件，设备上可以通过 /romfs/hello.txt 读取
+func (g *Generator) generateRomfs(projectPath string, data *ProjectData) error {
+	dir := filepath.Join(projectPath, RomfsDir)
+	if err := g.out.MkdirAll(dir); err != nil {
+		return fmt.Errorf("创建 %s 目录失败: %v", RomfsDir, err)
+	}
+	content := fmt.Sprintf("Hello from %s!\n", data.ProjectName)
+	if err := g.out.WriteFile(filepath.Join(dir, "hello.txt"), []byte(content)); err != nil {
+		return fmt.Errorf("生成 %s/hello.txt 失败: %v", RomfsDir, err)
+	}
+	return nil
+}
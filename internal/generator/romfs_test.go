@@ -0,0 +1,24 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"wb2-cli/internal/config"
+)
+
+func TestGenerateProjectRomfs(t *testing.T) {
+	chdirRepoRoot(t)
+
+	projectDir := filepath.Join(t.TempDir(), "demo")
+	out := NewMemoryOutput()
+	gen := New(t.TempDir())
+	gen.SetOutput(out)
+	if err := gen.GenerateProject("demo", projectDir, []config.Component{}); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	if got := string(out.Content(filepath.Join(projectDir, RomfsDir, "hello.txt"))); got != "Hello from demo!\n" {
+		t.Errorf("Expected romfs sample file, got %q", got)
+	}
+}
@@ -0,0 +1,206 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SDKPathMode 生成的 Makefile 中引用 SDK 路径的方式
+type SDKPathMode string
+
+const (
+	// SDKPathAbsolute 写入 SDK 的绝对路径（默认）
+	SDKPathAbsolute SDKPathMode = "absolute"
+	// SDKPathRelative 写入相对于项目目录的路径
+	SDKPathRelative SDKPathMode = "relative"
+	// SDKPathEnv 只引用环境变量 BL60X_SDK_PATH
+	SDKPathEnv SDKPathMode = "env"
+	// SDKPathSubmodule SDK 作为 git 子模块位于项目目录内
+	SDKPathSubmodule SDKPathMode = "submodule"
+)
+
+// SDKPathEnvVar SDK 路径环境变量名
+const SDKPathEnvVar = "BL60X_SDK_PATH"
+
+// DefaultSubmoduleDir submodule 模式下 SDK 子模块的默认目录
+const DefaultSubmoduleDir = "sdk"
+
+// SDKRepository SDK 的 git 仓库，submodule 模式下提示用户添加子模块
+const SDKRepository = "https://github.com/Ai-Thinker-Open/Ai-Thinker-WB2"
+
+// SDKPathModes 返回所有支持的 SDK 路径模式
+func SDKPathModes() []SDKPathMode {
+	return []SDKPathMode{SDKPathAbsolute, SDKPathRelative, SDKPathEnv, SDKPathSubmodule}
+}
+
+// ParseSDKPathMode 解析 SDK 路径模式，空字符串视为 absolute
+func ParseSDKPathMode(s string) (SDKPathMode, error) {
+	if s == "" {
+		return SDKPathAbsolute, nil
+	}
+	for _, mode := range SDKPathModes() {
+		if string(mode) == s {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("未知的 SDK 路径模式: %s (可选: absolute, relative, env, submodule)", s)
+}
+
+// sdkPathRef 计算 Makefile 中 BL60X_SDK_PATH 的默认值，并检查它能否从项目目录解析到 SDK。
+// env 模式返回空字符串，表示 Makefile 只依赖环境变量。
+func (g *Generator) sdkPathRef(projectPath string) (string, error) {
+	switch g.sdkPathMode {
+	case "", SDKPathAbsolute:
+		absSDK, err := filepath.Abs(g.sdkPath)
+		if err != nil {
+			return "", fmt.Errorf("解析 SDK 路径失败: %v", err)
+		}
+		return filepath.ToSlash(absSDK), nil
+
+	case SDKPathRelative:
+		rel, err := relativeSDKPath(projectPath, g.sdkPath)
+		if err != nil {
+			return "", err
+		}
+		resolved, err := resolveFromProject(projectPath, rel)
+		if err != nil {
+			return "", err
+		}
+		if err := checkSDKResolves(resolved, g.sdkPath); err != nil {
+			return "", err
+		}
+		return "$(PROJECT_PATH)/" + filepath.ToSlash(rel), nil
+
+	case SDKPathSubmodule:
+		// SDK 已位于项目目录内（如 init 已添加子模块的项目）时直接引用
+		if rel, err := relativeSDKPath(projectPath, g.sdkPath); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			resolved, err := resolveFromProject(projectPath, rel)
+			if err != nil {
+				return "", err
+			}
+			if err := checkSDKResolves(resolved, g.sdkPath); err != nil {
+				return "", err
+			}
+			return "$(PROJECT_PATH)/" + filepath.ToSlash(rel), nil
+		}
+		// 否则引用子模块目录，生成后再用 git submodule add 添加
+		dir, err := cleanSubmoduleDir(g.submoduleDir)
+		if err != nil {
+			return "", err
+		}
+		return "$(PROJECT_PATH)/" + dir, nil
+
+	case SDKPathEnv:
+		envPath := os.Getenv(SDKPathEnvVar)
+		if envPath == "" {
+			return "", fmt.Errorf("env 模式要求设置环境变量 %s", SDKPathEnvVar)
+		}
+		if err := checkSDKResolves(envPath, g.sdkPath); err != nil {
+			return "", err
+		}
+		return "", nil
+	}
+
+	return "", fmt.Errorf("未知的 SDK 路径模式: %s", g.sdkPathMode)
+}
+
+// cleanSubmoduleDir 检查子模块目录是项目目录内的相对路径
+func cleanSubmoduleDir(dir string) (string, error) {
+	if dir == "" {
+		dir = DefaultSubmoduleDir
+	}
+	clean := filepath.ToSlash(filepath.Clean(dir))
+	if filepath.IsAbs(dir) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("SDK 子模块目录必须是项目目录内的相对路径: %s", dir)
+	}
+	return clean, nil
+}
+
+// MissingSubmodule 返回 submodule 模式下项目中尚不存在的 SDK 子模块目录（相对于项目目录），
+// 其他模式或目录已存在时返回空字符串
+func (g *Generator) MissingSubmodule(projectPath string) string {
+	if g.sdkPathMode != SDKPathSubmodule {
+		return ""
+	}
+	ref, err := g.sdkPathRef(projectPath)
+	if err != nil {
+		return ""
+	}
+	dir := manifestSDKPath(ref)
+	if _, err := os.Stat(filepath.Join(projectPath, dir)); err == nil {
+		return ""
+	}
+	return dir
+}
+
+// relativeSDKPath 计算 SDK 相对于项目目录的路径
+func relativeSDKPath(projectPath, sdkPath string) (string, error) {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("解析项目路径失败: %v", err)
+	}
+	absSDK, err := filepath.Abs(sdkPath)
+	if err != nil {
+		return "", fmt.Errorf("解析 SDK 路径失败: %v", err)
+	}
+	rel, err := filepath.Rel(absProject, absSDK)
+	if err != nil {
+		return "", fmt.Errorf("无法计算 SDK 相对路径: %v", err)
+	}
+	return rel, nil
+}
+
+// resolveFromProject 返回 make 在最终项目目录中展开 $(PROJECT_PATH)/rel 时实际访问的路径。
+// 项目目录可能尚未创建：先解析已存在的最近上级目录中的符号链接，
+// 之后新建的目录都是普通目录，rel 中的 .. 才能按字面计算
+func resolveFromProject(projectPath, rel string) (string, error) {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("解析项目路径失败: %v", err)
+	}
+
+	existing, missing := absProject, ""
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		missing = filepath.Join(filepath.Base(existing), missing)
+		existing = parent
+	}
+
+	physical, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("解析项目路径失败: %v", err)
+	}
+	return filepath.Join(physical, missing, rel), nil
+}
+
+// checkSDKResolves 检查 resolved 处存在 SDK（包含 make_scripts_riscv），且与 sdkPath 是同一目录
+func checkSDKResolves(resolved, sdkPath string) error {
+	resolvedInfo, err := os.Stat(resolved)
+	if err != nil {
+		return fmt.Errorf("SDK 路径无法从项目目录解析: %s", resolved)
+	}
+	if _, err := os.Stat(filepath.Join(resolved, "make_scripts_riscv")); err != nil {
+		return fmt.Errorf("SDK 路径 %s 中没有 make_scripts_riscv，不是有效的 SDK", resolved)
+	}
+	sdkInfo, err := os.Stat(sdkPath)
+	if err != nil {
+		return fmt.Errorf("SDK 路径不存在: %s", sdkPath)
+	}
+	if !os.SameFile(resolvedInfo, sdkInfo) {
+		return fmt.Errorf("SDK 路径 %s 与 %s 不是同一目录", resolved, sdkPath)
+	}
+	return nil
+}
+
+// manifestSDKPath 返回写入项目清单的 SDK 路径：relative 和 submodule 模式下为相对路径
+func manifestSDKPath(sdkPathRef string) string {
+	return strings.TrimPrefix(sdkPathRef, "$(PROJECT_PATH)/")
+}
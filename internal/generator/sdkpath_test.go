@@ -0,0 +1,187 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/config"
+)
+
+func TestParseSDKPathMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SDKPathMode
+		wantErr  bool
+	}{
+		{"", SDKPathAbsolute, false},
+		{"absolute", SDKPathAbsolute, false},
+		{"relative", SDKPathRelative, false},
+		{"env", SDKPathEnv, false},
+		{"submodule", SDKPathSubmodule, false},
+		{"bogus", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := ParseSDKPathMode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSDKPathMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if mode != tt.expected {
+				t.Errorf("ParseSDKPathMode(%q) = %q, want %q", tt.input, mode, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSDKPathRef(t *testing.T) {
+	tempDir := t.TempDir()
+	sdkDir := filepath.Join(tempDir, "sdk")
+	if err := os.MkdirAll(sdkDir, 0755); err != nil {
+		t.Fatalf("Failed to create SDK directory: %v", err)
+	}
+	projectDir := filepath.Join(tempDir, "projects", "demo")
+
+	// relative：SDK 目录中没有 make_scripts_riscv 时不是有效的 SDK
+	gen := New(sdkDir)
+	gen.SetSDKPathMode(SDKPathRelative)
+	if _, err := gen.sdkPathRef(projectDir); err == nil || !strings.Contains(err.Error(), "make_scripts_riscv") {
+		t.Errorf("Expected error for SDK without make_scripts_riscv, got %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(sdkDir, "make_scripts_riscv"), 0755); err != nil {
+		t.Fatalf("Failed to create make_scripts_riscv: %v", err)
+	}
+
+	gen = New(sdkDir)
+
+	// absolute
+	ref, err := gen.sdkPathRef(projectDir)
+	if err != nil {
+		t.Fatalf("absolute mode failed: %v", err)
+	}
+	if ref != filepath.ToSlash(sdkDir) {
+		t.Errorf("Expected absolute ref %q, got %q", filepath.ToSlash(sdkDir), ref)
+	}
+
+	// relative（项目目录尚未创建）
+	gen.SetSDKPathMode(SDKPathRelative)
+	ref, err = gen.sdkPathRef(projectDir)
+	if err != nil {
+		t.Fatalf("relative mode failed: %v", err)
+	}
+	if ref != "$(PROJECT_PATH)/../../sdk" {
+		t.Errorf("Expected relative ref '$(PROJECT_PATH)/../../sdk', got %q", ref)
+	}
+
+	// submodule：SDK 不在项目目录内时引用子模块目录，不要求目录已存在
+	gen.SetSDKPathMode(SDKPathSubmodule)
+	gen.SetSubmoduleDir("third_party/wb2-sdk/")
+	ref, err = gen.sdkPathRef(projectDir)
+	if err != nil {
+		t.Fatalf("submodule mode failed: %v", err)
+	}
+	if ref != "$(PROJECT_PATH)/third_party/wb2-sdk" {
+		t.Errorf("Expected submodule ref '$(PROJECT_PATH)/third_party/wb2-sdk', got %q", ref)
+	}
+	for _, dir := range []string{"..", "../sdk", filepath.Join(tempDir, "sdk"), "."} {
+		gen.SetSubmoduleDir(dir)
+		if _, err := gen.sdkPathRef(projectDir); err == nil {
+			t.Errorf("Expected error for submodule directory %q", dir)
+		}
+	}
+
+	// SDK 已位于项目目录内时直接引用
+	gen.SetSubmoduleDir(DefaultSubmoduleDir)
+	ref, err = gen.sdkPathRef(tempDir)
+	if err != nil {
+		t.Fatalf("submodule mode failed: %v", err)
+	}
+	if ref != "$(PROJECT_PATH)/sdk" {
+		t.Errorf("Expected submodule ref '$(PROJECT_PATH)/sdk', got %q", ref)
+	}
+
+	// env
+	gen.SetSDKPathMode(SDKPathEnv)
+	t.Setenv(SDKPathEnvVar, "")
+	if _, err := gen.sdkPathRef(projectDir); err == nil {
+		t.Error("Expected error when BL60X_SDK_PATH is not set in env mode")
+	}
+	t.Setenv(SDKPathEnvVar, filepath.Join(tempDir, "other"))
+	if _, err := gen.sdkPathRef(projectDir); err == nil {
+		t.Error("Expected error when BL60X_SDK_PATH points elsewhere")
+	}
+	t.Setenv(SDKPathEnvVar, sdkDir)
+	ref, err = gen.sdkPathRef(projectDir)
+	if err != nil {
+		t.Fatalf("env mode failed: %v", err)
+	}
+	if ref != "" {
+		t.Errorf("Expected empty ref in env mode, got %q", ref)
+	}
+}
+
+func TestSDKPathRefRelativeSymlink(t *testing.T) {
+	tempDir := t.TempDir()
+	sdkDir := filepath.Join(tempDir, "sdk")
+	if err := os.MkdirAll(filepath.Join(sdkDir, "make_scripts_riscv"), 0755); err != nil {
+		t.Fatalf("Failed to create SDK directory: %v", err)
+	}
+	realDir := filepath.Join(tempDir, "data", "workspace")
+	if err := os.MkdirAll(realDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(realDir, filepath.Join(tempDir, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	// 字面上 link/demo/../../sdk 是 SDK，但 make 经过符号链接实际访问的是 data/sdk
+	gen := New(sdkDir)
+	gen.SetSDKPathMode(SDKPathRelative)
+	if _, err := gen.sdkPathRef(filepath.Join(tempDir, "link", "demo")); err == nil {
+		t.Error("Expected error when the relative path does not resolve through the symlinked parent")
+	}
+
+	// 项目直接位于真实目录下时可以解析
+	if _, err := gen.sdkPathRef(filepath.Join(tempDir, "demo")); err != nil {
+		t.Errorf("Expected relative mode to resolve, got %v", err)
+	}
+}
+
+func TestGenerateProjectSubmodule(t *testing.T) {
+	chdirRepoRoot(t)
+
+	// new：项目目录尚不存在，SDK 在项目目录之外
+	projectDir := filepath.Join(t.TempDir(), "demo")
+	gen := New(t.TempDir())
+	gen.SetSDKPathMode(SDKPathSubmodule)
+	if err := gen.GenerateProject("demo", projectDir, nil); err != nil {
+		t.Fatalf("GenerateProject failed: %v", err)
+	}
+
+	makefile, err := os.ReadFile(filepath.Join(projectDir, "Makefile"))
+	if err != nil {
+		t.Fatalf("Failed to read Makefile: %v", err)
+	}
+	if !strings.Contains(string(makefile), "BL60X_SDK_PATH ?= $(PROJECT_PATH)/sdk") {
+		t.Errorf("Expected Makefile to reference the submodule, got:\n%s", makefile)
+	}
+	manifest, err := config.LoadManifest(projectDir)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if manifest.SDKPath != DefaultSubmoduleDir {
+		t.Errorf("Expected manifest sdk_path %q, got %q", DefaultSubmoduleDir, manifest.SDKPath)
+	}
+
+	if dir := gen.MissingSubmodule(projectDir); dir != DefaultSubmoduleDir {
+		t.Errorf("Expected missing submodule %q, got %q", DefaultSubmoduleDir, dir)
+	}
+	if err := os.Mkdir(filepath.Join(projectDir, DefaultSubmoduleDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if dir := gen.MissingSubmodule(projectDir); dir != "" {
+		t.Errorf("Expected no missing submodule after adding it, got %q", dir)
+	}
+}
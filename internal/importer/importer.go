@@ -0,0 +1,341 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+)
+
+// Result 导入已有项目的结果
+type Result struct {
+	Manifest *config.Manifest
+	// 映射到组件目录的组件
+	Mapped []config.Component
+}
+
+var (
+	// makeAssignRe 匹配 Makefile 中的变量赋值
+	makeAssignRe = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*(:=|\+=|\?=|=)\s*(.*)$`)
+	// configAssignRe 匹配 proj_config.mk 中的配置项
+	configAssignRe = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*(:=|\+=|\?=|=)\s*(.*?)\s*$`)
+)
+
+// ParseMakefile 解析项目 Makefile，返回 PROJECT_NAME、PROJECT_BOARD 和各组件列表。
+// 未知的 COMPONENTS_* 列表归入 INCLUDE_COMPONENTS；$(...) 引用会被忽略。
+func ParseMakefile(content string) (string, string, config.ExtraComponents) {
+	var name, board string
+	var lists config.ExtraComponents
+
+	for _, line := range logicalLines(content) {
+		m := makeAssignRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		variable, op, value := m[1], m[2], m[3]
+
+		var list *[]string
+		switch variable {
+		case "PROJECT_NAME":
+			name = strings.TrimSpace(value)
+			continue
+		case "PROJECT_BOARD":
+			board = strings.TrimSpace(value)
+			continue
+		case "INCLUDE_COMPONENTS":
+			list = &lists.Include
+		case "COMPONENTS_NETWORK":
+			list = &lists.Network
+		case "COMPONENTS_BLSYS":
+			list = &lists.BLSys
+		case "COMPONENTS_VFS":
+			list = &lists.VFS
+		case "COMPONENTS_MQTT":
+			list = &lists.MQTT
+		default:
+			if !strings.HasPrefix(variable, "COMPONENTS_") {
+				continue
+			}
+			list = &lists.Include
+			op = "+="
+		}
+
+		var names []string
+		for _, field := range strings.Fields(value) {
+			if strings.HasPrefix(field, "$(") || strings.HasPrefix(field, "${") {
+				continue
+			}
+			names = append(names, field)
+		}
+
+		if op == "+=" {
+			*list = append(*list, names...)
+		} else {
+			*list = names
+		}
+	}
+
+	return name, board, lists
+}
+
+// MatchBoard 将 Makefile 中的 PROJECT_BOARD 映射到模组目录：先按模组名称或型号匹配，
+// 再按 sdk_board 匹配（多个模组共用时优先默认模组）。找不到时返回 nil
+func MatchBoard(boards []config.Board, projectBoard string) *config.Board {
+	if projectBoard == "" {
+		return nil
+	}
+	if board, ok := config.FindBoard(boards, projectBoard); ok {
+		return board
+	}
+
+	var match *config.Board
+	for i := range boards {
+		board := &boards[i]
+		if board.SDKBoard != projectBoard {
+			continue
+		}
+		if match == nil || board.Default {
+			match = board
+		}
+	}
+	return match
+}
+
+// ParseConfig 解析 proj_config.mk 中的配置项：?= 只在未设置时赋值，+= 以空格追加
+func ParseConfig(content string) map[string]string {
+	flags := make(map[string]string)
+	for _, line := range logicalLines(content) {
+		m := configAssignRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name, op, value := m[1], m[2], m[3]
+		switch op {
+		case "?=":
+			if _, ok := flags[name]; !ok {
+				flags[name] = value
+			}
+		case "+=":
+			if old := flags[name]; old != "" && value != "" {
+				flags[name] = old + " " + value
+			} else if old != "" {
+				flags[name] = old
+			} else {
+				flags[name] = value
+			}
+		default:
+			flags[name] = value
+		}
+	}
+	return flags
+}
+
+// logicalLines 合并续行并去掉注释和空行
+func logicalLines(content string) []string {
+	var lines []string
+	var current strings.Builder
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimRight(line, " \t\r")
+
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(line)
+
+		if joined := strings.TrimSpace(current.String()); joined != "" {
+			lines = append(lines, joined)
+		}
+		current.Reset()
+	}
+	if joined := strings.TrimSpace(current.String()); joined != "" {
+		lines = append(lines, joined)
+	}
+
+	return lines
+}
+
+// allNames 返回所有列表中的组件名称集合
+func allNames(lists config.ExtraComponents) map[string]bool {
+	set := make(map[string]bool)
+	for _, list := range [][]string{lists.Include, lists.Network, lists.BLSys, lists.VFS, lists.MQTT} {
+		for _, name := range list {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// sdkNames 返回组件引入的所有 SDK 组件名称
+func sdkNames(comp config.Component) []string {
+	var names []string
+	names = append(names, comp.IncludeComponents...)
+	names = append(names, comp.NetworkComponents...)
+	names = append(names, comp.BLSysComponents...)
+	names = append(names, comp.VFSComponents...)
+	names = append(names, comp.MQTTComponents...)
+	return names
+}
+
+// MapComponents 将项目中出现的 SDK 组件映射回组件目录。
+// 组件的所有 SDK 组件都出现在项目中才算匹配；引入内容更多的组件优先，
+// 已被其他组件完全覆盖的组件（如 wifi 已包含的 sntp）不会重复映射。
+func MapComponents(catalog []config.Component, present map[string]bool) []config.Component {
+	covered := make(map[string]bool)
+	for _, base := range [][]string{generator.BaseIncludeComponents, generator.BaseBLSysComponents, generator.BaseVFSComponents} {
+		for _, name := range base {
+			covered[name] = true
+		}
+	}
+
+	var candidates []config.Component
+	for _, comp := range catalog {
+		names := sdkNames(comp)
+		if len(names) == 0 {
+			continue
+		}
+		matched := true
+		for _, name := range names {
+			if !present[name] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			candidates = append(candidates, comp)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(uniqueNames(sdkNames(candidates[i]))) > len(uniqueNames(sdkNames(candidates[j])))
+	})
+
+	var mapped []config.Component
+	for _, comp := range candidates {
+		contributes := false
+		for _, name := range sdkNames(comp) {
+			if !covered[name] {
+				contributes = true
+				covered[name] = true
+			}
+		}
+		if contributes {
+			mapped = append(mapped, comp)
+		}
+	}
+
+	return mapped
+}
+
+// uniqueNames 去重
+func uniqueNames(names []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// Import 解析 projectDir 中的 Makefile 和 proj_config.mk，生成项目清单。
+// 生成器对映射组件产生的输出作为基准，项目中多出的 SDK 组件记为额外组件，
+// 与基准不同的配置项记为配置覆盖。PROJECT_BOARD 映射到 boards 中的模组，
+// 无法映射时原样记录，避免重新生成时静默退回默认模组。
+func Import(projectDir string, catalog []config.Component, boards []config.Board, sdkPath string) (*Result, error) {
+	makefile, err := os.ReadFile(filepath.Join(projectDir, "Makefile"))
+	if err != nil {
+		return nil, fmt.Errorf("读取 Makefile 失败: %v", err)
+	}
+
+	name, projectBoard, lists := ParseMakefile(string(makefile))
+	if name == "" {
+		return nil, fmt.Errorf("Makefile 中没有 PROJECT_NAME")
+	}
+
+	flags := map[string]string{}
+	if data, err := os.ReadFile(filepath.Join(projectDir, "proj_config.mk")); err == nil {
+		flags = ParseConfig(string(data))
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取 proj_config.mk 失败: %v", err)
+	}
+
+	mapped := MapComponents(catalog, allNames(lists))
+
+	board := MatchBoard(boards, projectBoard)
+
+	// 在内存中为映射组件生成项目，作为比较基准；
+	// 基准不会写入磁盘，生成器的警告（如缺少射频参数）与导入无关，不输出
+	out := generator.NewMemoryOutput()
+	gen := generator.New(sdkPath)
+	gen.SetOutput(out)
+	if board != nil {
+		gen.SetBoard(*board)
+	}
+	if err := gen.GenerateProject(name, projectDir, mapped); err != nil {
+		return nil, fmt.Errorf("生成比较基准失败: %v", err)
+	}
+	_, _, generatedLists := ParseMakefile(string(out.Content(filepath.Join(projectDir, "Makefile"))))
+	generatedFlags := ParseConfig(string(out.Content(filepath.Join(projectDir, "proj_config.mk"))))
+
+	generated := allNames(generatedLists)
+	extra := func(list []string) []string {
+		var result []string
+		seen := make(map[string]bool)
+		for _, name := range list {
+			if !generated[name] && !seen[name] && name != "$(PROJECT_NAME)" {
+				seen[name] = true
+				result = append(result, name)
+			}
+		}
+		return result
+	}
+
+	manifest := &config.Manifest{
+		Name:        name,
+		SDKPath:     sdkPath,
+		SDKPathMode: string(generator.SDKPathAbsolute),
+		Board:       projectBoard,
+		Components:  []string{},
+		Extras: config.ExtraComponents{
+			Include: extra(lists.Include),
+			Network: extra(lists.Network),
+			BLSys:   extra(lists.BLSys),
+			VFS:     extra(lists.VFS),
+			MQTT:    extra(lists.MQTT),
+		},
+	}
+	if board != nil {
+		manifest.Board = board.Name
+	}
+	if sdkPath == "" {
+		manifest.SDKPathMode = string(generator.SDKPathEnv)
+	}
+
+	for _, comp := range mapped {
+		manifest.Components = append(manifest.Components, comp.Name)
+	}
+	sort.Strings(manifest.Components)
+
+	for key, value := range flags {
+		if generatedFlags[key] != value {
+			if manifest.ConfigFlags == nil {
+				manifest.ConfigFlags = make(map[string]string)
+			}
+			manifest.ConfigFlags[key] = value
+		}
+	}
+
+	return &Result{Manifest: manifest, Mapped: mapped}, nil
+}
@@ -0,0 +1,228 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"wb2-cli/internal/config"
+	"wb2-cli/internal/generator"
+)
+
+const sampleMakefile = `#
+# This is a project Makefile.
+#
+
+PROJECT_NAME := sdk_app_wifi
+PROJECT_PATH := $(abspath .)
+PROJECT_BOARD := evb
+export PROJECT_PATH PROJECT_BOARD
+
+-include ./proj_config.mk
+
+ifeq ($(origin BL60X_SDK_PATH), undefined)
+BL60X_SDK_PATH_GUESS ?= $(shell pwd)
+BL60X_SDK_PATH ?= $(BL60X_SDK_PATH_GUESS)/../..
+endif
+
+COMPONENTS_NETWORK := sntp dns_server
+COMPONENTS_BLSYS   := bltime blfdt blmtd bloop loopadc looprt loopset blota
+COMPONENTS_VFS     := romfs
+COMPONENTS_EXTRA   := my_driver
+
+INCLUDE_COMPONENTS += freertos_riscv_ram bl602 bl602_std newlibc \
+                      wifi wifi_manager wpa_supplicant bl_os_adapter wifi_hosal \
+                      hosal mbedtls_lts lwip lwip_dhcpd vfs yloop utils cli blog blog_testc
+INCLUDE_COMPONENTS += netutils blcrypto_suite rfparam_adapter_tmp coredump
+INCLUDE_COMPONENTS += httpc # http client
+INCLUDE_COMPONENTS += $(COMPONENTS_NETWORK)
+INCLUDE_COMPONENTS += $(COMPONENTS_BLSYS)
+INCLUDE_COMPONENTS += $(COMPONENTS_VFS)
+INCLUDE_COMPONENTS += $(PROJECT_NAME)
+
+include $(BL60X_SDK_PATH)/make_scripts_riscv/project.mk
+`
+
+var testCatalog = []config.Component{
+	{
+		Name: "wifi",
+		IncludeComponents: []string{
+			"wifi", "wifi_manager", "wpa_supplicant", "bl_os_adapter", "wifi_hosal",
+			"lwip_dhcpd", "netutils", "blcrypto_suite", "rfparam_adapter_tmp",
+		},
+		NetworkComponents: []string{"sntp", "dns_server"},
+		BLSysComponents:   []string{"blota", "loopadc"},
+		ConfigFlags:       map[string]string{"CONFIG_WIFI": "1"},
+	},
+	{Name: "sntp", Dependencies: []string{"wifi"}, NetworkComponents: []string{"sntp"}},
+	{Name: "http_client", Dependencies: []string{"wifi"}, IncludeComponents: []string{"httpc"}},
+	{Name: "ble", IncludeComponents: []string{"bl602_os_adapter"}},
+	{Name: "vfs", IncludeComponents: []string{"vfs"}},
+	{Name: "gpio"},
+}
+
+// testBoards 三个模组的引脚和 Flash 与默认模组相同，只有名称和 sdk_board 不同
+var testBoards = func() []config.Board {
+	boards := []config.Board{generator.DefaultBoard, generator.DefaultBoard, generator.DefaultBoard}
+	boards[0].Name, boards[0].Module = "ai-wb2-01s", "Ai-WB2-01S"
+	boards[1].Default = true
+	boards[2].Name, boards[2].Module, boards[2].SDKBoard = "custom", "Custom", "custom_board"
+	return boards
+}()
+
+func TestParseMakefile(t *testing.T) {
+	name, board, lists := ParseMakefile(sampleMakefile)
+
+	if name != "sdk_app_wifi" {
+		t.Errorf("Expected project name 'sdk_app_wifi', got %q", name)
+	}
+	if board != "evb" {
+		t.Errorf("Expected project board 'evb', got %q", board)
+	}
+	if !reflect.DeepEqual(lists.Network, []string{"sntp", "dns_server"}) {
+		t.Errorf("Unexpected network list: %v", lists.Network)
+	}
+	if !reflect.DeepEqual(lists.VFS, []string{"romfs"}) {
+		t.Errorf("Unexpected vfs list: %v", lists.VFS)
+	}
+
+	include := make(map[string]bool)
+	for _, name := range lists.Include {
+		include[name] = true
+	}
+	for _, want := range []string{"freertos_riscv_ram", "wifi_hosal", "blog_testc", "coredump", "httpc", "my_driver"} {
+		if !include[want] {
+			t.Errorf("Expected %q in include list, got %v", want, lists.Include)
+		}
+	}
+	for _, unwanted := range []string{"$(COMPONENTS_NETWORK)", "$(PROJECT_NAME)", "#", "http"} {
+		if include[unwanted] {
+			t.Errorf("Did not expect %q in include list", unwanted)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	flags := ParseConfig("#comment\nCONFIG_WIFI:=1\nCONFIG_A := 2 # trailing\n#CONFIG_B:=1\n")
+
+	expected := map[string]string{"CONFIG_WIFI": "1", "CONFIG_A": "2"}
+	if !reflect.DeepEqual(flags, expected) {
+		t.Errorf("Expected %v, got %v", expected, flags)
+	}
+
+	// ?= 只在未设置时赋值，+= 追加
+	flags = ParseConfig("CONFIG_BAUD ?= 2000000\nCONFIG_WIFI:=1\nCONFIG_WIFI ?= 0\nCONFIG_CFLAGS = -DA\nCONFIG_CFLAGS += -DB\nCONFIG_NEW += x\n")
+	expected = map[string]string{"CONFIG_BAUD": "2000000", "CONFIG_WIFI": "1", "CONFIG_CFLAGS": "-DA -DB", "CONFIG_NEW": "x"}
+	if !reflect.DeepEqual(flags, expected) {
+		t.Errorf("Expected %v, got %v", expected, flags)
+	}
+}
+
+func TestMapComponents(t *testing.T) {
+	_, _, lists := ParseMakefile(sampleMakefile)
+	mapped := MapComponents(testCatalog, allNames(lists))
+
+	var names []string
+	for _, comp := range mapped {
+		names = append(names, comp.Name)
+	}
+
+	// sntp 已被 wifi 覆盖，vfs 属于基础组件，ble 不存在
+	expected := []string{"wifi", "http_client"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected mapped %v, got %v", expected, names)
+	}
+}
+
+func TestImport(t *testing.T) {
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(filepath.Join("..", "..")); err != nil {
+		t.Fatalf("Failed to change to repo root: %v", err)
+	}
+
+	projectDir := t.TempDir()
+	os.WriteFile(filepath.Join(projectDir, "Makefile"), []byte(sampleMakefile), 0644)
+	os.WriteFile(filepath.Join(projectDir, "proj_config.mk"), []byte("CONFIG_WIFI:=1\nCONFIG_SYS_AOS_CLI_ENABLE:=1\nCONFIG_CUSTOM:=3\n"), 0644)
+
+	result, err := Import(projectDir, testCatalog, testBoards, "/opt/sdk")
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	manifest := result.Manifest
+	if manifest.Name != "sdk_app_wifi" {
+		t.Errorf("Expected name 'sdk_app_wifi', got %q", manifest.Name)
+	}
+	if manifest.Board != "ai-wb2-12f" {
+		t.Errorf("Expected PROJECT_BOARD evb to map to the default board, got %q", manifest.Board)
+	}
+	if !reflect.DeepEqual(manifest.Components, []string{"http_client", "wifi"}) {
+		t.Errorf("Unexpected components: %v", manifest.Components)
+	}
+	if !reflect.DeepEqual(manifest.Extras.Include, []string{"my_driver"}) {
+		t.Errorf("Expected extras [my_driver], got %+v", manifest.Extras)
+	}
+	if len(manifest.Extras.Network) != 0 || len(manifest.Extras.BLSys) != 0 {
+		t.Errorf("Expected no network/blsys extras, got %+v", manifest.Extras)
+	}
+
+	// CONFIG_WIFI 与模板一致，不应记为覆盖
+	expectedFlags := map[string]string{"CONFIG_SYS_AOS_CLI_ENABLE": "1", "CONFIG_CUSTOM": "3"}
+	if !reflect.DeepEqual(manifest.ConfigFlags, expectedFlags) {
+		t.Errorf("Expected config flags %v, got %v", expectedFlags, manifest.ConfigFlags)
+	}
+}
+
+func TestMatchBoard(t *testing.T) {
+	tests := []struct {
+		projectBoard string
+		expected     string
+	}{
+		{"evb", "ai-wb2-12f"},
+		{"custom_board", "custom"},
+		{"Ai-WB2-01S", "ai-wb2-01s"},
+		{"unknown", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		var got string
+		if board := MatchBoard(testBoards, tt.projectBoard); board != nil {
+			got = board.Name
+		}
+		if got != tt.expected {
+			t.Errorf("MatchBoard(%q) = %q, expected %q", tt.projectBoard, got, tt.expected)
+		}
+	}
+}
+
+func TestImportUnknownBoard(t *testing.T) {
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(filepath.Join("..", "..")); err != nil {
+		t.Fatalf("Failed to change to repo root: %v", err)
+	}
+
+	// 无法映射的 PROJECT_BOARD 原样记录，不退回默认模组
+	projectDir := t.TempDir()
+	os.WriteFile(filepath.Join(projectDir, "Makefile"), []byte("PROJECT_NAME := demo\nPROJECT_BOARD := my_board\n"), 0644)
+
+	result, err := Import(projectDir, testCatalog, testBoards, "")
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Manifest.Board != "my_board" {
+		t.Errorf("Expected board 'my_board', got %q", result.Manifest.Board)
+	}
+}
+
+func TestImportMissingProjectName(t *testing.T) {
+	projectDir := t.TempDir()
+	os.WriteFile(filepath.Join(projectDir, "Makefile"), []byte("INCLUDE_COMPONENTS += wifi\n"), 0644)
+
+	if _, err := Import(projectDir, testCatalog, testBoards, ""); err == nil {
+		t.Error("Expected error when PROJECT_NAME is missing")
+	}
+}
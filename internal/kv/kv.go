@@ -0,0 +1,312 @@
+// Package kv 解析项目的 kv_defaults.yaml，生成 EasyFlash（easyflash4 组件）的默认键值表，
+// 并估算键值在存储分区中占用的空间
+package kv
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File 默认键值文件（位于项目根目录）
+const File = "kv_defaults.yaml"
+
+// EasyFlash 的限制（SDK easyflash4 的 ef_cfg.h 和 ef_env.c）
+const (
+	// MaxKeyLen 键的最大长度（EF_ENV_NAME_MAX）
+	MaxKeyLen = 32
+	// MaxStringLen ef_get_env 读取字符串值的缓冲区大小（EF_STR_ENV_VALUE_MAX_SIZE），
+	// 更长的字符串需要用 ef_get_env_blob 读取
+	MaxStringLen = 128
+)
+
+// Type 值的类型
+type Type string
+
+const (
+	TypeString Type = "string"
+	TypeBool   Type = "bool"
+	TypeU8     Type = "u8"
+	TypeU16    Type = "u16"
+	TypeU32    Type = "u32"
+	TypeU64    Type = "u64"
+	TypeI8     Type = "i8"
+	TypeI16    Type = "i16"
+	TypeI32    Type = "i32"
+	TypeI64    Type = "i64"
+	// TypeHex 十六进制表示的字节串
+	TypeHex Type = "hex"
+)
+
+// intSizes 整数类型的字节数
+var intSizes = map[Type]int{
+	TypeU8: 1, TypeU16: 2, TypeU32: 4, TypeU64: 8,
+	TypeI8: 1, TypeI16: 2, TypeI32: 4, TypeI64: 8,
+}
+
+// Types 支持的全部类型
+var Types = []Type{TypeString, TypeBool, TypeU8, TypeU16, TypeU32, TypeU64, TypeI8, TypeI16, TypeI32, TypeI64, TypeHex}
+
+// Entry 一个默认键值
+type Entry struct {
+	Key  string
+	Type Type
+	// Value YAML 中的原始值
+	Value string
+	// Data 写入 EasyFlash 的数据：字符串不含结尾的 0，整数为小端序（与 BL602 一致），
+	// bool 为 1 字节
+	Data []byte
+}
+
+// C 返回值在 C 中的类型，字符串和字节串为空
+func (t Type) C() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeU8, TypeU16, TypeU32, TypeU64:
+		return fmt.Sprintf("uint%d_t", intSizes[t]*8)
+	case TypeI8, TypeI16, TypeI32, TypeI64:
+		return fmt.Sprintf("int%d_t", intSizes[t]*8)
+	}
+	return ""
+}
+
+// Parse 解析 kv_defaults.yaml。每个键可以直接写值（按 YAML 类型推断为 string、i32 或 bool），
+// 也可以写为 {type, value}：
+//
+//	device_name: my_device
+//	boot_times:
+//	  type: u32
+//	  value: 0
+func Parse(data []byte) ([]Entry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", File, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s 应为键到值的映射", File)
+	}
+
+	var entries []Entry
+	// 宏名 -> 键，不同的键可能生成相同的宏名
+	macros := make(map[string]string)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], root.Content[i+1]
+		key := keyNode.Value
+		if err := checkKey(key); err != nil {
+			return nil, fmt.Errorf("第 %d 行: %v", keyNode.Line, err)
+		}
+		macro := MacroName(key)
+		if other, ok := macros[macro]; ok {
+			if other == key {
+				return nil, fmt.Errorf("第 %d 行: 键 %q 重复", keyNode.Line, key)
+			}
+			return nil, fmt.Errorf("第 %d 行: 键 %q 和 %q 的宏名都是 %s", keyNode.Line, other, key, macro)
+		}
+		macros[macro] = key
+		entry, err := parseValue(key, valueNode)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: 键 %s: %v", valueNode.Line, key, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// checkKey 检查键名能否用于 EasyFlash 和生成的 C 代码
+func checkKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("键不能为空")
+	}
+	if len(key) > MaxKeyLen {
+		return fmt.Errorf("键 %q 长度为 %d，超过 EasyFlash 的上限 %d（EF_ENV_NAME_MAX）", key, len(key), MaxKeyLen)
+	}
+	for _, r := range key {
+		if r <= ' ' || r > '~' || r == '"' || r == '\\' {
+			return fmt.Errorf("键 %q 只能包含可打印的 ASCII 字符（不含空格、引号和反斜杠）", key)
+		}
+	}
+	return nil
+}
+
+// parseValue 解析一个键的值
+func parseValue(key string, node *yaml.Node) (Entry, error) {
+	entry := Entry{Key: key}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		entry.Value = node.Value
+		switch node.Tag {
+		case "!!int":
+			entry.Type = TypeI32
+		case "!!bool":
+			entry.Type = TypeBool
+		case "!!str":
+			entry.Type = TypeString
+		default:
+			return entry, fmt.Errorf("无法推断 %q 的类型，请使用 {type, value} 指定", node.Value)
+		}
+	case yaml.MappingNode:
+		var typed struct {
+			Type  string    `yaml:"type"`
+			Value yaml.Node `yaml:"value"`
+		}
+		if err := node.Decode(&typed); err != nil {
+			return entry, err
+		}
+		if typed.Value.Kind != yaml.ScalarNode {
+			return entry, fmt.Errorf("缺少 value 或 value 不是标量")
+		}
+		entry.Type, entry.Value = Type(typed.Type), typed.Value.Value
+		if typed.Type == "" {
+			return entry, fmt.Errorf("缺少 type")
+		}
+	default:
+		return entry, fmt.Errorf("值应为标量或 {type, value}")
+	}
+
+	data, err := Encode(entry.Type, entry.Value)
+	if err != nil {
+		return entry, err
+	}
+	entry.Data = data
+	return entry, nil
+}
+
+// Encode 把值编码为写入 EasyFlash 的数据
+func Encode(t Type, value string) ([]byte, error) {
+	switch t {
+	case TypeString:
+		if strings.IndexByte(value, 0) >= 0 {
+			return nil, fmt.Errorf("字符串不能包含 NUL 字符，请使用 hex 类型")
+		}
+		return []byte(value), nil
+	case TypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q 不是 bool 值", value)
+		}
+		if b {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+	case TypeHex:
+		s := strings.NewReplacer(" ", "", ":", "", "0x", "", "0X", "").Replace(value)
+		data, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q 不是有效的十六进制字节串", value)
+		}
+		return data, nil
+	}
+
+	size, ok := intSizes[t]
+	if !ok {
+		return nil, fmt.Errorf("不支持的类型 %q（支持 %s）", t, typeList())
+	}
+	var u uint64
+	if t[0] == 'u' {
+		v, err := strconv.ParseUint(value, 0, size*8)
+		if err != nil {
+			return nil, fmt.Errorf("%q 超出 %s 的范围或不是整数", value, t)
+		}
+		u = v
+	} else {
+		v, err := strconv.ParseInt(value, 0, size*8)
+		if err != nil {
+			return nil, fmt.Errorf("%q 超出 %s 的范围或不是整数", value, t)
+		}
+		u = uint64(v)
+	}
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, u)
+	return data[:size], nil
+}
+
+func typeList() string {
+	names := make([]string, len(Types))
+	for i, t := range Types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, "、")
+}
+
+// EasyFlash 在 NOR Flash 上的存储布局（EF_WRITE_GRAN 为 1），用于估算占用
+const (
+	// SectorSize EasyFlash 的扇区大小（EF_ERASE_MIN_SIZE）
+	SectorSize = 4096
+	// sectorHeaderSize 每个扇区头的大小
+	sectorHeaderSize = 16
+	// envHeaderSize 每个键值头的大小（状态、magic、长度、CRC32、名称长度、值长度）
+	envHeaderSize = 24
+	// gcSectors 垃圾回收保留的空扇区数（EF_GC_EMPTY_SEC_THRESHOLD）
+	gcSectors = 1
+)
+
+// NodeSize 估算一个键值在 Flash 中占用的字节数
+func NodeSize(e Entry) int {
+	return envHeaderSize + align4(len(e.Key)) + align4(len(e.Data))
+}
+
+// Capacity 估算大小为 partitionSize 的分区可以存放的键值字节数
+func Capacity(partitionSize int) int {
+	sectors := partitionSize/SectorSize - gcSectors
+	if sectors <= 0 {
+		return 0
+	}
+	return sectors * (SectorSize - sectorHeaderSize)
+}
+
+// Check 检查键值能否放入大小为 partitionSize 的分区，返回估算的占用
+func Check(entries []Entry, partitionSize int) (int, error) {
+	used := 0
+	for _, e := range entries {
+		size := NodeSize(e)
+		// 键值不能跨扇区
+		if size > SectorSize-sectorHeaderSize {
+			return 0, fmt.Errorf("键 %s 占用 %d 字节，超过一个扇区可以存放的 %d 字节", e.Key, size, SectorSize-sectorHeaderSize)
+		}
+		used += size
+	}
+	if capacity := Capacity(partitionSize); used > capacity {
+		return used, fmt.Errorf("默认键值约占用 %d 字节，超过分区可用的约 %d 字节", used, capacity)
+	}
+	return used, nil
+}
+
+// Warnings 返回不影响使用但需要注意的问题
+func Warnings(entries []Entry) []string {
+	var warnings []string
+	for _, e := range entries {
+		if e.Type == TypeString && len(e.Data) >= MaxStringLen {
+			warnings = append(warnings, fmt.Sprintf("键 %s 的字符串长度为 %d，ef_get_env 最多读取 %d 字节，请使用 ef_get_env_blob", e.Key, len(e.Data), MaxStringLen-1))
+		}
+	}
+	return warnings
+}
+
+func align4(n int) int {
+	return (n + 3) &^ 3
+}
+
+// Default 新项目的 kv_defaults.yaml
+func Default(projectName string) []byte {
+	return []byte(fmt.Sprintf(`# EasyFlash 默认键值：设备启动时写入不存在的键，已有的值不会被覆盖
+# 修改后运行 wb2-cli kv 检查并重新生成 %s/kv_defaults.c
+#
+# 键名最多 %d 个字符；值可以直接写（推断为 string、i32 或 bool），
+# 也可以用 {type, value} 指定类型：%s
+# 整数按小端序存储，可以用 ef_get_env_blob(key, &value, sizeof(value), NULL) 读取
+
+device_name: %s
+boot_times:
+  type: u32
+  value: 0
+`, projectName, MaxKeyLen, typeList(), projectName))
+}
@@ -0,0 +1,147 @@
+package kv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`
+device_name: demo
+debug: true
+retries: 3
+boot_times:
+  type: u32
+  value: 0x10
+offset:
+  type: i16
+  value: -2
+key:
+  type: hex
+  value: "de:ad be ef"
+empty: ""
+`)
+	entries, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		key  string
+		typ  Type
+		data []byte
+	}{
+		{"device_name", TypeString, []byte("demo")},
+		{"debug", TypeBool, []byte{1}},
+		{"retries", TypeI32, []byte{3, 0, 0, 0}},
+		{"boot_times", TypeU32, []byte{0x10, 0, 0, 0}},
+		{"offset", TypeI16, []byte{0xfe, 0xff}},
+		{"key", TypeHex, []byte{0xde, 0xad, 0xbe, 0xef}},
+		{"empty", TypeString, []byte{}},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Key != w.key || e.Type != w.typ || !bytes.Equal(e.Data, w.data) {
+			t.Errorf("entry %d = %s %s %x, want %s %s %x", i, e.Key, e.Type, e.Data, w.key, w.typ, w.data)
+		}
+	}
+
+	if entries, err := Parse([]byte("# 只有注释\n")); err != nil || len(entries) != 0 {
+		t.Errorf("Parse(empty) = %v, %v", entries, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"- a\n- b\n", "映射"},
+		{"a: 1\na: 2\n", "重复"},
+		{"a.b: 1\na_b: 2\n", "KV_A_B"},
+		{strings.Repeat("k", MaxKeyLen+1) + ": 1\n", "EF_ENV_NAME_MAX"},
+		{"\"a b\": 1\n", "可打印"},
+		{"a: 1.5\n", "无法推断"},
+		{"a: [1]\n", "标量"},
+		{"a:\n  value: 1\n", "缺少 type"},
+		{"a:\n  type: u8\n", "缺少 value"},
+		{"a:\n  type: u8\n  value: 256\n", "u8 的范围"},
+		{"a:\n  type: i8\n  value: -129\n", "i8 的范围"},
+		{"a:\n  type: float\n  value: 1\n", "不支持的类型"},
+		{"a:\n  type: hex\n  value: xyz\n", "十六进制"},
+		{"a:\n  type: bool\n  value: maybe\n", "bool"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want containing %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	entries := []Entry{
+		{Key: "boot_times", Data: make([]byte, 4)},
+		{Key: "name", Data: []byte("demo1")},
+	}
+	// 24 + 12 + 4，24 + 4 + 8
+	used, err := Check(entries, 64*1024)
+	if err != nil || used != 76 {
+		t.Errorf("Check() = %d, %v, want 76", used, err)
+	}
+
+	if _, err := Check(entries, SectorSize); err == nil || !strings.Contains(err.Error(), "超过分区") {
+		t.Errorf("Check() in one sector error = %v", err)
+	}
+	big := []Entry{{Key: "cert", Data: make([]byte, SectorSize)}}
+	if _, err := Check(big, 64*1024); err == nil || !strings.Contains(err.Error(), "一个扇区") {
+		t.Errorf("Check() with oversized value error = %v", err)
+	}
+}
+
+func TestDefaultParses(t *testing.T) {
+	entries, err := Parse(Default("demo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Key != "device_name" || string(entries[0].Data) != "demo" || entries[1].Type != TypeU32 {
+		t.Errorf("Default() entries = %+v", entries)
+	}
+}
+
+func TestSource(t *testing.T) {
+	entries, err := Parse([]byte("wifi.ssid: \"a*/b\"\nboot_times:\n  type: u16\n  value: 258\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := string(Header(entries))
+	for _, want := range []string{
+		`#define KV_WIFI_SSID "wifi.ssid"  /* string "a*\/b" */`,
+		`#define KV_BOOT_TIMES "boot_times"  /* uint16_t 258 */`,
+		"void kv_defaults_init(void);",
+	} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header missing %q:\n%s", want, header)
+		}
+	}
+
+	source := string(Source(entries))
+	for _, want := range []string{
+		"static const uint8_t kv_value_0[] = {0x61, 0x2a, 0x2f, 0x62};",
+		"static const uint8_t kv_value_1[] = {0x02, 0x01};",
+		"{KV_WIFI_SSID, (void *)kv_value_0, 4},",
+		"{KV_BOOT_TIMES, (void *)kv_value_1, 2},",
+		"ef_set_env_blob(",
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("source missing %q:\n%s", want, source)
+		}
+	}
+
+	if empty := string(Source(nil)); strings.Contains(empty, "kv_default_env") || !strings.Contains(empty, "void kv_defaults_init(void)") {
+		t.Errorf("Source(nil) =\n%s", empty)
+	}
+}
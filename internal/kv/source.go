@@ -0,0 +1,139 @@
+package kv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 生成的 C 文件（位于项目源代码目录，由 SDK 自动编译）
+const (
+	SourceFile = "kv_defaults.c"
+	HeaderFile = "kv_defaults.h"
+)
+
+// MacroName 键在 kv_defaults.h 中的宏名，例如 boot_times -> KV_BOOT_TIMES
+func MacroName(key string) string {
+	var sb strings.Builder
+	sb.WriteString("KV_")
+	for _, r := range strings.ToUpper(key) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// Header 生成 kv_defaults.h：kv_defaults_init 的声明和每个键的宏
+func Header(entries []Entry) []byte {
+	var sb strings.Builder
+	sb.WriteString(`/**
+ * @file kv_defaults.h
+ * @brief EasyFlash 默认键值，由 wb2-cli 根据 kv_defaults.yaml 生成，请勿手动修改
+ */
+
+#ifndef KV_DEFAULTS_H
+#define KV_DEFAULTS_H
+
+`)
+	for _, e := range entries {
+		fmt.Fprintf(&sb, "#define %s %s  /* %s */\n", MacroName(e.Key), strconv.Quote(e.Key), describe(e))
+	}
+	if len(entries) > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`/* 写入 EasyFlash 中不存在的默认键值，SDK 启动时已初始化 EasyFlash */
+void kv_defaults_init(void);
+
+#endif /* KV_DEFAULTS_H */
+`)
+	return []byte(sb.String())
+}
+
+// Source 生成 kv_defaults.c：默认键值表（ef_env）和写入缺失键值的函数。
+//
+// 该表没有作为 EasyFlash 的默认键值表（ef_port_init 返回的 default_env_set）：它定义在
+// SDK 的 ef_port.c 中，替换需要修改 SDK；而且 EasyFlash 只在存储为空或损坏时写入默认
+// 键值，已有数据的设备不会得到新增的键。因此 kv_defaults_init() 在启动时补充不存在的键，
+// 不覆盖设备上已有的值
+func Source(entries []Entry) []byte {
+	var sb strings.Builder
+	sb.WriteString(`/**
+ * @file kv_defaults.c
+ * @brief EasyFlash 默认键值，由 wb2-cli 根据 kv_defaults.yaml 生成，请勿手动修改
+ */
+
+#include <stddef.h>
+#include <stdint.h>
+#include <easyflash.h>
+#include "blog.h"
+#include "kv_defaults.h"
+`)
+	if len(entries) == 0 {
+		sb.WriteString(`
+void kv_defaults_init(void)
+{
+    /* kv_defaults.yaml 中没有默认键值 */
+}
+`)
+		return []byte(sb.String())
+	}
+
+	sb.WriteString("\n")
+	for i, e := range entries {
+		fmt.Fprintf(&sb, "/* %s: %s */\n", e.Key, describe(e))
+		fmt.Fprintf(&sb, "static const uint8_t kv_value_%d[] = {%s};\n", i, byteList(e.Data))
+	}
+
+	sb.WriteString("\nstatic const ef_env kv_default_env[] = {\n")
+	for i, e := range entries {
+		fmt.Fprintf(&sb, "    {%s, (void *)kv_value_%d, %d},\n", MacroName(e.Key), i, len(e.Data))
+	}
+	sb.WriteString(`};
+
+void kv_defaults_init(void)
+{
+    size_t i, saved_len;
+
+    for (i = 0; i < sizeof(kv_default_env) / sizeof(kv_default_env[0]); i++) {
+        saved_len = 0;
+        ef_get_env_blob(kv_default_env[i].key, NULL, 0, &saved_len);
+        if (saved_len > 0 || kv_default_env[i].value_len == 0) {
+            continue;
+        }
+        if (ef_set_env_blob(kv_default_env[i].key, kv_default_env[i].value, kv_default_env[i].value_len) != EF_NO_ERR) {
+            blog_error("kv: failed to set default %s", kv_default_env[i].key);
+        }
+    }
+}
+`)
+	return []byte(sb.String())
+}
+
+// describe 键的类型和值，用于生成代码中的注释
+func describe(e Entry) string {
+	value := e.Value
+	if e.Type == TypeString {
+		value = strconv.Quote(value)
+	}
+	// 避免值中的 */ 结束注释
+	value = strings.ReplaceAll(value, "*/", "*\\/")
+	if c := e.Type.C(); c != "" {
+		return c + " " + value
+	}
+	return string(e.Type) + " " + value
+}
+
+// byteList 把数据格式化为 C 数组的初始化列表，空数据为 0（数组不能为空）
+func byteList(data []byte) string {
+	if len(data) == 0 {
+		return "0"
+	}
+	parts := make([]string, len(data))
+	for i, b := range data {
+		parts[i] = fmt.Sprintf("0x%02x", b)
+	}
+	return strings.Join(parts, ", ")
+}
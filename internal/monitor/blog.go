@@ -0,0 +1,134 @@
+// Package monitor 实现串口监视：解析 SDK blog 日志行，按级别着色并过滤
+package monitor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Level blog 日志级别
+type Level int
+
+const (
+	// LevelNone 不是 blog 格式的行，例如启动信息和命令行输出
+	LevelNone Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelAssert
+)
+
+// levelNames blog 输出的级别名称
+var levelNames = map[string]Level{
+	"DEBUG":  LevelDebug,
+	"INFO":   LevelInfo,
+	"WARN":   LevelWarn,
+	"ERROR":  LevelError,
+	"ASSERT": LevelAssert,
+}
+
+// ParseLevel 解析级别名称（不区分大小写），如 warn
+func ParseLevel(name string) (Level, bool) {
+	level, ok := levelNames[strings.ToUpper(strings.TrimSpace(name))]
+	return level, ok
+}
+
+func (l Level) String() string {
+	for name, level := range levelNames {
+		if level == l {
+			return name
+		}
+	}
+	return ""
+}
+
+// Line 解析后的一行串口输出
+type Line struct {
+	// Text 去掉行尾和 ANSI 转义序列后的内容
+	Text  string
+	Level Level
+	// Tick blog 输出的 FreeRTOS 系统节拍
+	Tick uint32
+	// File、LineNo 输出日志的源文件名和行号
+	File   string
+	LineNo int
+	// Message 日志正文
+	Message string
+	// Tags 正文开头的 [标签]，例如 main.c 中 "[APP] [EVT] GOT IP" 的 APP 和 EVT
+	Tags []string
+}
+
+// IsEvent 判断是否是生成的 main.c 输出的 [APP] [EVT] 事件
+func (l Line) IsEvent() bool {
+	return l.HasTag("EVT")
+}
+
+// HasTag 判断正文是否带有指定标签（不区分大小写）
+func (l Line) HasTag(tag string) bool {
+	for _, t := range l.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// blogLine 匹配 blog_info 等宏的输出格式 "[%10u][%s: %s:%4d] "，
+// 级别名称后可能有对齐用的空格
+var blogLine = regexp.MustCompile(`^\[\s*(\d+)\]\[\s*([A-Za-z]+)\s*:\s*([^:\]]+?)\s*:\s*(\d+)\]\s?(.*)$`)
+
+// ansiEscape 匹配 ANSI 转义序列，blog 开启颜色时级别名称带颜色
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// StripANSI 去掉 ANSI 转义序列
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// ParseLine 解析一行输出（不含换行符）；不是 blog 格式的行 Level 为 LevelNone
+func ParseLine(s string) Line {
+	text := StripANSI(strings.TrimRight(s, "\r\n"))
+	line := Line{Text: text, Message: text}
+
+	m := blogLine.FindStringSubmatch(text)
+	if m == nil {
+		return line
+	}
+	level, ok := ParseLevel(m[2])
+	if !ok {
+		return line
+	}
+	tick, _ := strconv.ParseUint(m[1], 10, 32)
+	lineNo, _ := strconv.Atoi(m[4])
+	line.Level = level
+	line.Tick = uint32(tick)
+	line.File = m[3]
+	line.LineNo = lineNo
+	line.Message = m[5]
+	line.Tags = parseTags(m[5])
+	return line
+}
+
+// parseTags 解析正文开头连续的 [标签]
+func parseTags(message string) []string {
+	var tags []string
+	rest := message
+	for strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]")
+		if end <= 1 {
+			break
+		}
+		tag := rest[1:end]
+		if strings.ContainsAny(tag, " \t") {
+			break
+		}
+		tags = append(tags, tag)
+		rest = strings.TrimLeft(rest[end+1:], " ")
+	}
+	return tags
+}
@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"wb2-cli/internal/size"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Line
+	}{
+		{
+			"[      1234][INFO  : main.c: 114] [APP] [EVT] GOT IP 5678\r",
+			Line{Level: LevelInfo, Tick: 1234, File: "main.c", LineNo: 114, Message: "[APP] [EVT] GOT IP 5678", Tags: []string{"APP", "EVT"}},
+		},
+		{
+			// blog 开启颜色时级别名称带 ANSI 转义序列
+			"[        12][\x1b[31mERROR \x1b[0m: hal_uart.c:  88] uart init failed",
+			Line{Level: LevelError, Tick: 12, File: "hal_uart.c", LineNo: 88, Message: "uart init failed"},
+		},
+		{
+			"[         0][WARN  : bl_sys.c:1024] [SYS] reset reason",
+			Line{Level: LevelWarn, File: "bl_sys.c", LineNo: 1024, Message: "[SYS] reset reason", Tags: []string{"SYS"}},
+		},
+		{
+			"[         5][DEBUG : wifi_mgmr.c:  40] [not a tag] x",
+			Line{Level: LevelDebug, Tick: 5, File: "wifi_mgmr.c", LineNo: 40, Message: "[not a tag] x"},
+		},
+		{"Booting BL602 Chip...", Line{Message: "Booting BL602 Chip..."}},
+		{"[12][TRACE: a.c: 1] unknown level", Line{Message: "[12][TRACE: a.c: 1] unknown level"}},
+	}
+	for _, tt := range tests {
+		got := ParseLine(tt.input)
+		tt.want.Text = StripANSI(strings.TrimRight(tt.input, "\r"))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLine(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	event := ParseLine("[1][INFO  : main.c: 87] [APP] [EVT] INIT DONE 12")
+	warn := ParseLine("[1][WARN  : tcp.c: 5] retransmit")
+	debug := ParseLine("[1][DEBUG : main.c: 9] detail")
+	raw := ParseLine("# help")
+
+	tests := []struct {
+		filter    Filter
+		line      Line
+		component string
+		want      bool
+	}{
+		{Filter{}, debug, "", true},
+		{Filter{MinLevel: LevelInfo}, debug, "demo", false},
+		{Filter{MinLevel: LevelWarn}, warn, "lwip", true},
+		{Filter{MinLevel: LevelError}, raw, "", true},
+		{Filter{Components: []string{"lwip"}}, warn, "lwip", true},
+		{Filter{Components: []string{"LWIP"}}, event, "demo", false},
+		{Filter{Components: []string{"demo"}}, event, "demo", true},
+		{Filter{Components: []string{"app"}}, event, "", true},
+		{Filter{Components: []string{"main"}}, event, "", true},
+		{Filter{Components: []string{"tcp.c"}}, warn, "", true},
+		{Filter{Components: []string{"demo"}}, raw, "", false},
+	}
+	for i, tt := range tests {
+		if got := tt.filter.Match(tt.line, tt.component); got != tt.want {
+			t.Errorf("#%d %+v.Match(%q, %q) = %v, want %v", i, tt.filter, tt.line.Text, tt.component, got, tt.want)
+		}
+	}
+}
+
+func TestSourceComponents(t *testing.T) {
+	m := &size.MapFile{Inputs: []size.InputSection{
+		{Name: ".text.main", File: "build_out/demo/libdemo.a(main.o)", Archive: "build_out/demo/libdemo.a", Object: "main.o"},
+		{Name: ".text.tcp", File: "build_out/lwip/liblwip.a(tcp.o)", Archive: "build_out/lwip/liblwip.a", Object: "tcp.o"},
+		{Name: ".text.utils", File: "build_out/lwip/liblwip.a(utils.o)", Archive: "build_out/lwip/liblwip.a", Object: "utils.o"},
+		{Name: ".text.utils", File: "build_out/blog/libblog.a(utils.o)", Archive: "build_out/blog/libblog.a", Object: "utils.o"},
+		{Name: ".text.hal", File: "build_out/hosal/libhosal.a(hal_uart.c.o)", Archive: "build_out/hosal/libhosal.a", Object: "hal_uart.c.o"},
+		{Name: "*fill*", Size: 2},
+	}}
+	want := map[string]string{"main": "demo", "tcp": "lwip", "hal_uart": "hosal"}
+	if got := SourceComponents(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("SourceComponents() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"path/filepath"
+	"strings"
+
+	"wb2-cli/internal/size"
+)
+
+// Filter 决定哪些行显示在终端上
+type Filter struct {
+	// Components 只显示这些组件的日志；名称与行所属的 SDK 组件、源文件名
+	// （可以不带扩展名）或正文标签（如 APP）匹配，不区分大小写。为空时不过滤
+	Components []string
+	// MinLevel 只显示不低于该级别的 blog 日志；不是 blog 格式的行不受影响
+	MinLevel Level
+}
+
+// Match 判断是否显示该行，component 为行所属的 SDK 组件（未知时为空）
+func (f Filter) Match(line Line, component string) bool {
+	if line.Level != LevelNone && line.Level < f.MinLevel {
+		return false
+	}
+	if len(f.Components) == 0 {
+		return true
+	}
+	if line.Level == LevelNone {
+		return false
+	}
+	stem := strings.TrimSuffix(line.File, filepath.Ext(line.File))
+	for _, name := range f.Components {
+		if strings.EqualFold(name, component) || strings.EqualFold(name, line.File) ||
+			strings.EqualFold(name, stem) || line.HasTag(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SourceComponents 根据 map 文件建立源文件名（不含扩展名）到 SDK 组件的映射，
+// 用于判断 blog 日志行属于哪个组件。多个组件中有同名源文件时无法判断，不加入映射
+func SourceComponents(m *size.MapFile) map[string]string {
+	components := make(map[string]string)
+	ambiguous := make(map[string]bool)
+	for _, in := range m.Inputs {
+		if in.IsFill() {
+			continue
+		}
+		object := in.Object
+		if object == "" {
+			object = filepath.Base(in.File)
+		}
+		if filepath.Ext(object) != ".o" {
+			continue
+		}
+		stem := strings.TrimSuffix(object, ".o")
+		// 有的 SDK 组件把 foo.c 编译为 foo.c.o
+		stem = strings.TrimSuffix(stem, filepath.Ext(stem))
+		component := in.Component()
+		if existing, ok := components[stem]; ok && existing != component {
+			ambiguous[stem] = true
+		}
+		components[stem] = component
+	}
+	for stem := range ambiguous {
+		delete(components, stem)
+	}
+	return components
+}
@@ -0,0 +1,285 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"wb2-cli/internal/serial"
+)
+
+// ANSI 颜色
+const (
+	colorReset  = "\033[0m"
+	colorDim    = "\033[2m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+	colorBold   = "\033[1;31m"
+	colorCyan   = "\033[1;36m"
+	colorNotice = "\033[35m"
+)
+
+// TimeFormat 时间戳格式
+const TimeFormat = "15:04:05.000"
+
+// NotePrefix 说明行的前缀，wb2-cli decode 据此跳过日志中已有的说明
+const NotePrefix = "    ↳ "
+
+// pollInterval 读取串口的超时，用于检查是否停止和输出没有换行的提示符
+const pollInterval = 100 * time.Millisecond
+
+// Options 监视选项
+type Options struct {
+	// Color 按日志级别着色
+	Color bool
+	// Timestamp 在每行前加上收到该行的本机时间
+	Timestamp bool
+	Filter    Filter
+	// Components 源文件名到 SDK 组件的映射，见 SourceComponents
+	Components map[string]string
+	// Log 不为 nil 时把所有行（不过滤、不着色，带时间戳）写入 Log
+	Log io.Writer
+	// Annotator 不为 nil 时为每行补充说明，例如解析崩溃信息中的地址
+	Annotator Annotator
+	// Now 返回当前时间，测试时替换
+	Now func() time.Time
+}
+
+// Annotator 为输出行补充说明
+type Annotator interface {
+	// Annotate 返回显示在该行之后的说明；important 为 true 时该行不受过滤影响
+	Annotate(text string) (notes []string, important bool)
+}
+
+// Monitor 从串口读取输出并逐行显示
+type Monitor struct {
+	port serial.Port
+	out  io.Writer
+	opts Options
+
+	mu sync.Mutex
+	// 当前行已收到的内容
+	partial []byte
+	// 当前行开始的时间
+	started time.Time
+	// 当前行的开头已因等待超时原样输出，其余部分也原样输出
+	raw bool
+}
+
+// New 创建监视器，输出写入 out
+func New(port serial.Port, out io.Writer, opts Options) *Monitor {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Monitor{port: port, out: out, opts: opts}
+}
+
+// Run 持续读取串口直到 stop 关闭或读取出错
+func (m *Monitor) Run(stop <-chan struct{}) error {
+	if err := m.port.SetReadTimeout(pollInterval); err != nil {
+		return err
+	}
+	buf := make([]byte, 1024)
+	for {
+		select {
+		case <-stop:
+			m.Flush()
+			m.endLine()
+			return nil
+		default:
+		}
+		n, err := m.port.Read(buf)
+		if n > 0 {
+			m.Feed(buf[:n])
+		}
+		if err == serial.ErrTimeout {
+			m.Flush()
+			continue
+		}
+		if err != nil {
+			m.Flush()
+			return fmt.Errorf("读取串口失败: %v", err)
+		}
+	}
+}
+
+// Feed 处理收到的数据，输出其中完整的行
+func (m *Monitor) Feed(data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for len(data) > 0 {
+		if len(m.partial) == 0 && !m.raw {
+			m.started = m.opts.Now()
+		}
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			m.partial = append(m.partial, data...)
+			return
+		}
+		m.partial = append(m.partial, data[:i]...)
+		data = data[i+1:]
+		m.finishLine()
+	}
+}
+
+// Flush 原样输出没有换行的内容，例如命令行提示符
+func (m *Monitor) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.partial) == 0 {
+		return
+	}
+	if !m.raw {
+		m.writePrefix()
+	}
+	m.out.Write(m.partial)
+	m.logPartial()
+	m.partial = m.partial[:0]
+	m.raw = true
+}
+
+// endLine 结束已原样输出的未换行内容，避免和之后的输出混在一起
+func (m *Monitor) endLine() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.raw {
+		io.WriteString(m.out, "\n")
+		m.writeLog("\n")
+		m.raw = false
+	}
+}
+
+// Notice 在输出中插入一行本地提示，例如复位设备
+func (m *Monitor) Notice(msg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.partial) > 0 || m.raw {
+		// 在未结束的行之后换行，避免和提示混在一起
+		if !m.raw {
+			m.writePrefix()
+		}
+		m.out.Write(m.partial)
+		m.logPartial()
+		m.partial = m.partial[:0]
+		m.raw = false
+		io.WriteString(m.out, "\n")
+		m.writeLog("\n")
+	}
+	text := "--- " + msg + " ---"
+	if m.opts.Color {
+		text = colorNotice + text + colorReset
+	}
+	io.WriteString(m.out, text+"\n")
+	m.writeLog(m.opts.Now().Format(TimeFormat) + " --- " + msg + " ---\n")
+}
+
+// Send 向设备发送数据，例如键盘输入
+func (m *Monitor) Send(data []byte) error {
+	_, err := m.port.Write(data)
+	return err
+}
+
+// finishLine 输出一个完整的行
+func (m *Monitor) finishLine() {
+	text := strings.TrimRight(string(m.partial), "\r")
+	m.partial = m.partial[:0]
+
+	if m.raw {
+		// 行首已输出，剩余部分原样输出
+		io.WriteString(m.out, text+"\n")
+		m.writeLog(StripANSI(text) + "\n")
+		m.raw = false
+		return
+	}
+
+	line := ParseLine(text)
+	m.writeLog(m.started.Format(TimeFormat) + " " + line.Text + "\n")
+	var notes []string
+	important := false
+	if m.opts.Annotator != nil {
+		notes, important = m.opts.Annotator.Annotate(line.Text)
+	}
+	for _, note := range notes {
+		m.writeLog(m.started.Format(TimeFormat) + " " + NotePrefix + note + "\n")
+	}
+	if !important && !m.opts.Filter.Match(line, m.component(line)) {
+		return
+	}
+
+	m.writePrefix()
+	switch color := lineColor(line); {
+	case !m.opts.Color:
+		io.WriteString(m.out, line.Text+"\n")
+	case color != "":
+		io.WriteString(m.out, color+line.Text+colorReset+"\n")
+	default:
+		io.WriteString(m.out, text+"\n")
+	}
+	for _, note := range notes {
+		m.writePrefix()
+		if m.opts.Color {
+			note = colorYellow + NotePrefix + note + colorReset
+		} else {
+			note = NotePrefix + note
+		}
+		io.WriteString(m.out, note+"\n")
+	}
+}
+
+// component 返回日志行所属的 SDK 组件
+func (m *Monitor) component(line Line) string {
+	if line.File == "" {
+		return ""
+	}
+	stem := strings.TrimSuffix(line.File, filepath.Ext(line.File))
+	return m.opts.Components[stem]
+}
+
+// writePrefix 输出行首的时间戳
+func (m *Monitor) writePrefix() {
+	if !m.opts.Timestamp {
+		return
+	}
+	stamp := m.started.Format(TimeFormat) + " "
+	if m.opts.Color {
+		stamp = colorDim + stamp + colorReset
+	}
+	io.WriteString(m.out, stamp)
+}
+
+// logPartial 把没有换行的内容写入日志文件，行首带时间戳
+func (m *Monitor) logPartial() {
+	text := StripANSI(string(m.partial))
+	if !m.raw {
+		text = m.started.Format(TimeFormat) + " " + text
+	}
+	m.writeLog(text)
+}
+
+func (m *Monitor) writeLog(s string) {
+	if m.opts.Log != nil {
+		io.WriteString(m.opts.Log, s)
+	}
+}
+
+// lineColor 返回日志行的颜色，不需要着色时返回空字符串
+func lineColor(line Line) string {
+	switch line.Level {
+	case LevelDebug:
+		return colorDim
+	case LevelWarn:
+		return colorYellow
+	case LevelError:
+		return colorRed
+	case LevelAssert:
+		return colorBold
+	}
+	if line.IsEvent() {
+		return colorCyan
+	}
+	return ""
+}
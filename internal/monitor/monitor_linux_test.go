@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"wb2-cli/internal/serial"
+)
+
+// syncBuffer 可并发读写的输出缓冲
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor 等待输出中出现 want
+func waitFor(t *testing.T, b *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(b.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q, got:\n%q", want, b.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// startMonitor 在伪终端的从端上运行监视器，返回主端（模拟设备）和输出
+func startMonitor(t *testing.T, opts Options) (io.ReadWriter, *Monitor, *syncBuffer) {
+	t.Helper()
+	master, path, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("伪终端不可用: %v", err)
+	}
+	port, err := serial.Open(path, 2000000)
+	if err != nil {
+		master.Close()
+		t.Fatalf("serial.Open() error = %v", err)
+	}
+
+	opts.Now = func() time.Time { return time.Date(2026, 1, 2, 10, 20, 30, 456e6, time.Local) }
+	out := &syncBuffer{}
+	m := New(port, out, opts)
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- m.Run(stop) }()
+	t.Cleanup(func() {
+		close(stop)
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+		port.Close()
+		master.Close()
+	})
+	return master, m, out
+}
+
+func TestMonitorLoopback(t *testing.T) {
+	log := &syncBuffer{}
+	device, m, out := startMonitor(t, Options{
+		Color:      true,
+		Timestamp:  true,
+		Filter:     Filter{Components: []string{"demo", "lwip"}, MinLevel: LevelInfo},
+		Components: map[string]string{"main": "demo", "tcp": "lwip", "hal_uart": "hosal"},
+		Log:        log,
+	})
+
+	device.Write([]byte("[  10][INFO  : main.c:  87] [APP] [EVT] INIT DONE 10\r\n" +
+		"[  11][DEBUG : main.c:  90] hidden by level\r\n" +
+		"[  12][INFO  : hal_uart.c: 5] hidden by component\r\n" +
+		"[  13][ERROR : tcp"))
+	// 一行分两次到达
+	time.Sleep(20 * time.Millisecond)
+	device.Write([]byte(".c: 7] rto\r\n"))
+	waitFor(t, out, "rto")
+
+	want := "\x1b[2m10:20:30.456 \x1b[0m\x1b[1;36m[  10][INFO  : main.c:  87] [APP] [EVT] INIT DONE 10\x1b[0m\n" +
+		"\x1b[2m10:20:30.456 \x1b[0m\x1b[31m[  13][ERROR : tcp.c: 7] rto\x1b[0m\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	// 日志文件记录所有行，不着色
+	for _, line := range []string{
+		"10:20:30.456 [  11][DEBUG : main.c:  90] hidden by level\n",
+		"10:20:30.456 [  12][INFO  : hal_uart.c: 5] hidden by component\n",
+	} {
+		if !strings.Contains(log.String(), line) {
+			t.Errorf("log = %q, want line %q", log.String(), line)
+		}
+	}
+
+	// 键盘输入发送给设备
+	if err := m.Send([]byte("help\r")); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 5)
+	if _, err := io.ReadFull(device, got); err != nil || string(got) != "help\r" {
+		t.Errorf("device read %q, %v", got, err)
+	}
+}
+
+func TestMonitorPrompt(t *testing.T) {
+	device, m, out := startMonitor(t, Options{})
+
+	// 没有换行的提示符在等待超时后原样输出
+	device.Write([]byte("Booting BL602 Chip...\r\n# "))
+	waitFor(t, out, "# ")
+	device.Write([]byte("help\r\n"))
+	waitFor(t, out, "help\n")
+	device.Write([]byte("# "))
+	waitFor(t, out, "help\n# ")
+	m.Notice("已复位设备")
+
+	want := "Booting BL602 Chip...\n# help\n# \n--- 已复位设备 ---\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
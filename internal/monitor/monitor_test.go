@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// crashAnnotator 把以 crash 开头的行视为崩溃信息
+type crashAnnotator struct{}
+
+func (crashAnnotator) Annotate(text string) ([]string, bool) {
+	if strings.HasPrefix(text, "crash") {
+		return []string{"decoded " + text}, true
+	}
+	return nil, false
+}
+
+func TestMonitorAnnotator(t *testing.T) {
+	var out, log bytes.Buffer
+	m := New(nil, &out, Options{
+		Filter:    Filter{Components: []string{"demo"}},
+		Annotator: crashAnnotator{},
+		Log:       &log,
+		Now:       func() time.Time { return time.Date(2026, 1, 2, 10, 20, 30, 0, time.Local) },
+	})
+	m.Feed([]byte("boot\r\ncrash 23000010\r\n[1][INFO  : main.c: 1] hi\r\n"))
+
+	// 崩溃信息不受过滤影响
+	if want := "crash 23000010\n" + NotePrefix + "decoded crash 23000010\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	want := "10:20:30.000 boot\n" +
+		"10:20:30.000 crash 23000010\n" +
+		"10:20:30.000 " + NotePrefix + "decoded crash 23000010\n" +
+		"10:20:30.000 [1][INFO  : main.c: 1] hi\n"
+	if log.String() != want {
+		t.Errorf("log = %q, want %q", log.String(), want)
+	}
+}
@@ -0,0 +1,97 @@
+// Package ota 生成和检查 SDK（bl_sys_ota）使用的 OTA 镜像，并提供开发用的 HTTP 服务
+package ota
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// OTA 镜像以 512 字节的头开始，之后是固件（原样或 xz 压缩）：
+//
+//	0x000 magic            16 字节 "BL60X_OTA_Ver1.0"
+//	0x010 type             4 字节  "RAW " 或 "XZ  "
+//	0x014 len              小端 uint32，固件长度
+//	0x018 pad              8 字节
+//	0x020 ver_hardware     16 字节
+//	0x030 ver_software     16 字节
+//	0x040 sha256           32 字节，固件的 SHA-256
+const (
+	Magic      = "BL60X_OTA_Ver1.0"
+	HeaderSize = 512
+
+	// TypeRaw 不压缩，TypeXZ 使用 xz 压缩
+	TypeRaw = "RAW "
+	TypeXZ  = "XZ  "
+
+	// versionSize 版本字段的长度
+	versionSize = 16
+)
+
+// Header OTA 镜像头
+type Header struct {
+	Type            string
+	Length          uint32
+	HardwareVersion string
+	SoftwareVersion string
+	SHA256          [32]byte
+}
+
+// Compressed 判断固件是否经过 xz 压缩
+func (h Header) Compressed() bool {
+	return h.Type == TypeXZ
+}
+
+// Pack 生成 OTA 镜像，body 为写入固件分区的数据（compressed 时已经过 xz 压缩）
+func Pack(body []byte, compressed bool, hardware, software string) ([]byte, error) {
+	if len(hardware) > versionSize || len(software) > versionSize {
+		return nil, fmt.Errorf("版本号不能超过 %d 个字符", versionSize)
+	}
+	h := Header{Type: TypeRaw, Length: uint32(len(body)), HardwareVersion: hardware, SoftwareVersion: software, SHA256: sha256.Sum256(body)}
+	if compressed {
+		h.Type = TypeXZ
+	}
+
+	image := make([]byte, HeaderSize, HeaderSize+len(body))
+	copy(image[0x00:], Magic)
+	copy(image[0x10:], h.Type)
+	binary.LittleEndian.PutUint32(image[0x14:], h.Length)
+	copy(image[0x20:0x30], h.HardwareVersion)
+	copy(image[0x30:0x40], h.SoftwareVersion)
+	copy(image[0x40:0x60], h.SHA256[:])
+	return append(image, body...), nil
+}
+
+// Parse 解析并校验 OTA 镜像，返回镜像头和固件
+func Parse(image []byte) (*Header, []byte, error) {
+	if len(image) < HeaderSize || string(image[:len(Magic)]) != Magic {
+		return nil, nil, fmt.Errorf("不是 OTA 镜像（缺少 %s 头）", Magic)
+	}
+	h := &Header{
+		Type:            string(image[0x10:0x14]),
+		Length:          binary.LittleEndian.Uint32(image[0x14:]),
+		HardwareVersion: cString(image[0x20:0x30]),
+		SoftwareVersion: cString(image[0x30:0x40]),
+	}
+	copy(h.SHA256[:], image[0x40:0x60])
+	if h.Type != TypeRaw && h.Type != TypeXZ {
+		return nil, nil, fmt.Errorf("未知的 OTA 镜像类型 %q", h.Type)
+	}
+	body := image[HeaderSize:]
+	if uint32(len(body)) != h.Length {
+		return nil, nil, fmt.Errorf("固件长度 %d 与镜像头中的 %d 不一致", len(body), h.Length)
+	}
+	if sha256.Sum256(body) != h.SHA256 {
+		return nil, nil, fmt.Errorf("固件的 SHA-256 与镜像头不一致")
+	}
+	return h, body, nil
+}
+
+// cString 取出以 0 结尾的字符串
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,104 @@
+package ota
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestPackParse(t *testing.T) {
+	body := bytes.Repeat([]byte{0xA5, 0x5A, 0x00}, 1000)
+	image, err := Pack(body, false, "WB2-01S", "1.2.0")
+	if err != nil {
+		t.Fatalf("Pack() error = %v", err)
+	}
+	if len(image) != HeaderSize+len(body) {
+		t.Fatalf("len(image) = %d, want %d", len(image), HeaderSize+len(body))
+	}
+	// 字段位置与 bl_sys_ota 的 ota_header_t 一致
+	sum := sha256.Sum256(body)
+	for _, field := range []struct {
+		offset int
+		want   []byte
+	}{
+		{0x00, []byte("BL60X_OTA_Ver1.0RAW ")},
+		{0x14, []byte{0xB8, 0x0B, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{0x20, []byte("WB2-01S\x00\x00\x00\x00\x00\x00\x00\x00\x00" + "1.2.0\x00")},
+		{0x40, sum[:]},
+		{0x60, make([]byte, HeaderSize-0x60)},
+	} {
+		if got := image[field.offset : field.offset+len(field.want)]; !bytes.Equal(got, field.want) {
+			t.Errorf("image[0x%X:] = % X, want % X", field.offset, got, field.want)
+		}
+	}
+
+	h, got, err := Parse(image)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if h.Type != TypeRaw || h.Compressed() || h.Length != uint32(len(body)) || h.HardwareVersion != "WB2-01S" || h.SoftwareVersion != "1.2.0" || h.SHA256 != sum {
+		t.Errorf("Parse() header = %+v", h)
+	}
+	if !bytes.Equal(got, body) {
+		t.Error("Parse() body differs")
+	}
+
+	xz, _ := Pack(body, true, "", "")
+	if h, _, err := Parse(xz); err != nil || !h.Compressed() {
+		t.Errorf("Parse(xz) = %+v, %v", h, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	image, _ := Pack([]byte("firmware"), false, "", "")
+
+	corrupt := bytes.Clone(image)
+	corrupt[len(corrupt)-1] ^= 0xFF
+	unknown := bytes.Clone(image)
+	copy(unknown[0x10:], "LZ4 ")
+
+	tests := []struct {
+		name  string
+		image []byte
+		want  string
+	}{
+		{"short", image[:100], "不是 OTA 镜像"},
+		{"magic", append([]byte("BL70X"), image[5:]...), "不是 OTA 镜像"},
+		{"type", unknown, "未知的 OTA 镜像类型"},
+		{"truncated", image[:len(image)-1], "长度"},
+		{"sha", corrupt, "SHA-256"},
+	}
+	for _, tt := range tests {
+		if _, _, err := Parse(tt.image); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Parse() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := Pack(nil, false, "", "12345678901234567"); err == nil {
+		t.Error("Pack() with long version should fail")
+	}
+}
+
+func TestCompress(t *testing.T) {
+	if _, err := exec.LookPath("xz"); err != nil {
+		t.Skip("没有 xz 命令")
+	}
+	data := bytes.Repeat([]byte("wb2-cli ota "), 4096)
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	// xz 流头：魔数和流标志，校验类型 0x01 为 CRC32
+	if !bytes.HasPrefix(compressed, []byte("\xFD7zXZ\x00\x00\x01")) {
+		t.Errorf("stream header = % X", compressed[:8])
+	}
+
+	cmd := exec.Command("xz", "--decompress", "--stdout")
+	cmd.Stdin = bytes.NewReader(compressed)
+	out, err := cmd.Output()
+	if err != nil || !bytes.Equal(out, data) {
+		t.Errorf("decompressed %d bytes, %v", len(out), err)
+	}
+}
@@ -0,0 +1,68 @@
+package ota
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Handler 返回提供 dir 中文件下载的 HTTP 处理器，每个请求记录到 log
+func Handler(dir string, log io.Writer) http.Handler {
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		files.ServeHTTP(rec, r)
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		fmt.Fprintf(log, "%s %s %s %d %d 字节 %s\n", start.Format("15:04:05"), host, r.Method+" "+r.URL.Path, rec.status, rec.written, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// statusRecorder 记录响应状态和发送的字节数
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.written += int64(n)
+	return n, err
+}
+
+// LocalAddresses 返回本机可供局域网设备访问的 IPv4 地址
+func LocalAddresses() []string {
+	var addrs []string
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range ifAddrs {
+			if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				addrs = append(addrs, ipNet.IP.String())
+			}
+		}
+	}
+	sort.Strings(addrs)
+	return addrs
+}
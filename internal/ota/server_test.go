@@ -0,0 +1,57 @@
+package ota
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "demo.ota"), []byte("image"), 0644)
+
+	var log bytes.Buffer
+	server := httptest.NewServer(Handler(dir, &log))
+
+	resp, err := http.Get(server.URL + "/demo.ota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "image" {
+		t.Errorf("GET /demo.ota = %d %q", resp.StatusCode, body)
+	}
+
+	// 设备断点续传使用 Range
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/demo.ota", nil)
+	req.Header.Set("Range", "bytes=2-")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "age" {
+		t.Errorf("GET /demo.ota with Range = %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = http.Get(server.URL + "/missing.ota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	// 等待所有请求处理完，日志在响应之后写入
+	server.Close()
+
+	for _, want := range []string{"127.0.0.1 GET /demo.ota 200 5 字节", "GET /demo.ota 206 3 字节", "GET /missing.ota 404"} {
+		if !strings.Contains(log.String(), want) {
+			t.Errorf("log = %q, want %q", log.String(), want)
+		}
+	}
+}
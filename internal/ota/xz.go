@@ -0,0 +1,28 @@
+package ota
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// xzArgs 设备端的 xz 解压只支持 CRC32 校验和较小的字典，与 SDK 烧录工具的压缩参数一致
+var xzArgs = []string{"--compress", "--stdout", "--check=crc32", "--lzma2=preset=9,dict=32KiB"}
+
+// Compress 调用 xz 命令压缩固件
+func Compress(data []byte) ([]byte, error) {
+	path, err := exec.LookPath("xz")
+	if err != nil {
+		return nil, fmt.Errorf("找不到 xz 命令，请安装 xz（如 apt install xz-utils）或去掉 --xz")
+	}
+	var out, stderr bytes.Buffer
+	cmd := exec.Command(path, xzArgs...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("xz 压缩失败: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out.Bytes(), nil
+}
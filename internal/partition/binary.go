@@ -0,0 +1,44 @@
+package partition
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// 写入 Flash 的二进制分区表（与 boot2 的 PtTable_Config 一致），所有数值为小端序：
+//
+//	表头  magic "BFPT"、版本、分区数量、age、前 12 字节的 CRC32
+//	分区  类型、设备、活动槽、名称（9 字节，以 0 结尾）、两个地址、两个大小、长度、age
+//	末尾  所有分区的 CRC32
+const (
+	// BinaryMagic 二进制分区表的 magic（"BFPT"）
+	BinaryMagic = 0x54504642
+
+	binaryHeaderSize = 16
+	binaryEntrySize  = 36
+	binaryNameSize   = 9
+)
+
+// Encode 返回写入 0xE000 和 0xF000 的二进制分区表
+func Encode(t *Table) []byte {
+	data := make([]byte, binaryHeaderSize+binaryEntrySize*len(t.Entries)+4)
+	binary.LittleEndian.PutUint32(data[0:], BinaryMagic)
+	binary.LittleEndian.PutUint16(data[6:], uint16(len(t.Entries)))
+	binary.LittleEndian.PutUint32(data[12:], crc32.ChecksumIEEE(data[:12]))
+
+	for i, e := range t.Entries {
+		b := data[binaryHeaderSize+binaryEntrySize*i:]
+		b[0] = byte(e.Type)
+		b[1] = byte(e.Device)
+		copy(b[3:3+binaryNameSize-1], e.Name)
+		binary.LittleEndian.PutUint32(b[12:], uint32(e.Address0))
+		binary.LittleEndian.PutUint32(b[16:], uint32(e.Address1))
+		binary.LittleEndian.PutUint32(b[20:], uint32(e.Size0))
+		binary.LittleEndian.PutUint32(b[24:], uint32(e.Size1))
+		binary.LittleEndian.PutUint32(b[28:], uint32(e.Len))
+	}
+
+	entries := data[binaryHeaderSize : len(data)-4]
+	binary.LittleEndian.PutUint32(data[len(data)-4:], crc32.ChecksumIEEE(entries))
+	return data
+}
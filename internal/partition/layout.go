@@ -0,0 +1,94 @@
+package partition
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Request 组件需要在 Flash 中保留的区域
+//
+// 名称为 FW 的请求表示 OTA 的第二个固件槽；与基础分区同名时取较大的大小。
+type Request struct {
+	Name string
+	Type int
+	Size int
+}
+
+// baseEntries 所有项目都有的分区，按地址顺序排在 Flash 末尾（与 SDK 默认分区表一致）。
+// romfs 是所有项目都挂载的 VFS 组件，因此 media 分区也总是保留
+var baseEntries = []Request{
+	{Name: "mfg", Type: TypeMfg, Size: 0x32000},
+	{Name: "media", Type: TypeMedia, Size: 0x57000},
+	{Name: "PSM", Type: TypePSM, Size: 0x8000},
+	{Name: "KEY", Type: TypeKey, Size: 0x2000},
+	{Name: "DATA", Type: TypeData, Size: 0x5000},
+	{Name: "factory", Type: TypeFactory, Size: 0x7000},
+}
+
+// Layout 为 flashSize 字节的 Flash 生成分区表
+//
+// 组件请求的区域按名称排序后排在 media 之后、PSM 之前（与请求的顺序无关），固件分区占用
+// 0x10000 之后剩余的全部空间；有 OTA 槽时第二个槽紧跟在第一个槽之后。
+func Layout(flashSize int, requests []Request) (*Table, error) {
+	tail := make([]Request, len(baseEntries))
+	copy(tail, baseEntries)
+	insertAt := 2
+	slot1 := 0
+
+	sorted := append([]Request(nil), requests...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, req := range sorted {
+		size := alignUp(req.Size)
+		if req.Name == FirmwareName {
+			slot1 = max(slot1, size)
+			continue
+		}
+		merged := false
+		for i := range tail {
+			if tail[i].Name == req.Name {
+				tail[i].Size = max(tail[i].Size, size)
+				merged = true
+				break
+			}
+		}
+		if merged {
+			continue
+		}
+		req.Size = size
+		tail = append(tail[:insertAt], append([]Request{req}, tail[insertAt:]...)...)
+		insertAt++
+	}
+
+	tailSize := 0
+	for _, req := range tail {
+		tailSize += req.Size
+	}
+	slot0 := flashSize - tailSize - slot1 - FirmwareAddress
+	if slot0 < MinFirmwareSize {
+		return nil, fmt.Errorf("Flash 空间不足: %s Flash 中保留 %s 后固件分区只剩 %s，至少需要 %s",
+			FormatSize(flashSize), FormatSize(tailSize+slot1), FormatSize(max(slot0, 0)), FormatSize(MinFirmwareSize))
+	}
+
+	fw := Entry{Type: TypeFirmware, Name: FirmwareName, Address0: FirmwareAddress, Size0: slot0, Header: 1, Security: 1}
+	if slot1 > 0 {
+		fw.Address1 = FirmwareAddress + slot0
+		fw.Size1 = slot1
+	}
+	table := &Table{Address0: TableAddress0, Address1: TableAddress1, Entries: []Entry{fw}}
+
+	addr := flashSize - tailSize
+	for _, req := range tail {
+		table.Entries = append(table.Entries, Entry{Type: req.Type, Name: req.Name, Address0: addr, Size0: req.Size})
+		addr += req.Size
+	}
+
+	if err := table.Validate(flashSize); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// alignUp 将大小向上对齐到扇区
+func alignUp(size int) int {
+	return (size + SectorSize - 1) / SectorSize * SectorSize
+}
@@ -0,0 +1,186 @@
+// Package partition 生成、解析和校验 BL602 的 Flash 分区表（SDK 烧录工具使用的 TOML 格式）
+package partition
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flash 布局中的固定区域
+const (
+	// SectorSize Flash 擦除扇区大小，分区地址和大小都必须按此对齐
+	SectorSize = 0x1000
+	// Boot2Size Flash 开头的 boot2 引导程序区域
+	Boot2Size = 0xE000
+	// TableAddress0 / TableAddress1 两份分区表的地址，各占一个扇区
+	TableAddress0 = 0xE000
+	TableAddress1 = 0xF000
+	// FirmwareAddress 固件分区的起始地址
+	FirmwareAddress = 0x10000
+	// MinFirmwareSize 固件分区（每个槽）的最小大小
+	MinFirmwareSize = 0x80000
+)
+
+// 分区类型（与 SDK 的 partition_cfg_*.toml 一致）
+const (
+	TypeFirmware = 0
+	TypeMfg      = 2
+	TypeMedia    = 3
+	TypePSM      = 4
+	TypeKey      = 5
+	TypeData     = 6
+	TypeFactory  = 7
+)
+
+// FirmwareName 固件分区名称
+const FirmwareName = "FW"
+
+// Entry 分区表中的一个分区；Address1/Size1 非零时为 OTA 的第二个槽
+type Entry struct {
+	Type     int
+	Name     string
+	Device   int
+	Address0 int
+	Size0    int
+	Address1 int
+	Size1    int
+	// 压缩镜像的长度，普通镜像为 0
+	Len int
+	// 是否添加镜像头，以及在启用加密时是否加密（仅固件分区）
+	Header   int
+	Security int
+}
+
+// Table 分区表
+type Table struct {
+	Address0 int
+	Address1 int
+	Entries  []Entry
+}
+
+// Find 按名称查找分区
+func (t *Table) Find(name string) (*Entry, bool) {
+	for i := range t.Entries {
+		if t.Entries[i].Name == name {
+			return &t.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// Region Flash 中的一段连续区域，用于展示和校验
+type Region struct {
+	Name    string
+	Type    int
+	Address int
+	Size    int
+	// 不在分区表中的保留区域（boot2、分区表）
+	Reserved bool
+}
+
+// End 返回区域结束地址（不含）
+func (r Region) End() int {
+	return r.Address + r.Size
+}
+
+// Regions 返回按地址排序的所有区域，包括 boot2 和分区表本身
+func (t *Table) Regions() []Region {
+	regions := []Region{
+		{Name: "boot2", Address: 0, Size: Boot2Size, Reserved: true},
+		{Name: "pt_table[0]", Address: t.Address0, Size: SectorSize, Reserved: true},
+		{Name: "pt_table[1]", Address: t.Address1, Size: SectorSize, Reserved: true},
+	}
+	for _, e := range t.Entries {
+		if e.Size1 == 0 {
+			regions = append(regions, Region{Name: e.Name, Type: e.Type, Address: e.Address0, Size: e.Size0})
+			continue
+		}
+		regions = append(regions,
+			Region{Name: e.Name + "[0]", Type: e.Type, Address: e.Address0, Size: e.Size0},
+			Region{Name: e.Name + "[1]", Type: e.Type, Address: e.Address1, Size: e.Size1},
+		)
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].Address < regions[j].Address
+	})
+	return regions
+}
+
+// Validate 检查分区表能否放入 flashSize 字节的 Flash：
+// 地址和大小按扇区对齐、名称不重复且不超过 8 个字符、区域之间没有重叠、不超出 Flash 容量，且包含固件分区
+func (t *Table) Validate(flashSize int) error {
+	names := map[string]bool{}
+	for _, e := range t.Entries {
+		if e.Name == "" {
+			return fmt.Errorf("分区缺少名称（类型 %d）", e.Type)
+		}
+		if len(e.Name) > binaryNameSize-1 {
+			return fmt.Errorf("分区名称 %s 过长，最多 %d 个字符", e.Name, binaryNameSize-1)
+		}
+		if names[e.Name] {
+			return fmt.Errorf("分区名称重复: %s", e.Name)
+		}
+		names[e.Name] = true
+		if e.Size0 <= 0 {
+			return fmt.Errorf("分区 %s 的大小必须大于 0", e.Name)
+		}
+		if (e.Address1 == 0) != (e.Size1 == 0) {
+			return fmt.Errorf("分区 %s 的 address1 和 size1 必须同时设置", e.Name)
+		}
+	}
+	if !names[FirmwareName] {
+		return fmt.Errorf("分区表中没有固件分区 %s", FirmwareName)
+	}
+
+	regions := t.Regions()
+	for i, r := range regions {
+		if r.Address%SectorSize != 0 || r.Size%SectorSize != 0 {
+			return fmt.Errorf("%s（%s，%s）没有按 4K 扇区对齐", r.Name, FormatAddress(r.Address), FormatSize(r.Size))
+		}
+		if r.End() > flashSize {
+			return fmt.Errorf("%s（%s - %s）超出 Flash 容量 %s", r.Name, FormatAddress(r.Address), FormatAddress(r.End()), FormatSize(flashSize))
+		}
+		if i > 0 && r.Address < regions[i-1].End() {
+			prev := regions[i-1]
+			return fmt.Errorf("%s（%s - %s）与 %s（%s - %s）重叠",
+				prev.Name, FormatAddress(prev.Address), FormatAddress(prev.End()),
+				r.Name, FormatAddress(r.Address), FormatAddress(r.End()))
+		}
+	}
+	return nil
+}
+
+// ParseSize 解析分区大小，支持 0x 十六进制、十进制字节数以及 K/M 后缀（如 348K、1M）
+func ParseSize(s string) (int, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(text, "K"):
+		multiplier, text = 1024, strings.TrimSuffix(text, "K")
+	case strings.HasSuffix(text, "M"):
+		multiplier, text = 1024*1024, strings.TrimSuffix(text, "M")
+	}
+	v, err := strconv.ParseInt(text, 0, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("无效的分区大小: %q", s)
+	}
+	return int(v) * multiplier, nil
+}
+
+// FormatSize 以 K/M 为单位格式化大小
+func FormatSize(size int) string {
+	switch {
+	case size >= 1024*1024 && size%(1024*1024) == 0:
+		return fmt.Sprintf("%dM", size/(1024*1024))
+	case size%1024 == 0:
+		return fmt.Sprintf("%dK", size/1024)
+	}
+	return fmt.Sprintf("%dB", size)
+}
+
+// FormatAddress 格式化 Flash 地址
+func FormatAddress(addr int) string {
+	return fmt.Sprintf("0x%06X", addr)
+}
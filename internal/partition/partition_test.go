@@ -0,0 +1,243 @@
+package partition
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const mb = 1024 * 1024
+
+func TestLayoutDefault(t *testing.T) {
+	table, err := Layout(2*mb, nil)
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	fw, ok := table.Find(FirmwareName)
+	if !ok || fw.Address0 != FirmwareAddress || fw.Size1 != 0 || fw.Header != 1 {
+		t.Fatalf("Unexpected firmware partition: %+v", fw)
+	}
+	// 基础分区排在 Flash 末尾，固件占用剩余空间
+	factory, _ := table.Find("factory")
+	if factory.Address0+factory.Size0 != 2*mb {
+		t.Errorf("Expected factory at end of flash, got %+v", factory)
+	}
+	mfg, _ := table.Find("mfg")
+	if fw.Address0+fw.Size0 != mfg.Address0 {
+		t.Errorf("Expected firmware to fill up to mfg, got %+v %+v", fw, mfg)
+	}
+	// 所有项目都挂载 romfs，默认保留 SDK 默认大小的 media 分区
+	media, ok := table.Find("media")
+	if !ok || media.Type != TypeMedia || media.Size0 != 0x57000 || media.Address0 != mfg.Address0+mfg.Size0 {
+		t.Errorf("Expected default media partition after mfg, got %+v", media)
+	}
+}
+
+func TestLayoutRequests(t *testing.T) {
+	table, err := Layout(2*mb, []Request{
+		{Name: "media", Type: TypeMedia, Size: 348 * 1024},
+		{Name: "PSM", Type: TypePSM, Size: 64 * 1024},
+		{Name: "spiffs", Type: 8, Size: 100 * 1024}, // 向上对齐到 4K
+		{Name: FirmwareName, Size: 544 * 1024},
+	})
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	var names []string
+	for _, e := range table.Entries {
+		names = append(names, e.Name)
+	}
+	expected := []string{"FW", "mfg", "media", "spiffs", "PSM", "KEY", "DATA", "factory"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected order %v, got %v", expected, names)
+	}
+
+	fw, _ := table.Find(FirmwareName)
+	if fw.Size1 != 544*1024 || fw.Address1 != fw.Address0+fw.Size0 {
+		t.Errorf("Unexpected OTA slot: %+v", fw)
+	}
+	if psm, _ := table.Find("PSM"); psm.Size0 != 64*1024 {
+		t.Errorf("Expected PSM enlarged to 64K, got %+v", psm)
+	}
+	if spiffs, _ := table.Find("spiffs"); spiffs.Size0 != 0x19000 {
+		t.Errorf("Expected spiffs aligned to 0x19000, got 0x%X", spiffs.Size0)
+	}
+}
+
+func TestLayoutOrderIndependent(t *testing.T) {
+	requests := []Request{
+		{Name: "spiffs", Type: 8, Size: 100 * 1024},
+		{Name: "media", Type: TypeMedia, Size: 348 * 1024},
+		{Name: FirmwareName, Size: 544 * 1024},
+		{Name: "PSM", Type: TypePSM, Size: 64 * 1024},
+	}
+	want, err := Layout(4*mb, requests)
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	for _, perm := range [][]int{{1, 0, 2, 3}, {3, 2, 1, 0}, {2, 3, 0, 1}} {
+		permuted := make([]Request, len(perm))
+		for i, j := range perm {
+			permuted[i] = requests[j]
+		}
+		got, err := Layout(4*mb, permuted)
+		if err != nil {
+			t.Fatalf("Layout failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("order %v: expected the same layout\n%+v\ngot\n%+v", perm, want.Entries, got.Entries)
+		}
+	}
+}
+
+func TestLayoutOverflow(t *testing.T) {
+	_, err := Layout(2*mb, []Request{{Name: "media", Type: TypeMedia, Size: 1536 * 1024}})
+	if err == nil || !strings.Contains(err.Error(), "Flash 空间不足") {
+		t.Errorf("Expected overflow error, got %v", err)
+	}
+
+	// 4MB Flash 可以容纳
+	if _, err := Layout(4*mb, []Request{{Name: "media", Type: TypeMedia, Size: 1536 * 1024}}); err != nil {
+		t.Errorf("Expected 4MB layout to fit, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Table {
+		return &Table{Address0: TableAddress0, Address1: TableAddress1, Entries: []Entry{
+			{Type: TypeFirmware, Name: "FW", Address0: 0x10000, Size0: 0x100000},
+			{Type: TypePSM, Name: "PSM", Address0: 0x110000, Size0: 0x8000},
+		}}
+	}
+	if err := valid().Validate(2 * mb); err != nil {
+		t.Fatalf("Expected valid table, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Table)
+		want   string
+	}{
+		{"overlap", func(t *Table) { t.Entries[1].Address0 = 0x108000 }, "重叠"},
+		{"overflow", func(t *Table) { t.Entries[1].Address0 = 0x1FC000 }, "超出 Flash 容量"},
+		{"unaligned", func(t *Table) { t.Entries[1].Size0 = 0x8100 }, "对齐"},
+		{"boot2", func(t *Table) { t.Entries[0].Address0 = 0x8000 }, "boot2"},
+		{"duplicate", func(t *Table) { t.Entries[1].Name = "FW" }, "重复"},
+		{"long name", func(t *Table) { t.Entries[1].Name = "settings9" }, "过长"},
+		{"no firmware", func(t *Table) { t.Entries[0].Name = "app" }, "没有固件分区"},
+		{"slot", func(t *Table) { t.Entries[0].Address1 = 0x120000 }, "address1 和 size1"},
+		{"slot overlap", func(t *Table) { t.Entries[0].Address1, t.Entries[0].Size1 = 0x10C000, 0x10000 }, "FW[1]"},
+	}
+	for _, tt := range tests {
+		table := valid()
+		tt.modify(table)
+		if err := table.Validate(2 * mb); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	table, err := Layout(2*mb, []Request{{Name: FirmwareName, Size: 0x80000}})
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	data := Encode(table)
+	if len(data) != 16+36*len(table.Entries)+4 {
+		t.Fatalf("Unexpected size %d for %d entries", len(data), len(table.Entries))
+	}
+
+	le := binary.LittleEndian
+	if le.Uint32(data) != BinaryMagic || int(le.Uint16(data[6:])) != len(table.Entries) {
+		t.Errorf("Unexpected header % X", data[:16])
+	}
+	if le.Uint32(data[12:]) != crc32.ChecksumIEEE(data[:12]) {
+		t.Errorf("Header CRC mismatch")
+	}
+	if le.Uint32(data[len(data)-4:]) != crc32.ChecksumIEEE(data[16:len(data)-4]) {
+		t.Errorf("Entries CRC mismatch")
+	}
+
+	// 第一个分区是带两个槽的固件分区
+	fw, _ := table.Find(FirmwareName)
+	entry := data[16:52]
+	if entry[0] != TypeFirmware || string(entry[3:5]) != "FW" || entry[5] != 0 {
+		t.Errorf("Unexpected firmware entry % X", entry)
+	}
+	got := []int{int(le.Uint32(entry[12:])), int(le.Uint32(entry[16:])), int(le.Uint32(entry[20:])), int(le.Uint32(entry[24:]))}
+	if want := []int{fw.Address0, fw.Address1, fw.Size0, fw.Size1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected addresses and sizes %v, got %v", want, got)
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	table, err := Layout(4*mb, []Request{{Name: "media", Type: TypeMedia, Size: 348 * 1024}, {Name: FirmwareName, Size: 1 * mb}})
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	data := Format(table, "demo 分区表\n第二行")
+	if !strings.HasPrefix(string(data), "# demo 分区表\n# 第二行\n\n[pt_table]") {
+		t.Errorf("Unexpected header:\n%s", data)
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, table) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v", table, parsed)
+	}
+}
+
+func TestParseSDKFormat(t *testing.T) {
+	// SDK 分区表中的写法：注释、security= 1 这样不带空格的赋值
+	table, err := Parse([]byte(`[pt_table]
+#partition table is 4K in size
+address0 = 0xE000
+address1 = 0xF000
+
+[[pt_entry]]
+type = 0
+name = "FW"   # 固件
+device = 0
+address0 = 0x10000
+size0 = 0xC8000
+address1 = 0xD8000
+size1 = 0x88000
+len = 0
+header = 1
+security= 1
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	fw, ok := table.Find("FW")
+	if !ok || fw.Size0 != 0xC8000 || fw.Address1 != 0xD8000 || fw.Security != 1 {
+		t.Errorf("Unexpected FW entry: %+v", fw)
+	}
+
+	for _, invalid := range []string{"[other]\n", "[pt_table]\naddress0 0xE000\n", "[[pt_entry]]\nsize0 = big\n", "type = 0\n"} {
+		if _, err := Parse([]byte(invalid)); err == nil {
+			t.Errorf("Expected parse error for %q", invalid)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for input, want := range map[string]int{"348K": 348 * 1024, "1M": mb, "0x57000": 0x57000, "4096": 4096, " 64k ": 64 * 1024} {
+		got, err := ParseSize(input)
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	for _, invalid := range []string{"", "abc", "-1K", "0"} {
+		if _, err := ParseSize(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
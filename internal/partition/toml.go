@@ -0,0 +1,143 @@
+package partition
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format 将分区表输出为 SDK 烧录工具使用的 TOML，title 作为文件开头的注释
+func Format(t *Table, title string) []byte {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(title, "\n") {
+		fmt.Fprintf(&buf, "# %s\n", line)
+	}
+	fmt.Fprintf(&buf, "\n[pt_table]\n#partition table is 4K in size\naddress0 = 0x%X\naddress1 = 0x%X\n", t.Address0, t.Address1)
+
+	for _, e := range t.Entries {
+		fmt.Fprintf(&buf, "\n[[pt_entry]]\n")
+		fmt.Fprintf(&buf, "type = %d\n", e.Type)
+		fmt.Fprintf(&buf, "name = %q\n", e.Name)
+		fmt.Fprintf(&buf, "device = %d\n", e.Device)
+		fmt.Fprintf(&buf, "address0 = 0x%X\n", e.Address0)
+		fmt.Fprintf(&buf, "size0 = 0x%X\n", e.Size0)
+		fmt.Fprintf(&buf, "address1 = 0x%X\n", e.Address1)
+		fmt.Fprintf(&buf, "size1 = 0x%X\n", e.Size1)
+		fmt.Fprintf(&buf, "# compressed image must set len,normal image can left it to 0\n")
+		fmt.Fprintf(&buf, "len = %d\n", e.Len)
+		if e.Type == TypeFirmware {
+			fmt.Fprintf(&buf, "# If header is 1, it will add the header.\n")
+			fmt.Fprintf(&buf, "header = %d\n", e.Header)
+			fmt.Fprintf(&buf, "# If header is 1 and security is 1, It will be encrypted.\n")
+			fmt.Fprintf(&buf, "security = %d\n", e.Security)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Parse 解析分区表 TOML（只支持 SDK 分区表用到的 [pt_table]、[[pt_entry]] 和整数/字符串值）
+func Parse(data []byte) (*Table, error) {
+	t := &Table{}
+	section := ""
+	var entry *Entry
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+
+		switch line {
+		case "[pt_table]":
+			section = "pt_table"
+			continue
+		case "[[pt_entry]]":
+			section = "pt_entry"
+			t.Entries = append(t.Entries, Entry{})
+			entry = &t.Entries[len(t.Entries)-1]
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("第 %d 行: 不支持的表 %s", lineNo, line)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("第 %d 行: 无法解析 %q", lineNo, line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if key == "name" {
+			name, err := strconv.Unquote(value)
+			if err != nil || section != "pt_entry" {
+				return nil, fmt.Errorf("第 %d 行: 无效的 name %s", lineNo, value)
+			}
+			entry.Name = name
+			continue
+		}
+
+		n, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %s 的值不是整数: %s", lineNo, key, value)
+		}
+		v := int(n)
+
+		switch section {
+		case "pt_table":
+			switch key {
+			case "address0":
+				t.Address0 = v
+			case "address1":
+				t.Address1 = v
+			}
+		case "pt_entry":
+			setEntryField(entry, key, v)
+		default:
+			return nil, fmt.Errorf("第 %d 行: %s 不在 [pt_table] 或 [[pt_entry]] 中", lineNo, key)
+		}
+	}
+	return t, nil
+}
+
+// setEntryField 设置分区的整数字段，未知的键忽略
+func setEntryField(e *Entry, key string, v int) {
+	switch key {
+	case "type":
+		e.Type = v
+	case "device":
+		e.Device = v
+	case "address0":
+		e.Address0 = v
+	case "size0":
+		e.Size0 = v
+	case "address1":
+		e.Address1 = v
+	case "size1":
+		e.Size1 = v
+	case "len":
+		e.Len = v
+	case "header":
+		e.Header = v
+	case "security":
+		e.Security = v
+	}
+}
+
+// stripComment 去掉行中不在字符串内的 # 注释
+func stripComment(line string) string {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inString = !inString
+		case '#':
+			if !inString {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
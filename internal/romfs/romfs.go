@@ -0,0 +1,311 @@
+// Package romfs 生成和解析 SDK romfs 组件使用的 ROMFS 镜像（与 genromfs 相同的 -rom1fs- 格式）
+package romfs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// 镜像以超级块开始，之后是文件头；所有数值为大端序，文件头和数据按 16 字节对齐：
+//
+//	超级块  "-rom1fs-"、镜像大小、前 512 字节的校验和、卷名（以 0 结尾）
+//	文件头  next（下一个文件头的偏移，低 4 位为类型和可执行标志）、spec.info、
+//	        大小、文件头和文件名的校验和、文件名（以 0 结尾），之后是文件数据
+//
+// 目录的 spec.info 指向其中第一个文件头，每个目录以 "." 和 ".." 开始。
+const (
+	Magic = "-rom1fs-"
+
+	// align 文件头和数据的对齐
+	align = 16
+	// checksumSize 超级块校验和覆盖的长度
+	checksumSize = 512
+	// padding 镜像末尾补齐到 1K，与 genromfs 一致
+	padding = 1024
+)
+
+// Type 文件类型
+type Type int
+
+const (
+	TypeHardLink Type = iota
+	TypeDir
+	TypeFile
+	TypeSymlink
+	TypeBlockDev
+	TypeCharDev
+	TypeSocket
+	TypeFIFO
+)
+
+// execFlag next 字段中的可执行标志
+const execFlag = 8
+
+func (t Type) String() string {
+	switch t {
+	case TypeHardLink:
+		return "硬链接"
+	case TypeDir:
+		return "目录"
+	case TypeFile:
+		return "文件"
+	case TypeSymlink:
+		return "符号链接"
+	}
+	return "设备"
+}
+
+// node 生成镜像时的一个目录项
+type node struct {
+	name     string
+	typ      Type
+	exec     bool
+	data     []byte
+	children []*node
+	// spec spec.info 字段
+	spec uint32
+	// offset 文件头的偏移，first 为目录中第一个文件头（"."）的偏移
+	offset int
+	first  int
+}
+
+// Build 把目录 root 打包为 ROMFS 镜像，volume 为卷名。文件按名称排序，
+// 符号链接按目标的内容打包
+func Build(root, volume string) ([]byte, error) {
+	dir, err := readDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// 第一遍计算每个文件头的偏移
+	offset := align + pad(len(volume)+1)
+	var place func(d *node, parent uint32)
+	place = func(d *node, parent uint32) {
+		dot := &node{name: ".", typ: TypeDir, exec: true}
+		dotdot := &node{name: "..", typ: TypeHardLink}
+		d.children = append([]*node{dot, dotdot}, d.children...)
+		d.first = offset
+		for _, c := range d.children {
+			c.offset = offset
+			offset += align + pad(len(c.name)+1) + pad(len(c.data))
+		}
+		if parent == 0 {
+			// 根目录的 ".." 指向自身
+			parent = uint32(d.first)
+		}
+		dot.spec, dotdot.spec = uint32(d.first), parent
+		for _, c := range d.children {
+			if c.typ == TypeDir && c != dot {
+				place(c, uint32(d.first))
+				c.spec = uint32(c.first)
+			}
+		}
+	}
+	place(dir, 0)
+	size := offset
+
+	// 第二遍写入
+	image := make([]byte, size, size+padding)
+	copy(image, Magic)
+	binary.BigEndian.PutUint32(image[8:], uint32(size))
+	copy(image[16:], volume)
+	var write func(d *node)
+	write = func(d *node) {
+		for i, c := range d.children {
+			next := 0
+			if i+1 < len(d.children) {
+				next = d.children[i+1].offset
+			}
+			writeHeader(image, c, next)
+			if c.typ == TypeDir && c.name != "." {
+				write(c)
+			}
+		}
+	}
+	write(dir)
+	setChecksum(image[:min(size, checksumSize)], 12)
+
+	if rem := len(image) % padding; rem != 0 {
+		image = append(image, make([]byte, padding-rem)...)
+	}
+	return image, nil
+}
+
+// readDir 读取目录树
+func readDir(dir string) (*node, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %v", err)
+	}
+	d := &node{typ: TypeDir, exec: true}
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %v", p, err)
+		}
+		switch {
+		case info.IsDir():
+			child, err := readDir(p)
+			if err != nil {
+				return nil, err
+			}
+			child.name = e.Name()
+			d.children = append(d.children, child)
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return nil, fmt.Errorf("读取 %s 失败: %v", p, err)
+			}
+			d.children = append(d.children, &node{name: e.Name(), typ: TypeFile, exec: info.Mode()&0111 != 0, data: data})
+		default:
+			return nil, fmt.Errorf("%s 不是普通文件或目录", p)
+		}
+	}
+	sort.Slice(d.children, func(i, j int) bool { return d.children[i].name < d.children[j].name })
+	return d, nil
+}
+
+// writeHeader 写入文件头、文件名和数据
+func writeHeader(image []byte, n *node, next int) {
+	field := uint32(next) | uint32(n.typ)
+	if n.exec {
+		field |= execFlag
+	}
+	h := image[n.offset:]
+	binary.BigEndian.PutUint32(h[0:], field)
+	binary.BigEndian.PutUint32(h[4:], n.spec)
+	binary.BigEndian.PutUint32(h[8:], uint32(len(n.data)))
+	copy(h[16:], n.name)
+	headerSize := align + pad(len(n.name)+1)
+	setChecksum(h[:headerSize], 12)
+	copy(h[headerSize:], n.data)
+}
+
+// setChecksum 设置 b[at:at+4]，使 b 中所有大端 32 位字之和为 0
+func setChecksum(b []byte, at int) {
+	binary.BigEndian.PutUint32(b[at:], 0)
+	binary.BigEndian.PutUint32(b[at:], -sum(b))
+}
+
+// sum 计算大端 32 位字之和，末尾不足 4 字节的部分忽略
+func sum(b []byte) uint32 {
+	var s uint32
+	for i := 0; i+4 <= len(b); i += 4 {
+		s += binary.BigEndian.Uint32(b[i:])
+	}
+	return s
+}
+
+// pad 向上对齐到 16 字节
+func pad(n int) int {
+	return (n + align - 1) / align * align
+}
+
+// File 镜像中的一个文件或目录
+type File struct {
+	// Path 以 / 分隔的路径，不以 / 开头
+	Path string
+	Type Type
+	Exec bool
+	Size int
+	// Offset 数据在镜像中的偏移
+	Offset int
+	// Target 硬链接指向的文件头偏移
+	Target uint32
+}
+
+// Image 解析后的镜像
+type Image struct {
+	Volume string
+	// Size 超级块中记录的镜像大小
+	Size  int
+	Files []File
+	data  []byte
+}
+
+// Parse 解析 ROMFS 镜像并校验超级块和每个文件头的校验和
+func Parse(data []byte) (*Image, error) {
+	if len(data) < 32 || string(data[:8]) != Magic {
+		return nil, fmt.Errorf("不是 ROMFS 镜像（缺少 %s 头）", Magic)
+	}
+	size := int(binary.BigEndian.Uint32(data[8:]))
+	if size > len(data) || size < 32 {
+		return nil, fmt.Errorf("镜像大小 %d 与文件大小 %d 不符", size, len(data))
+	}
+	data = data[:size]
+	if sum(data[:min(size, checksumSize)]) != 0 {
+		return nil, fmt.Errorf("超级块校验和错误")
+	}
+	volume, end, err := readName(data, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	img := &Image{Volume: volume, Size: size, data: data}
+	visited := make(map[int]bool)
+	if err := img.walk(end, "", visited); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// walk 读取从 offset 开始的一个目录中的所有文件头
+func (img *Image) walk(offset int, dir string, visited map[int]bool) error {
+	data := img.data
+	for offset != 0 {
+		if offset+align > len(data) || offset%align != 0 {
+			return fmt.Errorf("文件头偏移 0x%X 无效", offset)
+		}
+		if visited[offset] {
+			return fmt.Errorf("文件头 0x%X 重复出现", offset)
+		}
+		visited[offset] = true
+
+		field := binary.BigEndian.Uint32(data[offset:])
+		spec := binary.BigEndian.Uint32(data[offset+4:])
+		size := int(binary.BigEndian.Uint32(data[offset+8:]))
+		name, dataOffset, err := readName(data, offset+align)
+		if err != nil {
+			return err
+		}
+		if sum(data[offset:dataOffset]) != 0 {
+			return fmt.Errorf("文件 %s 的文件头校验和错误", path.Join(dir, name))
+		}
+		if dataOffset+size > len(data) {
+			return fmt.Errorf("文件 %s 超出镜像", path.Join(dir, name))
+		}
+
+		typ := Type(field & 7)
+		if name != "." && name != ".." {
+			p := path.Join(dir, name)
+			img.Files = append(img.Files, File{Path: p, Type: typ, Exec: field&execFlag != 0, Size: size, Offset: dataOffset, Target: spec})
+			if typ == TypeDir {
+				if err := img.walk(int(spec), p, visited); err != nil {
+					return err
+				}
+			}
+		}
+		offset = int(field &^ 15)
+	}
+	return nil
+}
+
+// readName 读取从 offset 开始以 0 结尾的名称，返回名称和之后按 16 字节对齐的偏移
+func readName(data []byte, offset int) (string, int, error) {
+	for i := offset; i < len(data); i++ {
+		if data[i] == 0 {
+			return string(data[offset:i]), offset + pad(i-offset+1), nil
+		}
+	}
+	return "", 0, fmt.Errorf("偏移 0x%X 处的名称没有结尾", offset)
+}
+
+// ReadFile 返回文件的内容
+func (img *Image) ReadFile(f File) []byte {
+	return img.data[f.Offset : f.Offset+f.Size]
+}
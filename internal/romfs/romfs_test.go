@@ -0,0 +1,148 @@
+package romfs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree 在临时目录中创建文件，内容为 nil 的项为目录
+func writeTree(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, data := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if data == nil {
+			os.MkdirAll(p, 0755)
+			continue
+		}
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestBuildGenromfsLayout(t *testing.T) {
+	root := writeTree(t, map[string][]byte{"hello.txt": []byte("hello\n")})
+	image, err := Build(root, "rom 5a7dcee9")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	// 与 genromfs 生成的超级块、"." 和 ".." 文件头一致
+	want := []byte{
+		'-', 'r', 'o', 'm', '1', 'f', 's', '-', 0x00, 0x00, 0x00, 0x90, 0, 0, 0, 0,
+		'r', 'o', 'm', ' ', '5', 'a', '7', 'd', 'c', 'e', 'e', '9', 0, 0, 0, 0,
+		0x00, 0x00, 0x00, 0x49, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0xd1, 0xff, 0xff, 0x97,
+		'.', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0x00, 0x00, 0x00, 0x60, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0xd1, 0xd1, 0xff, 0x80,
+		'.', '.', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		// hello.txt：普通文件，是目录中最后一项
+		0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06,
+	}
+	got := bytes.Clone(image[:len(want)])
+	copy(got[12:16], []byte{0, 0, 0, 0}) // 超级块校验和单独检查
+	if !bytes.Equal(got, want) {
+		t.Errorf("image header =\n% X\nwant\n% X", got, want)
+	}
+	if sum(image[:0x90]) != 0 {
+		t.Error("superblock checksum is wrong")
+	}
+	if string(image[0x80:0x86]) != "hello\n" {
+		t.Errorf("file data = %q", image[0x80:0x86])
+	}
+	if len(image) != 1024 {
+		t.Errorf("len(image) = %d, want padding to 1024", len(image))
+	}
+}
+
+func TestBuildParse(t *testing.T) {
+	large := bytes.Repeat([]byte{0x5A}, 1500)
+	root := writeTree(t, map[string][]byte{
+		"index.html":                        []byte("<h1>demo</h1>"),
+		"certs/ca.pem":                      []byte("-----BEGIN CERTIFICATE-----"),
+		"certs/empty":                       {},
+		"www/js/app.js":                     large,
+		"www/img":                           nil,
+		"config/device.json":                []byte(`{"id":1}`),
+		"a_very_long_file_name_over_16.txt": []byte("x"),
+	})
+	os.Chmod(filepath.Join(root, "index.html"), 0755)
+
+	image, err := Build(root, "demo")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	img, err := Parse(image)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if img.Volume != "demo" || img.Size > len(image) || len(image)%1024 != 0 {
+		t.Errorf("volume %q, size %d, image %d", img.Volume, img.Size, len(image))
+	}
+
+	type item struct {
+		path string
+		typ  Type
+		exec bool
+		size int
+	}
+	var got []item
+	for _, f := range img.Files {
+		got = append(got, item{f.Path, f.Type, f.Exec, f.Size})
+	}
+	want := []item{
+		{"a_very_long_file_name_over_16.txt", TypeFile, false, 1},
+		{"certs", TypeDir, true, 0},
+		{"certs/ca.pem", TypeFile, false, 27},
+		{"certs/empty", TypeFile, false, 0},
+		{"config", TypeDir, true, 0},
+		{"config/device.json", TypeFile, false, 8},
+		{"index.html", TypeFile, true, 13},
+		{"www", TypeDir, true, 0},
+		{"www/img", TypeDir, true, 0},
+		{"www/js", TypeDir, true, 0},
+		{"www/js/app.js", TypeFile, false, 1500},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("files =\n%v\nwant\n%v", got, want)
+	}
+	for _, f := range img.Files {
+		if f.Path == "www/js/app.js" && !bytes.Equal(img.ReadFile(f), large) {
+			t.Error("app.js content differs")
+		}
+		if f.Type == TypeFile && f.Offset%16 != 0 {
+			t.Errorf("%s data at 0x%X is not aligned", f.Path, f.Offset)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	image, err := Build(writeTree(t, map[string][]byte{"a.txt": []byte("a")}), "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	badSuper := bytes.Clone(image)
+	badSuper[0x21] ^= 1
+	// 只改文件名并修正超级块校验和，让文件头校验和出错
+	badHeader := bytes.Clone(image)
+	badHeader[0x70] = 'b'
+	setChecksum(badHeader[:512], 12)
+	truncated := bytes.Clone(image[:0x70])
+
+	for name, data := range map[string][]byte{
+		"magic":     append([]byte("-rom2fs-"), image[8:]...),
+		"super":     badSuper,
+		"header":    badHeader,
+		"truncated": truncated,
+	} {
+		if _, err := Parse(data); err == nil {
+			t.Errorf("%s: Parse() error = nil", name)
+		}
+	}
+}
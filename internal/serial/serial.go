@@ -0,0 +1,26 @@
+// Package serial 提供串口访问，供烧录和串口监视使用
+package serial
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrTimeout 读取超时
+var ErrTimeout = errors.New("串口读取超时")
+
+// Port 串口
+type Port interface {
+	io.ReadWriteCloser
+	// SetBaud 修改波特率
+	SetBaud(baud int) error
+	// SetReadTimeout 设置读取超时，Read 在超时前没有收到数据时返回 ErrTimeout；0 表示一直等待
+	SetReadTimeout(timeout time.Duration) error
+	// SetDTR 设置 DTR 信号
+	SetDTR(on bool) error
+	// SetRTS 设置 RTS 信号
+	SetRTS(on bool) error
+	// ResetInput 丢弃接收缓冲区中的数据
+	ResetInput() error
+}
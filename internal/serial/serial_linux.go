@@ -0,0 +1,142 @@
+package serial
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// baudRates 支持的波特率
+var baudRates = map[int]uint32{
+	9600:    unix.B9600,
+	19200:   unix.B19200,
+	38400:   unix.B38400,
+	57600:   unix.B57600,
+	115200:  unix.B115200,
+	230400:  unix.B230400,
+	460800:  unix.B460800,
+	500000:  unix.B500000,
+	576000:  unix.B576000,
+	921600:  unix.B921600,
+	1000000: unix.B1000000,
+	1152000: unix.B1152000,
+	1500000: unix.B1500000,
+	2000000: unix.B2000000,
+	2500000: unix.B2500000,
+	3000000: unix.B3000000,
+}
+
+// tty Linux 终端设备上的串口
+type tty struct {
+	file    *os.File
+	fd      int
+	timeout time.Duration
+}
+
+// Open 以 raw 模式（8N1，无流控）打开串口
+func Open(path string, baud int) (Port, error) {
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("打开串口 %s 失败: %v", path, err)
+	}
+	p := &tty{fd: fd}
+	if err := p.configure(baud); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("配置串口 %s 失败: %v", path, err)
+	}
+	// 非阻塞的文件描述符由 Go 的网络轮询器管理，读取可以设置超时
+	p.file = os.NewFile(uintptr(fd), path)
+	return p, nil
+}
+
+// configure 设置 raw 模式和波特率
+func (p *tty) configure(baud int) error {
+	rate, ok := baudRates[baud]
+	if !ok {
+		return fmt.Errorf("不支持的波特率 %d", baud)
+	}
+	t, err := unix.IoctlGetTermios(p.fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF | unix.IXANY
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB | unix.CRTSCTS | unix.CBAUD
+	t.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL | rate
+	t.Ispeed, t.Ospeed = rate, rate
+	t.Cc[unix.VMIN], t.Cc[unix.VTIME] = 1, 0
+	return unix.IoctlSetTermios(p.fd, unix.TCSETS, t)
+}
+
+func (p *tty) Read(b []byte) (int, error) {
+	if p.timeout > 0 {
+		p.file.SetReadDeadline(time.Now().Add(p.timeout))
+	} else {
+		p.file.SetReadDeadline(time.Time{})
+	}
+	n, err := p.file.Read(b)
+	if err != nil && os.IsTimeout(err) {
+		return n, ErrTimeout
+	}
+	return n, err
+}
+
+func (p *tty) Write(b []byte) (int, error) {
+	return p.file.Write(b)
+}
+
+func (p *tty) Close() error {
+	return p.file.Close()
+}
+
+func (p *tty) SetBaud(baud int) error {
+	return p.configure(baud)
+}
+
+func (p *tty) SetReadTimeout(timeout time.Duration) error {
+	p.timeout = timeout
+	return nil
+}
+
+func (p *tty) SetDTR(on bool) error {
+	return p.setModemBit(unix.TIOCM_DTR, on)
+}
+
+func (p *tty) SetRTS(on bool) error {
+	return p.setModemBit(unix.TIOCM_RTS, on)
+}
+
+// setModemBit 设置调制解调器控制信号
+func (p *tty) setModemBit(bit int, on bool) error {
+	req := uint(unix.TIOCMBIC)
+	if on {
+		req = unix.TIOCMBIS
+	}
+	return unix.IoctlSetPointerInt(p.fd, req, bit)
+}
+
+func (p *tty) ResetInput() error {
+	return unix.IoctlSetInt(p.fd, unix.TCFLSH, unix.TCIFLUSH)
+}
+
+// OpenPTY 创建伪终端，返回主端和从端路径；从端可以像串口一样用 Open 打开，
+// 用于在测试中模拟设备
+func OpenPTY() (*os.File, string, error) {
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, "", fmt.Errorf("创建伪终端失败: %v", err)
+	}
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		unix.Close(fd)
+		return nil, "", fmt.Errorf("解锁伪终端失败: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		unix.Close(fd)
+		return nil, "", fmt.Errorf("获取伪终端编号失败: %v", err)
+	}
+	return os.NewFile(uintptr(fd), "/dev/ptmx"), fmt.Sprintf("/dev/pts/%d", n), nil
+}
@@ -0,0 +1,76 @@
+package serial
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func openTestPTY(t *testing.T) (io.ReadWriteCloser, Port) {
+	t.Helper()
+	master, path, err := OpenPTY()
+	if err != nil {
+		t.Skipf("伪终端不可用: %v", err)
+	}
+	port, err := Open(path, 115200)
+	if err != nil {
+		master.Close()
+		t.Fatalf("Open(%s) error = %v", path, err)
+	}
+	t.Cleanup(func() {
+		port.Close()
+		master.Close()
+	})
+	return master, port
+}
+
+func TestPortReadWrite(t *testing.T) {
+	master, port := openTestPTY(t)
+
+	// raw 模式下 \r\n 和控制字符原样传输
+	data := []byte{0x55, 0x0d, 0x0a, 0x03, 0x11, 0xff}
+	if _, err := master.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	port.SetReadTimeout(time.Second)
+	got := make([]byte, len(data))
+	if _, err := io.ReadFull(port, got); err != nil {
+		t.Fatalf("ReadFull() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("port read % X, want % X", got, data)
+	}
+
+	if _, err := port.Write([]byte("OK\n")); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, 3)
+	if _, err := io.ReadFull(master, reply); err != nil || string(reply) != "OK\n" {
+		t.Errorf("master read %q, %v", reply, err)
+	}
+}
+
+func TestPortReadTimeout(t *testing.T) {
+	_, port := openTestPTY(t)
+
+	port.SetReadTimeout(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := port.Read(make([]byte, 1)); err != ErrTimeout {
+		t.Errorf("Read() error = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Read() took %v", elapsed)
+	}
+}
+
+func TestPortBaud(t *testing.T) {
+	_, port := openTestPTY(t)
+
+	if err := port.SetBaud(2000000); err != nil {
+		t.Errorf("SetBaud(2000000) error = %v", err)
+	}
+	if err := port.SetBaud(123); err == nil {
+		t.Error("SetBaud(123) error = nil, want error")
+	}
+}
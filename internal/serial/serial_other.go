@@ -0,0 +1,19 @@
+//go:build !linux
+
+package serial
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Open 打开串口；目前只支持 Linux
+func Open(path string, baud int) (Port, error) {
+	return nil, fmt.Errorf("暂不支持在 %s 上访问串口", runtime.GOOS)
+}
+
+// OpenPTY 创建伪终端；目前只支持 Linux
+func OpenPTY() (*os.File, string, error) {
+	return nil, "", fmt.Errorf("暂不支持在 %s 上创建伪终端", runtime.GOOS)
+}
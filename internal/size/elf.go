@@ -0,0 +1,96 @@
+// Package size 分析编译产物（ELF 和 GNU ld map 文件）占用的 Flash 和 RAM
+package size
+
+import (
+	"debug/elf"
+	"fmt"
+	"sort"
+)
+
+// BL602 地址空间中 XIP Flash 的范围，其余可分配的段都在 RAM（TCM、WRAM）中
+const (
+	flashStart = 0x23000000
+	flashEnd   = 0x24000000
+)
+
+// heapSymbols 链接脚本中定义的堆区域（FreeRTOS 启动时用这些区域初始化堆）
+var heapSymbols = []struct {
+	name, start, size string
+}{
+	{"heap", "_heap_start", "_heap_size"},
+	{"heap_wifi", "_heap_wifi_start", "_heap_wifi_size"},
+}
+
+// Section ELF 中一个可分配的段
+type Section struct {
+	Name    string `json:"name"`
+	Address uint64 `json:"address"`
+	Size    int    `json:"size"`
+	// 占用 Flash（代码、只读数据、RAM 段的初始值）
+	Flash bool `json:"flash"`
+	// 占用 RAM（数据、bss、TCM 代码）
+	RAM bool `json:"ram"`
+}
+
+// HeapRegion 一段堆区域
+type HeapRegion struct {
+	Name    string `json:"name"`
+	Address uint64 `json:"address"`
+	Size    int    `json:"size"`
+}
+
+// inFlash 判断地址是否在 XIP Flash 中
+func inFlash(addr uint64) bool {
+	return addr >= flashStart && addr < flashEnd
+}
+
+// ReadELF 读取 ELF 中可分配的段，统计 Flash、RAM 占用和链接脚本中的堆区域
+func ReadELF(path string) (*Report, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 ELF 失败: %v", err)
+	}
+	defer f.Close()
+
+	report := &Report{}
+	for _, s := range f.Sections {
+		if s.Flags&elf.SHF_ALLOC == 0 || s.Size == 0 {
+			continue
+		}
+		sec := Section{Name: s.Name, Address: s.Addr, Size: int(s.Size)}
+		if inFlash(s.Addr) {
+			sec.Flash = true
+		} else {
+			sec.RAM = true
+			// RAM 中有初始值的段（.data、TCM 代码）在 Flash 中保存一份加载镜像
+			sec.Flash = s.Type != elf.SHT_NOBITS
+		}
+		report.Sections = append(report.Sections, sec)
+		if sec.Flash {
+			report.Flash += sec.Size
+		}
+		if sec.RAM {
+			report.RAM += sec.Size
+		}
+	}
+	sort.SliceStable(report.Sections, func(i, j int) bool {
+		return report.Sections[i].Address < report.Sections[j].Address
+	})
+
+	symbols, err := f.Symbols()
+	if err != nil && err != elf.ErrNoSymbols {
+		return nil, fmt.Errorf("读取 ELF 符号表失败: %v", err)
+	}
+	values := map[string]uint64{}
+	for _, sym := range symbols {
+		values[sym.Name] = sym.Value
+	}
+	for _, h := range heapSymbols {
+		start, ok1 := values[h.start]
+		size, ok2 := values[h.size]
+		if ok1 && ok2 && size > 0 {
+			report.Heap = append(report.Heap, HeapRegion{Name: h.name, Address: start, Size: int(size)})
+		}
+	}
+	return report, nil
+}
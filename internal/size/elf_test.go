@@ -0,0 +1,172 @@
+package size
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testSection struct {
+	name  string
+	typ   elf.SectionType
+	flags elf.SectionFlag
+	addr  uint32
+	size  uint32
+}
+
+type testSymbol struct {
+	name  string
+	value uint32
+}
+
+// writeTestELF 生成只包含段头和符号表的 32 位 RISC-V ELF
+func writeTestELF(t *testing.T, sections []testSection, symbols []testSymbol) string {
+	t.Helper()
+	le := binary.LittleEndian
+
+	strtab := func(names []string) ([]byte, []uint32) {
+		buf := []byte{0}
+		offsets := make([]uint32, len(names))
+		for i, name := range names {
+			offsets[i] = uint32(len(buf))
+			buf = append(append(buf, name...), 0)
+		}
+		return buf, offsets
+	}
+
+	var secNames []string
+	for _, s := range sections {
+		secNames = append(secNames, s.name)
+	}
+	secNames = append(secNames, ".symtab", ".strtab", ".shstrtab")
+	shstr, shOff := strtab(secNames)
+
+	var symNames []string
+	for _, s := range symbols {
+		symNames = append(symNames, s.name)
+	}
+	symstr, symOff := strtab(symNames)
+	symtab := make([]byte, 16) // 第 0 项为空符号
+	for i, s := range symbols {
+		entry := make([]byte, 16)
+		le.PutUint32(entry[0:], symOff[i])
+		le.PutUint32(entry[4:], s.value)
+		entry[12] = byte(elf.STB_GLOBAL)<<4 | byte(elf.STT_NOTYPE)
+		le.PutUint16(entry[14:], uint16(elf.SHN_ABS))
+		symtab = append(symtab, entry...)
+	}
+
+	var body bytes.Buffer
+	body.Write(make([]byte, 52))
+	type header struct {
+		name, typ, flags, addr, off, size, link, info, align, entsize uint32
+	}
+	headers := []header{{}}
+	for i, s := range sections {
+		h := header{name: shOff[i], typ: uint32(s.typ), flags: uint32(s.flags), addr: s.addr, off: uint32(body.Len()), size: s.size, align: 4}
+		if s.typ != elf.SHT_NOBITS {
+			body.Write(make([]byte, s.size))
+		}
+		headers = append(headers, h)
+	}
+	n := len(sections)
+	headers = append(headers,
+		header{name: shOff[n], typ: uint32(elf.SHT_SYMTAB), off: uint32(body.Len()), size: uint32(len(symtab)), link: uint32(n + 2), info: 1, align: 4, entsize: 16})
+	body.Write(symtab)
+	headers = append(headers, header{name: shOff[n+1], typ: uint32(elf.SHT_STRTAB), off: uint32(body.Len()), size: uint32(len(symstr)), align: 1})
+	body.Write(symstr)
+	headers = append(headers, header{name: shOff[n+2], typ: uint32(elf.SHT_STRTAB), off: uint32(body.Len()), size: uint32(len(shstr)), align: 1})
+	body.Write(shstr)
+
+	shoff := uint32(body.Len())
+	for _, h := range headers {
+		for _, v := range []uint32{h.name, h.typ, h.flags, h.addr, h.off, h.size, h.link, h.info, h.align, h.entsize} {
+			binary.Write(&body, le, v)
+		}
+	}
+
+	data := body.Bytes()
+	copy(data, []byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS32), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)})
+	le.PutUint16(data[16:], uint16(elf.ET_EXEC))
+	le.PutUint16(data[18:], uint16(elf.EM_RISCV))
+	le.PutUint32(data[20:], uint32(elf.EV_CURRENT))
+	le.PutUint32(data[32:], shoff)
+	le.PutUint16(data[40:], 52)
+	le.PutUint16(data[46:], 40)
+	le.PutUint16(data[48:], uint16(len(headers)))
+	le.PutUint16(data[50:], uint16(len(headers)-1))
+
+	path := filepath.Join(t.TempDir(), "demo.elf")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testELFSections 与 SDK 链接脚本布局相近的段
+var testELFSections = []testSection{
+	{".text", elf.SHT_PROGBITS, elf.SHF_ALLOC | elf.SHF_EXECINSTR, 0x23000000, 0x30000},
+	{".rodata", elf.SHT_PROGBITS, elf.SHF_ALLOC, 0x23030000, 0x8000},
+	{".tcm_code", elf.SHT_PROGBITS, elf.SHF_ALLOC | elf.SHF_EXECINSTR, 0x22008000, 0x2000},
+	{".data", elf.SHT_PROGBITS, elf.SHF_ALLOC | elf.SHF_WRITE, 0x42020000, 0x1000},
+	{".bss", elf.SHT_NOBITS, elf.SHF_ALLOC | elf.SHF_WRITE, 0x42021000, 0x4000},
+	{".comment", elf.SHT_PROGBITS, 0, 0, 0x40},
+}
+
+func TestReadELF(t *testing.T) {
+	path := writeTestELF(t, testELFSections, []testSymbol{
+		{"_heap_start", 0x42025000},
+		{"_heap_size", 0x18000},
+		{"_heap_wifi_start", 0x42040000},
+		{"_heap_wifi_size", 0x6000},
+		{"main", 0x23000100},
+	})
+
+	report, err := ReadELF(path)
+	if err != nil {
+		t.Fatalf("ReadELF() error = %v", err)
+	}
+
+	if len(report.Sections) != 5 {
+		t.Fatalf("Sections = %+v, want 5 allocated sections", report.Sections)
+	}
+	// 按地址排序
+	if report.Sections[0].Name != ".tcm_code" || report.Sections[4].Name != ".bss" {
+		t.Errorf("Sections order = %+v", report.Sections)
+	}
+	if want := 0x30000 + 0x8000 + 0x2000 + 0x1000; report.Flash != want {
+		t.Errorf("Flash = %#x, want %#x", report.Flash, want)
+	}
+	if want := 0x2000 + 0x1000 + 0x4000; report.RAM != want {
+		t.Errorf("RAM = %#x, want %#x", report.RAM, want)
+	}
+	bss := report.section(".bss")
+	if bss.Flash || !bss.RAM {
+		t.Errorf(".bss = %+v, want RAM only", bss)
+	}
+	if len(report.Heap) != 2 || report.HeapSize() != 0x1E000 {
+		t.Errorf("Heap = %+v, want 2 regions totalling 0x1E000", report.Heap)
+	}
+}
+
+func TestReadELFNoHeapSymbols(t *testing.T) {
+	path := writeTestELF(t, testELFSections[:2], nil)
+	report, err := ReadELF(path)
+	if err != nil {
+		t.Fatalf("ReadELF() error = %v", err)
+	}
+	if report.RAM != 0 || report.HeapSize() != 0 {
+		t.Errorf("RAM = %d, heap = %d, want 0", report.RAM, report.HeapSize())
+	}
+}
+
+func TestReadELFInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.elf")
+	os.WriteFile(path, []byte("not an elf"), 0644)
+	if _, err := ReadELF(path); err == nil {
+		t.Error("ReadELF() error = nil, want error")
+	}
+}
@@ -0,0 +1,181 @@
+package size
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// mapStart GNU ld map 文件中内存布局部分的标题，之前的丢弃段等内容不计入占用
+const mapStart = "Linker script and memory map"
+
+// Symbol map 文件中输入段内的符号
+type Symbol struct {
+	Name    string
+	Address uint64
+}
+
+// InputSection map 文件中链接进输出段的一个输入段
+type InputSection struct {
+	// 所属输出段，例如 .text
+	Output string
+	// 输入段名称，例如 .text.main、COMMON、*fill*
+	Name    string
+	Address uint64
+	Size    int
+	// 输入文件，例如 build_out/lwip/liblwip.a(tcp.o)；填充没有输入文件
+	File string
+	// 静态库路径，不在库中的目标文件为空
+	Archive string
+	// 目标文件名
+	Object  string
+	Symbols []Symbol
+}
+
+// IsFill 判断是否是链接器插入的对齐填充
+func (s InputSection) IsFill() bool {
+	return s.Name == "*fill*" || s.File == ""
+}
+
+// Component 返回输入段所属的 SDK 组件
+//
+// SDK 把每个组件编译为 build_out/<组件>/lib<组件>.a，不在库中的目标文件
+// 按所在目录归属。
+func (s InputSection) Component() string {
+	if s.Archive != "" {
+		name := strings.TrimSuffix(filepath.Base(s.Archive), ".a")
+		return strings.TrimPrefix(name, "lib")
+	}
+	if s.File == "" {
+		return ""
+	}
+	return filepath.Base(filepath.Dir(s.File))
+}
+
+// ObjectName 返回用于显示的目标文件名，例如 liblwip.a(tcp.o)
+func (s InputSection) ObjectName() string {
+	if s.Archive != "" {
+		return fmt.Sprintf("%s(%s)", filepath.Base(s.Archive), s.Object)
+	}
+	return filepath.Base(s.File)
+}
+
+// MapFile 解析后的 map 文件
+type MapFile struct {
+	Inputs []InputSection
+}
+
+// ParseMap 解析 GNU ld 的 map 文件（-Wl,-Map=...）
+//
+// 只解析内存布局部分：顶格的行是输出段，缩进一格的行是输入段，
+// 缩进更多的 "地址 名称" 行是前一个输入段中的符号。名称过长时 ld 会把
+// 地址和大小折到下一行。
+func ParseMap(r io.Reader) (*MapFile, error) {
+	m := &MapFile{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	started := false
+	output := ""
+	pendingOutput := ""
+	pendingInput := ""
+	current := -1
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !started {
+			started = strings.HasPrefix(line, mapStart)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case line[0] != ' ':
+			// 输出段
+			pendingInput, current = "", -1
+			if len(fields) == 1 {
+				pendingOutput = fields[0]
+				continue
+			}
+			pendingOutput = ""
+			if _, ok := parseHex(fields[1]); ok {
+				output = fields[0]
+			}
+		case len(line) > 1 && line[1] != ' ':
+			// 输入段
+			pendingOutput, current = "", -1
+			if strings.HasPrefix(fields[0], "*(") || strings.Contains(line, "(size before relaxing)") {
+				continue
+			}
+			if len(fields) == 1 {
+				pendingInput = fields[0]
+				continue
+			}
+			pendingInput = ""
+			current = m.addInput(output, fields[0], fields[1:])
+		case pendingOutput != "":
+			if _, ok := parseHex(fields[0]); ok {
+				output = pendingOutput
+			}
+			pendingOutput = ""
+		case pendingInput != "":
+			current = m.addInput(output, pendingInput, fields)
+			pendingInput = ""
+		case current >= 0 && len(fields) == 2:
+			// 符号；赋值语句（含 =）和 PROVIDE 不是符号
+			addr, ok := parseHex(fields[0])
+			if ok && !strings.ContainsAny(fields[1], "=(") {
+				in := &m.Inputs[current]
+				in.Symbols = append(in.Symbols, Symbol{Name: fields[1], Address: addr})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 map 文件失败: %v", err)
+	}
+	if !started {
+		return nil, fmt.Errorf("不是有效的 map 文件：找不到 %q", mapStart)
+	}
+	return m, nil
+}
+
+// addInput 记录输入段，fields 为 "地址 大小 [文件]"；返回输入段下标，无效时返回 -1
+func (m *MapFile) addInput(output, name string, fields []string) int {
+	if output == "" || len(fields) < 2 {
+		return -1
+	}
+	addr, ok1 := parseHex(fields[0])
+	size, ok2 := parseHex(fields[1])
+	if !ok1 || !ok2 {
+		return -1
+	}
+	in := InputSection{
+		Output:  output,
+		Name:    name,
+		Address: addr,
+		Size:    int(size),
+		File:    strings.Join(fields[2:], " "),
+	}
+	if open := strings.LastIndex(in.File, ".a("); open >= 0 && strings.HasSuffix(in.File, ")") {
+		in.Archive = in.File[:open+2]
+		in.Object = in.File[open+3 : len(in.File)-1]
+	} else if in.File != "" {
+		in.Object = filepath.Base(in.File)
+	}
+	m.Inputs = append(m.Inputs, in)
+	return len(m.Inputs) - 1
+}
+
+// parseHex 解析 0x 开头的十六进制数
+func parseHex(s string) (uint64, bool) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s[2:], 16, 64)
+	return v, err == nil
+}
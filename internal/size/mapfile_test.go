@@ -0,0 +1,109 @@
+package size
+
+import (
+	"strings"
+	"testing"
+)
+
+// testMap GNU ld map 文件片段
+const testMap = `Archive member included to satisfy reference by file (symbol)
+
+build_out/lwip/liblwip.a(tcp.o)
+                              build_out/demo/libdemo.a(main.o) (tcp_new)
+
+Discarded input sections
+
+ .text.unused   0x00000000       0x40 build_out/demo/libdemo.a(main.o)
+
+Memory Configuration
+
+Name             Origin             Length             Attributes
+flash            0x0000000023000000 0x0000000000400000 xr
+
+Linker script and memory map
+
+LOAD build_out/demo/libdemo.a
+                0x0000000000001000                __stack_size = 0x1000
+
+.text           0x0000000023000000    0x30000
+ *(.text.entry)
+ .text.entry    0x0000000023000000       0x2c build_out/bl602/libbl602.a(start.o)
+                0x0000000023000000                bl602_start
+ *(.text*)
+ .text.main     0x000000002300002c       0x24 build_out/demo/libdemo.a(main.o)
+                0x000000002300002c                main
+ .text.tcp_enqueue_flags_and_more
+                0x0000000023000050      0x200 build_out/lwip/liblwip.a(tcp.o)
+                0x0000000023000050                tcp_enqueue_flags_and_more
+                0x0000000023000150                tcp_new
+ *fill*         0x0000000023000250        0x4 
+ .text          0x0000000023000254       0x10 /opt/toolchain/lib/crt0.o
+                0x0000000023000264                PROVIDE (_etext = .)
+
+.data           0x0000000042020000       0x40
+ .data          0x0000000042020000       0x40 build_out/demo/libdemo.a(main.o)
+                0x0000000042020000                counter
+
+.bss
+                0x0000000042020040      0x110
+ COMMON         0x0000000042020040      0x100 build_out/lwip/liblwip.a(tcp.o)
+                0x0000000042020040                tcp_pcbs
+ .bss.flag      0x0000000042020140       0x10 build_out/demo/libdemo.a(main.o)
+                0x0000000042020150                _heap_start = .
+
+.debug_info     0x0000000000000000     0x2000
+ .debug_info    0x0000000000000000     0x2000 build_out/demo/libdemo.a(main.o)
+`
+
+func TestParseMap(t *testing.T) {
+	m, err := ParseMap(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatalf("ParseMap() error = %v", err)
+	}
+
+	if len(m.Inputs) != 9 {
+		for _, in := range m.Inputs {
+			t.Logf("%+v", in)
+		}
+		t.Fatalf("len(Inputs) = %d, want 9", len(m.Inputs))
+	}
+
+	tcp := m.Inputs[2]
+	if tcp.Output != ".text" || tcp.Name != ".text.tcp_enqueue_flags_and_more" || tcp.Size != 0x200 {
+		t.Errorf("wrapped input = %+v", tcp)
+	}
+	if tcp.Archive != "build_out/lwip/liblwip.a" || tcp.Object != "tcp.o" {
+		t.Errorf("archive/object = %q/%q", tcp.Archive, tcp.Object)
+	}
+	if tcp.Component() != "lwip" || tcp.ObjectName() != "liblwip.a(tcp.o)" {
+		t.Errorf("Component() = %q, ObjectName() = %q", tcp.Component(), tcp.ObjectName())
+	}
+	if len(tcp.Symbols) != 2 || tcp.Symbols[1].Name != "tcp_new" || tcp.Symbols[1].Address != 0x23000150 {
+		t.Errorf("Symbols = %+v", tcp.Symbols)
+	}
+
+	if !m.Inputs[3].IsFill() {
+		t.Errorf("Inputs[3] = %+v, want fill", m.Inputs[3])
+	}
+	crt := m.Inputs[4]
+	if crt.Archive != "" || crt.ObjectName() != "crt0.o" || crt.Component() != "lib" {
+		t.Errorf("crt0 = %+v, component %q", crt, crt.Component())
+	}
+	if len(crt.Symbols) != 0 {
+		t.Errorf("PROVIDE recorded as symbol: %+v", crt.Symbols)
+	}
+
+	// 折行的输出段名称
+	if m.Inputs[6].Output != ".bss" || m.Inputs[6].Name != "COMMON" {
+		t.Errorf("Inputs[6] = %+v", m.Inputs[6])
+	}
+	if syms := m.Inputs[7].Symbols; len(syms) != 0 {
+		t.Errorf("assignment recorded as symbol: %+v", syms)
+	}
+}
+
+func TestParseMapInvalid(t *testing.T) {
+	if _, err := ParseMap(strings.NewReader("hello\n")); err == nil {
+		t.Error("ParseMap() error = nil, want error")
+	}
+}
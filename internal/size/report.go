@@ -0,0 +1,234 @@
+package size
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Report 一次编译产物的大小统计，可以保存为 JSON 作为基线
+type Report struct {
+	// Flash 占用：XIP 代码和只读数据，以及 RAM 段的加载镜像
+	Flash int `json:"flash"`
+	// 静态 RAM 占用：数据、bss 和 TCM 代码
+	RAM        int            `json:"ram"`
+	Sections   []Section      `json:"sections"`
+	Heap       []HeapRegion   `json:"heap,omitempty"`
+	Components []Contribution `json:"components,omitempty"`
+	Objects    []Contribution `json:"objects,omitempty"`
+	// 符号占用（size --symbols），按大小从大到小排序
+	Symbols []SymbolSize `json:"symbols,omitempty"`
+	// 按 分组 / 组件 / 目标文件 / 符号 汇总的占用树
+	Tree *Node `json:"tree,omitempty"`
+}
+
+// Contribution 一个组件或目标文件占用的空间
+type Contribution struct {
+	Name  string `json:"name"`
+	Flash int    `json:"flash"`
+	RAM   int    `json:"ram"`
+}
+
+// HeapSize 返回链接后剩余给堆的 RAM，即启动时的可用堆上限
+func (r *Report) HeapSize() int {
+	total := 0
+	for _, h := range r.Heap {
+		total += h.Size
+	}
+	return total
+}
+
+// section 按名称查找段
+func (r *Report) section(name string) *Section {
+	for i := range r.Sections {
+		if r.Sections[i].Name == name {
+			return &r.Sections[i]
+		}
+	}
+	return nil
+}
+
+// AddMap 根据 map 文件统计各组件和目标文件的占用
+//
+// 输入段按所属输出段在 ELF 中的位置计入 Flash 或 RAM，不在 ELF 可分配段中的
+// 输出段（调试信息等）和链接器填充不计入。
+func (r *Report) AddMap(m *MapFile) {
+	components := map[string]*Contribution{}
+	objects := map[string]*Contribution{}
+	add := func(table map[string]*Contribution, name string, sec *Section, size int) {
+		c, ok := table[name]
+		if !ok {
+			c = &Contribution{Name: name}
+			table[name] = c
+		}
+		if sec.Flash {
+			c.Flash += size
+		}
+		if sec.RAM {
+			c.RAM += size
+		}
+	}
+	for _, in := range m.Inputs {
+		sec := r.section(in.Output)
+		if sec == nil || in.IsFill() || in.Size == 0 {
+			continue
+		}
+		add(components, in.Component(), sec, in.Size)
+		add(objects, in.ObjectName(), sec, in.Size)
+	}
+	r.Components = sortContributions(components)
+	r.Objects = sortContributions(objects)
+}
+
+// sortContributions 按总占用从大到小排序
+func sortContributions(table map[string]*Contribution) []Contribution {
+	list := make([]Contribution, 0, len(table))
+	for _, c := range table {
+		list = append(list, *c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].Flash+list[i].RAM, list[j].Flash+list[j].RAM
+		if a != b {
+			return a > b
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Delta 一个组件或目标文件相对基线的变化
+type Delta struct {
+	Name           string
+	Flash, RAM     int
+	OldFlash       int
+	OldRAM         int
+	Added, Removed bool
+}
+
+// FlashDiff 返回 Flash 变化量
+func (d Delta) FlashDiff() int { return d.Flash - d.OldFlash }
+
+// RAMDiff 返回 RAM 变化量
+func (d Delta) RAMDiff() int { return d.RAM - d.OldRAM }
+
+// Diff 比较两组占用，只返回有变化的项，按变化量从大到小排序
+func Diff(old, cur []Contribution) []Delta {
+	previous := map[string]Contribution{}
+	for _, c := range old {
+		previous[c.Name] = c
+	}
+	var deltas []Delta
+	for _, c := range cur {
+		p, ok := previous[c.Name]
+		delete(previous, c.Name)
+		d := Delta{Name: c.Name, Flash: c.Flash, RAM: c.RAM, OldFlash: p.Flash, OldRAM: p.RAM, Added: !ok}
+		if d.FlashDiff() != 0 || d.RAMDiff() != 0 {
+			deltas = append(deltas, d)
+		}
+	}
+	for _, p := range previous {
+		deltas = append(deltas, Delta{Name: p.Name, OldFlash: p.Flash, OldRAM: p.RAM, Removed: true})
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		a := abs(deltas[i].FlashDiff()) + abs(deltas[i].RAMDiff())
+		b := abs(deltas[j].FlashDiff()) + abs(deltas[j].RAMDiff())
+		if a != b {
+			return a > b
+		}
+		return deltas[i].Name < deltas[j].Name
+	})
+	return deltas
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Budget 大小预算，0 表示不限制
+type Budget struct {
+	Flash   int
+	RAM     int
+	MinHeap int
+}
+
+// Check 检查报告是否超出预算，返回超出的项
+func (b Budget) Check(r *Report) []string {
+	var violations []string
+	if b.Flash > 0 && r.Flash > b.Flash {
+		violations = append(violations, fmt.Sprintf("Flash %s 超出预算 %s（多 %s）",
+			FormatBytes(r.Flash), FormatBytes(b.Flash), FormatBytes(r.Flash-b.Flash)))
+	}
+	if b.RAM > 0 && r.RAM > b.RAM {
+		violations = append(violations, fmt.Sprintf("RAM %s 超出预算 %s（多 %s）",
+			FormatBytes(r.RAM), FormatBytes(b.RAM), FormatBytes(r.RAM-b.RAM)))
+	}
+	if b.MinHeap > 0 && r.HeapSize() < b.MinHeap {
+		violations = append(violations, fmt.Sprintf("可用堆 %s 低于预算 %s（少 %s）",
+			FormatBytes(r.HeapSize()), FormatBytes(b.MinHeap), FormatBytes(b.MinHeap-r.HeapSize())))
+	}
+	return violations
+}
+
+// FormatBytes 格式化字节数，例如 812.4K
+func FormatBytes(n int) string {
+	switch {
+	case n < 0:
+		return "-" + FormatBytes(-n)
+	case n < 1024:
+		return fmt.Sprintf("%dB", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1fK", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.2fM", float64(n)/(1024*1024))
+	}
+}
+
+// FormatDiff 格式化变化量，带正负号；没有变化时返回空字符串
+func FormatDiff(n int) string {
+	switch {
+	case n > 0:
+		return "+" + FormatBytes(n)
+	case n < 0:
+		return FormatBytes(n)
+	default:
+		return ""
+	}
+}
+
+// Load 读取保存的报告
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取大小报告失败: %v", err)
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("解析大小报告 %s 失败: %v", path, err)
+	}
+	return &r, nil
+}
+
+// JSON 序列化报告
+func (r *Report) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化大小报告失败: %v", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Save 保存报告
+func (r *Report) Save(path string) error {
+	data, err := r.JSON()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("保存大小报告失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package size
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testReport(t *testing.T) *Report {
+	t.Helper()
+	report := &Report{
+		Flash: 0x30040,
+		RAM:   0x150,
+		Sections: []Section{
+			{Name: ".text", Address: 0x23000000, Size: 0x30000, Flash: true},
+			{Name: ".data", Address: 0x42020000, Size: 0x40, Flash: true, RAM: true},
+			{Name: ".bss", Address: 0x42020040, Size: 0x110, RAM: true},
+		},
+		Heap: []HeapRegion{{Name: "heap", Address: 0x42020150, Size: 0x8000}},
+	}
+	m, err := ParseMap(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report.AddMap(m)
+	return report
+}
+
+func TestAddMap(t *testing.T) {
+	report := testReport(t)
+
+	want := []Contribution{
+		{Name: "lwip", Flash: 0x200, RAM: 0x100},
+		{Name: "demo", Flash: 0x24 + 0x40, RAM: 0x40 + 0x10},
+		{Name: "bl602", Flash: 0x2c},
+		{Name: "lib", Flash: 0x10},
+	}
+	if !reflect.DeepEqual(report.Components, want) {
+		t.Errorf("Components = %+v, want %+v", report.Components, want)
+	}
+	if report.Objects[0].Name != "liblwip.a(tcp.o)" || len(report.Objects) != 4 {
+		t.Errorf("Objects = %+v", report.Objects)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := []Contribution{
+		{Name: "lwip", Flash: 100, RAM: 10},
+		{Name: "demo", Flash: 50},
+		{Name: "mbedtls", Flash: 300},
+	}
+	cur := []Contribution{
+		{Name: "lwip", Flash: 120, RAM: 10},
+		{Name: "demo", Flash: 50},
+		{Name: "cjson", Flash: 40},
+	}
+
+	deltas := Diff(old, cur)
+	if len(deltas) != 3 {
+		t.Fatalf("Diff() = %+v, want 3 changes", deltas)
+	}
+	if deltas[0].Name != "mbedtls" || !deltas[0].Removed || deltas[0].FlashDiff() != -300 {
+		t.Errorf("deltas[0] = %+v", deltas[0])
+	}
+	if deltas[1].Name != "cjson" || !deltas[1].Added {
+		t.Errorf("deltas[1] = %+v", deltas[1])
+	}
+	if deltas[2].Name != "lwip" || deltas[2].FlashDiff() != 20 {
+		t.Errorf("deltas[2] = %+v", deltas[2])
+	}
+}
+
+func TestBudgetCheck(t *testing.T) {
+	report := testReport(t)
+
+	if v := (Budget{}).Check(report); len(v) != 0 {
+		t.Errorf("empty budget violations = %v", v)
+	}
+	if v := (Budget{Flash: 256 * 1024, RAM: 1024, MinHeap: 16 * 1024}).Check(report); len(v) != 0 {
+		t.Errorf("violations = %v, want none", v)
+	}
+
+	v := Budget{Flash: 128 * 1024, RAM: 0x100, MinHeap: 64 * 1024}.Check(report)
+	if len(v) != 3 {
+		t.Fatalf("violations = %v, want 3", v)
+	}
+	if !strings.Contains(v[0], "Flash 192.1K 超出预算 128.0K") {
+		t.Errorf("flash violation = %q", v[0])
+	}
+	if !strings.Contains(v[2], "可用堆 32.0K 低于预算 64.0K") {
+		t.Errorf("heap violation = %q", v[2])
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	report := testReport(t)
+	path := filepath.Join(t.TempDir(), "size.json")
+	if err := report.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, report) {
+		t.Errorf("Load() = %+v, want %+v", loaded, report)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int]string{
+		0:           "0B",
+		512:         "512B",
+		2048:        "2.0K",
+		1536 * 1024: "1.50M",
+		-3 * 1024:   "-3.0K",
+	}
+	for n, want := range tests {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+	if FormatDiff(0) != "" || FormatDiff(1024) != "+1.0K" || FormatDiff(-1024) != "-1.0K" {
+		t.Errorf("FormatDiff() = %q %q %q", FormatDiff(0), FormatDiff(1024), FormatDiff(-1024))
+	}
+}
@@ -0,0 +1,163 @@
+package size
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OtherGroup 没有分组的组件（工具链库、预编译库等）所属的分组
+const OtherGroup = "其他"
+
+// SymbolSize 一个符号占用的空间
+type SymbolSize struct {
+	Name    string `json:"name"`
+	Address uint64 `json:"address"`
+	Size    int    `json:"size"`
+	// 所属输出段
+	Section   string `json:"section"`
+	Component string `json:"component"`
+	Object    string `json:"object"`
+	Flash     bool   `json:"flash"`
+	RAM       bool   `json:"ram"`
+}
+
+// SymbolSizes 按 map 文件计算各符号的大小，按大小从大到小排序
+//
+// map 文件只列出全局符号，符号大小取到同一输入段中下一个符号的距离。
+// 没有列出符号的部分（静态函数、静态变量）按输入段命名：-ffunction-sections
+// 编译的 .text.foo 记为 foo，其余记为 目标文件:段名。
+func (r *Report) SymbolSizes(m *MapFile) []SymbolSize {
+	var symbols []SymbolSize
+	for _, in := range m.Inputs {
+		sec := r.section(in.Output)
+		if sec == nil || in.IsFill() || in.Size == 0 {
+			continue
+		}
+		add := func(name string, addr uint64, size int) {
+			if size <= 0 {
+				return
+			}
+			symbols = append(symbols, SymbolSize{
+				Name:      name,
+				Address:   addr,
+				Size:      size,
+				Section:   in.Output,
+				Component: in.Component(),
+				Object:    in.ObjectName(),
+				Flash:     sec.Flash,
+				RAM:       sec.RAM,
+			})
+		}
+
+		end := in.Address + uint64(in.Size)
+		var syms []Symbol
+		for _, s := range in.Symbols {
+			if s.Address >= in.Address && s.Address < end {
+				syms = append(syms, s)
+			}
+		}
+		sort.SliceStable(syms, func(i, j int) bool { return syms[i].Address < syms[j].Address })
+
+		if len(syms) == 0 || syms[0].Address > in.Address {
+			next := end
+			if len(syms) > 0 {
+				next = syms[0].Address
+			}
+			add(anonymousName(in), in.Address, int(next-in.Address))
+		}
+		for i, s := range syms {
+			next := end
+			if i+1 < len(syms) {
+				next = syms[i+1].Address
+			}
+			// 同一地址的别名只计算一次
+			add(s.Name, s.Address, int(next-s.Address))
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].Size != symbols[j].Size {
+			return symbols[i].Size > symbols[j].Size
+		}
+		return symbols[i].Name < symbols[j].Name
+	})
+	return symbols
+}
+
+// anonymousName 返回输入段中没有列出符号部分的名称
+func anonymousName(in InputSection) string {
+	for _, prefix := range []string{".text.", ".rodata.", ".data.", ".bss.", ".sdata.", ".sbss.", ".srodata.", ".tcm_code.", ".wifi_ram."} {
+		if name := strings.TrimPrefix(in.Name, prefix); name != in.Name && name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("%s:%s", in.Object, in.Name)
+}
+
+// Node 占用树中的一个节点：分组、组件、目标文件或符号
+type Node struct {
+	Name     string  `json:"name"`
+	Flash    int     `json:"flash"`
+	RAM      int     `json:"ram"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+// child 查找或创建子节点
+func (n *Node) child(name string) *Node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &Node{Name: name}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// add 累加占用
+func (n *Node) add(s SymbolSize) {
+	if s.Flash {
+		n.Flash += s.Size
+	}
+	if s.RAM {
+		n.RAM += s.Size
+	}
+}
+
+// sort 按总占用从大到小递归排序子节点
+func (n *Node) sort() {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.Flash+a.RAM != b.Flash+b.RAM {
+			return a.Flash+a.RAM > b.Flash+b.RAM
+		}
+		return a.Name < b.Name
+	})
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+// SymbolTree 按 分组 / 组件 / 目标文件 / 符号 汇总符号占用
+//
+// groups 为组件到分组的映射，不在映射中的组件归入 OtherGroup。
+func SymbolTree(symbols []SymbolSize, groups map[string]string) *Node {
+	root := &Node{}
+	for _, s := range symbols {
+		group, ok := groups[s.Component]
+		if !ok {
+			group = OtherGroup
+		}
+		path := []*Node{root}
+		n := root
+		for _, name := range []string{group, s.Component, s.Object, s.Name} {
+			n = n.child(name)
+			path = append(path, n)
+		}
+		for _, p := range path {
+			p.add(s)
+		}
+	}
+	root.sort()
+	return root
+}
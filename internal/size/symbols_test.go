@@ -0,0 +1,95 @@
+package size
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSymbolSizes(t *testing.T) {
+	report := testReport(t)
+	m := mustParseTestMap(t)
+	symbols := report.SymbolSizes(m)
+
+	sizes := map[string]SymbolSize{}
+	for _, s := range symbols {
+		sizes[s.Name] = s
+	}
+
+	tests := []struct {
+		name       string
+		size       int
+		flash, ram bool
+	}{
+		// 同一输入段中的两个符号按地址差分配
+		{"tcp_enqueue_flags_and_more", 0x100, true, false},
+		{"tcp_new", 0x100, true, false},
+		{"bl602_start", 0x2c, true, false},
+		{"main", 0x24, true, false},
+		{"counter", 0x40, true, true},
+		{"tcp_pcbs", 0x100, false, true},
+		// 没有列出符号的输入段按段名命名
+		{"flag", 0x10, false, true},
+		{"crt0.o:.text", 0x10, true, false},
+	}
+	for _, tt := range tests {
+		s, ok := sizes[tt.name]
+		if !ok {
+			t.Errorf("symbol %s not found in %+v", tt.name, symbols)
+			continue
+		}
+		if s.Size != tt.size || s.Flash != tt.flash || s.RAM != tt.ram {
+			t.Errorf("%s = %+v, want size %#x flash %v ram %v", tt.name, s, tt.size, tt.flash, tt.ram)
+		}
+	}
+	if len(symbols) != len(tests) {
+		t.Errorf("len(symbols) = %d, want %d: %+v", len(symbols), len(tests), symbols)
+	}
+	if symbols[0].Size < symbols[len(symbols)-1].Size {
+		t.Errorf("symbols not sorted by size: %+v", symbols)
+	}
+	if tcp := sizes["tcp_new"]; tcp.Component != "lwip" || tcp.Object != "liblwip.a(tcp.o)" || tcp.Section != ".text" {
+		t.Errorf("tcp_new = %+v", tcp)
+	}
+}
+
+func TestSymbolTree(t *testing.T) {
+	report := testReport(t)
+	symbols := report.SymbolSizes(mustParseTestMap(t))
+	root := SymbolTree(symbols, map[string]string{"lwip": "wifi", "bl602": "基础组件", "demo": "项目代码"})
+
+	if root.Flash != report.Components[0].Flash+report.Components[1].Flash+report.Components[2].Flash+report.Components[3].Flash {
+		t.Errorf("root.Flash = %#x, want sum of components", root.Flash)
+	}
+	var names []string
+	for _, c := range root.Children {
+		names = append(names, c.Name)
+	}
+	want := []string{"wifi", "项目代码", "基础组件", OtherGroup}
+	if len(names) != len(want) {
+		t.Fatalf("groups = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("groups = %v, want %v", names, want)
+			break
+		}
+	}
+
+	wifi := root.Children[0]
+	if wifi.Flash != 0x200 || wifi.RAM != 0x100 {
+		t.Errorf("wifi = %+v", wifi)
+	}
+	tcp := wifi.Children[0].Children[0]
+	if tcp.Name != "liblwip.a(tcp.o)" || len(tcp.Children) != 3 {
+		t.Errorf("tcp.o = %+v", tcp)
+	}
+}
+
+func mustParseTestMap(t *testing.T) *MapFile {
+	t.Helper()
+	m, err := ParseMap(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}